@@ -0,0 +1,146 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package analytics implements analytics snippets for HTML pages
+// and Content-Security-Policy sources required by them.
+package analytics
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Config is site.yml -> analytics:.
+type Config struct {
+	Provider   string            `yaml:"provider"`   // plausible, fathom, goatcounter, umami, or empty for custom script
+	Site       string            `yaml:"site"`       // domain (Plausible), site ID (Fathom, Umami) or code (GoatCounter)
+	Script     string            `yaml:"script"`     // script URL, e.g. of self-hosted instance
+	Attributes map[string]string `yaml:"attributes"` // additional script attributes
+	Connect    []string          `yaml:"connect"`    // additional connect-src sources
+}
+
+type provider struct {
+	script  string // default script URL
+	siteKey string // script attribute with site
+	connect string // connect-src source, empty if same as script origin
+}
+
+var providers = map[string]provider{
+	"plausible":   {"https://plausible.io/js/script.js", "data-domain", ""},
+	"fathom":      {"https://cdn.usefathom.com/script.js", "data-site", ""},
+	"goatcounter": {"https://gc.zgo.at/count.js", "", "https://%s.goatcounter.com"},
+	"umami":       {"https://cloud.umami.is/script.js", "data-website-id", ""},
+}
+
+func (c *Config) provider() (provider, error) {
+	if c.Provider == "" {
+		if c.Script == "" {
+			return provider{}, fmt.Errorf("analytics: script or provider must be set")
+		}
+		return provider{}, nil
+	}
+	p, ok := providers[c.Provider]
+	if !ok {
+		return provider{}, fmt.Errorf("analytics: unknown provider %q", c.Provider)
+	}
+	if c.Site == "" {
+		return provider{}, fmt.Errorf("analytics: site must be set for %s", c.Provider)
+	}
+	return p, nil
+}
+
+func (c *Config) scriptURL(p provider) string {
+	if c.Script != "" {
+		return c.Script
+	}
+	return p.script
+}
+
+// Snippet returns the script element to insert into pages.
+func (c *Config) Snippet() (string, error) {
+	p, err := c.provider()
+	if err != nil {
+		return "", err
+	}
+	attrs := make(map[string]string)
+	if p.siteKey != "" {
+		attrs[p.siteKey] = c.Site
+	}
+	if c.Provider == "goatcounter" {
+		attrs["data-goatcounter"] = fmt.Sprintf(p.connect, c.Site) + "/count"
+	}
+	for k, v := range c.Attributes {
+		attrs[k] = v
+	}
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(`<script defer src="` + html.EscapeString(c.scriptURL(p)) + `"`)
+	for _, k := range keys {
+		b.WriteString(" " + k + `="` + html.EscapeString(attrs[k]) + `"`)
+	}
+	b.WriteString("></script>")
+	return b.String(), nil
+}
+
+func origin(s string) (string, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "self", nil // relative URL of self-hosted script
+	}
+	scheme := u.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	return scheme + "://" + u.Host, nil
+}
+
+// Sources returns Content-Security-Policy sources by directive
+// that are required to load the script and send events.
+// Keywords are not quoted, as in csp.yml.
+func (c *Config) Sources() (map[string][]string, error) {
+	p, err := c.provider()
+	if err != nil {
+		return nil, err
+	}
+	scriptOrigin, err := origin(c.scriptURL(p))
+	if err != nil {
+		return nil, fmt.Errorf("analytics: %w", err)
+	}
+	connect := scriptOrigin
+	if p.connect != "" {
+		connect = fmt.Sprintf(p.connect, c.Site)
+	}
+	return map[string][]string{
+		"script-src":  {scriptOrigin},
+		"connect-src": append([]string{connect}, c.Connect...),
+	}, nil
+}
+
+// Inject inserts the snippet before the closing head tag of the HTML
+// document. Documents without head are returned unchanged.
+func Inject(data []byte, snippet string) []byte {
+	const tag = "</head>"
+	i := len(data) - len(tag)
+	for i >= 0 && !bytes.EqualFold(data[i:i+len(tag)], []byte(tag)) {
+		i--
+	}
+	if i < 0 {
+		return data
+	}
+	out := make([]byte, 0, len(data)+len(snippet))
+	out = append(out, data[:i]...)
+	out = append(out, snippet...)
+	return append(out, data[i:]...)
+}
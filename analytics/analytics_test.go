@@ -0,0 +1,73 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analytics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSnippet(t *testing.T) {
+	for _, v := range []struct {
+		c       Config
+		snippet string
+		sources map[string][]string
+	}{
+		{
+			Config{Provider: "plausible", Site: "example.com"},
+			`<script defer src="https://plausible.io/js/script.js" data-domain="example.com"></script>`,
+			map[string][]string{
+				"script-src":  {"https://plausible.io"},
+				"connect-src": {"https://plausible.io"},
+			},
+		},
+		{
+			Config{Provider: "goatcounter", Site: "ex"},
+			`<script defer src="https://gc.zgo.at/count.js" data-goatcounter="https://ex.goatcounter.com/count"></script>`,
+			map[string][]string{
+				"script-src":  {"https://gc.zgo.at"},
+				"connect-src": {"https://ex.goatcounter.com"},
+			},
+		},
+		{
+			Config{Script: "/stats/script.js", Attributes: map[string]string{"data-x": `a"b`}, Connect: []string{"https://api.example.com"}},
+			`<script defer src="/stats/script.js" data-x="a&#34;b"></script>`,
+			map[string][]string{
+				"script-src":  {"self"},
+				"connect-src": {"self", "https://api.example.com"},
+			},
+		},
+	} {
+		snippet, err := v.c.Snippet()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if snippet != v.snippet {
+			t.Errorf("snippet: got %s, want %s", snippet, v.snippet)
+		}
+		sources, err := v.c.Sources()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(sources, v.sources) {
+			t.Errorf("sources: got %v, want %v", sources, v.sources)
+		}
+	}
+	for _, c := range []Config{{}, {Provider: "plausible"}, {Provider: "unknown", Site: "x"}} {
+		if _, err := c.Snippet(); err == nil {
+			t.Errorf("%+v: expected error", c)
+		}
+	}
+}
+
+func TestInject(t *testing.T) {
+	got := string(Inject([]byte("<html><HEAD><title>Ünï</title></HEAD><body></body></html>"), "<s>"))
+	if want := "<html><HEAD><title>Ünï</title><s></HEAD><body></body></html>"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got := string(Inject([]byte("<p>fragment</p>"), "<s>")); got != "<p>fragment</p>" {
+		t.Errorf("fragment changed: %s", got)
+	}
+}
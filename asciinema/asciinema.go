@@ -0,0 +1,110 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package asciinema implements parsing of asciinema cast files
+// (versions 2 and 3) and rendering of player embeds.
+package asciinema
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Class is the class of player container elements.
+const Class = "kkr-asciinema"
+
+// Cast describes a terminal session recording.
+type Cast struct {
+	Version  int
+	Cols     int
+	Rows     int
+	Title    string
+	Duration float64 // in seconds
+}
+
+type header struct {
+	Version int    `json:"version"`
+	Width   int    `json:"width"`  // v2
+	Height  int    `json:"height"` // v2
+	Title   string `json:"title"`
+	Term    struct {
+		Cols int `json:"cols"`
+		Rows int `json:"rows"`
+	} `json:"term"` // v3
+}
+
+var errFormat = errors.New("asciinema: bad cast format")
+
+// Parse parses a cast file in asciicast v2 or v3 format.
+func Parse(data []byte) (*Cast, error) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, 1<<20)
+	if !sc.Scan() {
+		return nil, errFormat
+	}
+	var h header
+	if err := json.Unmarshal(sc.Bytes(), &h); err != nil {
+		return nil, fmt.Errorf("asciinema: header: %w", err)
+	}
+	c := &Cast{Version: h.Version, Title: h.Title}
+	switch h.Version {
+	case 2:
+		c.Cols, c.Rows = h.Width, h.Height
+	case 3:
+		c.Cols, c.Rows = h.Term.Cols, h.Term.Rows
+	default:
+		return nil, fmt.Errorf("asciinema: unsupported version %d", h.Version)
+	}
+	// Calculate duration from event times, which are absolute in v2
+	// and relative to the previous event in v3.
+	var t float64
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		var ev []interface{}
+		if err := json.Unmarshal(line, &ev); err != nil || len(ev) == 0 {
+			return nil, errFormat
+		}
+		et, ok := ev[0].(float64)
+		if !ok {
+			return nil, errFormat
+		}
+		if h.Version == 2 {
+			t = et
+		} else {
+			t += et
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	c.Duration = t
+	return c, nil
+}
+
+// PlayerHTML returns a container element for asciinema-player with
+// the cast URL and terminal size in data attributes, and a link to
+// the cast file as a fallback.
+func (c *Cast) PlayerHTML(url string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<div class="%s" data-src="%s" data-cols="%d" data-rows="%d"`,
+		Class, html.EscapeString(url), c.Cols, c.Rows)
+	if c.Title != "" {
+		fmt.Fprintf(&b, ` data-title="%s"`, html.EscapeString(c.Title))
+	}
+	title := c.Title
+	if title == "" {
+		title = "terminal session"
+	}
+	fmt.Fprintf(&b, `><noscript><a href="%s">Download %s recording</a></noscript></div>`,
+		html.EscapeString(url), html.EscapeString(title))
+	return b.String()
+}
@@ -0,0 +1,47 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package asciinema
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in       string
+		cols     int
+		rows     int
+		duration float64
+	}{
+		{`{"version": 2, "width": 80, "height": 24, "title": "Demo"}
+[0.5, "o", "hello"]
+[1.75, "o", " world"]
+`, 80, 24, 1.75},
+		{`{"version": 3, "term": {"cols": 100, "rows": 30}}
+# comment
+[0.5, "o", "hello"]
+[1.25, "o", " world"]
+`, 100, 30, 1.75},
+	}
+	for i, v := range tests {
+		c, err := Parse([]byte(v.in))
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if c.Cols != v.cols || c.Rows != v.rows || c.Duration != v.duration {
+			t.Errorf("%d: got %dx%d %g, expected %dx%d %g", i, c.Cols, c.Rows, c.Duration, v.cols, v.rows, v.duration)
+		}
+	}
+	if _, err := Parse([]byte(`{"version": 1}`)); err == nil {
+		t.Errorf("expected error for version 1")
+	}
+}
+
+func TestPlayerHTML(t *testing.T) {
+	c := &Cast{Cols: 80, Rows: 24, Title: "Demo"}
+	expected := `<div class="kkr-asciinema" data-src="/demo.cast" data-cols="80" data-rows="24" data-title="Demo">` +
+		`<noscript><a href="/demo.cast">Download Demo recording</a></noscript></div>`
+	if out := c.PlayerHTML("/demo.cast"); out != expected {
+		t.Errorf("got\n%s\nexpected\n%s", out, expected)
+	}
+}
@@ -7,14 +7,20 @@ package assets
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/dchest/kkr/filewriter"
 	"github.com/dchest/kkr/filters"
+	"github.com/dchest/kkr/sourcemap"
 	"github.com/dchest/kkr/utils"
 )
 
@@ -26,6 +32,10 @@ type Asset struct {
 	Files     []string    `yaml:"files"`
 	Separator string      `yaml:"separator,omitempty"`
 	OutName   string      `yaml:"outname"`
+	SourceMap bool        `yaml:"sourcemap,omitempty"` // write .map file for minified JS or CSS
+	Variants  []Variant   `yaml:"variants,omitempty"`  // CSS variants, OutName must contain :variant
+	Type      string      `yaml:"type,omitempty"`      // "css" or "js" for inlining, guessed from names if empty
+	CSP       bool        `yaml:"csp,omitempty"`       // add hash of inlined content to CSP
 
 	// RenderedName is the output filename of the asset,
 	// or an empty string if OutName is "$".
@@ -34,51 +44,208 @@ type Asset struct {
 	// Result is the processed content of asset.
 	Result []byte
 
+	// Map is the source map of Result, if SourceMap is true.
+	Map []byte
+
+	dir       string            // directory of assets file, for paths in filter arguments
+	vars      map[string]string // CSS custom properties of variant
 	processed bool
 }
 
+// Variant is a variant of CSS asset built from the same files
+// with different values of CSS custom properties, for example,
+// light and dark themes.
+type Variant struct {
+	Name string            `yaml:"name"`
+	Vars map[string]string `yaml:"vars"` // custom property names without "--" -> values
+}
+
+// VariantName returns the name of asset variant.
+func VariantName(name, variant string) string {
+	return name + ":" + variant
+}
+
+// expandVariants returns assets for variants of the asset,
+// or the asset itself if it has no variants.
+func expandVariants(a *Asset) ([]*Asset, error) {
+	if len(a.Variants) == 0 {
+		return []*Asset{a}, nil
+	}
+	if !a.IsBuffered() && !strings.Contains(a.OutName, ":variant") {
+		return nil, fmt.Errorf("asset %q has variants, but its outname doesn't contain :variant", a.Name)
+	}
+	assets := make([]*Asset, len(a.Variants))
+	for i, v := range a.Variants {
+		if v.Name == "" {
+			return nil, fmt.Errorf("asset %q has variant without name", a.Name)
+		}
+		va := *a
+		va.Name = VariantName(a.Name, v.Name)
+		va.OutName = strings.Replace(a.OutName, ":variant", v.Name, -1)
+		va.Variants = nil
+		va.vars = v.Vars
+		assets[i] = &va
+	}
+	return assets, nil
+}
+
+// varsBlock returns CSS rule declaring custom properties
+// of the variant, or an empty string if it's not a variant.
+func (a *Asset) varsBlock() string {
+	if len(a.vars) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(a.vars))
+	for k := range a.vars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString(":root{")
+	for _, k := range names {
+		fmt.Fprintf(&b, "--%s:%s;", strings.TrimPrefix(k, "--"), a.vars[k])
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
 // IsBuffered returns true if the output of asset
 // is a buffer (OutName starts with $).
 func (a *Asset) IsBuffered() bool {
 	return isBufferName(a.OutName)
 }
 
+var kindsByExt = map[string]string{
+	".css":  "css",
+	".scss": "css",
+	".sass": "css",
+	".less": "css",
+	".js":   "js",
+	".mjs":  "js",
+	".jsx":  "js",
+	".ts":   "js",
+	".tsx":  "js",
+}
+
+// Kind returns "css" or "js" depending on Type or, if it's empty,
+// on the extension of OutName or of the first file that has one.
+// It returns an empty string if the kind is unknown.
+func (a *Asset) Kind() string {
+	if a.Type != "" {
+		return a.Type
+	}
+	if !a.IsBuffered() {
+		if k := kindsByExt[strings.ToLower(path.Ext(a.OutName))]; k != "" {
+			return k
+		}
+	}
+	for _, f := range a.Files {
+		if k := kindsByExt[strings.ToLower(filepath.Ext(f))]; k != "" {
+			return k
+		}
+	}
+	return ""
+}
+
 type Collection struct {
-	assets  map[string]*Asset
-	filters *filters.Collection
+	assets   map[string]*Asset
+	defaults map[string]string // name of asset with variants -> its first variant
+	filters  *filters.Collection
 }
 
-// Load loads an asset collection from the given assets config file and returns it.
-func Load(filename string) (c *Collection, err error) {
-	// Load assets description from file (or create a new).
+// readAssets reads assets description from file. If dir is not empty,
+// names of asset files are relative to it.
+func readAssets(filename, dir string) ([]*Asset, error) {
 	var assets []*Asset
-	err = utils.UnmarshallYAMLFile(filename, &assets)
-	if err != nil {
+	if err := utils.UnmarshallYAMLFile(filename, &assets); err != nil {
 		if os.IsNotExist(err) {
-			// No assets file is not an error,
-			// create an empty collection.
-			assets = make([]*Asset, 0)
-			err = nil
-		} else {
-			return
+			// No assets file is not an error.
+			return nil, nil
+		}
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var expanded []*Asset
+	for _, v := range assets {
+		if seen[v.Name] {
+			return nil, fmt.Errorf("duplicate asset name %q", v.Name)
 		}
+		seen[v.Name] = true
+		if v.Type != "" && v.Type != "css" && v.Type != "js" {
+			return nil, fmt.Errorf("asset %q: unknown type %q", v.Name, v.Type)
+		}
+		if dir != "" {
+			for i, name := range v.Files {
+				if !isBufferName(name) {
+					v.Files[i] = filepath.Join(dir, name)
+				}
+			}
+		}
+		v.dir = filepath.Dir(filename)
+		variants, err := expandVariants(v)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, variants...)
 	}
+	return expanded, nil
+}
 
+func newCollection(assets []*Asset) *Collection {
 	// Put assets into a map addressed by name and load filters.
-	c = &Collection{
-		assets:  make(map[string]*Asset),
-		filters: filters.NewCollection(),
+	c := &Collection{
+		assets:   make(map[string]*Asset),
+		defaults: make(map[string]string),
+		filters:  filters.NewCollection(),
 	}
 	for _, v := range assets {
-		if _, exists := c.assets[v.Name]; exists {
-			return nil, fmt.Errorf("duplicate asset name %q", v.Name)
-		}
 		c.assets[v.Name] = v
+		if v.vars != nil {
+			base := v.Name[:strings.LastIndexByte(v.Name, ':')]
+			if _, ok := c.defaults[base]; !ok {
+				c.defaults[base] = v.Name
+			}
+		}
 		if v.Filter != nil {
 			c.filters.AddFromYAML(v.Name, v.Filter)
+			c.filters.SetDir(v.Name, v.dir)
+		}
+	}
+	return c
+}
+
+// Load loads an asset collection from the given assets config file and returns it.
+func Load(filename string) (c *Collection, err error) {
+	assets, err := readAssets(filename, "")
+	if err != nil {
+		return nil, err
+	}
+	return newCollection(assets), nil
+}
+
+// LoadWithTheme loads an asset collection from the theme's assets
+// config file with the same name in themeDir, with names of files
+// relative to themeDir, and then from the given file. Assets from
+// the latter replace theme assets with the same names.
+func LoadWithTheme(filename, themeDir string) (c *Collection, err error) {
+	themeAssets, err := readAssets(filepath.Join(themeDir, filepath.Base(filename)), themeDir)
+	if err != nil {
+		return nil, err
+	}
+	assets, err := readAssets(filename, "")
+	if err != nil {
+		return nil, err
+	}
+	own := make(map[string]bool)
+	for _, v := range assets {
+		own[v.Name] = true
+	}
+	for _, v := range themeAssets {
+		if !own[v.Name] {
+			assets = append(assets, v)
 		}
 	}
-	return c, nil
+	return newCollection(assets), nil
 }
 
 // Process processes all assets in the collection.
@@ -113,9 +280,49 @@ func (c *Collection) SetStringAsset(name, data string) {
 	}
 }
 
+// Names returns sorted names of assets, with variants named as
+// returned by VariantName.
+func (c *Collection) Names() []string {
+	names := make([]string, 0, len(c.assets))
+	for name := range c.assets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Get returns an asset by name or nil if there's no such asset.
+// For assets with variants, it returns the first variant.
 func (c *Collection) Get(name string) *Asset {
-	return c.assets[name]
+	if a, ok := c.assets[name]; ok {
+		return a
+	}
+	return c.assets[c.defaults[name]]
+}
+
+// CSPSources returns hashes of processed assets which have CSP
+// enabled by directives for inline styles and scripts.
+func (c *Collection) CSPSources() (map[string][]string, error) {
+	names := c.Names()
+	m := make(map[string][]string)
+	for _, name := range names {
+		a := c.assets[name]
+		if !a.CSP {
+			continue
+		}
+		var directive string
+		switch a.Kind() {
+		case "css":
+			directive = "style-src"
+		case "js":
+			directive = "script-src"
+		default:
+			return nil, fmt.Errorf("asset %q: unknown type for CSP hash, set type: css or js", name)
+		}
+		sum := sha256.Sum256(a.Result)
+		m[directive] = append(m[directive], "sha256-"+base64.StdEncoding.EncodeToString(sum[:]))
+	}
+	return m, nil
 }
 
 func isBufferName(s string) bool {
@@ -135,24 +342,40 @@ func readFile(w io.Writer, filename string) error {
 }
 
 func (c *Collection) ProcessAsset(a *Asset, filters *filters.Collection) error {
+	return c.processAsset(a, filters, nil)
+}
+
+// processAsset processes the asset referenced by the chain of assets
+// being processed, which is used to detect circular references.
+func (c *Collection) processAsset(a *Asset, filters *filters.Collection, chain []string) error {
 	if a.processed {
 		return nil
 	}
+	for i, name := range chain {
+		if name == a.Name {
+			return fmt.Errorf("circular asset reference: %s -> %s", strings.Join(chain[i:], " -> "), a.Name)
+		}
+	}
+	chain = append(chain, a.Name)
 	separator := a.Separator
 	// Concatenate files and buffers.
 	var buf bytes.Buffer
+	var parts []sourcemap.Part
+	if vars := a.varsBlock(); vars != "" {
+		buf.WriteString(vars)
+		if a.SourceMap {
+			parts = append(parts, sourcemap.Part{Content: vars})
+		}
+	}
 	for i, name := range a.Files {
+		start := buf.Len()
 		if isBufferName(name) {
 			refAsset := c.Get(name[1:]) // e.g. $global-style -> global-style
 			if refAsset == nil {
 				return fmt.Errorf("asset %q not found", name[1:])
 			}
-			if !refAsset.processed {
-				// Process it.
-				// BUG Here will hang if we can have a circular reference.
-				if err := c.ProcessAsset(refAsset, filters); err != nil {
-					return err
-				}
+			if err := c.processAsset(refAsset, filters, chain); err != nil {
+				return err
 			}
 			buf.Write(refAsset.Result)
 		} else {
@@ -160,8 +383,14 @@ func (c *Collection) ProcessAsset(a *Asset, filters *filters.Collection) error {
 				return err
 			}
 		}
+		if a.SourceMap {
+			parts = append(parts, sourcemap.Part{Name: filepath.ToSlash(name), Content: string(buf.Bytes()[start:])})
+		}
 		if i != len(a.Files)-1 {
 			buf.WriteString(separator)
+			if a.SourceMap {
+				parts = append(parts, sourcemap.Part{Content: separator})
+			}
 		}
 	}
 	// Filter result.
@@ -169,6 +398,14 @@ func (c *Collection) ProcessAsset(a *Asset, filters *filters.Collection) error {
 	if err != nil {
 		return err
 	}
+	// Filters which produce source maps, such as esbuild,
+	// inline them; move them into the .map file.
+	var filterMap []byte
+	if a.SourceMap {
+		if content, m, ok := sourcemap.Extract(b); ok {
+			b, filterMap = content, m
+		}
+	}
 	a.Result = b
 	if a.IsBuffered() {
 		a.RenderedName = ""
@@ -177,6 +414,22 @@ func (c *Collection) ProcessAsset(a *Asset, filters *filters.Collection) error {
 		if a.RenderedName == "" {
 			return fmt.Errorf("templated hash for asset %s returned empty result", a.Name)
 		}
+		if a.SourceMap {
+			syntax := sourcemap.JS
+			if strings.EqualFold(path.Ext(a.RenderedName), ".css") {
+				syntax = sourcemap.CSS
+			}
+			mapName := path.Base(a.RenderedName) + ".map"
+			if filterMap != nil {
+				a.Map = filterMap
+			} else {
+				a.Map, err = sourcemap.Generate(path.Base(a.RenderedName), parts, string(b), syntax)
+				if err != nil {
+					return fmt.Errorf("source map for asset %s: %w", a.Name, err)
+				}
+			}
+			a.Result = append(b, sourcemap.Comment(mapName, syntax)...)
+		}
 	}
 	a.processed = true
 	return nil
@@ -188,5 +441,10 @@ func (c Collection) RenderAsset(a *Asset, fw *filewriter.FileWriter, outdir stri
 	}
 	log.Printf("A %s", a.RenderedName)
 	outfile := filepath.Join(outdir, filepath.FromSlash(a.RenderedName))
+	if a.Map != nil {
+		if err := fw.WriteFile(outfile+".map", a.Map); err != nil {
+			return err
+		}
+	}
 	return fw.WriteFile(outfile, a.Result)
 }
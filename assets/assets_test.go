@@ -0,0 +1,103 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package assets
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadTest writes files into a temporary directory
+// and loads assets.yml from it.
+func loadTest(t *testing.T, files map[string]string) *Collection {
+	dir, err := ioutil.TempDir("", "kkr-assets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assets, err := readAssets(filepath.Join(dir, "assets.yml"), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newCollection(assets)
+}
+
+func TestNestedBuffers(t *testing.T) {
+	c := loadTest(t, map[string]string{
+		"assets.yml": `
+- name: all
+  files: [a.css, $inner]
+  separator: "\n"
+  outname: /all-:hash.css
+- name: inner
+  files: [$innermost, b.css]
+  outname: $
+- name: innermost
+  files: [c.css]
+  outname: $
+`,
+		"a.css": "a{}",
+		"b.css": "b{}",
+		"c.css": "c{}",
+	})
+	if err := c.Process(); err != nil {
+		t.Fatal(err)
+	}
+	if s := string(c.Get("all").Result); s != "a{}\nc{}b{}" {
+		t.Errorf("all: got %q", s)
+	}
+	if s := string(c.Get("inner").Result); s != "c{}b{}" {
+		t.Errorf("inner: got %q", s)
+	}
+	if c.Get("inner").RenderedName != "" {
+		t.Errorf("buffered asset has rendered name")
+	}
+	if !strings.HasPrefix(c.Get("all").RenderedName, "/all-") {
+		t.Errorf("all: bad rendered name %q", c.Get("all").RenderedName)
+	}
+}
+
+func TestCircularReference(t *testing.T) {
+	c := loadTest(t, map[string]string{
+		"assets.yml": `
+- name: a
+  files: [x.css, $b]
+  outname: /a.css
+- name: b
+  files: [$c]
+  outname: $
+- name: c
+  files: [$b]
+  outname: $
+`,
+		"x.css": "x{}",
+	})
+	err := c.ProcessAsset(c.Get("a"), c.filters)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	expected := "circular asset reference: b -> c -> b"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err)
+	}
+	c = loadTest(t, map[string]string{
+		"assets.yml": `
+- name: self
+  files: [$self]
+  outname: $
+`,
+	})
+	if err := c.Process(); err == nil || !strings.Contains(err.Error(), "self -> self") {
+		t.Errorf("self reference: got %v", err)
+	}
+}
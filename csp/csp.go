@@ -3,6 +3,7 @@ package csp
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"regexp"
 	"sort"
@@ -19,6 +20,14 @@ func (d Directives) String() string {
 
 // Load loads an CSP definition from the file and returns it.
 func Load(filename string) (d Directives, err error) {
+	return LoadWithSources(filename, nil)
+}
+
+// LoadWithSources loads an CSP definition from the file and adds
+// sources to the given directives. Directives that are not defined
+// are created from default-src sources, so that they still restrict
+// other resources. Empty definition stays empty.
+func LoadWithSources(filename string, sources map[string][]string) (d Directives, err error) {
 	m := make(map[string][]string)
 	err = utils.UnmarshallYAMLFile(filename, &m)
 	if err != nil {
@@ -30,9 +39,38 @@ func Load(filename string) (d Directives, err error) {
 			return
 		}
 	}
+	if len(m) > 0 {
+		addSources(m, sources)
+	}
 	return Directives(directivesToString(m)), nil
 }
 
+func addSources(m map[string][]string, sources map[string][]string) {
+	for k, values := range sources {
+		list, ok := m[k]
+		if !ok {
+			def, ok := m["default-src"]
+			if !ok {
+				continue // not restricted
+			}
+			list = append([]string(nil), def...)
+		}
+		if len(list) == 1 && list[0] == "none" {
+			list = nil
+		}
+	next:
+		for _, v := range values {
+			for _, e := range list {
+				if e == v {
+					continue next
+				}
+			}
+			list = append(list, v)
+		}
+		m[k] = list
+	}
+}
+
 var quotableKeyword = regexp.MustCompile("^((none|self|unsafe-inline|unsafe-eval|strict-dynamic|unsafe-hashes|report-sample|unsafe-allow-redirects)|(nonce-.*|sha(256|384|512)-.*))$")
 
 func quoteValues(a []string) []string {
@@ -63,3 +101,79 @@ func directivesToString(m map[string][]string) string {
 	}
 	return strings.Join(out, ";")
 }
+
+// parse returns sources of directives.
+func (d Directives) parse() map[string][]string {
+	m := make(map[string][]string)
+	for _, s := range strings.Split(string(d), ";") {
+		f := strings.Fields(s)
+		if len(f) > 0 {
+			m[strings.ToLower(f[0])] = f[1:]
+		}
+	}
+	return m
+}
+
+// WithSources returns directives with sources added to the given
+// directives like LoadWithSources does. Empty directives stay empty.
+func (d Directives) WithSources(sources map[string][]string) Directives {
+	if len(d) == 0 || len(sources) == 0 {
+		return d
+	}
+	m := d.parse()
+	for k, values := range m {
+		for i, v := range values {
+			if len(v) > 1 && strings.HasPrefix(v, "'") && strings.HasSuffix(v, "'") {
+				values[i] = v[1 : len(v)-1]
+			}
+		}
+		m[k] = values
+	}
+	addSources(m, sources)
+	return Directives(directivesToString(m))
+}
+
+// Allows returns true if the directive (or default-src if it's not
+// defined) allows loading the resource with the absolute URL from
+// another origin. Keywords, nonces and hashes are not considered.
+func (d Directives) Allows(directive, rawURL string) bool {
+	m := d.parse()
+	sources, ok := m[directive]
+	if !ok {
+		if sources, ok = m["default-src"]; !ok {
+			return true // not restricted
+		}
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	scheme := u.Scheme
+	if scheme == "" {
+		scheme = "https" // protocol-relative URL
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, s := range sources {
+		if s == "*" || strings.EqualFold(s, scheme+":") {
+			return true
+		}
+		if strings.HasPrefix(s, "'") {
+			continue // keyword
+		}
+		if i := strings.Index(s, "://"); i >= 0 {
+			if !strings.EqualFold(s[:i], scheme) {
+				continue
+			}
+			s = s[i+3:]
+		}
+		// Ignore port and path.
+		if i := strings.IndexAny(s, ":/"); i >= 0 {
+			s = s[:i]
+		}
+		s = strings.ToLower(s)
+		if s == host || strings.HasPrefix(s, "*.") && strings.HasSuffix(host, s[1:]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package csp
+
+import "testing"
+
+func TestAllows(t *testing.T) {
+	d := Directives("default-src 'self';frame-src https://www.youtube.com *.vimeo.com;img-src https:")
+	var tests = []struct {
+		directive, url string
+		allowed        bool
+	}{
+		{"frame-src", "https://www.youtube.com/embed/x", true},
+		{"frame-src", "https://player.vimeo.com/video/1", true},
+		{"frame-src", "https://example.com/", false},
+		{"frame-src", "http://www.youtube.com/embed/x", false},
+		{"img-src", "https://any.example.com/x.png", true},
+		{"script-src", "https://cdn.example.com/x.js", false},
+		{"script-src", "//cdn.example.com/x.js", false},
+	}
+	for _, v := range tests {
+		if got := d.Allows(v.directive, v.url); got != v.allowed {
+			t.Errorf("%s %s: expected %v, got %v", v.directive, v.url, v.allowed, got)
+		}
+	}
+	if !Directives("").Allows("script-src", "https://example.com/x.js") {
+		t.Errorf("empty CSP doesn't allow")
+	}
+}
+
+func TestWithSources(t *testing.T) {
+	d := Directives("default-src 'self';img-src 'none'")
+	got := d.WithSources(map[string][]string{
+		"script-src": {"sha256-abc="},
+		"img-src":    {"data:"},
+	}).String()
+	expected := "default-src 'self';img-src data:;script-src 'self' 'sha256-abc='"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if s := Directives("").WithSources(map[string][]string{"script-src": {"self"}}); s != "" {
+		t.Errorf("empty CSP changed: %q", s)
+	}
+}
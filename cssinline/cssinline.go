@@ -0,0 +1,534 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cssinline implements moving CSS rules from style elements
+// into style attributes of matching elements, as required by many
+// email clients.
+//
+// Supported selectors consist of type, universal, class, ID and
+// attribute selectors, combined with descendant and child combinators.
+// Rules with other selectors, such as pseudo-classes, and at-rules,
+// such as @media, are left in style elements.
+package cssinline
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type declaration struct {
+	property  string
+	value     string
+	important bool
+}
+
+type attrSelector struct {
+	key, value string
+	hasValue   bool
+}
+
+// compound is a compound selector, such as "p.note#x".
+type compound struct {
+	tag     string // empty or "*" for any
+	id      string
+	classes []string
+	attrs   []attrSelector
+}
+
+type selector struct {
+	parts []compound
+	combs []byte // combinators between parts: ' ' or '>'
+}
+
+type rule struct {
+	sel          selector
+	specificity  int
+	order        int
+	declarations []declaration
+}
+
+// Inline moves rules from style elements of HTML document into style
+// attributes. Declarations in existing style attributes take precedence
+// over rules, except for !important ones.
+func Inline(in []byte) ([]byte, error) {
+	doc, err := html.Parse(bytes.NewReader(in))
+	if err != nil {
+		return nil, err
+	}
+	var rules []rule
+	var styles []*html.Node
+	walk(doc, func(n *html.Node) {
+		if n.DataAtom == atom.Style && isInlineable(n) {
+			styles = append(styles, n)
+		}
+	})
+	for _, n := range styles {
+		var text strings.Builder
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			text.WriteString(c.Data)
+		}
+		var kept string
+		rules, kept = parseStylesheet(text.String(), rules)
+		if strings.TrimSpace(kept) == "" {
+			n.Parent.RemoveChild(n)
+			continue
+		}
+		for n.FirstChild != nil {
+			n.RemoveChild(n.FirstChild)
+		}
+		n.AppendChild(&html.Node{Type: html.TextNode, Data: kept})
+	}
+	if len(rules) == 0 {
+		return in, nil
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].specificity != rules[j].specificity {
+			return rules[i].specificity < rules[j].specificity
+		}
+		return rules[i].order < rules[j].order
+	})
+	walk(body(doc), func(n *html.Node) {
+		var decls []declaration
+		for _, r := range rules {
+			if r.sel.match(n, len(r.sel.parts)-1) {
+				decls = append(decls, r.declarations...)
+			}
+		}
+		if len(decls) > 0 {
+			setStyle(n, decls)
+		}
+	})
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// isInlineable returns true if the style element applies to all media
+// and is not marked with data-inline="false".
+func isInlineable(n *html.Node) bool {
+	for _, a := range n.Attr {
+		switch a.Key {
+		case "media":
+			if m := strings.TrimSpace(strings.ToLower(a.Val)); m != "" && m != "all" && m != "screen" {
+				return false
+			}
+		case "data-inline":
+			if a.Val == "false" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// body returns the body element of the document.
+func body(doc *html.Node) *html.Node {
+	var b *html.Node
+	walk(doc, func(n *html.Node) {
+		if b == nil && n.DataAtom == atom.Body {
+			b = n
+		}
+	})
+	return b
+}
+
+func walk(n *html.Node, fn func(n *html.Node)) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.ElementNode {
+			fn(c)
+		}
+		walk(c, fn)
+		c = next
+	}
+}
+
+// setStyle sets style attribute of the element to rule declarations
+// merged with its existing style.
+func setStyle(n *html.Node, decls []declaration) {
+	idx := -1
+	for i, a := range n.Attr {
+		if a.Key == "style" && a.Namespace == "" {
+			idx = i
+			break
+		}
+	}
+	var own []declaration
+	if idx >= 0 {
+		own = parseDeclarations(n.Attr[idx].Val)
+	}
+	var order []string
+	values := make(map[string]declaration)
+	set := func(d declaration) {
+		old, ok := values[d.property]
+		if !ok {
+			order = append(order, d.property)
+		} else if old.important && !d.important {
+			return
+		}
+		values[d.property] = d
+	}
+	for _, d := range decls {
+		set(d)
+	}
+	for _, d := range own {
+		set(d)
+	}
+	parts := make([]string, 0, len(order))
+	for _, p := range order {
+		d := values[p]
+		v := d.property + ": " + d.value
+		if d.important {
+			v += " !important"
+		}
+		parts = append(parts, v)
+	}
+	style := strings.Join(parts, "; ")
+	if idx >= 0 {
+		n.Attr[idx].Val = style
+	} else {
+		n.Attr = append(n.Attr, html.Attribute{Key: "style", Val: style})
+	}
+}
+
+// stripComments removes CSS comments.
+func stripComments(s string) string {
+	for {
+		i := strings.Index(s, "/*")
+		if i < 0 {
+			return s
+		}
+		j := strings.Index(s[i+2:], "*/")
+		if j < 0 {
+			return s[:i]
+		}
+		s = s[:i] + " " + s[i+2+j+2:]
+	}
+}
+
+// blockEnd returns the position after the closing brace of the block
+// which starts at i with an opening brace.
+func blockEnd(s string, i int) int {
+	depth := 0
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case '"', '\'':
+			if j := strings.IndexByte(s[i+1:], s[i]); j >= 0 {
+				i += j + 1
+			}
+		}
+	}
+	return len(s)
+}
+
+// parseStylesheet appends inlineable rules of the stylesheet to rules,
+// and returns the rest of the stylesheet.
+func parseStylesheet(css string, rules []rule) ([]rule, string) {
+	css = stripComments(css)
+	var kept strings.Builder
+	for i := 0; i < len(css); {
+		for i < len(css) && isSpace(css[i]) {
+			i++
+		}
+		if i >= len(css) {
+			break
+		}
+		brace := strings.IndexByte(css[i:], '{')
+		if css[i] == '@' {
+			semi := strings.IndexByte(css[i:], ';')
+			end := len(css)
+			if semi >= 0 && (brace < 0 || semi < brace) {
+				end = i + semi + 1
+			} else if brace >= 0 {
+				end = blockEnd(css, i+brace)
+			}
+			kept.WriteString(strings.TrimSpace(css[i:end]) + "\n")
+			i = end
+			continue
+		}
+		if brace < 0 {
+			break
+		}
+		selectors := css[i : i+brace]
+		end := blockEnd(css, i+brace)
+		body := strings.TrimSuffix(css[i+brace+1:end], "}")
+		i = end
+		decls := parseDeclarations(body)
+		var rest []string
+		for _, s := range strings.Split(selectors, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			sel, ok := parseSelector(s)
+			if !ok {
+				rest = append(rest, s)
+				continue
+			}
+			rules = append(rules, rule{
+				sel:          sel,
+				specificity:  sel.specificity(),
+				order:        len(rules),
+				declarations: decls,
+			})
+		}
+		if len(rest) > 0 {
+			kept.WriteString(strings.Join(rest, ", ") + " { " + strings.TrimSpace(body) + " }\n")
+		}
+	}
+	return rules, kept.String()
+}
+
+// parseDeclarations parses declarations separated by semicolons.
+func parseDeclarations(s string) []declaration {
+	var decls []declaration
+	for _, d := range splitDeclarations(s) {
+		i := strings.IndexByte(d, ':')
+		if i < 0 {
+			continue
+		}
+		p := strings.ToLower(strings.TrimSpace(d[:i]))
+		v := strings.TrimSpace(d[i+1:])
+		important := false
+		if j := strings.LastIndexByte(v, '!'); j >= 0 && strings.EqualFold(strings.TrimSpace(v[j+1:]), "important") {
+			important = true
+			v = strings.TrimSpace(v[:j])
+		}
+		if p == "" || v == "" {
+			continue
+		}
+		decls = append(decls, declaration{p, v, important})
+	}
+	return decls
+}
+
+// splitDeclarations splits s by semicolons outside of
+// quotes and parentheses, such as in data URLs.
+func splitDeclarations(s string) []string {
+	var out []string
+	depth, last := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case '"', '\'':
+			if j := strings.IndexByte(s[i+1:], s[i]); j >= 0 {
+				i += j + 1
+			}
+		case ';':
+			if depth == 0 {
+				out = append(out, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(out, s[last:])
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isNameChar(c byte) bool {
+	return c == '-' || c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// parseSelector parses a complex selector. It returns false
+// if the selector is not supported.
+func parseSelector(s string) (sel selector, ok bool) {
+	i := 0
+	name := func() string {
+		j := i
+		for i < len(s) && isNameChar(s[i]) {
+			i++
+		}
+		return s[j:i]
+	}
+	for i < len(s) {
+		var c compound
+		start := i
+		if s[i] == '*' {
+			c.tag = "*"
+			i++
+		} else if isNameChar(s[i]) {
+			c.tag = strings.ToLower(name())
+		}
+	loop:
+		for i < len(s) {
+			switch s[i] {
+			case '.':
+				i++
+				n := name()
+				if n == "" {
+					return sel, false
+				}
+				c.classes = append(c.classes, n)
+			case '#':
+				i++
+				if c.id = name(); c.id == "" {
+					return sel, false
+				}
+			case '[':
+				j := strings.IndexByte(s[i:], ']')
+				if j < 0 {
+					return sel, false
+				}
+				a, ok := parseAttrSelector(s[i+1 : i+j])
+				if !ok {
+					return sel, false
+				}
+				c.attrs = append(c.attrs, a)
+				i += j + 1
+			default:
+				break loop
+			}
+		}
+		if i == start {
+			return sel, false // unsupported character
+		}
+		sel.parts = append(sel.parts, c)
+		comb := byte(0)
+		for i < len(s) && (isSpace(s[i]) || s[i] == '>') {
+			if s[i] == '>' {
+				if comb == '>' {
+					return sel, false
+				}
+				comb = '>'
+			} else if comb == 0 {
+				comb = ' '
+			}
+			i++
+		}
+		if i == len(s) {
+			if comb == '>' {
+				return sel, false
+			}
+			break
+		}
+		if comb == 0 {
+			return sel, false
+		}
+		sel.combs = append(sel.combs, comb)
+	}
+	return sel, len(sel.parts) > 0
+}
+
+// parseAttrSelector parses attribute selector without brackets,
+// supporting only presence and equality.
+func parseAttrSelector(s string) (attrSelector, bool) {
+	var a attrSelector
+	i := strings.IndexByte(s, '=')
+	if i < 0 {
+		a.key = strings.ToLower(strings.TrimSpace(s))
+		return a, a.key != ""
+	}
+	a.key = strings.ToLower(strings.TrimSpace(s[:i]))
+	if a.key == "" || strings.ContainsAny(a.key, "~|^$*") {
+		return a, false
+	}
+	v := strings.TrimSpace(s[i+1:])
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		v = v[1 : len(v)-1]
+	}
+	a.value, a.hasValue = v, true
+	return a, true
+}
+
+// specificity returns specificity of the selector
+// as a number for comparison.
+func (sel selector) specificity() int {
+	var a, b, c int
+	for _, p := range sel.parts {
+		if p.id != "" {
+			a++
+		}
+		b += len(p.classes) + len(p.attrs)
+		if p.tag != "" && p.tag != "*" {
+			c++
+		}
+	}
+	return a*10000 + b*100 + c
+}
+
+// match returns true if the element matches the selector
+// up to and including its i-th part.
+func (sel selector) match(n *html.Node, i int) bool {
+	if !sel.parts[i].match(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	for p := n.Parent; p != nil && p.Type == html.ElementNode; p = p.Parent {
+		if sel.match(p, i-1) {
+			return true
+		}
+		if sel.combs[i-1] == '>' {
+			break
+		}
+	}
+	return false
+}
+
+func (c compound) match(n *html.Node) bool {
+	if c.tag != "" && c.tag != "*" && c.tag != n.Data {
+		return false
+	}
+	if c.id != "" && attr(n, "id") != c.id {
+		return false
+	}
+	if len(c.classes) > 0 {
+		classes := strings.Fields(attr(n, "class"))
+		for _, want := range c.classes {
+			found := false
+			for _, cl := range classes {
+				if cl == want {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	for _, a := range c.attrs {
+		v, ok := lookupAttr(n, a.key)
+		if !ok || (a.hasValue && v != a.value) {
+			return false
+		}
+	}
+	return true
+}
+
+func lookupAttr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key && a.Namespace == "" {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func attr(n *html.Node, key string) string {
+	v, _ := lookupAttr(n, key)
+	return v
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cssinline
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInline(t *testing.T) {
+	in := `<!DOCTYPE html><html><head><style>
+/* comment */
+p { color: red; margin: 0 }
+.note { color: blue }
+div > p.note { font-weight: bold !important }
+td p { padding: 1px; }
+a:hover { color: green }
+a, [data-x="y"] { background: url("data:image/png;base64,AAA") }
+@media (max-width: 600px) { p { margin: 1em } }
+</style></head><body>
+<p>One</p>
+<div><p class="note" style="color: black; font-weight: normal">Two</p></div>
+<table><tr><td><span><p>Three</p></span></td></tr></table>
+<a href="/">Link</a><i data-x="y"></i>
+</body></html>`
+	out, err := Inline([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(out)
+	for _, want := range []string{
+		`<p style="color: red; margin: 0">One</p>`,
+		`<p class="note" style="color: black; margin: 0; font-weight: bold !important">Two</p>`,
+		`<p style="color: red; margin: 0; padding: 1px">Three</p>`,
+		`<a href="/" style="background: url(&#34;data:image/png;base64,AAA&#34;)">Link</a>`,
+		`<i data-x="y" style="background: url(&#34;data:image/png;base64,AAA&#34;)"></i>`,
+		"<style>a:hover { color: green }\n@media (max-width: 600px) { p { margin: 1em } }\n</style>",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("output doesn't contain %s:\n%s", want, s)
+		}
+	}
+}
+
+func TestParseSelector(t *testing.T) {
+	for s, ok := range map[string]bool{
+		"p":             true,
+		"div > p.a#b":   true,
+		"ul li [href]":  true,
+		"*":             true,
+		"a:hover":       false,
+		"p::first-line": false,
+		"h1 + p":        false,
+		"p >":           false,
+		"[href^=http]":  false,
+	} {
+		if _, got := parseSelector(s); got != ok {
+			t.Errorf("%q: got %v, want %v", s, got, ok)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package deps implements a graph of dependencies between build
+// outputs and their inputs, such as layouts.
+package deps
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+type Graph struct {
+	mu   sync.Mutex
+	deps map[string]map[string]bool // output -> inputs
+}
+
+// New returns a new empty graph.
+func New() *Graph {
+	return &Graph{deps: make(map[string]map[string]bool)}
+}
+
+// Set sets inputs of the output, replacing the previous ones.
+func (g *Graph) Set(output string, inputs ...string) {
+	m := make(map[string]bool, len(inputs))
+	for _, in := range inputs {
+		m[in] = true
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.deps[output] = m
+}
+
+// Has returns true if the graph contains the output.
+func (g *Graph) Has(output string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.deps[output]
+	return ok
+}
+
+// Len returns the number of outputs in the graph.
+func (g *Graph) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.deps)
+}
+
+// Dependents returns a sorted list of outputs which depend on any
+// of the inputs, directly or through other outputs, such as includes
+// which depend on other includes.
+func (g *Graph) Dependents(inputs ...string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	found := make(map[string]bool)
+	for len(inputs) > 0 {
+		var next []string
+		for output, m := range g.deps {
+			if found[output] {
+				continue
+			}
+			for _, in := range inputs {
+				if m[in] {
+					found[output] = true
+					next = append(next, output)
+					break
+				}
+			}
+		}
+		inputs = next
+	}
+	out := make([]string, 0, len(found))
+	for output := range found {
+		out = append(out, output)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Inputs returns a sorted list of inputs of the output, including
+// inputs of inputs which are outputs themselves, or nil if the graph
+// doesn't contain the output.
+func (g *Graph) Inputs(output string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.deps[output]; !ok {
+		return nil
+	}
+	found := make(map[string]bool)
+	queue := []string{output}
+	for len(queue) > 0 {
+		m := g.deps[queue[0]]
+		queue = queue[1:]
+		for in := range m {
+			if !found[in] && in != output {
+				found[in] = true
+				queue = append(queue, in)
+			}
+		}
+	}
+	out := make([]string, 0, len(found))
+	for in := range found {
+		out = append(out, in)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// MarshalJSON encodes the graph as an object mapping outputs
+// to lists of their inputs.
+func (g *Graph) MarshalJSON() ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	m := make(map[string][]string, len(g.deps))
+	for output, inputs := range g.deps {
+		list := make([]string, 0, len(inputs))
+		for in := range inputs {
+			list = append(list, in)
+		}
+		sort.Strings(list)
+		m[output] = list
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON replaces outputs of the graph with the ones
+// encoded by MarshalJSON.
+func (g *Graph) UnmarshalJSON(b []byte) error {
+	var m map[string][]string
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	g.Reset()
+	for output, inputs := range m {
+		g.Set(output, inputs...)
+	}
+	return nil
+}
+
+// Reset removes all outputs from the graph.
+func (g *Graph) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.deps = make(map[string]map[string]bool)
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package deps
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGraph(t *testing.T) {
+	g := New()
+	g.Set("/a/", "layout:page", "layout:default")
+	g.Set("/b/", "layout:post", "layout:default")
+	g.Set("/c/", "layout:simple")
+	if out := g.Dependents("layout:default"); !reflect.DeepEqual(out, []string{"/a/", "/b/"}) {
+		t.Errorf("got %q", out)
+	}
+	if out := g.Dependents("layout:post", "layout:simple"); !reflect.DeepEqual(out, []string{"/b/", "/c/"}) {
+		t.Errorf("got %q", out)
+	}
+	g.Set("/a/", "layout:simple")
+	if out := g.Dependents("layout:page"); len(out) != 0 {
+		t.Errorf("got %q after replacing inputs", out)
+	}
+	if !g.Has("/c/") || g.Has("/d/") {
+		t.Errorf("Has returned wrong result")
+	}
+	g.Set("include:a", "include:b")
+	g.Set("/d/", "layout:simple", "include:a")
+	if out := g.Dependents("include:b"); !reflect.DeepEqual(out, []string{"/d/", "include:a"}) {
+		t.Errorf("got %q for transitive dependency", out)
+	}
+	if in := g.Inputs("/d/"); !reflect.DeepEqual(in, []string{"include:a", "include:b", "layout:simple"}) {
+		t.Errorf("got %q for transitive inputs", in)
+	}
+	b, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g2 := New()
+	if err := json.Unmarshal(b, g2); err != nil {
+		t.Fatal(err)
+	}
+	if in := g2.Inputs("/d/"); !reflect.DeepEqual(in, g.Inputs("/d/")) {
+		t.Errorf("got %q after decoding", in)
+	}
+	g.Reset()
+	if g.Has("/a/") || g.Len() != 0 {
+		t.Errorf("Reset didn't remove outputs")
+	}
+}
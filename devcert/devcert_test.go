@@ -0,0 +1,36 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package devcert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	c1, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(c1.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range Hosts {
+		if err := leaf.VerifyHostname(h); err != nil {
+			t.Errorf("%s: %s", h, err)
+		}
+	}
+	// Second load must return the same certificate.
+	c2, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(c1.Certificate[0], c2.Certificate[0]) {
+		t.Errorf("certificate was regenerated")
+	}
+}
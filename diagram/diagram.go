@@ -0,0 +1,130 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package diagram implements replacing of code blocks containing
+// diagram sources (such as Mermaid or Graphviz) with rendered SVG.
+package diagram
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Class is the class of figure elements containing diagrams.
+const Class = "kkr-diagram"
+
+// RenderFunc returns SVG for the diagram source in the given language.
+type RenderFunc func(lang, source string) ([]byte, error)
+
+// codeLang returns language from "language-*" class of code element.
+func codeLang(t xhtml.Token) string {
+	for _, a := range t.Attr {
+		if a.Key != "class" {
+			continue
+		}
+		for _, c := range strings.Fields(a.Val) {
+			if strings.HasPrefix(c, "language-") {
+				return strings.TrimPrefix(c, "language-")
+			}
+		}
+	}
+	return ""
+}
+
+var prologRx = regexp.MustCompile(`(?s)^\s*(<\?xml.*?\?>\s*)?(<!DOCTYPE[^>]*>\s*)?`)
+
+// CleanSVG removes XML declaration and DOCTYPE from SVG,
+// so that it can be inlined into HTML.
+func CleanSVG(svg []byte) []byte {
+	return bytes.TrimSpace(prologRx.ReplaceAll(svg, nil))
+}
+
+// Replace replaces <pre><code class="language-X"> blocks, where X is
+// one of langs, with figures containing SVG returned by render.
+func Replace(in []byte, langs map[string]bool, render RenderFunc) ([]byte, error) {
+	var out bytes.Buffer
+	out.Grow(len(in))
+	z := xhtml.NewTokenizer(bytes.NewReader(in))
+	var pending []byte // raw <pre> token waiting for <code>
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			out.Write(pending)
+			return out.Bytes(), nil
+		}
+		raw := append([]byte(nil), z.Raw()...)
+		if tt != xhtml.StartTagToken {
+			out.Write(pending)
+			pending = nil
+			out.Write(raw)
+			continue
+		}
+		t := z.Token()
+		if t.DataAtom == atom.Pre {
+			out.Write(pending)
+			pending = raw
+			continue
+		}
+		if pending == nil || t.DataAtom != atom.Code || !langs[codeLang(t)] {
+			out.Write(pending)
+			pending = nil
+			out.Write(raw)
+			continue
+		}
+		lang := codeLang(t)
+		// Collect source until </code>.
+		var src strings.Builder
+		var rest bytes.Buffer // raw tokens in case of unexpected markup
+		closed := false
+		for !closed {
+			tt := z.Next()
+			if tt == xhtml.ErrorToken {
+				break
+			}
+			rest.Write(z.Raw())
+			switch tt {
+			case xhtml.TextToken:
+				src.WriteString(html.UnescapeString(string(z.Raw())))
+			case xhtml.EndTagToken:
+				if name, _ := z.TagName(); string(name) == "code" {
+					closed = true
+				}
+			}
+		}
+		if !closed {
+			return nil, fmt.Errorf("unclosed %s code block", lang)
+		}
+		// Skip whitespace and </pre>.
+		for {
+			tt := z.Next()
+			if tt == xhtml.TextToken && len(bytes.TrimSpace(z.Raw())) == 0 {
+				continue
+			}
+			if tt == xhtml.EndTagToken {
+				if name, _ := z.TagName(); string(name) == "pre" {
+					break
+				}
+			}
+			return nil, fmt.Errorf("unexpected markup after %s code block", lang)
+		}
+		svg, err := render(lang, src.String())
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&out, `<figure class="%s %s-%s">`, Class, Class, html.EscapeString(lang))
+		out.Write(CleanSVG(svg))
+		out.WriteString("</figure>")
+		pending = nil
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diagram
+
+import "testing"
+
+func TestReplace(t *testing.T) {
+	in := `<p>x</p><pre><code class="language-dot">a -&gt; b
+</code></pre><pre><code class="language-go">x := 1</code></pre>`
+	var gotSrc string
+	out, err := Replace([]byte(in), map[string]bool{"dot": true}, func(lang, src string) ([]byte, error) {
+		gotSrc = src
+		return []byte(`<?xml version="1.0"?>` + "\n" + `<!DOCTYPE svg>` + "\n<svg></svg>\n"), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotSrc != "a -> b\n" {
+		t.Errorf("source: got %q", gotSrc)
+	}
+	expected := `<p>x</p><figure class="kkr-diagram kkr-diagram-dot"><svg></svg></figure><pre><code class="language-go">x := 1</code></pre>`
+	if string(out) != expected {
+		t.Errorf("got\n%s\nexpected\n%s", out, expected)
+	}
+}
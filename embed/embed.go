@@ -0,0 +1,155 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package embed implements rendering of social media posts
+// (Mastodon toots and tweets) as static HTML quotations.
+package embed
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Post is an embedded post.
+type Post struct {
+	Kind       string // "mastodon" or "tweet"
+	URL        string
+	AuthorName string
+	AuthorURL  string
+	Content    string // sanitized HTML
+	Date       time.Time
+}
+
+// HTML returns a static quotation of the post.
+func (p *Post) HTML() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<blockquote class="kkr-embed kkr-embed-%s" cite="%s">`, p.Kind, html.EscapeString(p.URL))
+	fmt.Fprintf(&b, `<div class="kkr-embed-content">%s</div>`, p.Content)
+	b.WriteString(`<footer>— `)
+	if p.AuthorURL != "" {
+		fmt.Fprintf(&b, `<a href="%s">%s</a>`, html.EscapeString(p.AuthorURL), html.EscapeString(p.AuthorName))
+	} else {
+		b.WriteString(html.EscapeString(p.AuthorName))
+	}
+	b.WriteString(", ")
+	date := "link"
+	if !p.Date.IsZero() {
+		date = fmt.Sprintf(`<time datetime="%s">%s</time>`, p.Date.Format(time.RFC3339), p.Date.Format("January 2, 2006"))
+	}
+	fmt.Fprintf(&b, `<a href="%s">%s</a></footer></blockquote>`, html.EscapeString(p.URL), date)
+	return b.String()
+}
+
+var mastodonPathRx = regexp.MustCompile(`^/(?:@[^/]+|users/[^/]+/statuses)/(\d+)$`)
+
+// MastodonAPIURL returns Mastodon API URL for the status
+// with the given URL, such as https://mastodon.social/@user/123.
+func MastodonAPIURL(postURL string) (string, error) {
+	u, err := url.Parse(postURL)
+	if err != nil {
+		return "", err
+	}
+	m := mastodonPathRx.FindStringSubmatch(u.Path)
+	if u.Scheme != "https" || m == nil {
+		return "", fmt.Errorf("not a Mastodon post URL: %s", postURL)
+	}
+	return "https://" + u.Host + "/api/v1/statuses/" + m[1], nil
+}
+
+// ParseMastodon parses Mastodon API status response.
+func ParseMastodon(data []byte) (*Post, error) {
+	var s struct {
+		URL       string    `json:"url"`
+		CreatedAt time.Time `json:"created_at"`
+		Content   string    `json:"content"`
+		Account   struct {
+			DisplayName string `json:"display_name"`
+			Acct        string `json:"acct"`
+			URL         string `json:"url"`
+		} `json:"account"`
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	content, err := Sanitize(s.Content)
+	if err != nil {
+		return nil, err
+	}
+	name := s.Account.DisplayName
+	if name == "" {
+		name = "@" + s.Account.Acct
+	} else {
+		name += " (@" + s.Account.Acct + ")"
+	}
+	return &Post{
+		Kind:       "mastodon",
+		URL:        s.URL,
+		AuthorName: name,
+		AuthorURL:  s.Account.URL,
+		Content:    content,
+		Date:       s.CreatedAt,
+	}, nil
+}
+
+var tweetPathRx = regexp.MustCompile(`^/[^/]+/status/(\d+)$`)
+
+// TweetOEmbedURL returns oEmbed API URL for the tweet
+// with the given URL, such as https://twitter.com/user/status/123.
+func TweetOEmbedURL(postURL string) (string, error) {
+	u, err := url.Parse(postURL)
+	if err != nil {
+		return "", err
+	}
+	host := strings.TrimPrefix(u.Host, "www.")
+	if (host != "twitter.com" && host != "x.com") || !tweetPathRx.MatchString(u.Path) {
+		return "", fmt.Errorf("not a tweet URL: %s", postURL)
+	}
+	return "https://publish.twitter.com/oembed?omit_script=true&dnt=true&url=" + url.QueryEscape(postURL), nil
+}
+
+// ParseTweet parses oEmbed response for the tweet.
+func ParseTweet(data []byte, postURL string) (*Post, error) {
+	var o struct {
+		URL        string `json:"url"`
+		AuthorName string `json:"author_name"`
+		AuthorURL  string `json:"author_url"`
+		HTML       string `json:"html"`
+	}
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, err
+	}
+	// Take the first paragraph of embed HTML, which is the tweet text,
+	// the rest is author and date.
+	text := o.HTML
+	if i := strings.Index(text, "<p"); i >= 0 {
+		text = text[i:]
+		if j := strings.Index(text, "</p>"); j >= 0 {
+			text = text[:j+len("</p>")]
+		}
+	}
+	content, err := Sanitize(text)
+	if err != nil {
+		return nil, err
+	}
+	if o.URL == "" {
+		o.URL = postURL
+	}
+	name := o.AuthorName
+	if handle := path.Base(o.AuthorURL); o.AuthorURL != "" && handle != "" {
+		name += " (@" + handle + ")"
+	}
+	return &Post{
+		Kind:       "tweet",
+		URL:        o.URL,
+		AuthorName: name,
+		AuthorURL:  o.AuthorURL,
+		Content:    content,
+	}, nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package embed
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	var tests = []struct{ in, out string }{
+		{`<p>Hello <a href="https://a.com/" class="x" onclick="evil()">link</a></p>`, `<p>Hello <a href="https://a.com/" rel="nofollow noopener">link</a></p>`},
+		{`<p><span class="invisible">https://</span>text<br></p>`, `<p>https://text<br></p>`},
+		{`<a href="javascript:alert(1)">x</a><script>alert(1)</script>`, `<a rel="nofollow noopener">x</a>`},
+		{`<p>1 &lt; 2 &amp; 3</p>`, `<p>1 &lt; 2 &amp; 3</p>`},
+	}
+	for i, v := range tests {
+		out, err := Sanitize(v.in)
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if out != v.out {
+			t.Errorf("%d: expected\n%s\ngot\n%s", i, v.out, out)
+		}
+	}
+}
+
+func TestMastodon(t *testing.T) {
+	api, err := MastodonAPIURL("https://mastodon.social/@alice/109876543210")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api != "https://mastodon.social/api/v1/statuses/109876543210" {
+		t.Errorf("bad API URL: %s", api)
+	}
+	if _, err := MastodonAPIURL("https://mastodon.social/about"); err == nil {
+		t.Errorf("expected error")
+	}
+	p, err := ParseMastodon([]byte(`{
+		"url": "https://mastodon.social/@alice/109876543210",
+		"created_at": "2023-01-02T03:04:05.000Z",
+		"content": "<p>Hello, <strong>world</strong>!</p>",
+		"account": {"display_name": "Alice", "acct": "alice", "url": "https://mastodon.social/@alice"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := p.HTML()
+	for _, s := range []string{
+		`<blockquote class="kkr-embed kkr-embed-mastodon" cite="https://mastodon.social/@alice/109876543210">`,
+		`<p>Hello, <strong>world</strong>!</p>`,
+		`<a href="https://mastodon.social/@alice">Alice (@alice)</a>`,
+		`<time datetime="2023-01-02T03:04:05Z">January 2, 2023</time>`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output doesn't contain %q:\n%s", s, out)
+		}
+	}
+}
+
+func TestTweet(t *testing.T) {
+	u := "https://twitter.com/bob/status/123"
+	api, err := TweetOEmbedURL(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api != "https://publish.twitter.com/oembed?omit_script=true&dnt=true&url=https%3A%2F%2Ftwitter.com%2Fbob%2Fstatus%2F123" {
+		t.Errorf("bad oEmbed URL: %s", api)
+	}
+	p, err := ParseTweet([]byte(`{
+		"url": "https://twitter.com/bob/status/123",
+		"author_name": "Bob",
+		"author_url": "https://twitter.com/bob",
+		"html": "<blockquote class=\"twitter-tweet\"><p lang=\"en\" dir=\"ltr\">Just setting up</p>&mdash; Bob (@bob) <a href=\"https://twitter.com/bob/status/123\">March 21, 2006</a></blockquote>\n"
+	}`), u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Content != "<p>Just setting up</p>" {
+		t.Errorf("bad content: %s", p.Content)
+	}
+	if p.AuthorName != "Bob (@bob)" {
+		t.Errorf("bad author: %s", p.AuthorName)
+	}
+}
+
+func TestVideo(t *testing.T) {
+	for _, v := range []struct{ provider, in, id string }{
+		{"youtube", "dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"youtube", "https://youtu.be/dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"youtube", "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=1", "dQw4w9WgXcQ"},
+		{"youtube", "https://www.youtube.com/embed/dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"vimeo", "https://vimeo.com/76979871", "76979871"},
+		{"vimeo", "76979871", "76979871"},
+	} {
+		id, err := ParseVideoID(v.provider, v.in)
+		if err != nil {
+			t.Errorf("%s: %s", v.in, err)
+		} else if id != v.id {
+			t.Errorf("%s: expected %s, got %s", v.in, v.id, id)
+		}
+	}
+	for _, in := range []string{"https://vimeo.com/76979871", "x\" onclick=\"", ""} {
+		if _, err := ParseVideoID("youtube", in); err == nil {
+			t.Errorf("%q: expected error", in)
+		}
+	}
+	data := `{"title":"A <b>talk</b>","thumbnail_url":"https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg","thumbnail_width":480,"thumbnail_height":360}`
+	v, err := ParseVideoOEmbed([]byte(data), "youtube", "dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := v.HTML("/embeds/youtube-dQw4w9WgXcQ.jpg")
+	for _, s := range []string{
+		`data-embed="https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ?autoplay=1"`,
+		`href="https://www.youtube.com/watch?v=dQw4w9WgXcQ"`,
+		`<img src="/embeds/youtube-dQw4w9WgXcQ.jpg" alt="A &lt;b&gt;talk&lt;/b&gt;" width="480" height="360"`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("%s doesn't contain %s", out, s)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package embed
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedAtoms contains elements that are kept by Sanitize.
+var allowedAtoms = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true, atom.A: true,
+	atom.Em: true, atom.Strong: true, atom.B: true, atom.I: true,
+}
+
+// droppedAtoms contains elements that are removed with their content.
+var droppedAtoms = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Object: true,
+}
+
+func safeURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http")
+}
+
+// Sanitize returns HTML with only text, paragraphs, line breaks,
+// emphasis and links to HTTP(S) URLs. Other elements are removed,
+// keeping their text, except for scripts, styles and similar
+// elements, which are removed completely.
+func Sanitize(in string) (string, error) {
+	var out bytes.Buffer
+	z := html.NewTokenizer(strings.NewReader(in))
+	drop := 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				return "", err
+			}
+			return out.String(), nil
+		}
+		t := z.Token()
+		switch tt {
+		case html.TextToken:
+			if drop == 0 {
+				out.WriteString(html.EscapeString(t.Data))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedAtoms[t.DataAtom] {
+				if tt == html.StartTagToken {
+					drop++
+				}
+				continue
+			}
+			if drop > 0 || !allowedAtoms[t.DataAtom] {
+				continue
+			}
+			var attrs []html.Attribute
+			if t.DataAtom == atom.A {
+				for _, a := range t.Attr {
+					if a.Key == "href" && safeURL(a.Val) {
+						attrs = append(attrs, a)
+					}
+				}
+				attrs = append(attrs, html.Attribute{Key: "rel", Val: "nofollow noopener"})
+			}
+			t.Attr = attrs
+			out.WriteString(t.String())
+		case html.EndTagToken:
+			if droppedAtoms[t.DataAtom] {
+				if drop > 0 {
+					drop--
+				}
+				continue
+			}
+			if drop == 0 && allowedAtoms[t.DataAtom] {
+				out.WriteString(t.String())
+			}
+		}
+	}
+}
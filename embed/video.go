@@ -0,0 +1,149 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package embed
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Video is a YouTube or Vimeo video embedded as a click-to-load facade:
+// a locally stored thumbnail linking to the video, which doesn't load
+// anything from the video host until the reader clicks it.
+type Video struct {
+	Provider     string // "youtube" or "vimeo"
+	ID           string
+	Title        string
+	ThumbnailURL string // remote thumbnail from oEmbed
+	Width        int
+	Height       int
+}
+
+var (
+	youtubeIDRx = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	vimeoIDRx   = regexp.MustCompile(`^\d+$`)
+)
+
+// ParseVideoID returns the video ID from the video URL or the ID itself,
+// e.g. https://youtu.be/ID, https://www.youtube.com/watch?v=ID or
+// https://vimeo.com/ID.
+func ParseVideoID(provider, s string) (string, error) {
+	id := s
+	if u, err := url.Parse(s); err == nil && u.Host != "" {
+		host := strings.TrimPrefix(u.Host, "www.")
+		switch {
+		case provider == "youtube" && host == "youtu.be":
+			id = strings.Trim(u.Path, "/")
+		case provider == "youtube" && (host == "youtube.com" || host == "m.youtube.com" || host == "youtube-nocookie.com"):
+			if v := u.Query().Get("v"); v != "" {
+				id = v
+			} else {
+				id = u.Path[strings.LastIndexByte(u.Path, '/')+1:]
+			}
+		case provider == "vimeo" && (host == "vimeo.com" || host == "player.vimeo.com"):
+			id = u.Path[strings.LastIndexByte(u.Path, '/')+1:]
+		default:
+			return "", fmt.Errorf("not a %s URL: %s", provider, s)
+		}
+	}
+	switch provider {
+	case "youtube":
+		if youtubeIDRx.MatchString(id) {
+			return id, nil
+		}
+	case "vimeo":
+		if vimeoIDRx.MatchString(id) {
+			return id, nil
+		}
+	default:
+		return "", fmt.Errorf("unknown video provider %q", provider)
+	}
+	return "", fmt.Errorf("bad %s video ID %q", provider, s)
+}
+
+// WatchURL returns URL of the video page.
+func (v *Video) WatchURL() string {
+	if v.Provider == "vimeo" {
+		return "https://vimeo.com/" + v.ID
+	}
+	return "https://www.youtube.com/watch?v=" + v.ID
+}
+
+// EmbedURL returns URL of the player, which doesn't set tracking cookies.
+func (v *Video) EmbedURL() string {
+	if v.Provider == "vimeo" {
+		return "https://player.vimeo.com/video/" + v.ID + "?dnt=1&autoplay=1"
+	}
+	return "https://www.youtube-nocookie.com/embed/" + v.ID + "?autoplay=1"
+}
+
+// VideoOEmbedURL returns oEmbed API URL for the video.
+func VideoOEmbedURL(provider, id string) string {
+	v := &Video{Provider: provider, ID: id}
+	if provider == "vimeo" {
+		return "https://vimeo.com/api/oembed.json?url=" + url.QueryEscape(v.WatchURL())
+	}
+	return "https://www.youtube.com/oembed?format=json&url=" + url.QueryEscape(v.WatchURL())
+}
+
+// ParseVideoOEmbed parses oEmbed response for the video.
+func ParseVideoOEmbed(data []byte, provider, id string) (*Video, error) {
+	var o struct {
+		Title        string `json:"title"`
+		ThumbnailURL string `json:"thumbnail_url"`
+		Width        int    `json:"thumbnail_width"`
+		Height       int    `json:"thumbnail_height"`
+	}
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, err
+	}
+	if o.ThumbnailURL == "" {
+		return nil, fmt.Errorf("no thumbnail for %s video %s", provider, id)
+	}
+	return &Video{
+		Provider:     provider,
+		ID:           id,
+		Title:        o.Title,
+		ThumbnailURL: o.ThumbnailURL,
+		Width:        o.Width,
+		Height:       o.Height,
+	}, nil
+}
+
+// HTML returns the facade of the video with the thumbnail at the given
+// local URL. Without scripts, it links to the video page. VideoScript
+// replaces it with the player when clicked.
+func (v *Video) HTML(thumbnailURL string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<div class="kkr-video kkr-video-%s" data-embed="%s">`, v.Provider, html.EscapeString(v.EmbedURL()))
+	fmt.Fprintf(&b, `<a href="%s" title="%s">`, html.EscapeString(v.WatchURL()), html.EscapeString(v.Title))
+	fmt.Fprintf(&b, `<img src="%s" alt="%s"`, html.EscapeString(thumbnailURL), html.EscapeString(v.Title))
+	if v.Width > 0 && v.Height > 0 {
+		fmt.Fprintf(&b, ` width="%d" height="%d"`, v.Width, v.Height)
+	}
+	b.WriteString(` loading="lazy"><span class="kkr-video-play" aria-hidden="true">▶</span></a></div>`)
+	return b.String()
+}
+
+// VideoScript replaces video facades with players when clicked.
+// It's available as video-script asset.
+const VideoScript = `document.addEventListener("click", function(e) {
+  var a = e.target.closest && e.target.closest(".kkr-video a");
+  if (!a || e.ctrlKey || e.metaKey || e.shiftKey) return;
+  var div = a.parentNode, iframe = document.createElement("iframe");
+  e.preventDefault();
+  iframe.src = div.getAttribute("data-embed");
+  iframe.title = a.title;
+  iframe.allow = "autoplay; fullscreen; picture-in-picture";
+  iframe.allowFullscreen = true;
+  var img = a.querySelector("img");
+  if (img) { iframe.width = img.width; iframe.height = img.height; }
+  div.replaceChild(iframe, a);
+});
+`
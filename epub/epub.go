@@ -0,0 +1,267 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package epub implements writing of EPUB 3 books
+// from HTML chapters and their images.
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Book is an EPUB book.
+type Book struct {
+	Identifier string // unique identifier, e.g. URL
+	Title      string
+	Author     string
+	Language   string // "en" if empty
+	Modified   time.Time
+	Chapters   []Chapter
+	Files      []File
+}
+
+// Chapter is a chapter of the book.
+type Chapter struct {
+	Name  string // file name, e.g. "chapter-1.xhtml"
+	Title string
+	Body  string // XHTML fragment, see XHTML
+}
+
+// File is an additional file of the book, such as an image.
+type File struct {
+	Name string // slash-separated file name relative to chapters
+	Data []byte
+}
+
+// mediaTypes are media types of file extensions which
+// may be unknown to mime package.
+var mediaTypes = map[string]string{
+	".avif": "image/avif",
+	".css":  "text/css",
+	".gif":  "image/gif",
+	".jpeg": "image/jpeg",
+	".jpg":  "image/jpeg",
+	".png":  "image/png",
+	".svg":  "image/svg+xml",
+	".webp": "image/webp",
+}
+
+func mediaType(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if t, ok := mediaTypes[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		if i := strings.IndexByte(t, ';'); i >= 0 {
+			t = t[:i]
+		}
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// skippedElements are removed from chapters, since
+// scripting is optional for EPUB reading systems.
+var skippedElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Noscript: true,
+	atom.Iframe:   true,
+	atom.Form:     true,
+	atom.Template: true,
+}
+
+// XHTML converts HTML fragment into XHTML, removing scripts, iframes and
+// forms. If rewrite is not nil, it's called with the element name and
+// the value of each src and href attribute, and the attribute is set
+// to the returned value.
+func XHTML(fragment string, rewrite func(tag, attr, value string) string) (string, error) {
+	body := &xhtml.Node{Type: xhtml.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := xhtml.ParseFragment(strings.NewReader(fragment), body)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		clean(n, rewrite)
+		if n.Type == xhtml.ElementNode && skippedElements[n.DataAtom] {
+			continue
+		}
+		if err := xhtml.Render(&buf, n); err != nil {
+			return "", err
+		}
+	}
+	return buf.String(), nil
+}
+
+func clean(n *xhtml.Node, rewrite func(tag, attr, value string) string) {
+	if n.Type == xhtml.ElementNode {
+		attrs := n.Attr[:0]
+		for _, a := range n.Attr {
+			if a.Namespace == "" && strings.HasPrefix(a.Key, "on") {
+				continue // event handlers
+			}
+			if rewrite != nil && a.Namespace == "" && (a.Key == "src" || a.Key == "href") {
+				a.Val = rewrite(n.Data, a.Key, a.Val)
+			}
+			attrs = append(attrs, a)
+		}
+		n.Attr = attrs
+	}
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == xhtml.ElementNode && skippedElements[c.DataAtom] {
+			n.RemoveChild(c)
+		} else {
+			clean(c, rewrite)
+		}
+		c = next
+	}
+}
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+const navName = "nav.xhtml"
+
+func esc(s string) string { return html.EscapeString(s) }
+
+func (b *Book) language() string {
+	if b.Language == "" {
+		return "en"
+	}
+	return b.Language
+}
+
+// xhtmlDoc returns XHTML document with the title and body.
+func (b *Book) xhtmlDoc(title, body string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="` + esc(b.language()) + `" lang="` + esc(b.language()) + `">
+<head>
+<meta charset="utf-8"/>
+<title>` + esc(title) + `</title>
+</head>
+<body>
+` + body + `
+</body>
+</html>
+`
+}
+
+func (b *Book) nav() string {
+	var buf strings.Builder
+	buf.WriteString("<nav epub:type=\"toc\" id=\"toc\">\n<h1>" + esc(b.Title) + "</h1>\n<ol>\n")
+	for _, c := range b.Chapters {
+		fmt.Fprintf(&buf, "<li><a href=\"%s\">%s</a></li>\n", esc(c.Name), esc(c.Title))
+	}
+	buf.WriteString("</ol>\n</nav>")
+	return b.xhtmlDoc(b.Title, buf.String())
+}
+
+func (b *Book) opf() string {
+	var buf strings.Builder
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="id">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+`)
+	fmt.Fprintf(&buf, "<dc:identifier id=\"id\">%s</dc:identifier>\n", esc(b.Identifier))
+	fmt.Fprintf(&buf, "<dc:title>%s</dc:title>\n", esc(b.Title))
+	if b.Author != "" {
+		fmt.Fprintf(&buf, "<dc:creator>%s</dc:creator>\n", esc(b.Author))
+	}
+	fmt.Fprintf(&buf, "<dc:language>%s</dc:language>\n", esc(b.language()))
+	fmt.Fprintf(&buf, "<meta property=\"dcterms:modified\">%s</meta>\n", b.Modified.UTC().Format("2006-01-02T15:04:05Z"))
+	buf.WriteString("</metadata>\n<manifest>\n")
+	fmt.Fprintf(&buf, "<item id=\"nav\" href=\"%s\" media-type=\"application/xhtml+xml\" properties=\"nav\"/>\n", navName)
+	for i, c := range b.Chapters {
+		fmt.Fprintf(&buf, "<item id=\"c%d\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", i+1, esc(c.Name))
+	}
+	for i, f := range b.Files {
+		fmt.Fprintf(&buf, "<item id=\"f%d\" href=\"%s\" media-type=\"%s\"/>\n", i+1, esc(f.Name), mediaType(f.Name))
+	}
+	buf.WriteString("</manifest>\n<spine>\n")
+	for i := range b.Chapters {
+		fmt.Fprintf(&buf, "<itemref idref=\"c%d\"/>\n", i+1)
+	}
+	buf.WriteString("</spine>\n</package>\n")
+	return buf.String()
+}
+
+// WriteTo writes the book in EPUB format.
+func (b *Book) WriteTo(w io.Writer) (n int64, err error) {
+	if len(b.Chapters) == 0 {
+		return 0, errors.New("epub: no chapters")
+	}
+	if b.Identifier == "" {
+		return 0, errors.New("epub: no identifier")
+	}
+	cw := &countWriter{w: w}
+	z := zip.NewWriter(cw)
+	// The mimetype file must be the first one and stored uncompressed.
+	f, err := z.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return cw.n, err
+	}
+	if _, err := io.WriteString(f, "application/epub+zip"); err != nil {
+		return cw.n, err
+	}
+	add := func(name string, data []byte) error {
+		f, err := z.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: b.Modified})
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	}
+	if err := add("META-INF/container.xml", []byte(containerXML)); err != nil {
+		return cw.n, err
+	}
+	if err := add("OEBPS/content.opf", []byte(b.opf())); err != nil {
+		return cw.n, err
+	}
+	if err := add("OEBPS/"+navName, []byte(b.nav())); err != nil {
+		return cw.n, err
+	}
+	for _, c := range b.Chapters {
+		body := "<h1>" + esc(c.Title) + "</h1>\n" + c.Body
+		if err := add("OEBPS/"+c.Name, []byte(b.xhtmlDoc(c.Title, body))); err != nil {
+			return cw.n, err
+		}
+	}
+	for _, f := range b.Files {
+		if err := add("OEBPS/"+f.Name, f.Data); err != nil {
+			return cw.n, err
+		}
+	}
+	err = z.Close()
+	return cw.n, err
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestXHTML(t *testing.T) {
+	in := `<p onclick="x()">A<br>B &amp; <img src="/a.png" alt=a></p><script>alert(1)</script><div><iframe src="x"></iframe><a href="/b/">b</a></div>`
+	out, err := XHTML(in, func(tag, attr, value string) string {
+		if tag == "img" {
+			return "images/a.png"
+		}
+		return "https://example.com" + value
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<p>A<br/>B &amp; <img src="images/a.png" alt="a"/></p><div><a href="https://example.com/b/">b</a></div>`
+	if out != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	b := &Book{
+		Identifier: "https://example.com/",
+		Title:      "Series & Co",
+		Modified:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Chapters:   []Chapter{{Name: "chapter-1.xhtml", Title: "One", Body: "<p>Hello</p>"}},
+		Files:      []File{{Name: "images/a.png", Data: []byte("png")}},
+	}
+	var buf bytes.Buffer
+	if _, err := b.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if z.File[0].Name != "mimetype" || z.File[0].Method != zip.Store {
+		t.Fatalf("first file is %s, method %d", z.File[0].Name, z.File[0].Method)
+	}
+	files := make(map[string]string)
+	for _, f := range z.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(data)
+	}
+	for name, want := range map[string]string{
+		"mimetype":               "application/epub+zip",
+		"META-INF/container.xml": `full-path="OEBPS/content.opf"`,
+		"OEBPS/content.opf":      `<item id="f1" href="images/a.png" media-type="image/png"/>`,
+		"OEBPS/nav.xhtml":        `<li><a href="chapter-1.xhtml">One</a></li>`,
+		"OEBPS/chapter-1.xhtml":  "<h1>One</h1>\n<p>Hello</p>",
+		"OEBPS/images/a.png":     "png",
+	} {
+		if !strings.Contains(files[name], want) {
+			t.Errorf("%s: %q doesn't contain %q", name, files[name], want)
+		}
+	}
+	if !strings.Contains(files["OEBPS/content.opf"], "<dc:title>Series &amp; Co</dc:title>") {
+		t.Errorf("bad title in content.opf")
+	}
+}
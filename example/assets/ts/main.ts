@@ -0,0 +1,5 @@
+import { greet } from "./greet";
+
+document.addEventListener("DOMContentLoaded", () => {
+  console.log(greet("kkr"));
+});
@@ -0,0 +1,377 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package exif implements reading of selected EXIF fields from JPEG
+// and PNG images and stripping of metadata from them.
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Info contains selected EXIF fields.
+type Info struct {
+	Make         string
+	Model        string
+	Software     string
+	Date         time.Time // capture date (DateTimeOriginal or DateTime)
+	Orientation  int
+	ExposureTime string // e.g. "1/250"
+	FNumber      float64
+	ISO          int
+	FocalLength  float64
+	HasGPS       bool
+}
+
+// Camera returns make and model of the camera.
+func (i *Info) Camera() string {
+	if strings.HasPrefix(i.Model, i.Make) {
+		return i.Model
+	}
+	return strings.TrimSpace(i.Make + " " + i.Model)
+}
+
+const (
+	tagMake             = 0x010f
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagSoftware         = 0x0131
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagExposureTime     = 0x829a
+	tagFNumber          = 0x829d
+	tagISO              = 0x8827
+	tagDateTimeOriginal = 0x9003
+	tagFocalLength      = 0x920a
+)
+
+var (
+	jpegSOI   = []byte{0xff, 0xd8}
+	pngHeader = []byte("\x89PNG\r\n\x1a\n")
+	exifSig   = []byte("Exif\x00\x00")
+	xmpSig    = []byte("http://ns.adobe.com/xap/1.0/\x00")
+)
+
+var errFormat = errors.New("exif: bad format")
+
+// Read reads EXIF information from JPEG or PNG image data.
+// It returns nil if the image doesn't have EXIF data.
+func Read(data []byte) (*Info, error) {
+	tiff, err := findTIFF(data)
+	if err != nil || tiff == nil {
+		return nil, err
+	}
+	return parseTIFF(tiff)
+}
+
+// findTIFF returns TIFF-structured EXIF data from the image.
+func findTIFF(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, jpegSOI):
+		var tiff []byte
+		_, err := jpegSegments(data, func(marker byte, segment, payload []byte) bool {
+			if marker == 0xe1 && bytes.HasPrefix(payload, exifSig) {
+				tiff = payload[len(exifSig):]
+				return false
+			}
+			return true
+		})
+		return tiff, err
+	case bytes.HasPrefix(data, pngHeader):
+		var tiff []byte
+		err := pngChunks(data, func(typ string, chunk []byte) bool {
+			if typ == "eXIf" {
+				tiff = chunk[8 : len(chunk)-4]
+				return false
+			}
+			return true
+		})
+		return tiff, err
+	}
+	return nil, nil
+}
+
+// jpegSegments calls fn for each segment before start of scan with
+// its marker, the whole segment and its payload. If fn returns false,
+// iteration stops. It returns the offset at which iteration stopped.
+func jpegSegments(data []byte, fn func(marker byte, segment, payload []byte) bool) (int, error) {
+	i := len(jpegSOI)
+	for i+2 <= len(data) {
+		if data[i] != 0xff {
+			return i, errFormat
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xff:
+			i++ // fill byte
+			continue
+		case marker == 0xda || marker == 0xd9:
+			return i, nil // start of scan or end of image
+		case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7):
+			// No payload.
+			if !fn(marker, data[i:i+2], nil) {
+				return i, nil
+			}
+			i += 2
+			continue
+		}
+		if i+4 > len(data) {
+			return i, errFormat
+		}
+		n := int(binary.BigEndian.Uint16(data[i+2:]))
+		if n < 2 || i+2+n > len(data) {
+			return i, errFormat
+		}
+		if !fn(marker, data[i:i+2+n], data[i+4:i+2+n]) {
+			return i, nil
+		}
+		i += 2 + n
+	}
+	return i, nil
+}
+
+// pngChunks calls fn for each PNG chunk with its type and the whole
+// chunk (including length, type and CRC). If fn returns false,
+// iteration stops.
+func pngChunks(data []byte, fn func(typ string, chunk []byte) bool) error {
+	i := len(pngHeader)
+	for i < len(data) {
+		if i+12 > len(data) {
+			return errFormat
+		}
+		n := int(binary.BigEndian.Uint32(data[i:]))
+		if n < 0 || i+12+n > len(data) {
+			return errFormat
+		}
+		if !fn(string(data[i+4:i+8]), data[i:i+12+n]) {
+			return nil
+		}
+		i += 12 + n
+	}
+	return nil
+}
+
+type tiffReader struct {
+	b     []byte
+	order binary.ByteOrder
+}
+
+type ifdEntry struct {
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+var typeSizes = map[uint16]uint32{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 7: 1, 9: 4, 10: 8}
+
+func (r *tiffReader) readIFD(offset uint32) (map[uint16]ifdEntry, error) {
+	if uint64(offset)+2 > uint64(len(r.b)) {
+		return nil, errFormat
+	}
+	n := uint32(r.order.Uint16(r.b[offset:]))
+	entries := make(map[uint16]ifdEntry, n)
+	for i := uint32(0); i < n; i++ {
+		p := uint64(offset) + 2 + uint64(i)*12
+		if p+12 > uint64(len(r.b)) {
+			return nil, errFormat
+		}
+		e := r.b[p : p+12]
+		tag := r.order.Uint16(e)
+		typ := r.order.Uint16(e[2:])
+		count := r.order.Uint32(e[4:])
+		size, ok := typeSizes[typ]
+		if !ok {
+			continue
+		}
+		total := uint64(size) * uint64(count)
+		var value []byte
+		if total <= 4 {
+			value = e[8 : 8+total]
+		} else {
+			off := uint64(r.order.Uint32(e[8:]))
+			if off+total > uint64(len(r.b)) {
+				continue // skip broken entry
+			}
+			value = r.b[off : off+total]
+		}
+		entries[tag] = ifdEntry{typ: typ, count: count, value: value}
+	}
+	return entries, nil
+}
+
+func (r *tiffReader) str(e ifdEntry) string {
+	if e.typ != 2 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(e.value), "\x00"))
+}
+
+func (r *tiffReader) uint(e ifdEntry) uint32 {
+	switch {
+	case e.typ == 3 && len(e.value) >= 2:
+		return uint32(r.order.Uint16(e.value))
+	case e.typ == 4 && len(e.value) >= 4:
+		return r.order.Uint32(e.value)
+	}
+	return 0
+}
+
+func (r *tiffReader) rational(e ifdEntry) (num, den uint32) {
+	if (e.typ == 5 || e.typ == 10) && len(e.value) >= 8 {
+		return r.order.Uint32(e.value), r.order.Uint32(e.value[4:])
+	}
+	return 0, 0
+}
+
+func (r *tiffReader) float(e ifdEntry) float64 {
+	num, den := r.rational(e)
+	if den == 0 {
+		return 0
+	}
+	return float64(num) / float64(den)
+}
+
+func parseDate(s string) time.Time {
+	t, err := time.Parse("2006:01:02 15:04:05", s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func parseTIFF(b []byte) (*Info, error) {
+	if len(b) < 8 {
+		return nil, errFormat
+	}
+	r := &tiffReader{b: b}
+	switch string(b[:2]) {
+	case "II":
+		r.order = binary.LittleEndian
+	case "MM":
+		r.order = binary.BigEndian
+	default:
+		return nil, errFormat
+	}
+	if r.order.Uint16(b[2:]) != 42 {
+		return nil, errFormat
+	}
+	ifd0, err := r.readIFD(r.order.Uint32(b[4:]))
+	if err != nil {
+		return nil, err
+	}
+	info := &Info{
+		Make:        r.str(ifd0[tagMake]),
+		Model:       r.str(ifd0[tagModel]),
+		Software:    r.str(ifd0[tagSoftware]),
+		Date:        parseDate(r.str(ifd0[tagDateTime])),
+		Orientation: int(r.uint(ifd0[tagOrientation])),
+	}
+	if _, ok := ifd0[tagGPSIFD]; ok {
+		info.HasGPS = true
+	}
+	if e, ok := ifd0[tagExifIFD]; ok {
+		sub, err := r.readIFD(r.uint(e))
+		if err != nil {
+			return info, nil // ignore broken sub-IFD
+		}
+		if d := parseDate(r.str(sub[tagDateTimeOriginal])); !d.IsZero() {
+			info.Date = d
+		}
+		if num, den := r.rational(sub[tagExposureTime]); den != 0 {
+			if num == 1 || num == 0 {
+				info.ExposureTime = fmt.Sprintf("%d/%d", num, den)
+			} else {
+				info.ExposureTime = fmt.Sprintf("%g", float64(num)/float64(den))
+			}
+		}
+		info.FNumber = r.float(sub[tagFNumber])
+		info.ISO = int(r.uint(sub[tagISO]))
+		info.FocalLength = r.float(sub[tagFocalLength])
+	}
+	return info, nil
+}
+
+// minimalExif returns APP1 payload with EXIF data
+// containing only the orientation tag.
+func minimalExif(orientation int) []byte {
+	var b bytes.Buffer
+	b.Write(exifSig)
+	b.WriteString("MM\x00\x2a")
+	binary.Write(&b, binary.BigEndian, uint32(8))              // IFD0 offset
+	binary.Write(&b, binary.BigEndian, uint16(1))              // number of entries
+	binary.Write(&b, binary.BigEndian, uint16(tagOrientation)) // tag
+	binary.Write(&b, binary.BigEndian, uint16(3))              // SHORT
+	binary.Write(&b, binary.BigEndian, uint32(1))              // count
+	binary.Write(&b, binary.BigEndian, uint16(orientation))
+	binary.Write(&b, binary.BigEndian, uint16(0)) // padding
+	binary.Write(&b, binary.BigEndian, uint32(0)) // next IFD
+	return b.Bytes()
+}
+
+// Strip removes EXIF, XMP, and IPTC metadata from JPEG images and
+// EXIF and text chunks from PNG images. For JPEG images, orientation
+// is preserved, so that they are displayed correctly.
+// Data in other formats is returned unchanged.
+func Strip(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, jpegSOI):
+		return stripJPEG(data)
+	case bytes.HasPrefix(data, pngHeader):
+		return stripPNG(data)
+	}
+	return data, nil
+}
+
+func stripJPEG(data []byte) ([]byte, error) {
+	info, err := Read(data)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.Write(jpegSOI)
+	if info != nil && info.Orientation > 1 {
+		payload := minimalExif(info.Orientation)
+		out.Write([]byte{0xff, 0xe1})
+		binary.Write(&out, binary.BigEndian, uint16(len(payload)+2))
+		out.Write(payload)
+	}
+	rest, err := jpegSegments(data, func(marker byte, segment, payload []byte) bool {
+		strip := (marker == 0xe1 && (bytes.HasPrefix(payload, exifSig) || bytes.HasPrefix(payload, xmpSig))) ||
+			marker == 0xed // Photoshop IRB with IPTC
+		if !strip {
+			out.Write(segment)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Copy the rest (scan data).
+	out.Write(data[rest:])
+	return out.Bytes(), nil
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	out.Write(pngHeader)
+	err := pngChunks(data, func(typ string, chunk []byte) bool {
+		switch typ {
+		case "eXIf", "tEXt", "zTXt", "iTXt":
+			// skip
+		default:
+			out.Write(chunk)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
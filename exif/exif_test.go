@@ -0,0 +1,95 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"testing"
+	"time"
+)
+
+// testTIFF returns little-endian TIFF data with
+// Make, Orientation, GPS and Exif IFD pointers.
+func testTIFF() []byte {
+	var b bytes.Buffer
+	le := binary.LittleEndian
+	b.WriteString("II\x2a\x00")
+	binary.Write(&b, le, uint32(8))
+	// IFD0 at 8: 4 entries.
+	binary.Write(&b, le, uint16(4))
+	entry := func(tag, typ uint16, count, value uint32) {
+		binary.Write(&b, le, tag)
+		binary.Write(&b, le, typ)
+		binary.Write(&b, le, count)
+		binary.Write(&b, le, value)
+	}
+	const ifd0End = 8 + 2 + 4*12 + 4 // 62
+	entry(tagMake, 2, 6, ifd0End)    // "Canon\0"
+	entry(tagOrientation, 3, 1, 6)
+	entry(tagExifIFD, 4, 1, ifd0End+6)
+	entry(tagGPSIFD, 4, 1, ifd0End+6)
+	binary.Write(&b, le, uint32(0))
+	b.WriteString("Canon\x00")
+	// Exif IFD at 68: 1 entry.
+	binary.Write(&b, le, uint16(1))
+	entry(tagDateTimeOriginal, 2, 20, ifd0End+6+2+12+4)
+	binary.Write(&b, le, uint32(0))
+	b.WriteString("2020:05:06 07:08:09\x00")
+	return b.Bytes()
+}
+
+func testJPEG(t *testing.T) []byte {
+	var img bytes.Buffer
+	if err := jpeg.Encode(&img, image.NewGray(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	payload := append(append([]byte{}, exifSig...), testTIFF()...)
+	var b bytes.Buffer
+	b.Write(jpegSOI)
+	b.Write([]byte{0xff, 0xe1})
+	binary.Write(&b, binary.BigEndian, uint16(len(payload)+2))
+	b.Write(payload)
+	b.Write(img.Bytes()[2:])
+	return b.Bytes()
+}
+
+func TestRead(t *testing.T) {
+	info, err := Read(testJPEG(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info == nil {
+		t.Fatal("no info")
+	}
+	if info.Make != "Canon" || info.Orientation != 6 || !info.HasGPS {
+		t.Errorf("bad info: %+v", info)
+	}
+	if !info.Date.Equal(time.Date(2020, 5, 6, 7, 8, 9, 0, time.UTC)) {
+		t.Errorf("bad date: %s", info.Date)
+	}
+}
+
+func TestStrip(t *testing.T) {
+	out, err := Strip(testJPEG(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := Read(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info == nil || info.Orientation != 6 {
+		t.Fatalf("orientation was not preserved: %+v", info)
+	}
+	if info.Make != "" || info.HasGPS || !info.Date.IsZero() {
+		t.Errorf("metadata was not stripped: %+v", info)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(out)); err != nil {
+		t.Errorf("stripped image doesn't decode: %s", err)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fetch implements fetching of remote data during build
+// with on-disk caching.
+package fetch
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dchest/kkr/utils"
+)
+
+const (
+	DefaultTimeout = 10 * time.Second
+	DefaultMaxAge  = 1 * time.Hour
+
+	maxSize      = 10 << 20
+	maxRedirects = 10
+)
+
+// site.yml -> fetch:
+type Config struct {
+	Hosts   []string `yaml:"hosts"`   // allowed hosts
+	Timeout string   `yaml:"timeout"` // e.g. "10s"
+	MaxAge  string   `yaml:"max_age"` // how long to use cached data, e.g. "1h"
+}
+
+type Fetcher struct {
+	hosts    map[string]bool
+	client   *http.Client
+	cacheDir string
+	maxAge   time.Duration
+
+	mu      sync.Mutex
+	fetched map[string][]byte // fetched during this build
+	calls   map[string]*call  // in progress
+}
+
+// call is a fetch in progress, which other callers
+// requesting the same URL wait for.
+type call struct {
+	done chan struct{}
+	b    []byte
+	err  error
+}
+
+func parseDuration(s string, def time.Duration) (time.Duration, error) {
+	if s == "" {
+		return def, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// New returns a new fetcher, which stores cached data in cacheDir.
+// If config is nil, fetching is not allowed.
+func New(c *Config, cacheDir string) (*Fetcher, error) {
+	f := &Fetcher{
+		hosts:    make(map[string]bool),
+		cacheDir: cacheDir,
+		fetched:  make(map[string][]byte),
+		calls:    make(map[string]*call),
+	}
+	timeout := DefaultTimeout
+	if c != nil {
+		for _, h := range c.Hosts {
+			f.hosts[h] = true
+		}
+		var err error
+		if timeout, err = parseDuration(c.Timeout, DefaultTimeout); err != nil {
+			return nil, fmt.Errorf("fetch timeout: %w", err)
+		}
+		if f.maxAge, err = parseDuration(c.MaxAge, DefaultMaxAge); err != nil {
+			return nil, fmt.Errorf("fetch max_age: %w", err)
+		}
+	}
+	f.client = &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if err := f.checkURL(req.URL); err != nil {
+				return fmt.Errorf("redirect to %s: %w", req.URL, err)
+			}
+			return nil
+		},
+	}
+	return f, nil
+}
+
+// checkURL returns an error if the URL has unsupported
+// scheme or its host is not allowed.
+func (f *Fetcher) checkURL(u *url.URL) error {
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return errors.New("unsupported scheme")
+	}
+	if !f.hosts[u.Hostname()] {
+		return errors.New("host is not allowed (add it to fetch.hosts in site.yml)")
+	}
+	return nil
+}
+
+func (f *Fetcher) cacheFilename(rawurl string) string {
+	return filepath.Join(f.cacheDir, hex.EncodeToString(utils.Hash([]byte(rawurl))))
+}
+
+// Get returns data from the given URL.
+//
+// Data is fetched only once per fetcher, and cached on disk for max age.
+// If fetching fails, but there's stale cached data, it is returned.
+// Redirects are followed only to allowed hosts.
+func (f *Fetcher) Get(rawurl string) ([]byte, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkURL(u); err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", rawurl, err)
+	}
+	f.mu.Lock()
+	if b, ok := f.fetched[rawurl]; ok {
+		f.mu.Unlock()
+		return b, nil
+	}
+	if c, ok := f.calls[rawurl]; ok {
+		// Wait for the same URL being fetched by another caller,
+		// without blocking fetches of other URLs.
+		f.mu.Unlock()
+		<-c.done
+		return c.b, c.err
+	}
+	c := &call{done: make(chan struct{})}
+	f.calls[rawurl] = c
+	f.mu.Unlock()
+
+	c.b, c.err = f.get(rawurl)
+
+	f.mu.Lock()
+	if c.err == nil {
+		f.fetched[rawurl] = c.b
+	}
+	delete(f.calls, rawurl)
+	f.mu.Unlock()
+	close(c.done)
+	return c.b, c.err
+}
+
+// get returns data from the cache file or downloads it.
+func (f *Fetcher) get(rawurl string) ([]byte, error) {
+	cacheFile := f.cacheFilename(rawurl)
+	if fi, err := os.Stat(cacheFile); err == nil && time.Since(fi.ModTime()) < f.maxAge {
+		b, err := ioutil.ReadFile(cacheFile)
+		if err == nil {
+			return b, nil
+		}
+	}
+	b, err := f.download(rawurl)
+	if err != nil {
+		stale, serr := ioutil.ReadFile(cacheFile)
+		if serr != nil {
+			return nil, err
+		}
+		log.Printf("! %s, using cached data", err)
+		return stale, nil
+	}
+	if err := os.MkdirAll(f.cacheDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(cacheFile, b, 0644); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (f *Fetcher) download(rawurl string) ([]byte, error) {
+	log.Printf("F %s", rawurl)
+	resp, err := f.client.Get(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: %s", rawurl, resp.Status)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxSize {
+		return nil, fmt.Errorf("fetch %s: response is too large", rawurl)
+	}
+	return b, nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRedirectToDisallowedHost(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret"))
+	}))
+	defer other.Close()
+	u, err := url.Parse(other.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Same server, but with host which is not allowed.
+	otherURL := "http://localhost:" + u.Port()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/allowed" {
+			http.Redirect(w, r, "/data", http.StatusFound)
+			return
+		}
+		if r.URL.Path == "/data" {
+			w.Write([]byte("data"))
+			return
+		}
+		http.Redirect(w, r, otherURL, http.StatusFound)
+	}))
+	defer srv.Close()
+
+	f, err := New(&Config{Hosts: []string{"127.0.0.1"}}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := f.Get(srv.URL + "/allowed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "data" {
+		t.Errorf("expected %q, got %q", "data", b)
+	}
+	_, err = f.Get(srv.URL + "/redirect")
+	if err == nil || !strings.Contains(err.Error(), "host is not allowed") {
+		t.Errorf("expected host error, got %v", err)
+	}
+}
+
+func TestGetConcurrent(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer srv.Close()
+	f, err := New(&Config{Hosts: []string{"127.0.0.1"}}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		path := []string{"/a", "/b"}[i%2]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b, err := f.Get(srv.URL + path)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if string(b) != path {
+				t.Errorf("expected %q, got %q", path, b)
+			}
+		}()
+	}
+	wg.Wait()
+	if requests != 2 {
+		t.Errorf("%d requests, expected 2", requests)
+	}
+}
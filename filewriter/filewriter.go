@@ -1,20 +1,28 @@
 package filewriter
 
 import (
+	"bytes"
 	"compress/gzip"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
 
 	"github.com/andybalholm/brotli"
+	"github.com/dchest/kkr/hashcache"
 )
 
 // site.yaml -> compress:
 type CompressConfig struct {
 	Methods    []string `yaml:"methods"`
 	Extensions []string `yaml:"extensions"`
+	Parallel   int      `yaml:"parallel"` // maximum number of concurrent compressions, GOMAXPROCS if zero
 }
 
 type Compressor struct {
@@ -48,12 +56,24 @@ const (
 type FileWriter struct {
 	compressedExtensions map[string]struct{}
 	compressors          []*Compressor
+	cache                *hashcache.Cache
+	slots                chan struct{} // limits the number of concurrent compressions
+
+	writtenMu sync.Mutex
+	written   map[string]bool // absolute names of written files
 }
 
 func New(c *CompressConfig) (*FileWriter, error) {
 	extensions := make(map[string]struct{})
 	compressors := make([]*Compressor, 0)
+	parallel := runtime.GOMAXPROCS(0)
 	if c != nil {
+		if c.Parallel < 0 {
+			return nil, fmt.Errorf("Bad compression parallelism: %d", c.Parallel)
+		}
+		if c.Parallel > 0 {
+			parallel = c.Parallel
+		}
 		for _, v := range c.Extensions {
 			extensions["."+v] = struct{}{}
 		}
@@ -71,9 +91,38 @@ func New(c *CompressConfig) (*FileWriter, error) {
 	return &FileWriter{
 		compressedExtensions: extensions,
 		compressors:          compressors,
+		slots:                make(chan struct{}, parallel),
+		written:              make(map[string]bool),
 	}, nil
 }
 
+// SetCache sets a cache for compressed data, so that unchanged
+// files are not compressed again on rebuilds.
+func (f *FileWriter) SetCache(c *hashcache.Cache) {
+	f.cache = c
+}
+
+// compress returns data compressed with the compressor,
+// using the cache if it's set.
+func (f *FileWriter) compress(c *Compressor, data []byte) ([]byte, error) {
+	fn := func(in []byte) ([]byte, error) {
+		var buf bytes.Buffer
+		z := c.New(&buf)
+		if _, err := z.Write(in); err != nil {
+			z.Close()
+			return nil, err
+		}
+		if err := z.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	if f.cache == nil {
+		return fn(data)
+	}
+	return f.cache.Do(c.Ext, data, fn)
+}
+
 func (f *FileWriter) numberOfCompressors(ext string) int {
 	if _, ok := f.compressedExtensions[ext]; ok {
 		return len(f.compressors)
@@ -81,45 +130,146 @@ func (f *FileWriter) numberOfCompressors(ext string) int {
 	return 0
 }
 
-func (f *FileWriter) WriteFile(filename string, data []byte) error {
-	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+// writeIfChanged writes data to the file unless it already has the
+// same content, so that modification times of unchanged outputs are
+// preserved and they are not transferred again by deploys.
+func writeIfChanged(filename string, data []byte) error {
+	if fi, err := os.Stat(filename); err == nil && fi.Mode().IsRegular() && fi.Size() == int64(len(data)) {
+		if old, err := ioutil.ReadFile(filename); err == nil && bytes.Equal(old, data) {
+			return nil
+		}
+	}
+	return writeAtomic(filename, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// writeAtomic writes the file by calling write with a temporary file in
+// the same directory, which is then renamed to filename, so that readers
+// never see a partially written file.
+func writeAtomic(filename string, write func(w io.Writer) error) (err error) {
+	f, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(tempPrefix(filename))+"*")
+	if err != nil {
 		return err
 	}
-	nwriters := 1 + f.numberOfCompressors(filepath.Ext(filename))
-	done := make(chan error, nwriters)
-	go func() {
-		done <- ioutil.WriteFile(filename, data, 0644)
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
 	}()
-	if nwriters > 1 {
+	if err = write(f); err != nil {
+		return err
+	}
+	if err = f.Chmod(0644); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+// markWritten records the file and its compressed versions
+// as written for RemoveUnwritten.
+func (f *FileWriter) markWritten(filename string) {
+	filename, err := filepath.Abs(filename)
+	if err != nil {
+		return
+	}
+	f.writtenMu.Lock()
+	defer f.writtenMu.Unlock()
+	f.written[filename] = true
+	if f.numberOfCompressors(filepath.Ext(filename)) > 0 {
 		for _, c := range f.compressors {
-			ext, newc := c.Ext, c.New
-			go func() {
-				out, err := os.OpenFile(filename+"."+ext, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
-				if err != nil {
-					done <- err
-				}
-				z := newc(out)
-				if _, err := z.Write(data); err != nil {
-					z.Close()
-					out.Close()
-					os.Remove(out.Name())
-					done <- err
-				}
-				if err := z.Close(); err != nil {
-					out.Close()
-					os.Remove(out.Name())
-					done <- err
-				}
-				if err := out.Close(); err != nil {
-					os.Remove(out.Name())
-					done <- err
-				}
-				done <- nil
-			}()
+			f.written[filename+"."+c.Ext] = true
+		}
+	}
+}
+
+// RemoveUnwritten removes files in dir which haven't been written
+// or copied by the file writer, except for those for which keep
+// returns true, and then empty directories. It returns sorted names
+// of removed files.
+//
+// It is used instead of deleting the output directory before
+// building, so that unchanged outputs are not rewritten.
+func (f *FileWriter) RemoveUnwritten(dir string, keep func(name string) bool) (removed []string, err error) {
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	f.writtenMu.Lock()
+	defer f.writtenMu.Unlock()
+	var dirs []string
+	err = filepath.Walk(dir, func(name string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && name == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if fi.IsDir() {
+			if name != dir {
+				dirs = append(dirs, name)
+			}
+			return nil
+		}
+		if f.written[name] || (keep != nil && keep(name)) {
+			return nil
 		}
+		if err := os.Remove(name); err != nil {
+			return err
+		}
+		removed = append(removed, name)
+		return nil
+	})
+	if err != nil {
+		return removed, err
+	}
+	// Remove directories deepest first, ignoring errors
+	// for the ones that are not empty.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		os.Remove(dirs[i])
+	}
+	sort.Strings(removed)
+	return removed, nil
+}
+
+// WriteFile writes data to the file and, if compression is configured
+// for its extension, compressed data next to it. Files which already
+// have the same content are not rewritten.
+func (f *FileWriter) WriteFile(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	if err := writeIfChanged(filename, data); err != nil {
+		return err
+	}
+	f.markWritten(filename)
+	if f.numberOfCompressors(filepath.Ext(filename)) == 0 {
+		return nil
+	}
+	return f.writeAllCompressed(filename, data)
+}
+
+// writeAllCompressed writes data compressed with each compressor.
+// Compressions run in separate goroutines, but no more than the
+// configured number at once across all files, so that writing many
+// files concurrently doesn't use too much memory.
+func (f *FileWriter) writeAllCompressed(filename string, data []byte) error {
+	done := make(chan error, len(f.compressors))
+	for _, c := range f.compressors {
+		c := c
+		f.slots <- struct{}{}
+		go func() {
+			defer func() { <-f.slots }()
+			done <- f.writeCompressed(c, filename, data)
+		}()
 	}
 	var lastErr error
-	for i := 0; i < nwriters; i++ {
+	for range f.compressors {
 		err := <-done
 		if err != nil && lastErr == nil {
 			lastErr = err
@@ -128,43 +278,78 @@ func (f *FileWriter) WriteFile(filename string, data []byte) error {
 	return lastErr
 }
 
-func compressFile(c *Compressor, filename string) (err error) {
-	in, err := os.Open(filename)
+// writeCompressed writes data compressed with the compressor
+// to filename with the compressor's extension.
+func (f *FileWriter) writeCompressed(c *Compressor, filename string, data []byte) error {
+	b, err := f.compress(c, data)
 	if err != nil {
 		return err
 	}
-	defer in.Close()
 	outfile := filename + "." + c.Ext
-	out, err := os.OpenFile(outfile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
-	if err != nil {
+	if err := writeIfChanged(outfile, b); err != nil {
+		os.Remove(outfile)
 		return err
 	}
-	defer func() {
-		if cerr := out.Close(); cerr != nil && err == nil {
-			err = cerr
+	return nil
+}
+
+// tempPattern is the pattern of names of temporary files
+// created next to output files.
+const tempPattern = ".*.tmp*"
+
+// tempPrefix returns the prefix of temporary files for filename,
+// matching tempPattern.
+func tempPrefix(filename string) string {
+	return filepath.Join(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp")
+}
+
+// linkTemp makes a hard link to infile with a unique temporary
+// name next to outfile and returns this name.
+func linkTemp(outfile, infile string) (string, error) {
+	for i := 0; ; i++ {
+		tmp := tempPrefix(outfile) + strconv.FormatUint(uint64(rand.Uint32()), 10)
+		err := os.Link(infile, tmp)
+		if os.IsExist(err) && i < 10000 {
+			continue
 		}
+		return tmp, err
+	}
+}
+
+// RemoveTemp removes temporary files left in dir
+// by interrupted writes.
+func RemoveTemp(dir string) error {
+	err := filepath.Walk(dir, func(name string, fi os.FileInfo, err error) error {
 		if err != nil {
-			os.Remove(outfile)
+			return err
 		}
-	}()
-	z := c.New(out)
-	_, err = io.Copy(z, in)
-	if err != nil {
-		return err
-	}
-	err = z.Close()
-	if err != nil {
-		return err
+		if fi.IsDir() {
+			return nil
+		}
+		if ok, _ := filepath.Match(tempPattern, fi.Name()); ok {
+			return os.Remove(name)
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil
 	}
-	return nil
+	return err
 }
 
-func copyFile(outfile, infile string) (err error) {
-	// Remove old outfile, ignoring errors.
-	os.Remove(outfile)
-
-	// Try making hard link instead of copying.
-	if err := os.Link(infile, outfile); err == nil {
+func copyFile(outfile, infile string) error {
+	if fi, err := os.Stat(infile); err == nil {
+		if ofi, err := os.Stat(outfile); err == nil && os.SameFile(fi, ofi) {
+			return nil // already linked
+		}
+	}
+	// Try making hard link instead of copying,
+	// replacing the old outfile atomically.
+	if tmp, err := linkTemp(outfile, infile); err == nil {
+		if err := os.Rename(tmp, outfile); err != nil {
+			os.Remove(tmp)
+			return err
+		}
 		return nil // success
 	}
 
@@ -174,20 +359,10 @@ func copyFile(outfile, infile string) (err error) {
 		return err
 	}
 	defer in.Close()
-	out, err := os.Create(outfile)
-	if err != nil {
+	return writeAtomic(outfile, func(w io.Writer) error {
+		_, err := io.Copy(w, in)
 		return err
-	}
-	defer func() {
-		if cerr := out.Close(); cerr != nil && err == nil {
-			err = cerr
-		}
-		if err != nil {
-			os.Remove(outfile)
-		}
-	}()
-	_, err = io.Copy(out, in)
-	return err
+	})
 }
 
 func (f *FileWriter) CopyFile(outfile, infile string) error {
@@ -199,25 +374,15 @@ func (f *FileWriter) CopyFile(outfile, infile string) error {
 	if err := copyFile(outfile, infile); err != nil {
 		return err
 	}
+	f.markWritten(outfile)
 
 	// Compress.
-	n := f.numberOfCompressors(filepath.Ext(outfile))
-	if n == 0 {
+	if f.numberOfCompressors(filepath.Ext(outfile)) == 0 {
 		return nil
 	}
-	done := make(chan error, n)
-	for _, c := range f.compressors {
-		c := c
-		go func() {
-			done <- compressFile(c, outfile)
-		}()
-	}
-	var lastErr error
-	for i := 0; i < n; i++ {
-		err := <-done
-		if err != nil && lastErr == nil {
-			lastErr = err
-		}
+	data, err := ioutil.ReadFile(outfile)
+	if err != nil {
+		return err
 	}
-	return lastErr
+	return f.writeAllCompressed(outfile, data)
 }
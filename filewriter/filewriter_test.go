@@ -0,0 +1,251 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package filewriter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// writeOld writes the file with modification time in the past.
+func writeOld(t *testing.T, filename, data string) time.Time {
+	if err := ioutil.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(filename, old, old); err != nil {
+		t.Fatal(err)
+	}
+	return old
+}
+
+func TestWriteIfChanged(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "a.html")
+	var tests = []struct {
+		old, data string
+		rewritten bool
+	}{
+		{"hello", "hello", false},       // unchanged
+		{"hello", "hello, world", true}, // changed
+		{"hello", "jello", true},        // same size, different content
+	}
+	for i, v := range tests {
+		old := writeOld(t, filename, v.old)
+		if err := writeIfChanged(filename, []byte(v.data)); err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != v.data {
+			t.Errorf("%d: expected %q, got %q", i, v.data, b)
+		}
+		fi, err := os.Stat(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rewritten := !fi.ModTime().Equal(old); rewritten != v.rewritten {
+			t.Errorf("%d: rewritten = %t, expected %t", i, rewritten, v.rewritten)
+		}
+	}
+}
+
+func TestRemoveUnwritten(t *testing.T) {
+	dir := t.TempDir()
+	fw, err := New(&CompressConfig{Methods: []string{"gzip"}, Extensions: []string{"html"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"index.html", "old/index.html", "old/index.html.gz", "a/keep.txt", "a/stale.txt"} {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeOld(t, filename, "old")
+	}
+	if err := fw.WriteFile(filepath.Join(dir, "index.html"), []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := fw.RemoveUnwritten(dir, func(name string) bool {
+		return filepath.Base(name) == "keep.txt"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		filepath.Join(dir, "a", "stale.txt"),
+		filepath.Join(dir, "old", "index.html"),
+		filepath.Join(dir, "old", "index.html.gz"),
+	}
+	if !reflect.DeepEqual(removed, expected) {
+		t.Errorf("removed:\n%q\nexpected:\n%q", removed, expected)
+	}
+	for _, name := range []string{"index.html", "index.html.gz", "a/keep.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old")); !os.IsNotExist(err) {
+		t.Errorf("empty directory is not removed")
+	}
+}
+
+// tempFiles returns names of temporary files in dir.
+func tempFiles(t *testing.T, dir string) []string {
+	names, err := filepath.Glob(filepath.Join(dir, tempPattern))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+func TestWriteAtomic(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "a.txt")
+	writeOld(t, filename, "old")
+	err := writeAtomic(filename, func(w io.Writer) error {
+		w.Write([]byte("partial"))
+		return errors.New("failed")
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if b, _ := ioutil.ReadFile(filename); string(b) != "old" {
+		t.Errorf("failed write replaced file with %q", b)
+	}
+	err = writeAtomic(filename, func(w io.Writer) error {
+		_, err := w.Write([]byte("new"))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := ioutil.ReadFile(filename); string(b) != "new" {
+		t.Errorf("expected %q, got %q", "new", b)
+	}
+	if names := tempFiles(t, dir); len(names) != 0 {
+		t.Errorf("temporary files left: %q", names)
+	}
+}
+
+func TestCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	infile := filepath.Join(dir, "in.txt")
+	outfile := filepath.Join(dir, "out.txt")
+	writeOld(t, infile, "content")
+	writeOld(t, outfile, "old")
+	// Leftover from an interrupted copy shouldn't prevent copying.
+	leftover := tempPrefix(outfile) + "123"
+	writeOld(t, leftover, "leftover")
+	for i := 0; i < 2; i++ {
+		if err := copyFile(outfile, infile); err != nil {
+			t.Fatal(err)
+		}
+		if b, _ := ioutil.ReadFile(outfile); string(b) != "content" {
+			t.Errorf("%d: expected %q, got %q", i, "content", b)
+		}
+	}
+	if names := tempFiles(t, dir); len(names) != 1 || names[0] != leftover {
+		t.Errorf("unexpected temporary files: %q", names)
+	}
+	if err := RemoveTemp(dir); err != nil {
+		t.Fatal(err)
+	}
+	if names := tempFiles(t, dir); len(names) != 0 {
+		t.Errorf("temporary files are not removed: %q", names)
+	}
+	if b, _ := ioutil.ReadFile(outfile); string(b) != "content" {
+		t.Errorf("RemoveTemp removed output")
+	}
+}
+
+func TestNewParallel(t *testing.T) {
+	if _, err := New(&CompressConfig{Parallel: -1}); err == nil {
+		t.Errorf("expected error for negative parallel")
+	}
+	for _, v := range []struct{ parallel, slots int }{
+		{0, runtime.GOMAXPROCS(0)},
+		{3, 3},
+	} {
+		fw, err := New(&CompressConfig{Parallel: v.parallel})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cap(fw.slots) != v.slots {
+			t.Errorf("parallel %d: %d slots, expected %d", v.parallel, cap(fw.slots), v.slots)
+		}
+	}
+}
+
+// countingWriter counts concurrent writes.
+type countingWriter struct {
+	active, max *int32
+}
+
+func (w countingWriter) Write(p []byte) (int, error) {
+	n := atomic.AddInt32(w.active, 1)
+	for {
+		max := atomic.LoadInt32(w.max)
+		if n <= max || atomic.CompareAndSwapInt32(w.max, max, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	atomic.AddInt32(w.active, -1)
+	return len(p), nil
+}
+
+func (w countingWriter) Close() error { return nil }
+
+func TestWriteAllCompressedParallel(t *testing.T) {
+	const parallel = 2
+	dir := t.TempDir()
+	fw, err := New(&CompressConfig{Extensions: []string{"html"}, Parallel: parallel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var active, max int32
+	for _, ext := range []string{"a", "b", "c"} {
+		fw.compressors = append(fw.compressors, &Compressor{
+			Ext: ext,
+			New: func(io.Writer) io.WriteCloser { return countingWriter{&active, &max} },
+		})
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		filename := filepath.Join(dir, fmt.Sprintf("%d.html", i))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- fw.WriteFile(filename, []byte("content"))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if max > parallel {
+		t.Errorf("%d concurrent compressions, expected at most %d", max, parallel)
+	}
+	if max < 1 {
+		t.Errorf("no compressions")
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package filters
+
+// `esbuild` compiles TypeScript or JavaScript with esbuild command, which
+// is "esbuild" or the one in KKR_ESBUILD environment variable. It runs
+// like other external commands (see sandbox package) rather than with
+// esbuild's Go API, which is not among vendored dependencies. Options
+// map one-to-one to the API's, so it can replace the command later
+// without changing assets.yml.
+//
+// Optional arguments:
+//
+// 'bundle', 'minify', 'sourcemap', 'target=es2017', 'format=iife',
+// 'loader=ts' (default), 'dir=assets/ts' (directory relative to the site
+// from which imports are resolved when bundling, site directory by
+// default), and options starting with "--", which are passed as is.
+//
+// With 'sourcemap', esbuild inlines the source map into the output;
+// if the asset has 'sourcemap: true', it's written into a .map file
+// instead.
+//
+// Usage examples:
+//
+//  [esbuild, bundle, minify, target=es2017, dir=assets/ts]
+//  - bundles TypeScript entry point with its imports and minifies it.
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	Register("esbuild", func(args []string) Filter {
+		command := os.Getenv("KKR_ESBUILD")
+		if command == "" {
+			command = "esbuild"
+		}
+		f := &Esbuild{Exec{command: command}}
+		loader := "--loader=ts"
+		for _, v := range args {
+			switch {
+			case strings.HasPrefix(v, "--"):
+				f.args = append(f.args, v)
+			case v == "bundle", v == "minify":
+				f.args = append(f.args, "--"+v)
+			case v == "sourcemap":
+				f.args = append(f.args, "--sourcemap=inline")
+			case strings.HasPrefix(v, "dir="):
+				f.dir = strings.TrimPrefix(v, "dir=")
+			case strings.HasPrefix(v, "loader="):
+				loader = "--" + v
+			default:
+				f.args = append(f.args, "--"+v) // target=, format=, etc.
+			}
+		}
+		f.args = append(f.args, loader)
+		return f
+	})
+}
+
+type Esbuild struct {
+	Exec
+}
+
+func (f *Esbuild) Name() string { return "esbuild" }
+
+// SetDir sets the directory against which relative dir is resolved.
+func (f *Esbuild) SetDir(dir string) {
+	if !filepath.IsAbs(f.dir) {
+		f.dir = filepath.Join(dir, f.dir)
+	}
+}
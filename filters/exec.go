@@ -10,7 +10,8 @@ import (
 	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
+
+	"github.com/dchest/kkr/sandbox"
 )
 
 func init() {
@@ -22,12 +23,17 @@ func init() {
 type Exec struct {
 	command string
 	args    []string
+	dir     string // working directory, current if empty
 }
 
 func (f *Exec) Name() string { return fmt.Sprintf("exec %s %q", f.command, f.args) }
 
 func (f *Exec) Apply(in []byte) (out []byte, err error) {
-	cmd := exec.Command(f.command, f.args...)
+	cmd, err := sandbox.Command(f.command, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Dir = f.dir
 	cmd.Stdin = bytes.NewReader(in)
 	var buf bytes.Buffer
 	var errbuf bytes.Buffer
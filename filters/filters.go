@@ -86,6 +86,14 @@ func (c *Collection) AddFromYAML(key string, line interface{}) error {
 	}
 }
 
+// SetDir sets the directory against which the filter for key resolves
+// relative paths in its arguments, if it has any.
+func (c *Collection) SetDir(key, dir string) {
+	if f, ok := c.filters[key].(interface{ SetDir(string) }); ok {
+		f.SetDir(dir)
+	}
+}
+
 // Get returns a filter for key.
 // It returns nil if the filter wasn't found.
 func (c *Collection) Get(key string) Filter {
@@ -0,0 +1,25 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package filters
+
+// `inlinecss` moves CSS rules from style elements of HTML document into
+// style attributes of matching elements, for email clients that ignore
+// style elements. Rules which can't be inlined, such as @media, are kept.
+
+import "github.com/dchest/kkr/cssinline"
+
+func init() {
+	Register("inlinecss", func(args []string) Filter {
+		return new(InlineCSS)
+	})
+}
+
+type InlineCSS struct{}
+
+func (f *InlineCSS) Name() string { return "inlinecss" }
+
+func (f *InlineCSS) Apply(in []byte) ([]byte, error) {
+	return cssinline.Inline(in)
+}
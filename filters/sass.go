@@ -0,0 +1,39 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package filters
+
+// `sass` compiles Sass/SCSS with Dart Sass command, which is "sass"
+// or the one in KKR_SASS environment variable. Arguments are load
+// paths for @use and @import, or options starting with "--", such
+// as --indented for Sass indented syntax.
+
+import (
+	"os"
+	"strings"
+)
+
+func init() {
+	Register("sass", func(args []string) Filter {
+		command := os.Getenv("KKR_SASS")
+		if command == "" {
+			command = "sass"
+		}
+		cmdArgs := []string{"--stdin", "--no-source-map"}
+		for _, v := range args {
+			if strings.HasPrefix(v, "--") {
+				cmdArgs = append(cmdArgs, v)
+			} else {
+				cmdArgs = append(cmdArgs, "--load-path="+v)
+			}
+		}
+		return &Sass{Exec{command: command, args: cmdArgs}}
+	})
+}
+
+type Sass struct {
+	Exec
+}
+
+func (f *Sass) Name() string { return "sass" }
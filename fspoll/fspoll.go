@@ -8,6 +8,7 @@ package fspoll
 import (
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 )
 
@@ -20,7 +21,7 @@ type Watcher struct {
 	closed        chan bool
 
 	// event channels
-	Change chan bool
+	Change chan []string // changed, added, or deleted paths
 	Error  chan error
 }
 
@@ -52,7 +53,7 @@ func Watch(dir string, excludeGlobs []string, interval, sleepInterval time.Durat
 		excludeGlobs:  excludeGlobs,
 		interval:      interval,
 		sleepInterval: sleepInterval,
-		Change:        make(chan bool),
+		Change:        make(chan []string),
 		Error:         make(chan error),
 		closed:        make(chan bool),
 	}
@@ -70,11 +71,11 @@ func (w *Watcher) start() {
 	lastChangeTime := time.Now()
 	currentInterval := w.interval
 	for {
-		hasChange, err := w.check()
+		changed, err := w.check()
 		switch {
 		case err != nil:
 			w.Error <- err
-		case hasChange:
+		case len(changed) > 0:
 			now := time.Now()
 			if now.Sub(lastChangeTime) > SleepAfter {
 				currentInterval = w.sleepInterval
@@ -82,7 +83,7 @@ func (w *Watcher) start() {
 				currentInterval = w.interval
 			}
 			lastChangeTime = now
-			w.Change <- true
+			w.Change <- changed
 		}
 		select {
 		case <-time.After(currentInterval):
@@ -125,49 +126,44 @@ func (w *Watcher) getState() (map[string]os.FileInfo, error) {
 	return ns, err
 }
 
-func (w *Watcher) check() (hasChange bool, err error) {
+// check returns paths that changed since the previous check.
+func (w *Watcher) check() (changed []string, err error) {
 	ns, err := w.getState()
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	defer func() {
 		// Set new state as current when this function finishes.
 		w.state = ns
 	}()
-	if len(ns) != len(w.state) {
-		return true, nil
-	}
 	// Compare files.
 	for path, nfi := range ns {
 		ofi, ok := w.state[path]
 		if !ok {
 			// New file.
-			return true, nil
+			changed = append(changed, path)
+			continue
 		}
 		// Compare modes.
 		if ofi.Mode() != nfi.Mode() {
-			return true, nil
+			changed = append(changed, path)
+			continue
 		}
 		if !ofi.IsDir() {
-			// Compare times.
-			if !ofi.ModTime().Equal(nfi.ModTime()) {
-				return true, nil
-			}
-			// Compare sizes.
-			if ofi.Size() != nfi.Size() {
-				return true, nil
+			// Compare times and sizes.
+			if !ofi.ModTime().Equal(nfi.ModTime()) || ofi.Size() != nfi.Size() {
+				changed = append(changed, path)
 			}
 		}
 	}
 	// Check for deleted files.
 	for opath := range w.state {
-		_, ok := ns[opath]
-		if !ok {
-			return true, nil
+		if _, ok := ns[opath]; !ok {
+			changed = append(changed, opath)
 		}
 	}
-	// Nothing changed.
-	return false, nil
+	sort.Strings(changed)
+	return changed, nil
 }
 
 // Close stops the watcher.
@@ -0,0 +1,114 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package geojson implements validation and minification of GeoJSON.
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var geometryTypes = map[string]bool{
+	"Point":           true,
+	"MultiPoint":      true,
+	"LineString":      true,
+	"MultiLineString": true,
+	"Polygon":         true,
+	"MultiPolygon":    true,
+}
+
+type object struct {
+	Type        string            `json:"type"`
+	Features    []json.RawMessage `json:"features"`
+	Geometry    json.RawMessage   `json:"geometry"`
+	Geometries  []json.RawMessage `json:"geometries"`
+	Coordinates json.RawMessage   `json:"coordinates"`
+}
+
+// Validate checks that b contains a valid GeoJSON object.
+// It checks the structure of objects, but not the coordinates.
+func Validate(b []byte) error {
+	return validate(b, "")
+}
+
+func validate(b []byte, path string) error {
+	var o object
+	if err := json.Unmarshal(b, &o); err != nil {
+		return fmt.Errorf("%s: %w", where(path), err)
+	}
+	switch {
+	case o.Type == "FeatureCollection":
+		if o.Features == nil {
+			return fmt.Errorf("%s: FeatureCollection without features", where(path))
+		}
+		for i, f := range o.Features {
+			p := fmt.Sprintf("%s/features/%d", path, i)
+			if err := validateType(f, p, "Feature"); err != nil {
+				return err
+			}
+			if err := validate(f, p); err != nil {
+				return err
+			}
+		}
+	case o.Type == "Feature":
+		if o.Geometry == nil {
+			return fmt.Errorf("%s: Feature without geometry", where(path))
+		}
+		if string(o.Geometry) != "null" {
+			return validate(o.Geometry, path+"/geometry")
+		}
+	case o.Type == "GeometryCollection":
+		for i, g := range o.Geometries {
+			if err := validate(g, fmt.Sprintf("%s/geometries/%d", path, i)); err != nil {
+				return err
+			}
+		}
+	case geometryTypes[o.Type]:
+		var coords []interface{}
+		if err := json.Unmarshal(o.Coordinates, &coords); err != nil || coords == nil {
+			return fmt.Errorf("%s: %s without coordinates array", where(path), o.Type)
+		}
+	case o.Type == "":
+		return fmt.Errorf("%s: missing type", where(path))
+	default:
+		return fmt.Errorf("%s: unknown type %q", where(path), o.Type)
+	}
+	return nil
+}
+
+func validateType(b []byte, path, expected string) error {
+	var o object
+	if err := json.Unmarshal(b, &o); err != nil {
+		return err
+	}
+	if o.Type != expected {
+		return fmt.Errorf("%s: expected %s, got %q", where(path), expected, o.Type)
+	}
+	return nil
+}
+
+func where(path string) string {
+	if path == "" {
+		return "geojson"
+	}
+	return "geojson " + path
+}
+
+// Minify validates GeoJSON and returns it without insignificant whitespace.
+func Minify(b []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil, errors.New("geojson: empty file")
+	}
+	if err := Validate(b); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geojson
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	var tests = []struct {
+		in    string
+		valid bool
+	}{
+		{`{"type": "Point", "coordinates": [1, 2]}`, true},
+		{`{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "geometry": {"type": "LineString", "coordinates": [[1, 2], [3, 4]]}, "properties": {}},
+			{"type": "Feature", "geometry": null}
+		]}`, true},
+		{`{"type": "FeatureCollection", "features": [{"type": "Point", "coordinates": [1, 2]}]}`, false},
+		{`{"type": "Feature"}`, false},
+		{`{"type": "Circle", "coordinates": [1, 2]}`, false},
+		{`{"type": "Point"}`, false},
+		{`[1, 2]`, false},
+	}
+	for i, v := range tests {
+		err := Validate([]byte(v.in))
+		if v.valid && err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+		}
+		if !v.valid && err == nil {
+			t.Errorf("%d: expected error", i)
+		}
+	}
+}
+
+func TestMinify(t *testing.T) {
+	out, err := Minify([]byte("{\n  \"type\": \"Point\",\n  \"coordinates\": [1, 2]\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"type":"Point","coordinates":[1,2]}`; string(out) != expected {
+		t.Errorf("expected %s, got %s", expected, out)
+	}
+}
@@ -1,6 +1,6 @@
 module github.com/dchest/kkr
 
-go 1.16
+go 1.19
 
 require (
 	github.com/andybalholm/brotli v1.0.4
@@ -8,7 +8,7 @@ require (
 	github.com/dchest/htmlmin v1.2.0
 	github.com/dchest/jsmin v0.0.0-20220218165748-59f39799265f
 	github.com/dchest/stemmer v0.0.0-20161207102402-66719a20c4b5
-	github.com/russross/blackfriday/v2 v2.1.0
+	github.com/yuin/goldmark v1.7.8
 	golang.org/x/net v0.22.0
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
 	gopkg.in/yaml.v3 v3.0.1
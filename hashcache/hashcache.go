@@ -0,0 +1,125 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package hashcache implements an on-disk cache of results of expensive
+// transformations (such as compression) addressed by the hash of input.
+//
+// Entries which were not used since the cache was opened can be
+// removed with Prune, so that the cache doesn't grow indefinitely.
+package hashcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type Cache struct {
+	mu   sync.Mutex
+	dir  string
+	used map[string]bool
+}
+
+// New returns a cache, which stores entries in dir.
+func New(dir string) *Cache {
+	return &Cache{
+		dir:  dir,
+		used: make(map[string]bool),
+	}
+}
+
+func (c *Cache) filename(kind string, input []byte) string {
+	h := sha256.New()
+	h.Write([]byte(kind))
+	h.Write([]byte{0})
+	h.Write(input)
+	sum := hex.EncodeToString(h.Sum(nil))
+	return filepath.Join(c.dir, sum[:2], sum[2:])
+}
+
+func (c *Cache) markUsed(filename string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.used[filename] = true
+}
+
+// Get returns cached result of transformation of the given kind
+// (for example, "gz") of input.
+func (c *Cache) Get(kind string, input []byte) ([]byte, bool) {
+	filename := c.filename(kind, input)
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, false
+	}
+	c.markUsed(filename)
+	return b, true
+}
+
+// Put stores result of transformation of the given kind of input.
+func (c *Cache) Put(kind string, input, result []byte) error {
+	filename := c.filename(kind, input)
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(filename), ".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(result); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), filename); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	c.markUsed(filename)
+	return nil
+}
+
+// Do returns cached result of transformation of the given kind
+// of input or, if it's not cached, calls fn and caches its result.
+func (c *Cache) Do(kind string, input []byte, fn func([]byte) ([]byte, error)) ([]byte, error) {
+	if b, ok := c.Get(kind, input); ok {
+		return b, nil
+	}
+	b, err := fn(input)
+	if err != nil {
+		return nil, err
+	}
+	return b, c.Put(kind, input, b)
+}
+
+// Prune removes entries that weren't used since the cache was created
+// and returns the number of removed entries.
+func (c *Cache) Prune() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	err := filepath.Walk(c.dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() || c.used[path] {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		n++
+		return nil
+	})
+	if os.IsNotExist(err) {
+		err = nil
+	}
+	return n, err
+}
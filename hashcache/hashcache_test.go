@@ -0,0 +1,54 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hashcache
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	calls := 0
+	upper := func(in []byte) ([]byte, error) {
+		calls++
+		return bytes.ToUpper(in), nil
+	}
+	c := New(dir)
+	for i := 0; i < 2; i++ {
+		out, err := c.Do("upper", []byte("hello"), upper)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != "HELLO" {
+			t.Fatalf("got %q", out)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("fn called %d times, expected 1", calls)
+	}
+	if _, ok := c.Get("lower", []byte("hello")); ok {
+		t.Errorf("got result for a different kind")
+	}
+	if _, err := c.Do("upper", []byte("bye"), upper); err != nil {
+		t.Fatal(err)
+	}
+
+	// New cache uses only "hello", so "bye" is pruned.
+	c = New(dir)
+	if _, ok := c.Get("upper", []byte("hello")); !ok {
+		t.Fatalf("cached result not found")
+	}
+	n, err := c.Prune()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("pruned %d entries, expected 1", n)
+	}
+	if _, ok := c.Get("upper", []byte("bye")); ok {
+		t.Errorf("pruned entry found")
+	}
+}
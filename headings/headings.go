@@ -0,0 +1,244 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package headings implements adding IDs and permalink anchors
+// to HTML headings and generating tables of contents from them.
+package headings
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/dchest/kkr/utils"
+)
+
+// DefaultAnchor is a default markup of heading anchor.
+const DefaultAnchor = `<a class="anchor" href="#{{.ID}}" aria-label="Permalink">¶</a>`
+
+// site.yml -> anchors:
+type Options struct {
+	Levels []int  `yaml:"levels"` // heading levels to decorate, 2 to 6 by default
+	Markup string `yaml:"markup"` // template of anchor markup, DefaultAnchor if empty
+	Before bool   `yaml:"before"` // put anchor before heading text
+}
+
+type Heading struct {
+	Level int
+	ID    string
+	Text  string // plain text
+}
+
+var headingAtoms = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+var defaultLevels = []int{2, 3, 4, 5, 6}
+
+func hasLevel(levels []int, level int) bool {
+	if len(levels) == 0 {
+		levels = defaultLevels
+	}
+	for _, v := range levels {
+		if v == level {
+			return true
+		}
+	}
+	return false
+}
+
+// Slug returns ID for the heading text.
+func Slug(text string) string {
+	s := utils.ToSlug(text)
+	if s == "" {
+		return "section"
+	}
+	return s
+}
+
+func startID(t html.Token) string {
+	for _, a := range t.Attr {
+		if a.Key == "id" {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// process finds headings of the given levels, assigns IDs to those that
+// don't have them, and calls decorate, if it's not nil, for each heading
+// to get markup to insert after (or before) its content.
+func process(in string, levels []int, before bool, decorate func(h *Heading) (string, error)) (string, []*Heading, error) {
+	var out strings.Builder
+	var headings []*Heading
+	seen := make(map[string]int)
+	z := html.NewTokenizer(strings.NewReader(in))
+	var cur *Heading
+	var start html.Token
+	var inner, text strings.Builder
+	inSelfLink := false
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				return "", nil, err
+			}
+			break
+		}
+		raw := string(z.Raw())
+		if cur == nil {
+			if tt == html.StartTagToken {
+				t := z.Token()
+				if level, ok := headingAtoms[t.DataAtom]; ok && hasLevel(levels, level) {
+					start = t
+					cur = &Heading{Level: level}
+					inSelfLink = false
+					inner.Reset()
+					text.Reset()
+					continue
+				}
+			}
+			out.WriteString(raw)
+			continue
+		}
+		if tt == html.EndTagToken {
+			name, _ := z.TagName()
+			if headingAtoms[atom.Lookup(name)] == cur.Level {
+				cur.Text = strings.Join(strings.Fields(text.String()), " ")
+				cur.ID = startID(start)
+				if cur.ID == "" {
+					id := Slug(cur.Text)
+					if n := seen[id]; n > 0 {
+						id += "-" + strconv.Itoa(n)
+					}
+					cur.ID = id
+					start.Attr = append(start.Attr, html.Attribute{Key: "id", Val: id})
+				}
+				seen[cur.ID]++
+				anchor := ""
+				if decorate != nil {
+					var err error
+					if anchor, err = decorate(cur); err != nil {
+						return "", nil, err
+					}
+				}
+				out.WriteString(start.String())
+				if before {
+					out.WriteString(anchor)
+					out.WriteString(inner.String())
+				} else {
+					out.WriteString(inner.String())
+					out.WriteString(anchor)
+				}
+				out.WriteString(raw)
+				headings = append(headings, cur)
+				cur = nil
+				continue
+			}
+		}
+		switch tt {
+		case html.TextToken:
+			if !inSelfLink {
+				text.WriteString(html.UnescapeString(raw))
+			}
+		case html.StartTagToken:
+			// Skip text of existing anchor linking to the heading.
+			if t := z.Token(); t.DataAtom == atom.A {
+				for _, a := range t.Attr {
+					if a.Key == "href" && a.Val == "#"+startID(start) && a.Val != "#" {
+						inSelfLink = true
+					}
+				}
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); atom.Lookup(name) == atom.A {
+				inSelfLink = false
+			}
+		}
+		inner.WriteString(raw)
+	}
+	if cur != nil {
+		// Unclosed heading.
+		out.WriteString(start.String())
+		out.WriteString(inner.String())
+	}
+	return out.String(), headings, nil
+}
+
+// Anchors adds IDs and permalink anchors to headings in HTML.
+func Anchors(in string, opts *Options) (string, error) {
+	markup := opts.Markup
+	if markup == "" {
+		markup = DefaultAnchor
+	}
+	t, err := template.New("anchor").Parse(markup)
+	if err != nil {
+		return "", fmt.Errorf("anchors markup: %w", err)
+	}
+	out, _, err := process(in, opts.Levels, opts.Before, func(h *Heading) (string, error) {
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, h); err != nil {
+			return "", err
+		}
+		if opts.Before {
+			return buf.String() + " ", nil
+		}
+		return " " + buf.String(), nil
+	})
+	return out, err
+}
+
+// IDs adds IDs to headings of the given levels (2 to 6 if empty)
+// that don't have them, without anchors.
+func IDs(in string, levels []int) (string, error) {
+	out, _, err := process(in, levels, false, nil)
+	return out, err
+}
+
+// Find returns headings of the given levels (2 to 6 if empty)
+// with the same IDs that Anchors assigns to them.
+func Find(in string, levels []int) ([]*Heading, error) {
+	_, headings, err := process(in, levels, false, nil)
+	return headings, err
+}
+
+// TOC returns a table of contents as nested lists of links to headings.
+func TOC(headings []*Heading) string {
+	if len(headings) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(`<ul class="toc">`)
+	var stack []int // levels of open lists
+	stack = append(stack, headings[0].Level)
+	for i, h := range headings {
+		if i > 0 {
+			switch top := stack[len(stack)-1]; {
+			case h.Level > top:
+				b.WriteString("<ul>")
+				stack = append(stack, h.Level)
+			default:
+				b.WriteString("</li>")
+				for len(stack) > 1 && h.Level < stack[len(stack)-1] {
+					b.WriteString("</ul></li>")
+					stack = stack[:len(stack)-1]
+				}
+			}
+		}
+		fmt.Fprintf(&b, `<li><a href="#%s">%s</a>`, html.EscapeString(h.ID), html.EscapeString(h.Text))
+	}
+	b.WriteString("</li>")
+	for len(stack) > 1 {
+		b.WriteString("</ul></li>")
+		stack = stack[:len(stack)-1]
+	}
+	b.WriteString("</ul>")
+	return b.String()
+}
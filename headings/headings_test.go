@@ -0,0 +1,73 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package headings
+
+import "testing"
+
+const testHTML = `<h1>Title</h1><h2>Intro &amp; Setup</h2><p>x</p><h3 id="custom">Sub <em>one</em></h3><h2>Intro &amp; Setup</h2><h4>Deep</h4><h2>End</h2>`
+
+func TestAnchors(t *testing.T) {
+	out, err := Anchors(testHTML, &Options{Markup: `<a href="#{{.ID}}">#</a>`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<h1>Title</h1>` +
+		`<h2 id="intro-setup">Intro &amp; Setup <a href="#intro-setup">#</a></h2><p>x</p>` +
+		`<h3 id="custom">Sub <em>one</em> <a href="#custom">#</a></h3>` +
+		`<h2 id="intro-setup-1">Intro &amp; Setup <a href="#intro-setup-1">#</a></h2>` +
+		`<h4 id="deep">Deep <a href="#deep">#</a></h4>` +
+		`<h2 id="end">End <a href="#end">#</a></h2>`
+	if out != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, out)
+	}
+	out, err = Anchors(`<h2>A</h2>`, &Options{Levels: []int{2}, Before: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `<h2 id="a"><a class="anchor" href="#a" aria-label="Permalink">¶</a> A</h2>`
+	if out != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, out)
+	}
+}
+
+func TestTOC(t *testing.T) {
+	headings, err := Find(testHTML, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<ul class="toc">` +
+		`<li><a href="#intro-setup">Intro &amp; Setup</a><ul><li><a href="#custom">Sub one</a></li></ul></li>` +
+		`<li><a href="#intro-setup-1">Intro &amp; Setup</a><ul><li><a href="#deep">Deep</a></li></ul></li>` +
+		`<li><a href="#end">End</a></li></ul>`
+	if out := TOC(headings); out != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, out)
+	}
+}
+
+func TestFindAnchored(t *testing.T) {
+	in, err := Anchors(testHTML, &Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	headings, err := Find(in, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := Find(testHTML, nil)
+	if TOC(headings) != TOC(expected) {
+		t.Errorf("expected\n%s\ngot\n%s", TOC(expected), TOC(headings))
+	}
+}
+
+func TestIDs(t *testing.T) {
+	out, err := IDs(`<h1>Title</h1><h2 id="x">X</h2><h2>Title</h2>`, []int{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<h1 id="title">Title</h1><h2 id="x">X</h2><h2 id="title-1">Title</h2>`
+	if out != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, out)
+	}
+}
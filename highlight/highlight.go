@@ -0,0 +1,179 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package highlight implements syntax highlighting of code at build time.
+//
+// Tokens are marked with the same short classes as used by Pygments
+// and Chroma (e.g. "k" for keywords, "s" for strings), so their
+// stylesheets can be used, or with inline styles.
+package highlight
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+)
+
+// Options is site.yml -> markup: highlight:.
+type Options struct {
+	Style  string `yaml:"style"`  // github (default) or monokai
+	Inline bool   `yaml:"inline"` // use inline styles instead of classes
+}
+
+// Class is the class of highlighted pre elements.
+const Class = "chroma"
+
+// Token classes.
+const (
+	plain     = ""
+	comment   = "c"
+	keyword   = "k"
+	typ       = "kt"
+	constant  = "kc"
+	str       = "s"
+	number    = "m"
+	builtin   = "nb"
+	variable  = "nv"
+	tag       = "nt"
+	attribute = "na"
+	inserted  = "gi"
+	deleted   = "gd"
+	heading   = "gh"
+	subhead   = "gu"
+)
+
+type token struct {
+	class string
+	text  string
+}
+
+// Style maps token classes to CSS declarations. Empty class is the
+// style of the whole block.
+type Style map[string]string
+
+var styles = map[string]Style{
+	"github": {
+		plain:     "color:#24292e;background-color:#f6f8fa",
+		comment:   "color:#6a737d;font-style:italic",
+		keyword:   "color:#d73a49",
+		typ:       "color:#d73a49",
+		constant:  "color:#005cc5",
+		str:       "color:#032f62",
+		number:    "color:#005cc5",
+		builtin:   "color:#6f42c1",
+		variable:  "color:#e36209",
+		tag:       "color:#22863a",
+		attribute: "color:#6f42c1",
+		inserted:  "color:#22863a;background-color:#f0fff4",
+		deleted:   "color:#b31d28;background-color:#ffeef0",
+		heading:   "color:#24292e;font-weight:bold",
+		subhead:   "color:#6f42c1;font-weight:bold",
+	},
+	"monokai": {
+		plain:     "color:#f8f8f2;background-color:#272822",
+		comment:   "color:#75715e;font-style:italic",
+		keyword:   "color:#f92672",
+		typ:       "color:#66d9ef",
+		constant:  "color:#ae81ff",
+		str:       "color:#e6db74",
+		number:    "color:#ae81ff",
+		builtin:   "color:#a6e22e",
+		variable:  "color:#fd971f",
+		tag:       "color:#f92672",
+		attribute: "color:#a6e22e",
+		inserted:  "color:#a6e22e",
+		deleted:   "color:#f92672",
+		heading:   "color:#f8f8f2;font-weight:bold",
+		subhead:   "color:#75715e;font-weight:bold",
+	},
+}
+
+func (o *Options) style() (Style, error) {
+	name := o.Style
+	if name == "" {
+		name = "github"
+	}
+	st, ok := styles[name]
+	if !ok {
+		return nil, fmt.Errorf("highlight: unknown style %q", name)
+	}
+	return st, nil
+}
+
+// Check returns an error if options are invalid.
+func (o *Options) Check() error {
+	_, err := o.style()
+	return err
+}
+
+// CSS returns stylesheet of the style for highlighting with classes.
+func (o *Options) CSS() (string, error) {
+	st, err := o.style()
+	if err != nil {
+		return "", err
+	}
+	classes := make([]string, 0, len(st))
+	for c := range st {
+		classes = append(classes, c)
+	}
+	sort.Strings(classes)
+	var b strings.Builder
+	for _, c := range classes {
+		if c == plain {
+			fmt.Fprintf(&b, "pre.%s { %s }\n", Class, st[c])
+		} else {
+			fmt.Fprintf(&b, ".%s .%s { %s }\n", Class, c, st[c])
+		}
+	}
+	return b.String(), nil
+}
+
+// Supported returns true if the language is supported.
+func Supported(lang string) bool {
+	return lookup(lang) != nil
+}
+
+// HTML returns the highlighted code in a pre element, and false
+// if the language is not supported.
+func HTML(code, lang string, o *Options) (string, bool) {
+	l := lookup(lang)
+	if l == nil {
+		return "", false
+	}
+	st, err := o.style()
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	if o.Inline {
+		fmt.Fprintf(&b, `<pre style="%s">`, st[plain])
+	} else {
+		fmt.Fprintf(&b, `<pre class="%s">`, Class)
+	}
+	fmt.Fprintf(&b, `<code class="language-%s">`, html.EscapeString(lang))
+	// Adjacent tokens of the same class are put into one span.
+	class := plain
+	for _, t := range l.tokenize(code) {
+		if t.class != class {
+			if class != plain {
+				b.WriteString("</span>")
+			}
+			class = t.class
+			switch {
+			case class == plain:
+			case o.Inline:
+				fmt.Fprintf(&b, `<span style="%s">`, st[class])
+			default:
+				fmt.Fprintf(&b, `<span class="%s">`, class)
+			}
+		}
+		b.WriteString(html.EscapeString(t.text))
+	}
+	if class != plain {
+		b.WriteString("</span>")
+	}
+	b.WriteString("</code></pre>\n")
+	return b.String(), true
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package highlight
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	for _, v := range []struct {
+		lang, code, html string
+	}{
+		{
+			"go",
+			"func f() string { return `a<b` // x\n}",
+			`<pre class="chroma"><code class="language-go"><span class="k">func</span> f() <span class="kt">string</span> { ` +
+				`<span class="k">return</span> <span class="s">` + "`a&lt;b`" + `</span> <span class="c">// x</span>` + "\n}</code></pre>\n",
+		},
+		{
+			"py",
+			`x = """a"b""" + 1.5e-3`,
+			`<pre class="chroma"><code class="language-py">x = <span class="s">&#34;&#34;&#34;a&#34;b&#34;&#34;&#34;</span> + <span class="m">1.5e-3</span></code></pre>` + "\n",
+		},
+		{
+			"json",
+			`{"a": "b", "c": true}`,
+			`<pre class="chroma"><code class="language-json">{<span class="nt">&#34;a&#34;</span>: <span class="s">&#34;b&#34;</span>, ` +
+				`<span class="nt">&#34;c&#34;</span>: <span class="kc">true</span>}</code></pre>` + "\n",
+		},
+		{
+			"html",
+			`<a href="/">x</a><!-- c -->`,
+			`<pre class="chroma"><code class="language-html"><span class="nt">&lt;a</span> <span class="na">href</span>=<span class="s">&#34;/&#34;</span>` +
+				`<span class="nt">&gt;</span>x<span class="nt">&lt;/a&gt;</span><span class="c">&lt;!-- c --&gt;</span></code></pre>` + "\n",
+		},
+		{
+			"diff",
+			"@@ -1 +1 @@\n-a\n+b\n",
+			`<pre class="chroma"><code class="language-diff"><span class="gu">@@ -1 +1 @@` + "\n" + `</span><span class="gd">-a` + "\n" +
+				`</span><span class="gi">+b` + "\n" + `</span></code></pre>` + "\n",
+		},
+		{
+			"sh",
+			"echo \"$HOME\" ${X} # c",
+			`<pre class="chroma"><code class="language-sh"><span class="nb">echo</span> <span class="s">&#34;$HOME&#34;</span> <span class="nv">${X}</span> <span class="c"># c</span></code></pre>` + "\n",
+		},
+	} {
+		got, ok := HTML(v.code, v.lang, &Options{})
+		if !ok {
+			t.Fatalf("%s: not supported", v.lang)
+		}
+		if got != v.html {
+			t.Errorf("%s:\ngot  %s\nwant %s", v.lang, got, v.html)
+		}
+	}
+	if _, ok := HTML("x", "mermaid", &Options{}); ok {
+		t.Errorf("unsupported language highlighted")
+	}
+}
+
+func TestInline(t *testing.T) {
+	got, _ := HTML("nil", "go", &Options{Style: "monokai", Inline: true})
+	want := `<pre style="color:#f8f8f2;background-color:#272822"><code class="language-go"><span style="color:#ae81ff">nil</span></code></pre>` + "\n"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestCSS(t *testing.T) {
+	css, err := (&Options{}).CSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(css, "pre.chroma { color:#24292e;") || !strings.Contains(css, ".chroma .k { color:#d73a49 }") {
+		t.Errorf("unexpected CSS: %s", css)
+	}
+	if _, err := (&Options{Style: "unknown"}).CSS(); err == nil {
+		t.Errorf("expected error")
+	}
+}
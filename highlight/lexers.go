@@ -0,0 +1,445 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package highlight
+
+import (
+	"strings"
+)
+
+// lexer splits code into tokens.
+type lexer interface {
+	tokenize(code string) []token
+}
+
+// language describes a C-like language for the generic lexer.
+type language struct {
+	lineComments    []string
+	blockComment    [2]string
+	quotes          string // string delimiters with backslash escapes
+	rawQuotes       string // string delimiters without escapes, may span lines
+	tripleQuotes    bool   // """ and ''' strings
+	variablePrefix  byte   // e.g. $ in shell
+	keyMarker       byte   // marks preceding identifier or string as a key, e.g. : in YAML
+	caseInsensitive bool
+	keywords        map[string]bool
+	types           map[string]bool
+	constants       map[string]bool
+	builtins        map[string]bool
+}
+
+func words(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+var (
+	cKeywords = "auto break case const continue default do else enum extern for goto if inline register " +
+		"restrict return sizeof static struct switch typedef union volatile while"
+	cTypes = "char double float int long short signed unsigned void size_t bool " +
+		"int8_t int16_t int32_t int64_t uint8_t uint16_t uint32_t uint64_t"
+	jsKeywords = "async await break case catch class const continue debugger default delete do else export " +
+		"extends finally for from function if import in instanceof let new of return static super switch " +
+		"this throw try typeof var void while with yield"
+	jsConstants = "true false null undefined NaN Infinity"
+)
+
+var languages = map[string]lexer{
+	"go": &language{
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+		rawQuotes:    "`",
+		keywords: words("break case chan const continue default defer else fallthrough for func go goto if " +
+			"import interface map package range return select struct switch type var"),
+		types: words("bool byte complex64 complex128 error float32 float64 int int8 int16 int32 int64 " +
+			"rune string uint uint8 uint16 uint32 uint64 uintptr any"),
+		constants: words("true false nil iota"),
+		builtins:  words("append cap close complex copy delete imag len make new panic print println real recover"),
+	},
+	"c": &language{
+		lineComments: []string{"//", "#"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+		keywords:     words(cKeywords),
+		types:        words(cTypes),
+		constants:    words("NULL true false"),
+	},
+	"cpp": &language{
+		lineComments: []string{"//", "#"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+		keywords: words(cKeywords + " catch class constexpr delete explicit friend namespace new noexcept " +
+			"operator override private protected public template this throw try typename using virtual"),
+		types:     words(cTypes + " auto wchar_t"),
+		constants: words("NULL nullptr true false"),
+	},
+	"java": &language{
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+		keywords: words("abstract assert break case catch class continue default do else enum extends final " +
+			"finally for if implements import instanceof interface native new package private protected public " +
+			"return static super switch synchronized this throw throws transient try var volatile while"),
+		types:     words("boolean byte char double float int long short void String"),
+		constants: words("true false null"),
+	},
+	"javascript": &language{
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+		rawQuotes:    "`",
+		keywords:     words(jsKeywords),
+		constants:    words(jsConstants),
+		builtins:     words("Array Object String Number Boolean Promise Map Set JSON Math console document window"),
+	},
+	"typescript": &language{
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+		rawQuotes:    "`",
+		keywords:     words(jsKeywords + " abstract as declare enum implements interface keyof namespace private protected public readonly type"),
+		types:        words("any boolean never number object string symbol unknown void"),
+		constants:    words(jsConstants),
+		builtins:     words("Array Object String Number Boolean Promise Map Set JSON Math console document window"),
+	},
+	"python": &language{
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		tripleQuotes: true,
+		keywords: words("and as assert async await break class continue def del elif else except finally for " +
+			"from global if import in is lambda nonlocal not or pass raise return try while with yield"),
+		constants: words("True False None"),
+		builtins: words("abs all any bool dict enumerate filter float int isinstance len list map max min " +
+			"open print range repr set sorted str sum super tuple type zip"),
+	},
+	"ruby": &language{
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		keywords: words("alias and begin break case class def do else elsif end ensure for if in " +
+			"module next not or redo rescue retry return self super then undef unless until when while yield"),
+		constants: words("true false nil"),
+		builtins:  words("puts print require attr_accessor attr_reader attr_writer lambda proc raise"),
+	},
+	"rust": &language{
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"`,
+		keywords: words("as async await break const continue crate dyn else enum extern fn for if impl in let " +
+			"loop match mod move mut pub ref return self Self static struct super trait type unsafe use where while"),
+		types: words("bool char f32 f64 i8 i16 i32 i64 i128 isize str u8 u16 u32 u64 u128 usize " +
+			"String Vec Option Result Box"),
+		constants: words("true false None Some Ok Err"),
+	},
+	"shell": &language{
+		lineComments:   []string{"#"},
+		quotes:         `"`,
+		rawQuotes:      "'",
+		variablePrefix: '$',
+		keywords: words("if then else elif fi for while until do done case esac in function return " +
+			"export local readonly select"),
+		builtins: words("cd echo exit printf read set shift source test trap unset"),
+	},
+	"sql": &language{
+		lineComments:    []string{"--"},
+		blockComment:    [2]string{"/*", "*/"},
+		quotes:          `"'`,
+		caseInsensitive: true,
+		keywords: words("add all alter and as asc begin between by case check column commit constraint create " +
+			"default delete desc distinct drop else end exists foreign from group having if in index inner " +
+			"insert into is join key left like limit not null offset on or order outer primary references " +
+			"right rollback select set table then transaction union unique update values view when where with"),
+		types:     words("bigint blob boolean char date decimal double float int integer numeric real text timestamp varchar"),
+		constants: words("true false"),
+		builtins:  words("avg coalesce count max min sum"),
+	},
+	"css": &language{
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+		keyMarker:    ':',
+		constants:    words("inherit initial none auto important"),
+	},
+	"json": &language{
+		quotes:    `"`,
+		keyMarker: ':',
+		constants: words("true false null"),
+	},
+	"yaml": &language{
+		lineComments: []string{"#"},
+		quotes:       `"`,
+		rawQuotes:    "'",
+		keyMarker:    ':',
+		constants:    words("true false null yes no on off"),
+	},
+	"toml": &language{
+		lineComments: []string{"#"},
+		quotes:       `"`,
+		rawQuotes:    "'",
+		tripleQuotes: true,
+		keyMarker:    '=',
+		constants:    words("true false inf nan"),
+	},
+	"html": markupLexer{},
+	"diff": diffLexer{},
+}
+
+var aliases = map[string]string{
+	"golang": "go", "h": "c", "c++": "cpp", "cc": "cpp", "hpp": "cpp",
+	"js": "javascript", "jsx": "javascript", "mjs": "javascript", "ts": "typescript", "tsx": "typescript",
+	"py": "python", "python3": "python", "rb": "ruby", "rs": "rust",
+	"sh": "shell", "bash": "shell", "zsh": "shell", "console": "shell",
+	"yml": "yaml", "xml": "html", "svg": "html", "htm": "html", "xhtml": "html", "patch": "diff",
+}
+
+func lookup(lang string) lexer {
+	lang = strings.ToLower(lang)
+	if a, ok := aliases[lang]; ok {
+		lang = a
+	}
+	return languages[lang]
+}
+
+func isIdentStart(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || c >= 0x80
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || '0' <= c && c <= '9'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// tokens collects non-empty tokens.
+type tokens []token
+
+func (ts *tokens) add(class, text string) {
+	if text != "" {
+		*ts = append(*ts, token{class, text})
+	}
+}
+
+// isKey returns true if the text after a token starts with the key marker.
+func (l *language) isKey(rest string) bool {
+	if l.keyMarker == 0 {
+		return false
+	}
+	rest = strings.TrimLeft(rest, " \t")
+	return len(rest) > 0 && rest[0] == l.keyMarker
+}
+
+// readString returns length of the string starting at the beginning
+// of s with the quote q.
+func readString(s string, q string, escapes, multiline bool) int {
+	i := len(q)
+	for i < len(s) {
+		switch {
+		case escapes && s[i] == '\\':
+			i += 2
+			continue
+		case strings.HasPrefix(s[i:], q):
+			return i + len(q)
+		case s[i] == '\n' && !multiline:
+			return i
+		}
+		i++
+	}
+	return len(s)
+}
+
+func (l *language) tokenize(code string) []token {
+	var ts tokens
+	i := 0
+	for i < len(code) {
+		s := code[i:]
+		c := s[0]
+		n := 0
+		class := plain
+		ident := false
+		switch {
+		case l.blockComment[0] != "" && strings.HasPrefix(s, l.blockComment[0]):
+			n = strings.Index(s[len(l.blockComment[0]):], l.blockComment[1])
+			if n < 0 {
+				n = len(s)
+			} else {
+				n += len(l.blockComment[0]) + len(l.blockComment[1])
+			}
+			class = comment
+		case l.hasLineComment(s):
+			n = strings.IndexByte(s, '\n')
+			if n < 0 {
+				n = len(s)
+			}
+			class = comment
+		case l.tripleQuotes && (strings.HasPrefix(s, `"""`) || strings.HasPrefix(s, "'''")):
+			n = readString(s, s[:3], true, true)
+			class = str
+		case strings.IndexByte(l.quotes, c) >= 0:
+			n = readString(s, s[:1], true, false)
+			class = str
+		case strings.IndexByte(l.rawQuotes, c) >= 0:
+			n = readString(s, s[:1], false, true)
+			class = str
+		case l.variablePrefix != 0 && c == l.variablePrefix && len(s) > 1 && (isIdentStart(s[1]) || s[1] == '{'):
+			n = 1
+			if s[1] == '{' {
+				if j := strings.IndexByte(s, '}'); j > 0 {
+					n = j + 1
+				}
+			} else {
+				for n < len(s) && isIdentChar(s[n]) {
+					n++
+				}
+			}
+			class = variable
+		case isDigit(c) || c == '.' && len(s) > 1 && isDigit(s[1]):
+			for n < len(s) && (isIdentChar(s[n]) || s[n] == '.' ||
+				(s[n] == '+' || s[n] == '-') && (s[n-1] == 'e' || s[n-1] == 'E')) {
+				n++
+			}
+			class = number
+		case isIdentStart(c):
+			for n < len(s) && (isIdentChar(s[n]) || s[n] == '-' && l.keyMarker != 0) {
+				n++
+			}
+			class = l.classify(s[:n])
+			ident = true
+		default:
+			n = 1
+		}
+		if (class == str || ident) && l.isKey(s[n:]) {
+			class = tag
+		}
+		ts.add(class, s[:n])
+		i += n
+	}
+	return ts
+}
+
+func (l *language) hasLineComment(s string) bool {
+	for _, p := range l.lineComments {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *language) classify(word string) string {
+	if l.caseInsensitive {
+		word = strings.ToLower(word)
+	}
+	switch {
+	case l.keywords[word]:
+		return keyword
+	case l.types[word]:
+		return typ
+	case l.constants[word]:
+		return constant
+	case l.builtins[word]:
+		return builtin
+	}
+	return plain
+}
+
+// markupLexer highlights HTML and XML tags, attributes and comments.
+type markupLexer struct{}
+
+func (markupLexer) tokenize(code string) []token {
+	var ts tokens
+	for len(code) > 0 {
+		i := strings.IndexByte(code, '<')
+		if i < 0 {
+			ts.add(plain, code)
+			break
+		}
+		ts.add(plain, code[:i])
+		code = code[i:]
+		if strings.HasPrefix(code, "<!--") {
+			n := strings.Index(code, "-->")
+			if n < 0 {
+				n = len(code)
+			} else {
+				n += 3
+			}
+			ts.add(comment, code[:n])
+			code = code[n:]
+			continue
+		}
+		// Tag name.
+		n := 1
+		for n < len(code) && (code[n] == '/' || code[n] == '?' || code[n] == '!') {
+			n++
+		}
+		if n == len(code) || !isIdentStart(code[n]) {
+			ts.add(plain, code[:1])
+			code = code[1:]
+			continue
+		}
+		for n < len(code) && (isIdentChar(code[n]) || code[n] == '-' || code[n] == ':' || code[n] == '.') {
+			n++
+		}
+		ts.add(tag, code[:n])
+		code = code[n:]
+		// Attributes until the end of tag.
+	attrs:
+		for len(code) > 0 && code[0] != '<' {
+			c := code[0]
+			switch {
+			case c == '>' || c == '/' && strings.HasPrefix(code, "/>") || c == '?' && strings.HasPrefix(code, "?>"):
+				n = strings.IndexByte(code, '>') + 1
+				ts.add(tag, code[:n])
+				code = code[n:]
+				break attrs
+			case c == '"' || c == '\'':
+				n = readString(code, code[:1], false, true)
+				ts.add(str, code[:n])
+				code = code[n:]
+			case isIdentStart(c):
+				n = 0
+				for n < len(code) && (isIdentChar(code[n]) || code[n] == '-' || code[n] == ':') {
+					n++
+				}
+				ts.add(attribute, code[:n])
+				code = code[n:]
+			default:
+				ts.add(plain, code[:1])
+				code = code[1:]
+			}
+		}
+	}
+	return ts
+}
+
+// diffLexer highlights added and removed lines of unified diffs.
+type diffLexer struct{}
+
+func (diffLexer) tokenize(code string) []token {
+	var ts tokens
+	for len(code) > 0 {
+		n := strings.IndexByte(code, '\n') + 1
+		if n == 0 {
+			n = len(code)
+		}
+		line := code[:n]
+		class := plain
+		switch {
+		case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "diff "):
+			class = heading
+		case strings.HasPrefix(line, "@@"):
+			class = subhead
+		case strings.HasPrefix(line, "+"):
+			class = inserted
+		case strings.HasPrefix(line, "-"):
+			class = deleted
+		}
+		ts.add(class, line)
+		code = code[n:]
+	}
+	return ts
+}
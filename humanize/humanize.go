@@ -0,0 +1,142 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package humanize implements formatting of numbers and file sizes
+// with digit grouping and decimal separators of the language.
+package humanize
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type separators struct {
+	group   string
+	decimal string
+}
+
+const (
+	nbsp       = "\u00a0"
+	narrowNbsp = "\u202f" // French
+)
+
+var langSeparators = map[string]separators{
+	"en": {",", "."},
+	"ja": {",", "."},
+	"ko": {",", "."},
+	"zh": {",", "."},
+	"he": {",", "."},
+	"th": {",", "."},
+	"de": {".", ","},
+	"da": {".", ","},
+	"el": {".", ","},
+	"es": {".", ","},
+	"id": {".", ","},
+	"it": {".", ","},
+	"nl": {".", ","},
+	"pt": {".", ","},
+	"ro": {".", ","},
+	"sl": {".", ","},
+	"tr": {".", ","},
+	"bg": {nbsp, ","},
+	"cs": {nbsp, ","},
+	"fi": {nbsp, ","},
+	"hu": {nbsp, ","},
+	"nb": {nbsp, ","},
+	"no": {nbsp, ","},
+	"pl": {nbsp, ","},
+	"ru": {nbsp, ","},
+	"sk": {nbsp, ","},
+	"sv": {nbsp, ","},
+	"uk": {nbsp, ","},
+	"fr": {narrowNbsp, ","},
+}
+
+// separatorsFor returns separators for the language, such as "de"
+// or "de-AT", using English ones for unknown or empty languages.
+func separatorsFor(lang string) separators {
+	lang = strings.ToLower(lang)
+	switch lang {
+	case "de-ch", "de_ch":
+		return separators{"\u2019", "."}
+	}
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	if s, ok := langSeparators[lang]; ok {
+		return s
+	}
+	return langSeparators["en"]
+}
+
+// format localizes a number formatted by strconv, such as "-1234.5".
+func format(s, lang string) string {
+	seps := separatorsFor(lang)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	frac := ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		s, frac = s[:i], seps.decimal+s[i+1:]
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	for i := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteString(seps.group)
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteString(frac)
+	return b.String()
+}
+
+// Int returns n with digits grouped by thousands, e.g. "1,234".
+func Int(n int64, lang string) string {
+	return format(strconv.FormatInt(n, 10), lang)
+}
+
+// Float returns f with digits grouped by thousands and the given number
+// of decimals, or as many as needed if decimals is negative.
+func Float(f float64, decimals int, lang string) string {
+	return format(strconv.FormatFloat(f, 'f', decimals, 64), lang)
+}
+
+// FileSize returns human-readable size in binary units,
+// e.g. "1.5 MB" for 1572864 bytes.
+func FileSize(n int64, lang string) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return Float(float64(n)/float64(div), 1, lang) + " " + string("KMGTPE"[exp]) + "B"
+}
+
+// ParseSize parses size in bytes, optionally with binary unit as
+// returned by FileSize, e.g. "100 KB", "1.5MB" or "2048".
+func ParseSize(s string) (int64, error) {
+	t := strings.ToUpper(strings.TrimSpace(s))
+	t = strings.TrimSuffix(strings.TrimSuffix(t, "B"), "I") // KiB, KB, K
+	mult := int64(1)
+	if n := len(t); n > 0 {
+		if i := strings.IndexByte("KMGTPE", t[n-1]); i >= 0 {
+			for ; i >= 0; i-- {
+				mult *= 1024
+			}
+			t = t[:n-1]
+		}
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("bad size %q", s)
+	}
+	return int64(f * float64(mult)), nil
+}
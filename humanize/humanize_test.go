@@ -0,0 +1,60 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package humanize
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	for _, v := range []struct {
+		got, want string
+	}{
+		{Int(0, ""), "0"},
+		{Int(999, "en"), "999"},
+		{Int(1234, "en"), "1,234"},
+		{Int(-1234567, "en-US"), "-1,234,567"},
+		{Int(1234567, "de"), "1.234.567"},
+		{Int(1234, "ru"), "1\u00a0234"},
+		{Float(1234.5, -1, "en"), "1,234.5"},
+		{Float(1234.5, 2, "de-AT"), "1.234,50"},
+		{Float(1234.5, 1, "de-CH"), "1\u2019234.5"},
+		{Float(0.25, -1, "fr"), "0,25"},
+		{FileSize(100, "en"), "100 B"},
+		{FileSize(1536, "en"), "1.5 KB"},
+		{FileSize(1572864, "de"), "1,5 MB"},
+		{FileSize(1023*1024, "en"), "1,023.0 KB"},
+	} {
+		if v.got != v.want {
+			t.Errorf("got %q, want %q", v.got, v.want)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for _, v := range []struct {
+		in   string
+		want int64
+	}{
+		{"2048", 2048},
+		{"100 B", 100},
+		{"100KB", 102400},
+		{"1.5 MB", 1572864},
+		{"2k", 2048},
+		{"1 GiB", 1 << 30},
+	} {
+		got, err := ParseSize(v.in)
+		if err != nil {
+			t.Errorf("%q: %s", v.in, err)
+			continue
+		}
+		if got != v.want {
+			t.Errorf("%q: got %d, want %d", v.in, got, v.want)
+		}
+	}
+	for _, s := range []string{"", "KB", "-1", "1 XB"} {
+		if _, err := ParseSize(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
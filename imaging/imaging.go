@@ -0,0 +1,139 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package imaging implements decoding, resizing and encoding of images.
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	_ "image/gif" // for decoding
+)
+
+// Extensions contains extensions of files that can be resized.
+var Extensions = []string{".jpg", ".jpeg", ".png"}
+
+const JPEGQuality = 85
+
+// IsImage returns true if the file has one of the supported extensions.
+func IsImage(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, v := range Extensions {
+		if v == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// DecodeConfig returns dimensions of the image file.
+func DecodeConfig(filename string) (width, height int, err error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return 0, 0, err
+	}
+	c, _, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", filename, err)
+	}
+	return c.Width, c.Height, nil
+}
+
+// Decode decodes the image file.
+func Decode(filename string) (image.Image, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return img, nil
+}
+
+// Encode encodes the image into the format determined
+// by the filename extension (JPEG or PNG).
+func Encode(filename string, img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".jpg", ".jpeg":
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: JPEGQuality}); err != nil {
+			return nil, err
+		}
+	case ".png":
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("cannot encode image %s: unsupported format", filename)
+	}
+	return buf.Bytes(), nil
+}
+
+// FitSize returns dimensions scaled to fit into the given width,
+// preserving aspect ratio. Images are never upscaled.
+func FitSize(width, height, maxWidth int) (int, int) {
+	if width <= maxWidth || width == 0 {
+		return width, height
+	}
+	h := (height*maxWidth + width/2) / width
+	if h < 1 {
+		h = 1
+	}
+	return maxWidth, h
+}
+
+// Resize returns the image scaled down to fit into the given width,
+// preserving aspect ratio. It uses area averaging, which gives good
+// results for downscaling. Images are never upscaled.
+func Resize(src image.Image, maxWidth int) image.Image {
+	sb := src.Bounds()
+	sw, sh := sb.Dx(), sb.Dy()
+	dw, dh := FitSize(sw, sh, maxWidth)
+	if dw == sw && dh == sh {
+		return src
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0 := sb.Min.Y + y*sh/dh
+		y1 := sb.Min.Y + (y+1)*sh/dh
+		if y1 == y0 {
+			y1++
+		}
+		for x := 0; x < dw; x++ {
+			x0 := sb.Min.X + x*sw/dw
+			x1 := sb.Min.X + (x+1)*sw/dw
+			if x1 == x0 {
+				x1++
+			}
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
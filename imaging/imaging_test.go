@@ -0,0 +1,45 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFitSize(t *testing.T) {
+	var tests = []struct{ w, h, max, ow, oh int }{
+		{640, 480, 200, 200, 150},
+		{100, 50, 200, 100, 50},
+		{1000, 1, 10, 10, 1},
+	}
+	for i, v := range tests {
+		ow, oh := FitSize(v.w, v.h, v.max)
+		if ow != v.ow || oh != v.oh {
+			t.Errorf("%d: expected %dx%d, got %dx%d", i, v.ow, v.oh, ow, oh)
+		}
+	}
+}
+
+func TestResize(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		c := color.RGBA{0, 0, 0, 255}
+		if x%2 == 0 {
+			c = color.RGBA{200, 100, 50, 255}
+		}
+		src.Set(x, 0, c)
+		src.Set(x, 1, c)
+	}
+	dst := Resize(src, 2)
+	if b := dst.Bounds(); b.Dx() != 2 || b.Dy() != 1 {
+		t.Fatalf("expected 2x1, got %dx%d", b.Dx(), b.Dy())
+	}
+	r, g, b, a := dst.At(0, 0).RGBA()
+	if r>>8 != 100 || g>>8 != 50 || b>>8 != 25 || a>>8 != 255 {
+		t.Errorf("bad average color: %d %d %d %d", r>>8, g>>8, b>>8, a>>8)
+	}
+}
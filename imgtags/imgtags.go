@@ -0,0 +1,167 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package imgtags adds missing attributes to <img> tags in HTML:
+// dimensions of local images, which prevent layout shifts while
+// they load, and lazy loading. It can also inline small images.
+package imgtags
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// SizeFunc returns dimensions of the image by its URL path,
+// or false if it's unknown.
+type SizeFunc func(path string) (width, height int, ok bool)
+
+// InlineFunc returns data URI of the image by its URL path,
+// or false if it shouldn't be inlined.
+type InlineFunc func(path string) (uri string, ok bool)
+
+// Options are options for Apply.
+type Options struct {
+	Size   SizeFunc   // if not nil, adds width and height
+	Lazy   bool       // adds loading="lazy" and decoding="async"
+	Inline InlineFunc // if not nil, replaces src with data URI
+}
+
+func getAttr(t *html.Token, key string) (string, bool) {
+	for _, a := range t.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func addAttr(t *html.Token, key, val string) bool {
+	if _, ok := getAttr(t, key); ok {
+		return false
+	}
+	t.Attr = append(t.Attr, html.Attribute{Key: key, Val: val})
+	return true
+}
+
+// localPath returns the path of the image URL resolved relative to base,
+// or an empty string if it's not an image on the same site.
+func localPath(base *url.URL, src string) string {
+	u, err := url.Parse(strings.TrimSpace(src))
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return ""
+	}
+	return base.ResolveReference(u).Path
+}
+
+// addSize adds missing width and height to the image tag. If only one
+// of them is set, the other is calculated preserving aspect ratio.
+func addSize(t *html.Token, base *url.URL, size SizeFunc) bool {
+	ws, hasWidth := getAttr(t, "width")
+	hs, hasHeight := getAttr(t, "height")
+	if hasWidth && hasHeight {
+		return false
+	}
+	src, _ := getAttr(t, "src")
+	p := localPath(base, src)
+	if p == "" {
+		return false
+	}
+	w, h, ok := size(p)
+	if !ok || w == 0 || h == 0 {
+		return false
+	}
+	switch {
+	case hasWidth:
+		n, err := strconv.Atoi(ws)
+		if err != nil {
+			return false
+		}
+		return addAttr(t, "height", strconv.Itoa((n*h+w/2)/w))
+	case hasHeight:
+		n, err := strconv.Atoi(hs)
+		if err != nil {
+			return false
+		}
+		return addAttr(t, "width", strconv.Itoa((n*w+h/2)/h))
+	}
+	addAttr(t, "width", strconv.Itoa(w))
+	addAttr(t, "height", strconv.Itoa(h))
+	return true
+}
+
+// inline replaces src of the local image with its data URI.
+func inline(t *html.Token, base *url.URL, fn InlineFunc) bool {
+	for i, a := range t.Attr {
+		if a.Key != "src" {
+			continue
+		}
+		p := localPath(base, a.Val)
+		if p == "" {
+			return false
+		}
+		uri, ok := fn(p)
+		if !ok {
+			return false
+		}
+		t.Attr[i].Val = uri
+		return true
+	}
+	return false
+}
+
+// Apply adds missing attributes to <img> tags in HTML with relative
+// URLs resolved relative to the base URL path.
+func Apply(in []byte, base string, opts *Options) ([]byte, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.Grow(len(in))
+	z := html.NewTokenizer(bytes.NewReader(in))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			return out.Bytes(), nil
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			out.Write(z.Raw())
+			continue
+		}
+		raw := append([]byte(nil), z.Raw()...)
+		t := z.Token()
+		if t.DataAtom != atom.Img {
+			out.Write(raw)
+			continue
+		}
+		changed := false
+		if opts.Size != nil && addSize(&t, baseURL, opts.Size) {
+			changed = true
+		}
+		if opts.Inline != nil && inline(&t, baseURL, opts.Inline) {
+			changed = true
+		} else if opts.Lazy {
+			if addAttr(&t, "loading", "lazy") {
+				changed = true
+			}
+			if addAttr(&t, "decoding", "async") {
+				changed = true
+			}
+		}
+		if !changed {
+			out.Write(raw)
+			continue
+		}
+		out.WriteString(t.String())
+	}
+}
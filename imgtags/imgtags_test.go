@@ -0,0 +1,58 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imgtags
+
+import "testing"
+
+func testSize(path string) (int, int, bool) {
+	if path == "/blog/photo.jpg" {
+		return 800, 600, true
+	}
+	return 0, 0, false
+}
+
+func TestApply(t *testing.T) {
+	in := `<p><img src="photo.jpg" alt="A"><img src="/blog/photo.jpg" width="400">` +
+		`<img src="https://example.com/x.png"><img src="/missing.png" width="1" height="2"></p>`
+	expected := `<p><img src="photo.jpg" alt="A" width="800" height="600" loading="lazy" decoding="async">` +
+		`<img src="/blog/photo.jpg" width="400" height="300" loading="lazy" decoding="async">` +
+		`<img src="https://example.com/x.png" loading="lazy" decoding="async">` +
+		`<img src="/missing.png" width="1" height="2" loading="lazy" decoding="async"></p>`
+	out, err := Apply([]byte(in), "/blog/post.html", &Options{Size: testSize, Lazy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != expected {
+		t.Errorf("got\n%s\nexpected\n%s", out, expected)
+	}
+	out, err = Apply([]byte(in), "/blog/post.html", &Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != in {
+		t.Errorf("changed without options:\n%s", out)
+	}
+}
+
+func TestApplyInline(t *testing.T) {
+	in := `<img src="icon.png" alt="I"><img src="/big.png">`
+	expected := `<img src="data:image/png;base64,AA==" alt="I" width="16" height="16"><img src="/big.png" loading="lazy" decoding="async">`
+	opts := &Options{
+		Size: func(path string) (int, int, bool) {
+			return 16, 16, path == "/blog/icon.png"
+		},
+		Lazy: true,
+		Inline: func(path string) (string, bool) {
+			return "data:image/png;base64,AA==", path == "/blog/icon.png"
+		},
+	}
+	out, err := Apply([]byte(in), "/blog/post.html", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != expected {
+		t.Errorf("got\n%s\nexpected\n%s", out, expected)
+	}
+}
@@ -38,6 +38,12 @@ type wordpressPostMeta struct {
 	Value string `xml:"http://wordpress.org/export/1.2/ meta_value"`
 }
 
+// wordpressTerm is a category or tag of a post.
+type wordpressTerm struct {
+	Domain string `xml:"domain,attr"` // "category" or "post_tag"
+	Name   string `xml:",chardata"`
+}
+
 type wordpressPost struct {
 	Type        string              `xml:"http://wordpress.org/export/1.2/ post_type" yaml:"-"` // "post" or "page"
 	Status      string              `xml:"http://wordpress.org/export/1.2/ status" yaml:"-"`    // "publish" or "draft"
@@ -46,7 +52,9 @@ type wordpressPost struct {
 	Title       string              `xml:"title" yaml:"title"`
 	Description string              `xml:"description" yaml:"description,omitempty"`
 	Content     string              `xml:"http://purl.org/rss/1.0/modules/content/ encoded" yaml:"-"`
-	Tags        []string            `xml:"category" yaml:"tags,omitempty,flow"`
+	Terms       []wordpressTerm     `xml:"category" yaml:"-"`
+	Categories  []string            `xml:"-" yaml:"categories,omitempty,flow"`
+	Tags        []string            `xml:"-" yaml:"tags,omitempty,flow"`
 	Meta        []wordpressPostMeta `xml:"http://wordpress.org/export/1.2/ postmeta" yaml:"-"`
 }
 
@@ -149,6 +157,18 @@ func readWordpressPosts(filename string) ([]wordpressPost, error) {
 	for i := range posts {
 		posts[i].Title = strings.TrimSpace(posts[i].Title)
 		posts[i].Content = strings.TrimSpace(posts[i].Content)
+		// Separate categories from tags.
+		for _, t := range posts[i].Terms {
+			name := strings.TrimSpace(t.Name)
+			if name == "" {
+				continue
+			}
+			if t.Domain == "category" {
+				posts[i].Categories = append(posts[i].Categories, name)
+			} else {
+				posts[i].Tags = append(posts[i].Tags, name)
+			}
+		}
 		// Exclude some tag.
 		// TODO: make an option for this.
 		//posts[i].Tags = excludeStrings(posts[i].Tags, []string{"Uncategorized", "Link"})
@@ -0,0 +1,120 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package jsonld implements generation of schema.org structured data
+// in JSON-LD format.
+package jsonld
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// Object is a schema.org object.
+type Object map[string]interface{}
+
+// WebSite returns WebSite object. If searchURL is not empty, it adds
+// SearchAction with the search query in queryParam of the URL.
+func WebSite(name, url, searchURL, queryParam string) Object {
+	o := Object{
+		"@type": "WebSite",
+		"@id":   url + "#website",
+		"name":  name,
+		"url":   url,
+	}
+	if searchURL != "" {
+		o["potentialAction"] = Object{
+			"@type":       "SearchAction",
+			"target":      searchURL + "?" + queryParam + "={search_term_string}",
+			"query-input": "required name=search_term_string",
+		}
+	}
+	return o
+}
+
+// Post contains fields of BlogPosting.
+type Post struct {
+	URL         string
+	Title       string
+	Description string
+	Author      string
+	Image       string
+	Published   time.Time
+	Modified    time.Time
+	Keywords    []string
+	Section     string
+}
+
+// BlogPosting returns BlogPosting object for the post.
+func BlogPosting(p *Post, websiteURL string) Object {
+	o := Object{
+		"@type":            "BlogPosting",
+		"@id":              p.URL + "#post",
+		"url":              p.URL,
+		"mainEntityOfPage": p.URL,
+		"headline":         p.Title,
+		"isPartOf":         Object{"@id": websiteURL + "#website"},
+	}
+	if p.Description != "" {
+		o["description"] = p.Description
+	}
+	if p.Author != "" {
+		o["author"] = Object{"@type": "Person", "name": p.Author}
+	}
+	if p.Image != "" {
+		o["image"] = p.Image
+	}
+	if !p.Published.IsZero() {
+		o["datePublished"] = p.Published.Format(time.RFC3339)
+	}
+	if !p.Modified.IsZero() {
+		o["dateModified"] = p.Modified.Format(time.RFC3339)
+	}
+	if len(p.Keywords) > 0 {
+		o["keywords"] = p.Keywords
+	}
+	if p.Section != "" {
+		o["articleSection"] = p.Section
+	}
+	return o
+}
+
+// Crumb is an item of breadcrumb list.
+type Crumb struct {
+	Name string
+	URL  string
+}
+
+// BreadcrumbList returns BreadcrumbList object for the crumbs.
+func BreadcrumbList(crumbs []Crumb) Object {
+	items := make([]Object, len(crumbs))
+	for i, c := range crumbs {
+		items[i] = Object{
+			"@type":    "ListItem",
+			"position": i + 1,
+			"name":     c.Name,
+			"item":     c.URL,
+		}
+	}
+	return Object{
+		"@type":           "BreadcrumbList",
+		"itemListElement": items,
+	}
+}
+
+// Script returns <script> element with the graph of objects.
+func Script(objects ...Object) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(true) // prevents closing script with </script>
+	err := enc.Encode(Object{
+		"@context": "https://schema.org",
+		"@graph":   objects,
+	})
+	if err != nil {
+		return "", err
+	}
+	return `<script type="application/ld+json">` + string(bytes.TrimSpace(buf.Bytes())) + `</script>`, nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonld
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScript(t *testing.T) {
+	site := WebSite("Example", "https://example.com/", "https://example.com/search/", "query")
+	post := BlogPosting(&Post{
+		URL:       "https://example.com/blog/hello/",
+		Title:     "Hello </script>",
+		Published: time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, "https://example.com/")
+	crumbs := BreadcrumbList([]Crumb{{"Home", "https://example.com/"}, {"Hello", "https://example.com/blog/hello/"}})
+	out, err := Script(site, post, crumbs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<script type="application/ld+json">{"@context":"https://schema.org","@graph":[`,
+		`"target":"https://example.com/search/?query={search_term_string}"`,
+		`"headline":"Hello \u003c/script\u003e"`,
+		`"datePublished":"2013-01-01T00:00:00Z"`,
+		`{"@type":"ListItem","item":"https://example.com/blog/hello/","name":"Hello","position":2}`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output doesn't contain %s:\n%s", s, out)
+		}
+	}
+}
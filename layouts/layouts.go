@@ -8,12 +8,15 @@ package layouts
 import (
 	"bytes"
 	"fmt"
+	htmltemplate "html/template"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"sync"
 	"text/template"
 
+	"github.com/dchest/kkr/deps"
 	"github.com/dchest/kkr/metafile"
 )
 
@@ -22,6 +25,7 @@ type FuncMap template.FuncMap
 type SiteContext interface {
 	LayoutData() interface{}
 	LayoutFuncs() FuncMap
+	HTMLLayoutFuncs() FuncMap // for templates with autoescape
 }
 
 type PageContext interface {
@@ -31,16 +35,52 @@ type PageContext interface {
 	FileInfo() os.FileInfo
 }
 
+// Template is a parsed text/template or html/template.
+type Template interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// Parse parses a template with functions. If autoescape is true,
+// it's parsed with html/template, which escapes values depending
+// on their context, otherwise with text/template.
+func Parse(name, content string, funcs FuncMap, autoescape bool) (Template, error) {
+	if autoescape {
+		t, err := htmltemplate.New(name).Funcs(htmltemplate.FuncMap(funcs)).Parse(content)
+		if err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+	t, err := template.New(name).Funcs(template.FuncMap(funcs)).Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
 // Layout represends a layout.
 type Layout struct {
 	Name       string
 	ParentName string
-	Template   *template.Template
+	Template   Template
+	Filename   string // source file, empty for page content
+
+	refs   []string // dependency graph inputs referred to by the template
+	source string   // parent name, autoescape and template source
 }
 
+// ContentFilter transforms rendered page content
+// before it is passed to layouts.
+type ContentFilter func(pageContext PageContext, content string) (string, error)
+
 type Collection struct {
-	layouts map[string]*Layout
-	context SiteContext
+	layouts       map[string]*Layout
+	context       SiteContext
+	contentFilter ContentFilter
+	graph         *deps.Graph
+	refFuncs      map[string]RefFunc
+	autoescape    bool
+	trace         func(l, overridden *Layout)
 }
 
 func NewCollection(context SiteContext) *Collection {
@@ -50,18 +90,101 @@ func NewCollection(context SiteContext) *Collection {
 	}
 }
 
-func (c *Collection) newLayout(name string, parentName string, content string) (l *Layout, err error) {
-	t, err := template.New(name).Funcs(template.FuncMap(c.context.LayoutFuncs())).Parse(content)
+// SetContentFilter sets a filter for rendered page content.
+func (c *Collection) SetContentFilter(f ContentFilter) {
+	c.contentFilter = f
+}
+
+// SetAutoescape sets whether layouts and page contents are rendered
+// with html/template by default. Layouts and pages can override it
+// with `autoescape` meta. Content is a string in both cases, so
+// autoescaped layouts output it with {{ safeHTML .Content }}.
+func (c *Collection) SetAutoescape(value bool) {
+	c.autoescape = value
+}
+
+// SetGraph sets a dependency graph, to which layouts used
+// by rendered pages are added, see Input.
+func (c *Collection) SetGraph(g *deps.Graph) {
+	c.graph = g
+}
+
+// SetRefFuncs sets functions, calls of which in layouts and page
+// content are added to the dependency graph as inputs of pages,
+// see Refs. It must be called before adding layouts.
+func (c *Collection) SetRefFuncs(funcs map[string]RefFunc) {
+	c.refFuncs = funcs
+}
+
+// SetTrace sets a function which is called for each added layout with
+// the layout it overrides or nil, instead of logging the layout name.
+func (c *Collection) SetTrace(f func(l, overridden *Layout)) {
+	c.trace = f
+}
+
+// Input returns the name of dependency graph input for the layout.
+func Input(name string) string {
+	return "layout:" + name
+}
+
+// track adds the layout and its parents, and inputs referred to by
+// them and by page content p, as inputs of the page to the dependency
+// graph.
+func (c *Collection) track(pageContext PageContext, p *Layout, name string) {
+	if c.graph == nil {
+		return
+	}
+	inputs := append([]string(nil), p.refs...)
+	for depth := 0; name != "" && name != "none" && depth <= len(c.layouts); depth++ {
+		inputs = append(inputs, Input(name))
+		l, ok := c.layouts[name]
+		if !ok {
+			break
+		}
+		inputs = append(inputs, l.refs...)
+		name = l.ParentName
+	}
+	c.graph.Set(pageContext.URL(), inputs...)
+}
+
+func (c *Collection) newLayout(name string, parentName string, content string, autoescape bool) (l *Layout, err error) {
+	funcs := c.context.LayoutFuncs()
+	if autoescape {
+		funcs = c.context.HTMLLayoutFuncs()
+	}
+	t, err := Parse(name, content, funcs, autoescape)
 	if err != nil {
 		return nil, err
 	}
+	var refs []string
+	if c.graph != nil {
+		if refs, err = Refs(content, c.refFuncs); err != nil {
+			return nil, err
+		}
+	}
 	return &Layout{
 		Name:       name,
 		ParentName: parentName,
 		Template:   t,
+		refs:       refs,
+		source:     fmt.Sprintf("%s\x00%t\x00%s", parentName, autoescape, content),
 	}, nil
 }
 
+// autoescapeFromMeta returns the value of `autoescape` meta
+// or the collection default if it's not set.
+func (c *Collection) autoescapeFromMeta(meta map[string]interface{}) (bool, error) {
+	v, ok := meta["autoescape"]
+	if !ok {
+		return c.autoescape, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("`autoescape` must be a boolean")
+	}
+	return b, nil
+}
+
 func layoutNameFromMeta(meta map[string]interface{}) (string, error) {
 	l, ok := meta["layout"]
 	if ok {
@@ -88,23 +211,53 @@ func (c *Collection) newLayoutFromFile(filename string, stripExtension bool) (l
 	if err != nil {
 		return nil, err
 	}
+	autoescape, err := c.autoescapeFromMeta(f.Meta())
+	if err != nil {
+		return nil, err
+	}
 	content, err := f.Content()
 	if err != nil {
 		return nil, err
 	}
-	return c.newLayout(name, parentName, string(content))
+	l, err = c.newLayout(name, parentName, string(content), autoescape)
+	if err != nil {
+		return nil, err
+	}
+	l.Filename = filename
+	return l, nil
+}
+
+// Source returns the source of the layout, which changes when
+// the layout changes, for cache keys.
+func (c *Collection) Source(name string) (string, bool) {
+	l, ok := c.layouts[name]
+	if !ok {
+		return "", false
+	}
+	return l.source, true
 }
 
+// AddFile adds the layout from file, named by the file name without
+// extension. It replaces the layout with the same name added earlier.
 func (c *Collection) AddFile(filename string) error {
 	l, err := c.newLayoutFromFile(filename, true)
 	if err != nil {
 		return err
 	}
+	old := c.layouts[l.Name]
 	c.layouts[l.Name] = l
-	log.Printf("L %s", l.Name)
+	if c.trace != nil {
+		c.trace(l, old)
+	} else {
+		log.Printf("L %s", l.Name)
+	}
 	return nil
 }
 
+// AddDir adds layouts from files in the directory and its
+// subdirectories, see AddFile. Layouts are resolved in the order
+// in which directories are added: to override layouts from a shared
+// set or a theme, add its directory first, then the site's one.
 func (c *Collection) AddDir(dirname string) error {
 	return filepath.Walk(dirname, func(path string, fi os.FileInfo, err error) error {
 		if err != nil {
@@ -135,6 +288,13 @@ func (c *Collection) renderLayout(l *Layout, pageContext PageContext, content st
 
 	out = buf.String()
 
+	if l.Name == "" && c.contentFilter != nil {
+		// Page content.
+		if out, err = c.contentFilter(pageContext, out); err != nil {
+			return
+		}
+	}
+
 	if l.ParentName != "" && l.ParentName != "none" {
 		// Execute parent layout on output.
 		parentLayout, ok := c.layouts[l.ParentName]
@@ -160,11 +320,18 @@ func (c *Collection) RenderPage(pageContext PageContext, defaultLayoutName strin
 	if layoutName == "" {
 		layoutName = defaultLayoutName
 	}
-	p, err := c.newLayout("", layoutName, pageContext.Content())
+	autoescape, err := c.autoescapeFromMeta(pageContext.Meta())
+	if err != nil {
+		return
+	}
+	p, err := c.newLayout("", layoutName, pageContext.Content(), autoescape)
 	if err != nil {
 		return
 	}
 	out, err = c.renderLayout(p, pageContext, pageContext.Content())
+	if err == nil {
+		c.track(pageContext, p, layoutName)
+	}
 	if err == nil && renderedCache != nil {
 		// Add to cache
 		renderedCache.Put(pageContext.URL(), pageContext.FileInfo(), out)
@@ -172,6 +339,20 @@ func (c *Collection) RenderPage(pageContext PageContext, defaultLayoutName strin
 	return out, err
 }
 
+// RenderContent renders page content as a template
+// without applying any layouts.
+func (c *Collection) RenderContent(pageContext PageContext) (out string, err error) {
+	autoescape, err := c.autoescapeFromMeta(pageContext.Meta())
+	if err != nil {
+		return
+	}
+	p, err := c.newLayout("", "", pageContext.Content(), autoescape)
+	if err != nil {
+		return
+	}
+	return c.renderLayout(p, pageContext, pageContext.Content())
+}
+
 type cache struct {
 	mu sync.Mutex
 	m  map[string]cacheEntry
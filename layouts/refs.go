@@ -0,0 +1,103 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package layouts
+
+import (
+	"sort"
+	"text/template/parse"
+)
+
+// RefFunc returns dependency graph input for the first string constant
+// argument of a template function call, such as "include:footer.html"
+// for {{include "footer.html"}}. The argument is "*" if the call has
+// no constant arguments.
+type RefFunc func(arg string) string
+
+// Refs returns sorted dependency graph inputs referred to by calls of
+// the functions in the template source.
+func Refs(content string, funcs map[string]RefFunc) ([]string, error) {
+	if len(funcs) == 0 {
+		return nil, nil
+	}
+	t := parse.New("")
+	t.Mode = parse.SkipFuncCheck
+	trees := make(map[string]*parse.Tree)
+	if _, err := t.Parse(content, "", "", trees); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, tree := range trees {
+		walkRefs(tree.Root, funcs, seen)
+	}
+	out := make([]string, 0, len(seen))
+	for in := range seen {
+		out = append(out, in)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func walkRefs(node parse.Node, funcs map[string]RefFunc, seen map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walkRefs(c, funcs, seen)
+		}
+	case *parse.ActionNode:
+		walkRefs(n.Pipe, funcs, seen)
+	case *parse.IfNode:
+		walkRefs(&n.BranchNode, funcs, seen)
+	case *parse.RangeNode:
+		walkRefs(&n.BranchNode, funcs, seen)
+	case *parse.WithNode:
+		walkRefs(&n.BranchNode, funcs, seen)
+	case *parse.BranchNode:
+		walkRefs(n.Pipe, funcs, seen)
+		walkRefs(n.List, funcs, seen)
+		walkRefs(n.ElseList, funcs, seen)
+	case *parse.TemplateNode:
+		walkRefs(n.Pipe, funcs, seen)
+	case *parse.ChainNode:
+		walkRefs(n.Node, funcs, seen)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for i, cmd := range n.Cmds {
+			// "name" | include
+			if i > 0 && len(cmd.Args) == 1 && len(n.Cmds[i-1].Args) == 1 {
+				if id, ok := cmd.Args[0].(*parse.IdentifierNode); ok {
+					if f, ok := funcs[id.Ident]; ok {
+						arg := "*"
+						if s, ok := n.Cmds[i-1].Args[0].(*parse.StringNode); ok {
+							arg = s.Text
+						}
+						seen[f(arg)] = true
+					}
+				}
+			}
+			walkRefs(cmd, funcs, seen)
+		}
+	case *parse.CommandNode:
+		if id, ok := n.Args[0].(*parse.IdentifierNode); ok && len(n.Args) > 1 {
+			if f, ok := funcs[id.Ident]; ok {
+				arg := "*"
+				for _, a := range n.Args[1:] {
+					if s, ok := a.(*parse.StringNode); ok {
+						arg = s.Text
+						break
+					}
+				}
+				seen[f(arg)] = true
+			}
+		}
+		for _, a := range n.Args {
+			walkRefs(a, funcs, seen)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package layouts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRefs(t *testing.T) {
+	funcs := map[string]RefFunc{
+		"include": func(arg string) string { return "include:" + arg },
+		"asset":   func(arg string) string { return "asset:" + arg },
+	}
+	src := `{{define "head"}}<link href="{{asset "style"}}">{{end}}
+{{ include "header.html" }}
+{{ if .Page.x }}{{ "footer.html" | include }}{{ else }}{{ upper (include .Page.name) }}{{ end }}
+{{ range .Posts }}{{ asset "theme" "dark" }}{{ end }}
+{{ template "head" . }}`
+	refs, err := Refs(src, funcs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"asset:style", "asset:theme", "include:*", "include:footer.html", "include:header.html"}
+	if !reflect.DeepEqual(refs, expected) {
+		t.Errorf("expected %q, got %q", expected, refs)
+	}
+}
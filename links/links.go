@@ -0,0 +1,289 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package links implements a policy for external links in HTML:
+// adding marker class and rel attributes.
+package links
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// site.yml -> links:
+type Policy struct {
+	Class      string   `yaml:"class"`       // class added to external links, e.g. "external"
+	Rel        string   `yaml:"rel"`         // rel added to external links, e.g. "noopener"
+	Nofollow   []string `yaml:"nofollow"`    // domains, links to which get rel="nofollow ugc"
+	OwnDomains []string `yaml:"own_domains"` // domains, links to which are not external
+}
+
+// matchDomain returns true if host is one of the domains
+// or their subdomain.
+func matchDomain(host string, domains []string) bool {
+	host = strings.ToLower(host)
+	for _, d := range domains {
+		d = strings.ToLower(d)
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// addWords adds space-separated words to the attribute value
+// if it doesn't have them.
+func addWords(value, words string) string {
+	have := strings.Fields(value)
+	for _, w := range strings.Fields(words) {
+		found := false
+		for _, h := range have {
+			if strings.EqualFold(h, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			have = append(have, w)
+		}
+	}
+	return strings.Join(have, " ")
+}
+
+func setAttr(t *html.Token, key, words string) {
+	for i, a := range t.Attr {
+		if a.Key == key {
+			t.Attr[i].Val = addWords(a.Val, words)
+			return
+		}
+	}
+	t.Attr = append(t.Attr, html.Attribute{Key: key, Val: words})
+}
+
+// host returns host of an absolute HTTP(S) or protocol-relative URL,
+// or an empty string for other URLs.
+func host(href string) string {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return ""
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// Apply applies the policy to links in HTML. Links to ownHost
+// (such as the site's host) and own domains are not changed.
+func (p *Policy) Apply(in []byte, ownHost string) ([]byte, error) {
+	var out bytes.Buffer
+	out.Grow(len(in))
+	z := html.NewTokenizer(bytes.NewReader(in))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			return out.Bytes(), nil
+		}
+		if tt != html.StartTagToken {
+			out.Write(z.Raw())
+			continue
+		}
+		raw := append([]byte(nil), z.Raw()...)
+		t := z.Token()
+		if t.DataAtom != atom.A {
+			out.Write(raw)
+			continue
+		}
+		h := ""
+		for _, a := range t.Attr {
+			if a.Key == "href" {
+				h = host(a.Val)
+			}
+		}
+		if h == "" || strings.EqualFold(h, ownHost) || matchDomain(h, p.OwnDomains) {
+			out.Write(raw)
+			continue
+		}
+		if p.Class != "" {
+			setAttr(&t, "class", p.Class)
+		}
+		rel := p.Rel
+		if matchDomain(h, p.Nofollow) {
+			rel += " nofollow ugc"
+		}
+		if strings.TrimSpace(rel) != "" {
+			setAttr(&t, "rel", rel)
+		}
+		out.WriteString(t.String())
+	}
+}
+
+// Internal returns paths of links in HTML to pages of the site with
+// ownHost, resolved relative to the base URL path. Fragments and
+// queries are removed.
+func Internal(in []byte, base, ownHost string) ([]string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	z := html.NewTokenizer(bytes.NewReader(in))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			return paths, nil
+		}
+		if tt != html.StartTagToken {
+			continue
+		}
+		name, hasAttr := z.TagName()
+		if atom.Lookup(name) != atom.A || !hasAttr {
+			continue
+		}
+		for {
+			key, val, more := z.TagAttr()
+			if string(key) == "href" {
+				u, err := url.Parse(strings.TrimSpace(string(val)))
+				if err == nil && (u.Scheme == "" || u.Scheme == "http" || u.Scheme == "https") &&
+					(u.Host == "" || strings.EqualFold(u.Hostname(), ownHost)) &&
+					(u.Path != "" || u.Host != "") {
+					p := baseURL.ResolveReference(u).Path
+					if p == "" {
+						p = "/"
+					}
+					paths = append(paths, p)
+				}
+			}
+			if !more {
+				break
+			}
+		}
+	}
+}
+
+// Resource is a resource loaded by HTML from another site.
+type Resource struct {
+	Tag       string // e.g. script or iframe
+	URL       string
+	Directive string // Content-Security-Policy directive, e.g. script-src
+}
+
+// resourceAttrs maps elements loading resources to attributes
+// with their URLs and CSP directives controlling them.
+var resourceAttrs = map[atom.Atom]struct{ attr, directive string }{
+	atom.Script: {"src", "script-src"},
+	atom.Iframe: {"src", "frame-src"},
+	atom.Frame:  {"src", "frame-src"},
+	atom.Img:    {"src", "img-src"},
+	atom.Video:  {"src", "media-src"},
+	atom.Audio:  {"src", "media-src"},
+	atom.Source: {"src", "media-src"},
+	atom.Track:  {"src", "media-src"},
+	atom.Embed:  {"src", "object-src"},
+	atom.Object: {"data", "object-src"},
+	atom.Link:   {"href", "style-src"}, // only stylesheets
+}
+
+// ThirdParty returns resources (such as scripts, iframes, images
+// and stylesheets) in HTML that are loaded from hosts other than
+// own domains and their subdomains.
+func ThirdParty(in []byte, ownDomains []string) ([]Resource, error) {
+	var list []Resource
+	z := html.NewTokenizer(bytes.NewReader(in))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			return list, nil
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		t := z.Token()
+		ra, ok := resourceAttrs[t.DataAtom]
+		if !ok {
+			continue
+		}
+		var src, rel string
+		for _, a := range t.Attr {
+			switch a.Key {
+			case ra.attr:
+				src = strings.TrimSpace(a.Val)
+			case "rel":
+				rel = strings.ToLower(a.Val)
+			}
+		}
+		if t.DataAtom == atom.Link && !strings.Contains(rel, "stylesheet") {
+			continue
+		}
+		if h := host(src); h != "" && !matchDomain(h, ownDomains) {
+			list = append(list, Resource{Tag: t.Data, URL: src, Directive: ra.directive})
+		}
+	}
+}
+
+// Resources returns URLs of scripts, stylesheets, images and other
+// resources (except frames) loaded by HTML. Relative URLs are resolved
+// relative to the base URL path, and fragments and queries are removed
+// from them. Absolute URLs are returned as is.
+func Resources(in []byte, base string) ([]string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	var list []string
+	z := html.NewTokenizer(bytes.NewReader(in))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			return list, nil
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		t := z.Token()
+		ra, ok := resourceAttrs[t.DataAtom]
+		if !ok || ra.directive == "frame-src" {
+			continue
+		}
+		var src, rel string
+		for _, a := range t.Attr {
+			switch a.Key {
+			case ra.attr:
+				src = strings.TrimSpace(a.Val)
+			case "rel":
+				rel = strings.ToLower(a.Val)
+			}
+		}
+		if src == "" || t.DataAtom == atom.Link && !strings.Contains(rel, "stylesheet") {
+			continue
+		}
+		u, err := url.Parse(src)
+		if err != nil || (u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https") {
+			continue // e.g. data: URI
+		}
+		if u.Scheme == "" && u.Host == "" {
+			list = append(list, baseURL.ResolveReference(&url.URL{Path: u.Path}).Path)
+		} else {
+			u.RawQuery, u.Fragment = "", ""
+			list = append(list, u.String())
+		}
+	}
+}
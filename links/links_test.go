@@ -0,0 +1,94 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package links
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	p := &Policy{
+		Class:      "external",
+		Rel:        "noopener",
+		Nofollow:   []string{"forum.com"},
+		OwnDomains: []string{"example.org"},
+	}
+	var tests = []struct{ in, out string }{
+		{`<a href="/about">About</a>`, `<a href="/about">About</a>`},
+		{`<a href="https://www.example.com/x">Own</a>`, `<a href="https://www.example.com/x">Own</a>`},
+		{`<a href="https://blog.example.org/">Own</a>`, `<a href="https://blog.example.org/">Own</a>`},
+		{`<a href="mailto:a@b.com">Mail</a>`, `<a href="mailto:a@b.com">Mail</a>`},
+		{`<a href="https://golang.org">Go</a>`, `<a href="https://golang.org" class="external" rel="noopener">Go</a>`},
+		{`<a class="btn" href="//cdn.net/">CDN</a>`, `<a class="btn external" href="//cdn.net/" rel="noopener">CDN</a>`},
+		{`<a href="http://www.forum.com/t?a=1&amp;b=2" rel="me">F</a>`, `<a href="http://www.forum.com/t?a=1&amp;b=2" rel="me noopener nofollow ugc" class="external">F</a>`},
+		{`<p title="a &amp; b"><a href="#top">Top</a></p>`, `<p title="a &amp; b"><a href="#top">Top</a></p>`},
+	}
+	for i, v := range tests {
+		out, err := p.Apply([]byte(v.in), "www.example.com")
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if string(out) != v.out {
+			t.Errorf("%d: expected\n%s\ngot\n%s", i, v.out, out)
+		}
+	}
+}
+
+func TestInternal(t *testing.T) {
+	in := `<a href="/about">About</a> <a href="../other/#x">Other</a> <a href="#top">Top</a>
+<a href="https://www.example.com/y?q=1">Own</a> <a href="https://golang.org/">Go</a>
+<a href="mailto:a@b.com">Mail</a> <a href="https://www.example.com">Root</a>`
+	paths, err := Internal([]byte(in), "/blog/post/", "www.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"/about", "/blog/other/", "/y", "/"}
+	if len(paths) != len(expected) {
+		t.Fatalf("got %q, expected %q", paths, expected)
+	}
+	for i := range paths {
+		if paths[i] != expected[i] {
+			t.Errorf("%d: got %q, expected %q", i, paths[i], expected[i])
+		}
+	}
+}
+
+func TestThirdParty(t *testing.T) {
+	in := `<script src="/js/a.js"></script><script src="https://cdn.example.net/b.js"></script>
+<link rel="stylesheet" href="//fonts.example.org/css"><link rel="icon" href="https://icons.example.org/i.png">
+<img src="https://static.example.com/x.png"><iframe src="https://www.youtube.com/embed/x"></iframe>`
+	list, err := ThirdParty([]byte(in), []string{"example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Resource{
+		{"script", "https://cdn.example.net/b.js", "script-src"},
+		{"link", "//fonts.example.org/css", "style-src"},
+		{"iframe", "https://www.youtube.com/embed/x", "frame-src"},
+	}
+	if len(list) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, list)
+	}
+	for i := range list {
+		if list[i] != expected[i] {
+			t.Errorf("%d: expected %v, got %v", i, expected[i], list[i])
+		}
+	}
+}
+
+func TestResources(t *testing.T) {
+	in := `<script src="/js/a.js?v=1"></script><link rel="stylesheet" href="../css/b.css">
+<link rel="icon" href="/i.png"><img src="photo.jpg#x"><img src="data:image/gif;base64,AA==">
+<iframe src="/embed.html"></iframe><img src="https://cdn.example.net/c.png?w=1">`
+	list, err := Resources([]byte(in), "/blog/post.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "/js/a.js /css/b.css /blog/photo.jpg https://cdn.example.net/c.png"
+	if s := strings.Join(list, " "); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+}
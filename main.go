@@ -12,7 +12,9 @@ import (
 	"runtime/pprof"
 
 	"github.com/dchest/kkr/importer"
+	"github.com/dchest/kkr/metaedit"
 	"github.com/dchest/kkr/site"
+	"github.com/dchest/kkr/starter"
 	"github.com/dchest/kkr/utils"
 )
 
@@ -22,32 +24,88 @@ var (
 	fHttp  = flag.String("http", "localhost:8080", "address and port to use for serving")
 	fWatch = flag.Bool("watch", false, "watch for changes")
 	//fNoFilters  = flag.Bool("nofilters", false, "disable filters")
-	fNoClean    = flag.Bool("noclean", false, "don't delete output directory before building")
+	fClean      = flag.Bool("clean", false, "delete output directory before building")
+	fPrune      = flag.Bool("prune", false, "after building, delete files in output directory which the build didn't write")
+	fNoClean    = flag.Bool("noclean", false, "don't delete output directory or any files in it (default)")
 	fCPUProfile = flag.String("cpuprofile", "", "(debug) write CPU profile to file")
 	fNoCache    = flag.Bool("nocache", false, "disables caching when watching")
+	fDrafts     = flag.Bool("drafts", false, "include posts from drafts directory (always on for dev)")
+	fFuture     = flag.Bool("future", false, "include posts dated in the future")
+	fTraceTmpl  = flag.Bool("trace-templates", false, "log which files provide layouts, includes and shortcodes")
 	fBrowser    = flag.Bool("browser", false, "open local site in browser after starting the web server")
+	fHTTPS      = flag.Bool("https", false, "serve over HTTPS with a self-signed certificate")
+	fQR         = flag.Bool("qr", false, "print QR code with LAN URL when serving on non-local address")
+	fDryRun     = flag.Bool("dryrun", false, "only show what would be uploaded, deleted or sent (for deploy and newsletter)")
+	fTemplate   = flag.String("template", starter.DefaultTemplate, "starter template name or git repository URL (for new)")
 	fTitle      = flag.String("title", "", "post title (for newpost)")
 	fTags       = flag.String("tags", "", "comma-separatated post tags (for newpost)")
 	fLink       = flag.String("link", "", "link meta information (for newpost)")
+	fTo         = flag.String("to", "md", "output format: md or html (for convert)")
+	fUnified    = flag.Bool("u", false, "show unified diffs of changed files (for diff and test)")
+	fUpdate     = flag.Bool("update", false, "update snapshot with all or given output files (for test)")
+	fMatch      = flag.String("match", "", "condition for selecting files, e.g. 'tags contains golang' (for meta and export)")
+	fTag        = flag.String("tag", "", "select posts with tag (for export)")
+	fSafe       = flag.Bool("safe", false, "don't run external commands, such as exec filters, when building untrusted sites")
 )
 
 var Usage = func() {
 	fmt.Printf(`usage: kkr command [options]
 
 Commands:
-  build  - build website
-  serve  - start a web server
+  build [-drafts] [-future] [-safe] [-trace-templates] - build website
+  serve [-https] [-qr] - start a web server
   dev    - same as "serve -watch -browser", but disables compression
+  deploy [-dryrun] - build website, upload changed files to S3-compatible
+		 storage and purge them from CDN caches
+  newsletter [-dryrun] - build website and send the newsletter with
+		 the latest posts using the command from site.yml
+  orphans - build website and list pages that are not linked from anywhere
+  embeds - build website and list third-party scripts, iframes and other
+		 resources with pages using them, checking them against CSP
+  diff [-u] - build website into a temporary directory and list output
+		 files that would be added, changed or removed (with -u, also
+		 show unified diffs of changed HTML and XML files)
+  test [-u] - build website and compare output with the snapshot directory
+  test -update [path...] - build website and update the snapshot
+		 with all or given output files or directories
+  new [-template blog|docs|portfolio|git-url] [dir] - create new site
+		 in the given or current directory from starter template
   clean  - clean caches and remove output directory
+  theme install [git-url] [name] - install theme from git repository
+		 into themes directory, to be used with "theme: name" in site.yml
   import [type] [infile] - import from other blog engines (overwrites existing files)
 		 Supported types: wordpress
   newpost -title "Post title" [-tags "tag1,tag2"] - create new post file
+  convert [file] [-to md|html] - convert post or page between HTML and Markdown
+  meta [set|add|remove] key=value... [-match "condition"] - edit front matter
+  meta unset key... [-match "condition"]
+		 Condition clauses: "key contains value", "key = value",
+		 "key != value", "key exists", "key missing", joined by "and".
+  export epub [-tag name] [-match "condition"] outfile - build website
+		 and export selected posts with their images into EPUB book
 
 Options:
 `)
 	flag.PrintDefaults()
 }
 
+// parseFlags parses command line flags allowing them
+// to be intermixed with arguments, for example:
+//
+//	kkr meta set draft=true -match "tags contains old"
+func parseFlags() {
+	var args []string
+	for {
+		flag.Parse()
+		if flag.NArg() == 0 {
+			break
+		}
+		args = append(args, flag.Arg(0))
+		os.Args = append(os.Args[:1], flag.Args()[1:]...)
+	}
+	flag.CommandLine.Parse(append([]string{"--"}, args...))
+}
+
 func main() {
 	log.SetFlags(0)
 	flag.Usage = Usage
@@ -63,7 +121,7 @@ func main() {
 
 	watch := *fWatch || command == "dev"
 
-	flag.Parse()
+	parseFlags()
 
 	if *fCPUProfile != "" {
 		f, err := os.Create(*fCPUProfile)
@@ -79,6 +137,31 @@ func main() {
 	if err != nil {
 		log.Fatalf("! os.Getwd(): %s", err)
 	}
+	if command == "new" {
+		if flag.NArg() > 0 {
+			dir = flag.Arg(0)
+		}
+		if err := starter.Create(dir, *fTemplate); err != nil {
+			log.Fatalf("! new error: %s", err)
+		}
+		log.Printf("* Created site. Run \"kkr dev\" in %s to preview it.", dir)
+		return
+	}
+	if command == "theme" {
+		// Doesn't need the site to be loadable, since
+		// its config may refer to the theme being installed.
+		if flag.Arg(0) != "install" || flag.NArg() < 2 {
+			log.Printf("! theme: expected install and repository URL")
+			flag.Usage()
+			return
+		}
+		name, err := site.InstallTheme(dir, flag.Arg(1), flag.Arg(2))
+		if err != nil {
+			log.Fatalf("! theme error: %s", err)
+		}
+		log.Printf("* Installed theme. Add \"theme: %s\" to %s to use it.", name, site.ConfigFileName)
+		return
+	}
 	currentSite, err = site.Open(dir)
 	if err != nil {
 		log.Fatalf("! Cannot open site: %s", err)
@@ -94,7 +177,14 @@ func main() {
 			log.Fatalf("! Cannot start watcher: %s", err)
 		}
 	}
-	currentSite.SetCleanBeforeBuilding(!*fNoClean)
+	currentSite.SetCleanBeforeBuilding(*fClean && !*fNoClean)
+	currentSite.SetPruneAfterBuilding(*fPrune && !*fNoClean)
+	currentSite.SetSafeMode(*fSafe)
+	currentSite.SetDrafts(*fDrafts || command == "dev")
+	currentSite.SetFuture(*fFuture)
+	currentSite.SetHTTPS(*fHTTPS)
+	currentSite.SetQRCode(*fQR)
+	currentSite.SetTraceTemplates(*fTraceTmpl)
 
 	switch command {
 	case "build":
@@ -123,11 +213,50 @@ func main() {
 			log.Fatalf("! build error: %s", err)
 		}
 		if *fBrowser || command == "dev" {
-			if err := utils.OpenURL("http://" + *fHttp); err != nil {
+			if err := utils.OpenURL(currentSite.ServeURL(*fHttp)); err != nil {
 				log.Printf("! cannot open browser: %s", err)
 			}
 		}
 		<-serverDone
+	case "newsletter":
+		if err := currentSite.Build(); err != nil {
+			log.Fatalf("! build error: %s", err)
+		}
+		if err := currentSite.SendNewsletter(*fDryRun); err != nil {
+			log.Fatalf("! newsletter error: %s", err)
+		}
+	case "orphans":
+		if err := currentSite.Build(); err != nil {
+			log.Fatalf("! build error: %s", err)
+		}
+		if err := currentSite.ReportOrphans(); err != nil {
+			log.Printf("! orphans error: %s", err)
+		}
+	case "embeds":
+		if err := currentSite.Build(); err != nil {
+			log.Fatalf("! build error: %s", err)
+		}
+		if err := currentSite.ReportEmbeds(); err != nil {
+			log.Printf("! embeds error: %s", err)
+		}
+	case "diff":
+		if err := currentSite.ReportDiff(*fUnified); err != nil {
+			log.Fatalf("! diff error: %s", err)
+		}
+	case "test":
+		if err := currentSite.Build(); err != nil {
+			log.Fatalf("! build error: %s", err)
+		}
+		if err := currentSite.TestSnapshot(*fUnified, *fUpdate, flag.Args()); err != nil {
+			log.Fatalf("! test error: %s", err)
+		}
+	case "deploy":
+		if err := currentSite.Build(); err != nil {
+			log.Fatalf("! build error: %s", err)
+		}
+		if _, err := currentSite.Deploy(*fDryRun); err != nil {
+			log.Fatalf("! deploy error: %s", err)
+		}
 	case "clean":
 		err = currentSite.Clean()
 		if err != nil {
@@ -157,6 +286,52 @@ func main() {
 		if err := utils.OpenEditor(filename); err != nil {
 			log.Printf("! cannot open editor: %s", err)
 		}
+	case "convert":
+		if len(flag.Args()) < 1 {
+			log.Printf("! convert: missing file")
+			flag.Usage()
+			return
+		}
+		filename, err := currentSite.ConvertFile(flag.Arg(0), *fTo)
+		if err != nil {
+			log.Fatalf("! convert error: %s", err)
+		}
+		log.Printf("%s", filename)
+	case "meta":
+		if len(flag.Args()) < 2 {
+			log.Printf("! meta: missing arguments")
+			flag.Usage()
+			return
+		}
+		cond, err := metaedit.ParseCondition(*fMatch)
+		if err != nil {
+			log.Fatalf("! meta: %s", err)
+		}
+		ops, err := metaedit.ParseOps(flag.Arg(0), flag.Args()[1:])
+		if err != nil {
+			log.Fatalf("! meta: %s", err)
+		}
+		n, err := currentSite.EditMeta(cond, ops)
+		log.Printf("* Edited %d files.", n)
+		if err != nil {
+			log.Fatalf("! meta error: %s", err)
+		}
+	case "export":
+		if flag.NArg() != 2 || flag.Arg(0) != "epub" {
+			log.Printf("! export: expected epub format and output file")
+			flag.Usage()
+			return
+		}
+		cond, err := metaedit.ParseCondition(*fMatch)
+		if err != nil {
+			log.Fatalf("! export: %s", err)
+		}
+		if err := currentSite.Build(); err != nil {
+			log.Fatalf("! build error: %s", err)
+		}
+		if err := currentSite.ExportEPUB(flag.Arg(1), *fTag, cond); err != nil {
+			log.Fatalf("! export error: %s", err)
+		}
 	default:
 		log.Printf("! unknown command %s", command)
 		flag.Usage()
@@ -0,0 +1,94 @@
+package markup
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/filters"
+	"gopkg.in/yaml.v3"
+)
+
+// External is a markup processed by an external command, which gets
+// source on stdin and writes HTML to stdout.
+type External struct {
+	Command    []string `yaml:"command"`
+	Extensions []string `yaml:"extensions"` // ".name" if empty
+}
+
+// UnmarshalYAML allows specifying only the command as a list,
+// e.g. `rst: [rst2html, --quiet]`.
+func (e *External) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.SequenceNode {
+		return value.Decode(&e.Command)
+	}
+	type plain External
+	return value.Decode((*plain)(e))
+}
+
+func (e *External) extensions(name string) []string {
+	if len(e.Extensions) == 0 {
+		return []string{"." + name}
+	}
+	return e.Extensions
+}
+
+// Check returns an error if options are invalid.
+func (o *Options) Check() error {
+	if o.Highlight != nil {
+		if err := o.Highlight.Check(); err != nil {
+			return err
+		}
+	}
+	if o.Math != nil {
+		if err := o.Math.Check(); err != nil {
+			return err
+		}
+	}
+	seen := make(map[string]string)
+	for name, e := range o.External {
+		if name == "markdown" {
+			return errors.New("markup: external markup can't be named markdown")
+		}
+		if e == nil || len(e.Command) == 0 {
+			return fmt.Errorf("markup: no command for external markup %q", name)
+		}
+		for _, ext := range e.extensions(name) {
+			ext = strings.ToLower(ext)
+			if !strings.HasPrefix(ext, ".") {
+				return fmt.Errorf("markup: extension %q of %q doesn't start with a dot", ext, name)
+			}
+			if other, ok := seen[ext]; ok {
+				return fmt.Errorf("markup: extension %q is used by both %q and %q", ext, other, name)
+			}
+			seen[ext] = name
+		}
+	}
+	return nil
+}
+
+// ExternalFor returns the name of the external markup for the file
+// by its extension, or an empty string if there's none.
+func ExternalFor(filename string) string {
+	if options == nil {
+		return ""
+	}
+	ext := filepath.Ext(filename)
+	for name, e := range options.External {
+		for _, v := range e.extensions(name) {
+			if strings.EqualFold(v, ext) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func processExternal(e *External, content []byte) ([]byte, error) {
+	f := filters.Make("exec", e.Command)
+	if f == nil {
+		return nil, errors.New("exec filter not found")
+	}
+	return f.Apply(content)
+}
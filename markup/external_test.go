@@ -0,0 +1,48 @@
+package markup
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestExternal(t *testing.T) {
+	var opts Options
+	err := yaml.Unmarshal([]byte(`
+external:
+  upper: [tr, a-z, A-Z]
+  org:
+    command: [cat]
+    extensions: [.org, .ORG2]
+`), &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := opts.Check(); err != nil {
+		t.Fatal(err)
+	}
+	defer SetOptions(&Options{})
+	SetOptions(&opts)
+	for name, expected := range map[string]string{
+		"a/b.upper": "upper",
+		"x.org":     "org",
+		"x.org2":    "org",
+		"x.md":      "",
+	} {
+		if got := ExternalFor(name); got != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, got)
+		}
+	}
+	out, err := Process("upper", []byte("<p>hi</p>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "<P>HI</P>" {
+		t.Errorf("got %q", out)
+	}
+
+	opts.External["dup"] = &External{Command: []string{"cat"}, Extensions: []string{".org"}}
+	if err := opts.Check(); err == nil {
+		t.Errorf("expected error for duplicate extension")
+	}
+}
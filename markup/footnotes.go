@@ -0,0 +1,212 @@
+package markup
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Footnotes styles.
+const (
+	FootnotesList      = "list"      // list at the end of document
+	FootnotesSidenotes = "sidenotes" // Tufte-style sidenotes
+	FootnotesPopovers  = "popovers"  // popovers with list fallback
+)
+
+// noFootnoteReturnLinks removes links from footnotes
+// back to their references.
+type noFootnoteReturnLinks struct{}
+
+func (noFootnoteReturnLinks) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(extast.KindFootnoteBacklink, func(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+		return ast.WalkContinue, nil
+	})
+}
+
+func hasClass(n *html.Node, class string) bool {
+	for _, a := range n.Attr {
+		if a.Key == "class" {
+			for _, c := range strings.Fields(a.Val) {
+				if c == class {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func findAll(n *html.Node, fn func(*html.Node) bool) []*html.Node {
+	var found []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if fn(n) {
+			found = append(found, n)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return found
+}
+
+// inlineContent returns copies of footnote children with paragraphs
+// unwrapped and return links removed, so that they can be put into
+// an inline element.
+func inlineContent(li *html.Node) []*html.Node {
+	var out []*html.Node
+	for c := li.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.DataAtom == atom.A && hasClass(c, "footnote-return") {
+			continue
+		}
+		if li.DataAtom == atom.Li && c.Type == html.TextNode && strings.TrimSpace(c.Data) == "" {
+			continue // between paragraphs
+		}
+		if c.Type == html.ElementNode && c.DataAtom == atom.P {
+			if len(out) > 0 {
+				out = append(out, &html.Node{Type: html.TextNode, Data: " "})
+			}
+			out = append(out, inlineContent(c)...)
+			continue
+		}
+		out = append(out, cloneNode(c))
+	}
+	// Trim trailing whitespace.
+	for len(out) > 0 {
+		last := out[len(out)-1]
+		if last.Type != html.TextNode {
+			break
+		}
+		last.Data = strings.TrimRight(last.Data, " \n")
+		if last.Data != "" {
+			break
+		}
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+func cloneNode(n *html.Node) *html.Node {
+	m := &html.Node{
+		Type:     n.Type,
+		DataAtom: n.DataAtom,
+		Data:     n.Data,
+		Attr:     append([]html.Attribute(nil), n.Attr...),
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		m.AppendChild(cloneNode(c))
+	}
+	return m
+}
+
+func element(a atom.Atom, attrs ...string) *html.Node {
+	n := &html.Node{Type: html.ElementNode, DataAtom: a, Data: a.String()}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		n.Attr = append(n.Attr, html.Attribute{Key: attrs[i], Val: attrs[i+1]})
+	}
+	return n
+}
+
+// sidenote returns nodes for Tufte CSS sidenote.
+func sidenote(id string, content []*html.Node) []*html.Node {
+	id = "sn-" + id
+	label := element(atom.Label, "for", id, "class", "margin-toggle sidenote-number")
+	input := element(atom.Input, "type", "checkbox", "id", id, "class", "margin-toggle")
+	span := element(atom.Span, "class", "sidenote")
+	for _, c := range content {
+		span.AppendChild(c)
+	}
+	return []*html.Node{label, input, span}
+}
+
+// popover returns nodes for a footnote reference button
+// and a popover with footnote content.
+func popover(id, number string, content []*html.Node) []*html.Node {
+	id = "fnp-" + id
+	button := element(atom.Button, "type", "button", "class", "footnote-ref", "popovertarget", id)
+	button.AppendChild(&html.Node{Type: html.TextNode, Data: number})
+	span := element(atom.Span, "id", id, "class", "footnote-popover", "popover", "")
+	for _, c := range content {
+		span.AppendChild(c)
+	}
+	return []*html.Node{button, span}
+}
+
+// transformFootnotes converts footnotes generated by Markdown renderer
+// into sidenotes or popovers.
+func transformFootnotes(in []byte, style string) ([]byte, error) {
+	if style != FootnotesSidenotes && style != FootnotesPopovers {
+		return nil, fmt.Errorf("unknown footnotes style %q", style)
+	}
+	if !bytes.Contains(in, []byte(`class="footnotes"`)) {
+		return in, nil
+	}
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(bytes.NewReader(in), context)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range nodes {
+		context.AppendChild(n)
+	}
+	lists := findAll(context, func(n *html.Node) bool {
+		return n.Type == html.ElementNode && n.DataAtom == atom.Div && hasClass(n, "footnotes")
+	})
+	items := make(map[string]*html.Node)
+	for _, list := range lists {
+		for _, li := range findAll(list, func(n *html.Node) bool {
+			return n.Type == html.ElementNode && n.DataAtom == atom.Li
+		}) {
+			id, _ := getAttr(li, "id")
+			items[id] = li
+		}
+	}
+	refs := findAll(context, func(n *html.Node) bool {
+		return n.Type == html.ElementNode && n.DataAtom == atom.Sup &&
+			n.FirstChild != nil && hasClass(n.FirstChild, "footnote-ref")
+	})
+	for _, ref := range refs {
+		a := ref.FirstChild
+		if a == nil || a.DataAtom != atom.A || a.FirstChild == nil {
+			continue
+		}
+		href, _ := getAttr(a, "href")
+		id := strings.TrimPrefix(href, "#")
+		li := items[id]
+		if li == nil {
+			continue
+		}
+		id = strings.TrimPrefix(id, "fn:")
+		var repl []*html.Node
+		if style == FootnotesSidenotes {
+			repl = sidenote(id, inlineContent(li))
+		} else {
+			repl = popover(id, a.FirstChild.Data, inlineContent(li))
+		}
+		for _, r := range repl {
+			ref.Parent.InsertBefore(r, ref)
+		}
+		ref.Parent.RemoveChild(ref)
+	}
+	if style == FootnotesSidenotes {
+		for _, list := range lists {
+			list.Parent.RemoveChild(list)
+		}
+	}
+	var buf bytes.Buffer
+	for c := context.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(&buf, c); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,82 @@
+package markup
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFootnotes(t *testing.T) {
+	in := "Text[^a] and more[^b].\n\n[^a]: First note.\n[^b]: Second *note*.\n"
+	var tests = []struct {
+		style    string
+		contains []string
+		excludes []string
+	}{
+		{
+			FootnotesList,
+			[]string{
+				`<sup id="fnref:1"><a href="#fn:1" class="footnote-ref" role="doc-noteref">1</a></sup>`,
+				`<p>Second <em>note</em>.&#160;<a href="#fnref:2" class="footnote-return" role="doc-backlink">&#8617;&#xFE0E;</a></p>`,
+			},
+			nil,
+		},
+		{
+			FootnotesSidenotes,
+			[]string{
+				`<p>Text<label for="sn-1" class="margin-toggle sidenote-number"></label><input type="checkbox" id="sn-1" class="margin-toggle"/><span class="sidenote">First note.</span> and more`,
+				`<span class="sidenote">Second <em>note</em>.</span>.</p>`,
+			},
+			[]string{`class="footnotes"`, `footnote-ref`},
+		},
+		{
+			FootnotesPopovers,
+			[]string{
+				`<p>Text<button type="button" class="footnote-ref" popovertarget="fnp-1">1</button><span id="fnp-1" class="footnote-popover" popover="">First note.</span> and more`,
+				"<li id=\"fn:1\">\n<p>First note.</p>\n</li>",
+			},
+			[]string{`<sup`, `footnote-return`},
+		},
+	}
+	defer SetOptions(&Options{})
+	for i, v := range tests {
+		SetOptions(&Options{Footnotes: v.style})
+		out, err := Process("markdown", []byte(in))
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		for _, s := range v.contains {
+			if !strings.Contains(string(out), s) {
+				t.Errorf("%d: output doesn't contain %q:\n%s", i, s, out)
+			}
+		}
+		for _, s := range v.excludes {
+			if strings.Contains(string(out), s) {
+				t.Errorf("%d: output contains %q:\n%s", i, s, out)
+			}
+		}
+	}
+}
+
+func TestFootnotesToggle(t *testing.T) {
+	defer SetOptions(&Options{})
+	in := "Text[^a].\n\n[^a]: Note.\n"
+	for _, v := range []struct {
+		options   Options
+		footnotes bool
+	}{
+		{Options{}, false},
+		{Options{Footnotes: "true"}, true},
+		{Options{Footnotes: "false", MarkdownFootnotes: true}, false},
+		{Options{MarkdownFootnotes: true}, true}, // deprecated
+	} {
+		opts := v.options
+		SetOptions(&opts)
+		out, err := Process("markdown", []byte(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(string(out), `class="footnotes"`); got != v.footnotes {
+			t.Errorf("%+v: got footnotes %v:\n%s", v.options, got, out)
+		}
+	}
+}
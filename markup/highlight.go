@@ -0,0 +1,62 @@
+package markup
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/dchest/kkr/highlight"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// funcRegisterer collects node rendering functions.
+type funcRegisterer map[ast.NodeKind]renderer.NodeRendererFunc
+
+func (r funcRegisterer) Register(kind ast.NodeKind, f renderer.NodeRendererFunc) {
+	r[kind] = f
+}
+
+// defaultFencedCodeBlock renders fenced code blocks with the HTML
+// renderer of goldmark, for languages that aren't highlighted.
+var defaultFencedCodeBlock = func() renderer.NodeRendererFunc {
+	funcs := make(funcRegisterer)
+	html.NewRenderer(html.WithXHTML()).RegisterFuncs(funcs)
+	return funcs[ast.KindFencedCodeBlock]
+}()
+
+// highlightRenderer renders fenced code blocks in supported
+// languages with syntax highlighting.
+type highlightRenderer struct {
+	options *highlight.Options
+}
+
+func (r *highlightRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (r *highlightRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.FencedCodeBlock)
+	lang := ""
+	if n.Info != nil {
+		if f := strings.Fields(string(n.Info.Segment.Value(source))); len(f) > 0 {
+			lang = f[0]
+		}
+	}
+	if !highlight.Supported(lang) {
+		return defaultFencedCodeBlock(w, source, node, entering)
+	}
+	if entering {
+		var code bytes.Buffer
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			line := lines.At(i)
+			code.Write(line.Value(source))
+		}
+		if out, ok := highlight.HTML(code.String(), lang, r.options); ok {
+			w.WriteString(out)
+		}
+	}
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,26 @@
+package markup
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dchest/kkr/highlight"
+)
+
+func TestHighlight(t *testing.T) {
+	defer SetOptions(&Options{})
+	SetOptions(&Options{Highlight: &highlight.Options{}})
+	in := "```go\nvar x = 1\n```\n\n```mermaid\na --> b\n```\n"
+	out, err := Process("markdown", []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<pre class="chroma"><code class="language-go"><span class="k">var</span> x = <span class="m">1</span>`,
+		`<pre><code class="language-mermaid">a --&gt; b`,
+	} {
+		if !strings.Contains(string(out), s) {
+			t.Errorf("output doesn't contain %q:\n%s", s, out)
+		}
+	}
+}
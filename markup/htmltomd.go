@@ -0,0 +1,288 @@
+package markup
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTMLToMarkdown converts HTML into Markdown.
+//
+// Elements that don't have Markdown equivalents (such as tables,
+// iframes, or elements with attributes that would be lost) are
+// preserved as raw HTML.
+func HTMLToMarkdown(in []byte) ([]byte, error) {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(bytes.NewReader(in), context)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range nodes {
+		context.AppendChild(n)
+	}
+	out := strings.TrimSpace(convertBlocks(context))
+	return []byte(out + "\n"), nil
+}
+
+var blockAtoms = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.H1: true, atom.H2: true, atom.H3: true,
+	atom.H4: true, atom.H5: true, atom.H6: true, atom.Ul: true, atom.Ol: true,
+	atom.Blockquote: true, atom.Pre: true, atom.Hr: true, atom.Table: true,
+	atom.Figure: true, atom.Dl: true, atom.Iframe: true, atom.Section: true,
+	atom.Article: true, atom.Aside: true, atom.Header: true, atom.Footer: true,
+	atom.Script: true, atom.Style: true, atom.Form: true, atom.Video: true,
+	atom.Audio: true,
+}
+
+func isBlock(n *html.Node) bool {
+	return n.Type == html.ElementNode && blockAtoms[n.DataAtom]
+}
+
+// convertBlocks converts children of n into Markdown blocks
+// separated by empty lines.
+func convertBlocks(n *html.Node) string {
+	var blocks []string
+	var inline strings.Builder
+	flush := func() {
+		for _, p := range strings.Split(inline.String(), "\n\n") {
+			if p = strings.TrimSpace(p); p != "" {
+				blocks = append(blocks, p)
+			}
+		}
+		inline.Reset()
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if !isBlock(c) {
+			inline.WriteString(convertInline(c))
+			continue
+		}
+		flush()
+		if b := convertBlock(c); b != "" {
+			blocks = append(blocks, b)
+		}
+	}
+	flush()
+	return strings.Join(blocks, "\n\n")
+}
+
+func convertBlock(n *html.Node) string {
+	if len(n.Attr) > 0 {
+		return renderRaw(n)
+	}
+	switch n.DataAtom {
+	case atom.P:
+		return strings.TrimSpace(convertInlineChildren(n))
+	case atom.Div:
+		return convertBlocks(n)
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		return strings.Repeat("#", level) + " " + strings.TrimSpace(convertInlineChildren(n))
+	case atom.Hr:
+		return "* * *"
+	case atom.Blockquote:
+		return prefixLines(convertBlocks(n), "> ", "> ")
+	case atom.Pre:
+		return convertPre(n)
+	case atom.Ul, atom.Ol:
+		return convertList(n)
+	}
+	return renderRaw(n)
+}
+
+func convertPre(n *html.Node) string {
+	code := n
+	if c := n.FirstChild; c != nil && c.NextSibling == nil && c.DataAtom == atom.Code {
+		code = c
+	}
+	var lang string
+	for _, a := range code.Attr {
+		if a.Key == "class" && strings.HasPrefix(a.Val, "language-") {
+			lang = strings.TrimPrefix(a.Val, "language-")
+		}
+	}
+	text := strings.TrimRight(textContent(code), "\n")
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + text + "\n" + fence
+}
+
+func convertList(n *html.Node) string {
+	var items []string
+	i := 1
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.DataAtom != atom.Li {
+			continue
+		}
+		marker := "* "
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d. ", i)
+			i++
+		}
+		content := convertBlocks(c)
+		items = append(items, prefixLines(content, marker, strings.Repeat(" ", len(marker))))
+	}
+	return strings.Join(items, "\n")
+}
+
+// prefixLines adds first to the first line of s and
+// rest to other non-empty lines.
+func prefixLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		switch {
+		case i == 0:
+			lines[i] = first + l
+		case l != "":
+			lines[i] = rest + l
+		case strings.TrimSpace(rest) != "":
+			lines[i] = strings.TrimSpace(rest)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func convertInlineChildren(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(convertInline(c))
+	}
+	return b.String()
+}
+
+var (
+	paragraphBreakRx = regexp.MustCompile(`[ \t\r]*\n[ \t\r]*\n\s*`)
+	spaceRx          = regexp.MustCompile(`\s+`)
+	escapeReplacer   = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`,
+		"`", "\\`", "[", `\[`, "]", `\]`, "<", "&lt;")
+)
+
+// convertText escapes Markdown characters and collapses whitespace,
+// keeping paragraph breaks (which is what WordPress content uses
+// instead of <p> tags).
+func convertText(s string) string {
+	paragraphs := paragraphBreakRx.Split(s, -1)
+	for i, p := range paragraphs {
+		paragraphs[i] = escapeReplacer.Replace(spaceRx.ReplaceAllString(p, " "))
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+func getAttr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// hasOnlyAttrs returns true if n has no attributes other than the given ones.
+func hasOnlyAttrs(n *html.Node, keys ...string) bool {
+outer:
+	for _, a := range n.Attr {
+		for _, k := range keys {
+			if a.Key == k {
+				continue outer
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func convertInline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return convertText(n.Data)
+	case html.CommentNode:
+		return "<!--" + n.Data + "-->"
+	case html.ElementNode:
+		// handled below
+	default:
+		return ""
+	}
+	switch n.DataAtom {
+	case atom.Em, atom.I:
+		if len(n.Attr) == 0 {
+			return wrapInline(convertInlineChildren(n), "*")
+		}
+	case atom.Strong, atom.B:
+		if len(n.Attr) == 0 {
+			return wrapInline(convertInlineChildren(n), "**")
+		}
+	case atom.Code:
+		if len(n.Attr) == 0 {
+			text := textContent(n)
+			fence := "`"
+			for strings.Contains(text, fence) {
+				fence += "`"
+			}
+			if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+				text = " " + text + " "
+			}
+			return fence + text + fence
+		}
+	case atom.Br:
+		return "  \n"
+	case atom.Span:
+		if len(n.Attr) == 0 {
+			return convertInlineChildren(n)
+		}
+	case atom.A:
+		href, ok := getAttr(n, "href")
+		if ok && hasOnlyAttrs(n, "href", "title") {
+			return "[" + convertInlineChildren(n) + "](" + linkDestination(href, n) + ")"
+		}
+	case atom.Img:
+		src, ok := getAttr(n, "src")
+		if ok && hasOnlyAttrs(n, "src", "alt", "title") {
+			alt, _ := getAttr(n, "alt")
+			return "![" + escapeReplacer.Replace(alt) + "](" + linkDestination(src, n) + ")"
+		}
+	}
+	return renderRaw(n)
+}
+
+func linkDestination(url string, n *html.Node) string {
+	url = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(url)
+	if title, ok := getAttr(n, "title"); ok {
+		url += ` "` + strings.ReplaceAll(title, `"`, `\"`) + `"`
+	}
+	return url
+}
+
+// wrapInline wraps s into delimiters, keeping surrounding
+// whitespace outside of them.
+func wrapInline(s, delim string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	i := strings.Index(s, trimmed)
+	return s[:i] + delim + trimmed + delim + s[i+len(trimmed):]
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
+
+func renderRaw(n *html.Node) string {
+	var buf bytes.Buffer
+	if err := html.Render(&buf, n); err != nil {
+		return "" // shouldn't happen when writing to buffer
+	}
+	return buf.String()
+}
@@ -0,0 +1,33 @@
+package markup
+
+import "testing"
+
+func TestHTMLToMarkdown(t *testing.T) {
+	var tests = []struct{ in, out string }{
+		{
+			"<p>Hello, <em>world</em>!</p><p>See <a href=\"/about\">this</a>.</p>",
+			"Hello, *world*!\n\nSee [this](/about).\n",
+		},
+		{
+			"First paragraph\n\nSecond <strong>one</strong> with 2*2\n<!--more-->",
+			"First paragraph\n\nSecond **one** with 2\\*2 <!--more-->\n",
+		},
+		{
+			"<h2>Title</h2><ul><li>one</li><li>two</li></ul><pre><code class=\"language-go\">x := 1\n</code></pre>",
+			"## Title\n\n* one\n* two\n\n```go\nx := 1\n```\n",
+		},
+		{
+			"<blockquote><p>a</p><p>b</p></blockquote><table><tr><td>x</td></tr></table>",
+			"> a\n>\n> b\n\n<table><tbody><tr><td>x</td></tr></tbody></table>\n",
+		},
+	}
+	for i, v := range tests {
+		out, err := HTMLToMarkdown([]byte(v.in))
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if string(out) != v.out {
+			t.Errorf("%d: expected\n%q\ngot\n%q\n", i, v.out, out)
+		}
+	}
+}
@@ -1,15 +1,78 @@
 package markup
 
 import (
+	"bytes"
 	"fmt"
 
-	"github.com/russross/blackfriday/v2"
+	"github.com/dchest/kkr/headings"
+	"github.com/dchest/kkr/highlight"
+	"github.com/dchest/kkr/mathtex"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
 )
 
 type Options struct {
 	MarkdownAngledQuotes bool `yaml:"markdown_angled_quotes"`
+	// Footnotes turns Markdown footnotes on or off with true or false,
+	// or enables them with the style: "list" (same as true), in which
+	// each footnote links back to its reference, "sidenotes", or
+	// "popovers".
+	Footnotes string `yaml:"footnotes"`
+	// MarkdownFootnotes is the same as Footnotes set to true.
+	//
+	// Deprecated: use Footnotes.
+	MarkdownFootnotes bool `yaml:"markdown_footnotes"`
+	// Wikilinks enables [[page-id]] and [[page-id|label]] links.
+	Wikilinks bool `yaml:"wikilinks"`
+	// Highlight enables syntax highlighting of fenced code blocks.
+	Highlight *highlight.Options `yaml:"highlight"`
+	// Math enables rendering of $...$ and $$...$$ TeX math
+	// at build time with external commands.
+	Math *mathtex.Options `yaml:"math"`
+
+	// Extensions enabled by default, which can be turned off.
+	Tables          *bool `yaml:"tables"`
+	Strikethrough   *bool `yaml:"strikethrough"`    // ~~text~~
+	DefinitionLists *bool `yaml:"definition_lists"` // term, then ": definition" line
+	Typographer     *bool `yaml:"typographer"`      // smart quotes, dashes and fractions
+	Attributes      *bool `yaml:"attributes"`       // heading IDs: # Heading {#id}
+	AutoHeadingIDs  *bool `yaml:"auto_heading_ids"` // IDs from heading text
+
+	// External markups by name, such as rst or org.
+	External map[string]*External `yaml:"external"`
+}
+
+// headingLevels are levels of Markdown headings that get automatic IDs.
+var headingLevels = []int{1, 2, 3, 4, 5, 6}
+
+// enabled returns true if the extension is on, or not set.
+func enabled(v *bool) bool {
+	return v == nil || *v
+}
+
+// footnotesStyle returns the style of footnotes, or an empty string
+// if they are disabled.
+func (o *Options) footnotesStyle() string {
+	switch o.Footnotes {
+	case "true":
+		return FootnotesList
+	case "false":
+		return ""
+	case "":
+		if o.MarkdownFootnotes {
+			return FootnotesList
+		}
+	}
+	return o.Footnotes
 }
 
+// footnoteReturnLink is the content of links back to footnote references.
+const footnoteReturnLink = "&#8617;&#xFE0E;"
+
 var options *Options
 
 func SetOptions(opts *Options) {
@@ -21,19 +84,97 @@ func Process(markupName string, content []byte) ([]byte, error) {
 	case "markdown":
 		return processMarkdown(content)
 	default:
+		if e, ok := options.External[markupName]; ok {
+			return processExternal(e, content)
+		}
 		return nil, fmt.Errorf("unknown markup: %q", markupName)
 	}
 }
 
-func processMarkdown(content []byte) ([]byte, error) {
-	htmlFlags := blackfriday.CommonHTMLFlags
-
-	if options.MarkdownAngledQuotes {
-		htmlFlags |= blackfriday.SmartypantsAngledQuotes
+// markdownExtensions returns goldmark extensions enabled by options.
+func markdownExtensions(footnotes string) []goldmark.Extender {
+	// Bare URLs are linked, as with blackfriday used before.
+	extensions := []goldmark.Extender{extension.Linkify}
+	for _, v := range []struct {
+		option    *bool
+		extension goldmark.Extender
+	}{
+		{options.Tables, extension.Table},
+		{options.Strikethrough, extension.Strikethrough},
+		{options.DefinitionLists, extension.DefinitionList},
+	} {
+		if enabled(v.option) {
+			extensions = append(extensions, v.extension)
+		}
 	}
+	if enabled(options.Typographer) {
+		var opts []extension.TypographerOption
+		if options.MarkdownAngledQuotes {
+			opts = append(opts, extension.WithTypographicSubstitutions(extension.TypographicSubstitutions{
+				extension.LeftDoubleQuote:  []byte("&laquo;"),
+				extension.RightDoubleQuote: []byte("&raquo;"),
+			}))
+		}
+		extensions = append(extensions, extension.NewTypographer(opts...))
+	}
+	if footnotes != "" {
+		extensions = append(extensions, extension.NewFootnote(
+			extension.WithFootnoteBacklinkClass("footnote-return"),
+			extension.WithFootnoteBacklinkHTML(footnoteReturnLink),
+		))
+	}
+	return extensions
+}
 
-	extensions := blackfriday.CommonExtensions | blackfriday.LaxHTMLBlocks
-
-	renderer := blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{Flags: htmlFlags})
-	return blackfriday.Run(content, blackfriday.WithExtensions(extensions), blackfriday.WithRenderer(renderer)), nil
+func processMarkdown(content []byte) ([]byte, error) {
+	footnotes := options.footnotesStyle()
+	var parserOptions []parser.Option
+	if enabled(options.Attributes) {
+		parserOptions = append(parserOptions, parser.WithAttribute())
+	}
+	renderers := []util.PrioritizedValue{}
+	if options.Highlight != nil {
+		renderers = append(renderers, util.Prioritized(&highlightRenderer{options.Highlight}, 100))
+	}
+	// Other styles replace references, so there's nowhere to return.
+	if footnotes != "" && footnotes != FootnotesList {
+		renderers = append(renderers, util.Prioritized(noFootnoteReturnLinks{}, 100))
+	}
+	md := goldmark.New(
+		goldmark.WithExtensions(markdownExtensions(footnotes)...),
+		goldmark.WithParserOptions(parserOptions...),
+		goldmark.WithRendererOptions(
+			html.WithUnsafe(), // pages may contain HTML
+			html.WithXHTML(),
+			renderer.WithNodeRenderers(renderers...),
+		),
+	)
+	if options.Math != nil {
+		// Keep math from being changed by Markdown, such as
+		// underscores turning into emphasis.
+		content = mathtex.Protect(content)
+	}
+	var buf bytes.Buffer
+	if err := md.Convert(content, &buf); err != nil {
+		return nil, err
+	}
+	out := buf.Bytes()
+	if enabled(options.AutoHeadingIDs) {
+		// Use the same IDs as heading anchors and tables of contents.
+		s, err := headings.IDs(string(out), headingLevels)
+		if err != nil {
+			return nil, err
+		}
+		out = []byte(s)
+	}
+	if options.Wikilinks {
+		var err error
+		if out, err = replaceWikilinks(out); err != nil {
+			return nil, err
+		}
+	}
+	if footnotes != "" && footnotes != FootnotesList {
+		return transformFootnotes(out, footnotes)
+	}
+	return out, nil
 }
@@ -0,0 +1,44 @@
+package markup
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtensions(t *testing.T) {
+	defer SetOptions(&Options{})
+	in := "# Title {#custom}\n\n~~old~~ -- \"new\"\n\n| a |\n|---|\n| b |\n\nTerm\n: Definition\n"
+	SetOptions(&Options{})
+	out, err := Process("markdown", []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`<h1 id="custom">`, `<del>old</del>`, `&ndash;`, `&ldquo;new&rdquo;`, `<table>`, `<dd>Definition</dd>`} {
+		if !strings.Contains(string(out), s) {
+			t.Errorf("default output doesn't contain %q:\n%s", s, out)
+		}
+	}
+	off := false
+	SetOptions(&Options{Tables: &off, Strikethrough: &off, DefinitionLists: &off, Typographer: &off, Attributes: &off})
+	out, err = Process("markdown", []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`<h1 id="custom">`, `<del>`, `&ndash;`, `&ldquo;`, `<table>`, `<dd>`} {
+		if strings.Contains(string(out), s) {
+			t.Errorf("output with extensions off contains %q:\n%s", s, out)
+		}
+	}
+}
+
+func TestAngledQuotes(t *testing.T) {
+	defer SetOptions(&Options{})
+	SetOptions(&Options{MarkdownAngledQuotes: true})
+	out, err := Process("markdown", []byte(`Say "hi"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "&laquo;hi&raquo;") {
+		t.Errorf("no angled quotes:\n%s", out)
+	}
+}
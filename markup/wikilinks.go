@@ -0,0 +1,66 @@
+package markup
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// WikilinkFunc is the name of template function which
+// renders wikilinks, see WikilinkTemplate.
+const WikilinkFunc = "wikilink"
+
+var wikilinkRx = regexp.MustCompile(`\[\[([^\[\]|]+)(?:\|([^\[\]]+))?\]\]`)
+
+// WikilinkTemplate returns template action for the wikilink to target
+// with optional label, which is resolved when content is rendered.
+func WikilinkTemplate(target, label string) string {
+	return "{{" + WikilinkFunc + " " + strconv.Quote(target) + " " + strconv.Quote(label) + "}}"
+}
+
+// replaceWikilinks replaces [[target]] and [[target|label]] in text
+// outside of code blocks and links with wikilink template actions.
+func replaceWikilinks(in []byte) ([]byte, error) {
+	var out bytes.Buffer
+	out.Grow(len(in))
+	z := xhtml.NewTokenizer(bytes.NewReader(in))
+	skip := 0
+	for {
+		tt := z.Next()
+		switch tt {
+		case xhtml.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			return out.Bytes(), nil
+		case xhtml.StartTagToken, xhtml.EndTagToken:
+			name, _ := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Pre, atom.Code, atom.A, atom.Script, atom.Style:
+				if tt == xhtml.StartTagToken {
+					skip++
+				} else if skip > 0 {
+					skip--
+				}
+			}
+		case xhtml.TextToken:
+			if skip == 0 {
+				raw := z.Raw()
+				out.Write(wikilinkRx.ReplaceAllFunc(raw, func(m []byte) []byte {
+					sub := wikilinkRx.FindSubmatch(m)
+					target := strings.TrimSpace(html.UnescapeString(string(sub[1])))
+					label := strings.TrimSpace(html.UnescapeString(string(sub[2])))
+					return []byte(WikilinkTemplate(target, label))
+				}))
+				continue
+			}
+		}
+		out.Write(z.Raw())
+	}
+}
@@ -0,0 +1,15 @@
+package markup
+
+import "testing"
+
+func TestReplaceWikilinks(t *testing.T) {
+	in := `<p>See [[about]] and [[2013-01-01-new-year|New &amp; Year]].</p><pre><code>[[code]]</code></pre>`
+	expected := `<p>See {{wikilink "about" ""}} and {{wikilink "2013-01-01-new-year" "New & Year"}}.</p><pre><code>[[code]]</code></pre>`
+	out, err := replaceWikilinks([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != expected {
+		t.Errorf("got\n%s\nexpected\n%s", out, expected)
+	}
+}
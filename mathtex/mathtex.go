@@ -0,0 +1,314 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mathtex implements replacing of TeX math between $...$
+// (inline) and $$...$$ (display) delimiters with HTML rendered
+// at build time, for example, by KaTeX.
+package mathtex
+
+import (
+	"bytes"
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/dchest/kkr/typography"
+)
+
+// Options is site.yml -> markup: math:.
+type Options struct {
+	// Command rendering inline math, which gets TeX on stdin and
+	// writes HTML to stdout, e.g. [katex].
+	Inline []string `yaml:"inline"`
+	// Command rendering display math, e.g. [katex, --display-mode].
+	// Inline command is used if empty.
+	Display []string `yaml:"display"`
+}
+
+// Check returns an error if options are invalid.
+func (o *Options) Check() error {
+	if len(o.Inline) == 0 {
+		return errors.New("math: no inline command")
+	}
+	return nil
+}
+
+// Command returns the command for inline or display math.
+func (o *Options) Command(display bool) []string {
+	if display && len(o.Display) > 0 {
+		return o.Display
+	}
+	return o.Inline
+}
+
+// RenderFunc returns HTML for TeX math.
+type RenderFunc func(tex string, display bool) ([]byte, error)
+
+// find returns the position of the next math in s starting from i:
+// start and end of the whole math including delimiters, and whether
+// it's display math. It returns -1 if there's no math.
+//
+// Inline math must not start with a space after the opening $ or end
+// with a space before the closing $, and the closing $ must not be
+// followed by a digit, so that "$5 and $10" is not math. Dollars can
+// be escaped with backslash.
+func find(s string, i int) (start, end int, display bool) {
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++ // skip escaped character
+		case '$':
+			if strings.HasPrefix(s[i:], "$$") {
+				j := strings.Index(s[i+2:], "$$")
+				if j > 0 && strings.TrimSpace(s[i+2:i+2+j]) != "" {
+					return i, i + 2 + j + 2, true
+				}
+				i++ // skip the second $
+				continue
+			}
+			if e := inlineEnd(s, i); e > 0 {
+				return i, e, false
+			}
+		}
+	}
+	return -1, -1, false
+}
+
+// inlineEnd returns the position after the closing $ of inline math
+// starting at i, or -1 if it's not math.
+func inlineEnd(s string, i int) int {
+	if i+1 >= len(s) || isSpace(s[i+1]) {
+		return -1
+	}
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '\n':
+			if j+1 < len(s) && s[j+1] == '\n' {
+				return -1 // paragraph break
+			}
+		case '$':
+			if isSpace(s[j-1]) || (j+1 < len(s) && s[j+1] >= '0' && s[j+1] <= '9') {
+				return -1
+			}
+			return j + 1
+		}
+	}
+	return -1
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// tex returns TeX source of math without delimiters.
+func tex(math string, display bool) string {
+	if display {
+		return strings.TrimSpace(math[2 : len(math)-2])
+	}
+	return math[1 : len(math)-1]
+}
+
+// placeholder returns an HTML element which is replaced with math by Replace.
+func placeholder(tex string, display bool) string {
+	class := "kkr-math-inline"
+	if display {
+		class = "kkr-math-display"
+	}
+	tex = strings.Replace(html.EscapeString(tex), "\n", "&#10;", -1)
+	return `<span class="` + class + `" data-tex="` + tex + `"></span>`
+}
+
+var placeholderRx = regexp.MustCompile(`<span class="kkr-math-(inline|display)" data-tex="([^"]*)"></span>`)
+
+// Protect replaces math in Markdown source with HTML elements, so that
+// Markdown processor doesn't change it. Code blocks and spans, as well
+// as template actions, are skipped.
+func Protect(in []byte) []byte {
+	var out strings.Builder
+	out.Grow(len(in))
+	lines := strings.SplitAfter(string(in), "\n")
+	var text strings.Builder // text between fenced code blocks
+	fence := ""
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if fence != "" {
+			out.WriteString(line)
+			if strings.HasPrefix(trimmed, fence) && strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1])) == "" {
+				fence = ""
+			}
+			continue
+		}
+		if len(line)-len(trimmed) <= 3 && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")) {
+			n := len(trimmed) - len(strings.TrimLeft(trimmed, trimmed[:1]))
+			fence = strings.Repeat(trimmed[:1], n)
+			out.WriteString(protectText(text.String()))
+			text.Reset()
+			out.WriteString(line)
+			continue
+		}
+		text.WriteString(line)
+	}
+	out.WriteString(protectText(text.String()))
+	return []byte(out.String())
+}
+
+// protectText replaces math in Markdown text outside of code spans
+// and template actions.
+func protectText(s string) string {
+	var out strings.Builder
+	last := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case s[i] == '`':
+			n := 1
+			for i+n < len(s) && s[i+n] == '`' {
+				n++
+			}
+			ticks := s[i : i+n]
+			j := i + n
+			for {
+				k := strings.Index(s[j:], ticks)
+				if k < 0 {
+					j = -1
+					break
+				}
+				j += k
+				if j+n < len(s) && s[j+n] == '`' {
+					// Longer run of backticks.
+					for j < len(s) && s[j] == '`' {
+						j++
+					}
+					continue
+				}
+				break
+			}
+			if j < 0 {
+				i += n - 1
+			} else {
+				i = j + n - 1
+			}
+		case strings.HasPrefix(s[i:], "{{"):
+			if j := strings.Index(s[i:], "}}"); j >= 0 {
+				i += j + 1
+			}
+		case s[i] == '$':
+			start, end, display := find(s[:nextSkip(s, i)], i)
+			if start != i {
+				if strings.HasPrefix(s[i:], "$$") {
+					i++
+				}
+				continue
+			}
+			out.WriteString(s[last:start])
+			out.WriteString(placeholder(tex(s[start:end], display), display))
+			last = end
+			i = end - 1
+		}
+	}
+	out.WriteString(s[last:])
+	return out.String()
+}
+
+// nextSkip returns the position of the next code span or template
+// action after i, which math can't span, or the length of s.
+func nextSkip(s string, i int) int {
+	n := len(s)
+	if j := strings.IndexByte(s[i:], '`'); j >= 0 && i+j < n {
+		n = i + j
+	}
+	if j := strings.Index(s[i:], "{{"); j >= 0 && i+j < n {
+		n = i + j
+	}
+	return n
+}
+
+// escapedDollar is replaced with a dollar in text outside of math.
+var escapedDollar = strings.NewReplacer(`\$`, "$")
+
+// replaceText renders math in unescaped text and returns HTML.
+func replaceText(text string, render RenderFunc) (string, error) {
+	var out strings.Builder
+	last := 0
+	for {
+		start, end, display := find(text, last)
+		if start < 0 {
+			break
+		}
+		out.WriteString(html.EscapeString(escapedDollar.Replace(text[last:start])))
+		b, err := wrap(tex(text[start:end], display), display, render)
+		if err != nil {
+			return "", err
+		}
+		out.Write(b)
+		last = end
+	}
+	out.WriteString(html.EscapeString(escapedDollar.Replace(text[last:])))
+	return out.String(), nil
+}
+
+// wrap returns rendered math in a span with "math inline"
+// or "math display" class.
+func wrap(tex string, display bool, render RenderFunc) ([]byte, error) {
+	b, err := render(tex, display)
+	if err != nil {
+		return nil, err
+	}
+	class := "math inline"
+	if display {
+		class = "math display"
+	}
+	var out bytes.Buffer
+	out.WriteString(`<span class="` + class + `">`)
+	out.Write(bytes.TrimSpace(b))
+	out.WriteString("</span>")
+	return out.Bytes(), nil
+}
+
+// Replace replaces math in text of HTML outside of code, pre, script
+// and similar elements, and math protected by Protect, with HTML
+// returned by render.
+func Replace(in []byte, render RenderFunc) ([]byte, error) {
+	var rerr error
+	out, err := typography.TransformText(in, func(text []byte, block bool) []byte {
+		if rerr != nil || bytes.IndexByte(text, '$') < 0 {
+			return text
+		}
+		s, err := replaceText(html.UnescapeString(string(text)), render)
+		if err != nil {
+			rerr = err
+			return text
+		}
+		return []byte(s)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rerr != nil {
+		return nil, rerr
+	}
+	if !bytes.Contains(out, []byte("kkr-math-")) {
+		return out, nil
+	}
+	out = placeholderRx.ReplaceAllFunc(out, func(m []byte) []byte {
+		if rerr != nil {
+			return m
+		}
+		sm := placeholderRx.FindSubmatch(m)
+		b, err := wrap(html.UnescapeString(string(sm[2])), string(sm[1]) == "display", render)
+		if err != nil {
+			rerr = err
+			return m
+		}
+		return b
+	})
+	if rerr != nil {
+		return nil, rerr
+	}
+	return out, nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mathtex
+
+import "testing"
+
+func testRender(tex string, display bool) ([]byte, error) {
+	if display {
+		return []byte("<D>" + tex + "</D>\n"), nil
+	}
+	return []byte("<I>" + tex + "</I>"), nil
+}
+
+func TestProtect(t *testing.T) {
+	in := "Let $x_i$ and $$\na*b\n$$ cost $5 and $10, \\$y.\n" +
+		"`$code$` {{ $a := 1 }}{{$a}}\n" +
+		"```\n$fenced$\n```\n$z$\n"
+	expected := `Let <span class="kkr-math-inline" data-tex="x_i"></span> and ` +
+		`<span class="kkr-math-display" data-tex="a*b"></span> cost $5 and $10, \$y.` + "\n" +
+		"`$code$` {{ $a := 1 }}{{$a}}\n" +
+		"```\n$fenced$\n```\n" + `<span class="kkr-math-inline" data-tex="z"></span>` + "\n"
+	if out := string(Protect([]byte(in))); out != expected {
+		t.Errorf("got\n%s\nexpected\n%s", out, expected)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	in := `<p>A $a&lt;b$ and <span class="kkr-math-display" data-tex="x&#10;y"></span>, \$1 ` +
+		`<code>$c$</code> $5 and $10</p><script>var s = "$x$";</script>`
+	expected := `<p>A <span class="math inline"><I>a<b</I></span> and ` +
+		`<span class="math display"><D>x` + "\n" + `y</D></span>, $1 ` +
+		`<code>$c$</code> $5 and $10</p><script>var s = "$x$";</script>`
+	out, err := Replace([]byte(in), testRender)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != expected {
+		t.Errorf("got\n%s\nexpected\n%s", out, expected)
+	}
+}
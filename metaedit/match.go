@@ -0,0 +1,110 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metaedit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dchest/kkr/utils"
+)
+
+// Condition is a list of clauses that all must match meta.
+//
+// Clauses are separated by "and" and have the following forms:
+//
+//	key contains value  - list or comma-separated string contains value
+//	key = value         - value equals (also "==")
+//	key != value        - value doesn't equal
+//	key exists          - key is present
+//	key missing         - key is not present
+type Condition struct {
+	clauses []clause
+}
+
+type clause struct {
+	key   string
+	op    string
+	value string
+}
+
+// ParseCondition parses a condition. An empty string
+// results in a condition which matches everything.
+func ParseCondition(s string) (*Condition, error) {
+	c := new(Condition)
+	if strings.TrimSpace(s) == "" {
+		return c, nil
+	}
+	for _, part := range strings.Split(s, " and ") {
+		fields := strings.Fields(part)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("bad condition %q", part)
+		}
+		cl := clause{key: fields[0], op: fields[1]}
+		switch cl.op {
+		case "exists", "missing":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("bad condition %q", part)
+			}
+		case "contains", "=", "==", "!=":
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("missing value in condition %q", part)
+			}
+			value := strings.Join(fields[2:], " ")
+			if uq, err := strconv.Unquote(value); err == nil {
+				value = uq
+			} else if len(value) > 1 && value[0] == '\'' && value[len(value)-1] == '\'' {
+				value = value[1 : len(value)-1]
+			}
+			cl.value = value
+		default:
+			return nil, fmt.Errorf("unknown operator %q in condition %q", cl.op, part)
+		}
+		c.clauses = append(c.clauses, cl)
+	}
+	return c, nil
+}
+
+// Match returns true if meta matches the condition.
+func (c *Condition) Match(meta map[string]interface{}) bool {
+	for _, cl := range c.clauses {
+		if !cl.match(meta) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cl clause) match(meta map[string]interface{}) bool {
+	v, ok := meta[cl.key]
+	switch cl.op {
+	case "exists":
+		return ok
+	case "missing":
+		return !ok
+	case "=", "==":
+		return ok && fmt.Sprint(v) == cl.value
+	case "!=":
+		return !ok || fmt.Sprint(v) != cl.value
+	case "contains":
+		switch x := v.(type) {
+		case []interface{}:
+			for _, item := range x {
+				if fmt.Sprint(item) == cl.value {
+					return true
+				}
+			}
+			return false
+		case string:
+			for _, item := range utils.SplitTags(x) {
+				if item == cl.value {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
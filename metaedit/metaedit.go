@@ -0,0 +1,257 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package metaedit implements editing of YAML headers of metafiles
+// preserving their formatting as much as possible.
+package metaedit
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/dchest/kkr/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Op is an edit operation on a header.
+type Op struct {
+	Kind  string // "set", "unset", "add", or "remove"
+	Key   string
+	Value string
+}
+
+// ParseOps parses command line arguments for the given edit command
+// into operations. Arguments for "set", "add" and "remove" are in the
+// form of key=value, arguments for "unset" are keys.
+func ParseOps(command string, args []string) ([]Op, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%s: missing arguments", command)
+	}
+	ops := make([]Op, 0, len(args))
+	for _, arg := range args {
+		switch command {
+		case "set", "add", "remove":
+			i := strings.IndexByte(arg, '=')
+			if i <= 0 {
+				return nil, fmt.Errorf("%s: expected key=value, got %q", command, arg)
+			}
+			ops = append(ops, Op{Kind: command, Key: arg[:i], Value: arg[i+1:]})
+		case "unset":
+			ops = append(ops, Op{Kind: command, Key: arg})
+		default:
+			return nil, fmt.Errorf("unknown meta command %q", command)
+		}
+	}
+	return ops, nil
+}
+
+// Apply applies operations to the YAML header and returns the result.
+// If the header wasn't changed, it returns false. Comments are kept,
+// except for those describing removed keys.
+func Apply(header []byte, ops []Op) (out []byte, changed bool, err error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(header, &doc); err != nil {
+		return nil, false, err
+	}
+	var comments []byte
+	if doc.Kind == 0 {
+		// Empty header or only comments, which are kept as is.
+		doc.Kind = yaml.DocumentNode
+		comments = header
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+	}
+	m := doc.Content[0]
+	if m.Kind != yaml.MappingNode {
+		return nil, false, fmt.Errorf("meta is not a mapping")
+	}
+	for _, op := range ops {
+		c, err := applyOp(m, op)
+		if err != nil {
+			return nil, false, err
+		}
+		changed = changed || c
+	}
+	if !changed {
+		return header, false, nil
+	}
+	if len(m.Content) == 0 {
+		// All keys removed, keep only comments.
+		var buf bytes.Buffer
+		for _, c := range []string{doc.HeadComment, m.HeadComment, m.FootComment, doc.FootComment} {
+			if c != "" {
+				if buf.Len() > 0 {
+					buf.WriteByte('\n')
+				}
+				buf.WriteString(c + "\n")
+			}
+		}
+		return buf.Bytes(), true, nil
+	}
+	var buf bytes.Buffer
+	buf.Write(comments)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, false, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, false, err
+	}
+	return buf.Bytes(), true, nil
+}
+
+// find returns the index of value node for key in mapping, or -1.
+func find(m *yaml.Node, key string) int {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func parseValue(s string) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(s), &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}, nil
+	}
+	return doc.Content[0], nil
+}
+
+func applyOp(m *yaml.Node, op Op) (bool, error) {
+	i := find(m, op.Key)
+	switch op.Kind {
+	case "set":
+		v, err := parseValue(op.Value)
+		if err != nil {
+			return false, fmt.Errorf("bad value for %q: %w", op.Key, err)
+		}
+		if i < 0 {
+			m.Content = append(m.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: op.Key}, v)
+			return true, nil
+		}
+		old := m.Content[i]
+		if old.Kind == yaml.ScalarNode && v.Kind == yaml.ScalarNode && old.Value == v.Value {
+			return false, nil
+		}
+		// Keep comments attached to the old value.
+		v.LineComment = old.LineComment
+		m.Content[i] = v
+		return true, nil
+	case "unset":
+		if i < 0 {
+			return false, nil
+		}
+		key, value := m.Content[i-1], m.Content[i]
+		m.Content = append(m.Content[:i-1], m.Content[i+1:]...)
+		// Head comment of the first key and foot comments of the last
+		// key may be about the whole header, so move them to the keys
+		// which become first and last.
+		if i == 1 {
+			if len(m.Content) > 0 {
+				m.Content[0].HeadComment = joinComments(key.HeadComment, m.Content[0].HeadComment)
+			} else {
+				m.HeadComment = joinComments(key.HeadComment, m.HeadComment)
+			}
+		}
+		if foot := joinComments(key.FootComment, value.FootComment); foot != "" {
+			if i > 1 {
+				prev := m.Content[i-3]
+				prev.FootComment = joinComments(prev.FootComment, foot)
+			} else {
+				m.FootComment = joinComments(foot, m.FootComment)
+			}
+		}
+		return true, nil
+	case "add":
+		if i < 0 {
+			m.Content = append(m.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: op.Key},
+				&yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle,
+					Content: []*yaml.Node{newString(op.Value)}})
+			return true, nil
+		}
+		return addToList(m.Content[i], op.Key, op.Value)
+	case "remove":
+		if i < 0 {
+			return false, nil
+		}
+		return removeFromList(m.Content[i], op.Key, op.Value)
+	}
+	return false, fmt.Errorf("unknown operation %q", op.Kind)
+}
+
+// joinComments joins non-empty comments with a newline.
+func joinComments(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	return a + "\n" + b
+}
+
+func newString(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
+
+// addToList adds value to a sequence or to a comma-separated string
+// (which is how tags can be specified).
+func addToList(n *yaml.Node, key, value string) (bool, error) {
+	switch n.Kind {
+	case yaml.SequenceNode:
+		for _, v := range n.Content {
+			if v.Value == value {
+				return false, nil
+			}
+		}
+		n.Content = append(n.Content, newString(value))
+		return true, nil
+	case yaml.ScalarNode:
+		items := utils.SplitTags(n.Value)
+		for _, v := range items {
+			if v == value {
+				return false, nil
+			}
+		}
+		n.Value = strings.Join(append(items, value), ", ")
+		n.Tag = "!!str"
+		return true, nil
+	}
+	return false, fmt.Errorf("%q is not a list", key)
+}
+
+func removeFromList(n *yaml.Node, key, value string) (bool, error) {
+	switch n.Kind {
+	case yaml.SequenceNode:
+		out := n.Content[:0]
+		for _, v := range n.Content {
+			if v.Value != value {
+				out = append(out, v)
+			}
+		}
+		changed := len(out) != len(n.Content)
+		n.Content = out
+		return changed, nil
+	case yaml.ScalarNode:
+		items := utils.SplitTags(n.Value)
+		out := items[:0]
+		for _, v := range items {
+			if v != value {
+				out = append(out, v)
+			}
+		}
+		if len(out) == len(items) {
+			return false, nil
+		}
+		n.Value = strings.Join(out, ", ")
+		return true, nil
+	}
+	return false, fmt.Errorf("%q is not a list", key)
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metaedit
+
+import "testing"
+
+func TestApply(t *testing.T) {
+	var tests = []struct {
+		in  string
+		ops []Op
+		out string
+	}{
+		{
+			"title: Hello # comment\ntags: go, news\n",
+			[]Op{{Kind: "add", Key: "tags", Value: "golang"}},
+			"title: Hello # comment\ntags: go, news, golang\n",
+		},
+		{
+			"title: Hello\ntags: [go, news]\n",
+			[]Op{{Kind: "remove", Key: "tags", Value: "go"}, {Kind: "set", Key: "draft", Value: "true"}},
+			"title: Hello\ntags: [news]\ndraft: true\n",
+		},
+		{
+			"title: Hello\ndraft: true\n",
+			[]Op{{Kind: "unset", Key: "draft"}},
+			"title: Hello\n",
+		},
+		{
+			"title: Hello\n",
+			[]Op{{Kind: "set", Key: "title", Value: "Hello"}},
+			"title: Hello\n",
+		},
+		{
+			"# Head comment.\ndraft: true\ntitle: Hello\n# Foot comment.\n",
+			[]Op{{Kind: "unset", Key: "draft"}, {Kind: "unset", Key: "title"}},
+			"# Head comment.\n\n# Foot comment.\n",
+		},
+		{
+			"title: Hello\n# About draft.\ndraft: true\ntags: go\n\n# Foot comment.\n",
+			[]Op{{Kind: "unset", Key: "draft"}},
+			"title: Hello\ntags: go\n\n# Foot comment.\n",
+		},
+		{
+			"# Only comment.\n",
+			[]Op{{Kind: "set", Key: "draft", Value: "true"}},
+			"# Only comment.\ndraft: true\n",
+		},
+	}
+	for i, v := range tests {
+		out, _, err := Apply([]byte(v.in), v.ops)
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if string(out) != v.out {
+			t.Errorf("%d: expected\n%s\ngot\n%s\n", i, v.out, out)
+		}
+	}
+}
+
+func TestCondition(t *testing.T) {
+	meta := map[string]interface{}{
+		"title": "Hello world",
+		"tags":  []interface{}{"go", "news"},
+		"cats":  "golang, misc",
+	}
+	var tests = []struct {
+		cond  string
+		match bool
+	}{
+		{"", true},
+		{"tags contains go", true},
+		{"tags contains golang", false},
+		{"cats contains golang", true},
+		{"cats contains go", false},
+		{"title = 'Hello world'", true},
+		{"title != \"Hello world\"", false},
+		{"tags contains news and draft missing", true},
+		{"draft exists", false},
+	}
+	for _, v := range tests {
+		c, err := ParseCondition(v.cond)
+		if err != nil {
+			t.Fatalf("%q: %s", v.cond, err)
+		}
+		if m := c.Match(meta); m != v.match {
+			t.Errorf("%q: expected %v, got %v", v.cond, v.match, m)
+		}
+	}
+}
@@ -3,24 +3,47 @@
 // license that can be found in the LICENSE file.
 
 // Package metafile implements reading of files with YAML headers.
+//
+// TOML headers separated by "+++" lines and JSON objects starting
+// with a "{" line are also recognized, as in Hugo.
 package metafile
 
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 
+	"github.com/dchest/kkr/toml"
 	"gopkg.in/yaml.v3"
 )
 
-const metaSeparator = "---"
+const (
+	metaSeparator     = "---"
+	tomlMetaSeparator = "+++"
+	jsonMetaStart     = "{"
+)
+
+// Header formats.
+const (
+	YAML = "yaml"
+	TOML = "toml"
+	JSON = "json"
+)
+
+// JSONMetaExtensions are extensions of files in which JSON headers
+// are recognized, since other files starting with "{" are data.
+var JSONMetaExtensions = []string{".md", ".markdown", ".html", ".htm"}
 
 type File struct {
 	sync.Mutex
+	name        string
 	fi          os.FileInfo
 	f           *os.File
 	r           *bufio.Reader
@@ -28,6 +51,8 @@ type File struct {
 	contentRead bool
 
 	hasMeta bool
+	format  string
+	header  []byte
 	meta    map[string]interface{}
 	content []byte
 }
@@ -43,9 +68,10 @@ func Open(name string) (m *File, err error) {
 		return nil, err
 	}
 	m = &File{
-		fi: fi,
-		f:  f,
-		r:  bufio.NewReader(f),
+		name: name,
+		fi:   fi,
+		f:    f,
+		r:    bufio.NewReader(f),
 	}
 	// Try reading meta.
 	if err := m.readMeta(); err != nil {
@@ -61,6 +87,34 @@ func (m *File) Close() error {
 	return m.f.Close()
 }
 
+// firstLine returns the trimmed first line of the file
+// if it's short enough to be a meta separator.
+func (m *File) firstLine() (string, error) {
+	p, err := m.r.Peek(len(metaSeparator) + 2)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	i := bytes.IndexByte(p, '\n')
+	if i < 0 {
+		if err == io.EOF {
+			// The only line, such as separator without header.
+			return strings.TrimSpace(string(p)), nil
+		}
+		return "", nil
+	}
+	return strings.TrimSpace(string(p[:i])), nil
+}
+
+func hasExt(name string, exts []string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, v := range exts {
+		if ext == v {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *File) readMeta() error {
 	m.Lock()
 	defer m.Unlock()
@@ -69,8 +123,22 @@ func (m *File) readMeta() error {
 		return nil
 	}
 	// Check if we have a meta file.
-	p, err := m.r.Peek(len(metaSeparator) + 1)
-	if (err != nil && err == io.EOF) || strings.TrimSpace(string(p)) != metaSeparator {
+	first, err := m.firstLine()
+	if err != nil {
+		return err
+	}
+	var header []byte
+	switch {
+	case first == metaSeparator:
+		m.format = YAML
+		header, err = m.readSeparated(metaSeparator)
+	case first == tomlMetaSeparator:
+		m.format = TOML
+		header, err = m.readSeparated(tomlMetaSeparator)
+	case first == jsonMetaStart && hasExt(m.name, JSONMetaExtensions):
+		m.format = JSON
+		header, err = m.readJSON()
+	default:
 		m.metaRead = true
 		m.hasMeta = false
 		return nil
@@ -78,36 +146,127 @@ func (m *File) readMeta() error {
 	if err != nil {
 		return err
 	}
-
-	// Read meta.
-	// Skip starting separator
-	head, err := m.r.ReadString('\n')
+	meta, err := Decode(m.format, header)
 	if err != nil {
 		return err
 	}
-	if strings.TrimSpace(head) != metaSeparator {
-		// Bad separator.
-		return errors.New("Bad meta separator on the first line")
+	m.header = header
+	m.meta = meta
+	m.hasMeta = true
+	m.metaRead = true
+	return nil
+}
+
+// Decode decodes the header in the given format.
+func Decode(format string, header []byte) (map[string]interface{}, error) {
+	var meta map[string]interface{}
+	switch format {
+	case YAML:
+		meta = make(map[string]interface{})
+		if err := yaml.Unmarshal(header, &meta); err != nil {
+			return nil, err
+		}
+	case TOML:
+		var err error
+		if meta, err = toml.Unmarshal(header); err != nil {
+			return nil, err
+		}
+	case JSON:
+		d := json.NewDecoder(bytes.NewReader(header))
+		d.UseNumber()
+		if err := d.Decode(&meta); err != nil {
+			return nil, fmt.Errorf("JSON meta: %w", err)
+		}
+		meta = convertJSONNumbers(meta).(map[string]interface{})
+	default:
+		return nil, fmt.Errorf("unknown meta format %q", format)
+	}
+	if meta == nil {
+		meta = make(map[string]interface{})
+	}
+	return meta, nil
+}
+
+// readSeparated reads meta between separator lines.
+func (m *File) readSeparated(separator string) ([]byte, error) {
+	// Skip starting separator
+	if _, err := m.r.ReadString('\n'); err != nil {
+		return nil, errors.New("Missing closing meta separator")
 	}
 	buf := bytes.NewBuffer(nil)
 	for {
-		var s string
-		s, err = m.r.ReadString('\n')
-		if err != nil {
-			return err
+		s, err := m.r.ReadString('\n')
+		if err != nil && (err != io.EOF || len(s) == 0) {
+			return nil, errors.New("Missing closing meta separator")
 		}
-		if len(s) > 0 && strings.TrimSpace(s) == metaSeparator {
+		if strings.TrimSpace(s) == separator {
 			break
 		}
+		if err == io.EOF {
+			return nil, errors.New("Missing closing meta separator")
+		}
 		buf.WriteString(s)
 	}
-	m.meta = make(map[string]interface{})
-	if err = yaml.Unmarshal(buf.Bytes(), &m.meta); err != nil {
-		return err
+	return buf.Bytes(), nil
+}
+
+// readJSON reads meta from JSON object, which ends
+// on the line where its opening brace is closed.
+func (m *File) readJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	depth := 0
+	inString, escaped := false, false
+	for {
+		s, err := m.r.ReadString('\n')
+		if err != nil && (err != io.EOF || len(s) == 0) {
+			return nil, errors.New("Missing end of JSON meta")
+		}
+		buf.WriteString(s)
+		for i := 0; i < len(s); i++ {
+			c := s[i]
+			switch {
+			case escaped:
+				escaped = false
+			case inString && c == '\\':
+				escaped = true
+			case c == '"':
+				inString = !inString
+			case !inString && c == '{':
+				depth++
+			case !inString && c == '}':
+				depth--
+			}
+		}
+		if depth == 0 {
+			break
+		}
+		if err == io.EOF {
+			return nil, errors.New("Missing end of JSON meta")
+		}
 	}
-	m.hasMeta = true
-	m.metaRead = true
-	return nil
+	return buf.Bytes(), nil
+}
+
+// convertJSONNumbers replaces json.Number values with int
+// or float64, which are produced by YAML for numbers.
+func convertJSONNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n)
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = convertJSONNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = convertJSONNumbers(e)
+		}
+	}
+	return v
 }
 
 func (m *File) Content() ([]byte, error) {
@@ -176,3 +335,42 @@ func Changed(name string, fi os.FileInfo) bool {
 	}
 	return false
 }
+
+// Split splits data of the named file into header and content, returning
+// the header format: YAML or TOML header without separators, or JSON
+// object. JSON headers are recognized only in files with extensions
+// from JSONMetaExtensions. If data doesn't start with a header, format
+// is empty and content is the original data.
+func Split(name string, data []byte) (header, content []byte, format string, err error) {
+	br := bytes.NewReader(data)
+	m := &File{name: name, r: bufio.NewReader(br)}
+	if err := m.readMeta(); err != nil {
+		return nil, nil, "", err
+	}
+	if !m.hasMeta {
+		return nil, data, "", nil
+	}
+	offset := len(data) - br.Len() - m.r.Buffered()
+	return m.header, data[offset:], m.format, nil
+}
+
+// Join joins header in the given format and content into a file with meta.
+func Join(format string, header, content []byte) []byte {
+	var buf bytes.Buffer
+	separator := metaSeparator
+	if format == TOML {
+		separator = tomlMetaSeparator
+	}
+	if format != JSON {
+		buf.WriteString(separator + "\n")
+	}
+	buf.Write(header)
+	if len(header) > 0 && header[len(header)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	if format != JSON {
+		buf.WriteString(separator + "\n")
+	}
+	buf.Write(content)
+	return buf.Bytes()
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -96,3 +97,109 @@ func TestNoMeta(t *testing.T) {
 		t.Errorf("content differs: expecting `%s`, got `%s`", metaContent, content)
 	}
 }
+
+func testMeta(t *testing.T, pattern, s string, want map[string]interface{}) {
+	f, err := ioutil.TempFile("", pattern)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer os.Remove(f.Name())
+	_, err = io.WriteString(f, s)
+	f.Close()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	m, err := Open(f.Name())
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer m.Close()
+	if !m.HasMeta() {
+		t.Fatalf("HasMeta returned false, expecting true")
+	}
+	for k, v := range want {
+		if got := m.Meta()[k]; !reflect.DeepEqual(got, v) {
+			t.Errorf("expecting %q: %#v, got %#v", k, v, got)
+		}
+	}
+	content, err := m.Content()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if !bytes.Equal(content, []byte(metaContent)) {
+		t.Errorf("content differs: expecting `%s`, got `%s`", metaContent, content)
+	}
+}
+
+func TestTOMLMeta(t *testing.T) {
+	testMeta(t, "metafile-test-*.md",
+		"+++\ntitle = \"Hello world\"\ntags = [\"a\", \"b\"]\ncount = 3\n+++\n"+metaContent,
+		map[string]interface{}{
+			"title": metaValue,
+			"tags":  []interface{}{"a", "b"},
+			"count": int(3),
+		})
+}
+
+func TestJSONMeta(t *testing.T) {
+	testMeta(t, "metafile-test-*.md",
+		"{\n  \"title\": \"Hello world\",\n  \"brace\": \"}\\\"\",\n  \"count\": 3,\n  \"ratio\": 0.5,\n  \"nested\": {\"a\": [1]}\n}\n"+metaContent,
+		map[string]interface{}{
+			"title":  metaValue,
+			"brace":  "}\"",
+			"count":  int(3),
+			"ratio":  0.5,
+			"nested": map[string]interface{}{"a": []interface{}{int(1)}},
+		})
+}
+
+func TestJSONNotMeta(t *testing.T) {
+	filename, err := WriteTempFile("{\n\"a\": 1\n}\n")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer os.Remove(filename)
+	m, err := Open(filename)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer m.Close()
+	if m.HasMeta() {
+		t.Errorf("HasMeta returned true for file without extension")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	for _, v := range []struct {
+		name, data      string
+		header, content string
+		format          string
+	}{
+		{"a.md", fileWithMeta, metaKey + ": " + metaValue + "\n", metaContent, YAML},
+		{"a.md", "+++\ntitle = \"Hi\"\n+++\n" + metaContent, "title = \"Hi\"\n", metaContent, TOML},
+		{"a.md", "{\n\"title\": \"Hi\"\n}\n" + metaContent, "{\n\"title\": \"Hi\"\n}\n", metaContent, JSON},
+		{"a.json", "{\n\"title\": \"Hi\"\n}\n", "", "{\n\"title\": \"Hi\"\n}\n", ""},
+		{"a.md", fileNoMeta, "", fileNoMeta, ""},
+	} {
+		header, content, format, err := Split(v.name, []byte(v.data))
+		if err != nil {
+			t.Fatalf("%q: %s", v.data, err)
+		}
+		if string(header) != v.header || string(content) != v.content || format != v.format {
+			t.Errorf("%q: got %q, %q, %q", v.data, header, content, format)
+		}
+		if format != "" {
+			if joined := Join(format, header, content); string(joined) != v.data {
+				t.Errorf("%q: joined %q", v.data, joined)
+			}
+			if _, err := Decode(format, header); err != nil {
+				t.Errorf("%q: %s", v.data, err)
+			}
+		}
+	}
+	for _, data := range []string{"+++\ntitle = 1\n", "---\ntitle: 1", "---"} {
+		if _, _, _, err := Split("a.md", []byte(data)); err == nil {
+			t.Errorf("%q: expected error for missing closing separator", data)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ping implements notifying search engines about changed
+// pages with IndexNow, and WebSub hubs about updated feeds.
+//
+// Sitemap pings are not supported: Google and Bing removed their
+// ping endpoints, and IndexNow is the supported way to notify Bing,
+// Yandex and other search engines. Google discovers sitemaps
+// listed in robots.txt.
+package ping
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultIndexNowEndpoint = "https://api.indexnow.org/indexnow"
+
+	// indexNowMaxURLs is the maximum number of URLs in a single submission.
+	indexNowMaxURLs = 10000
+)
+
+var client = &http.Client{Timeout: 30 * time.Second}
+
+func do(req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
+
+// IsValidIndexNowKey returns true if the key has the format
+// required by IndexNow: 8 to 128 characters a-z, A-Z, 0-9 or dash.
+func IsValidIndexNowKey(key string) bool {
+	if len(key) < 8 || len(key) > 128 {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// IndexNow submits URLs of a single host to the IndexNow endpoint.
+// The key file must be published at keyLocation.
+func IndexNow(endpoint, key, keyLocation string, urls []string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+	u, err := url.Parse(urls[0])
+	if err != nil {
+		return err
+	}
+	if u.Host == "" {
+		return errors.New("indexnow: URLs must be absolute")
+	}
+	for len(urls) > 0 {
+		n := len(urls)
+		if n > indexNowMaxURLs {
+			n = indexNowMaxURLs
+		}
+		body, err := json.Marshal(struct {
+			Host        string   `json:"host"`
+			Key         string   `json:"key"`
+			KeyLocation string   `json:"keyLocation"`
+			URLList     []string `json:"urlList"`
+		}{u.Host, key, keyLocation, urls[:n]})
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequest("POST", endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		if err := do(req); err != nil {
+			return fmt.Errorf("indexnow: %w", err)
+		}
+		urls = urls[n:]
+	}
+	return nil
+}
+
+// WebSub notifies the WebSub (PubSubHubbub) hub
+// that the feed at feedURL has been updated.
+func WebSub(hub, feedURL string) error {
+	form := url.Values{
+		"hub.mode": {"publish"},
+		"hub.url":  {feedURL},
+	}
+	req, err := http.NewRequest("POST", hub, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if err := do(req); err != nil {
+		return fmt.Errorf("websub %s: %w", hub, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ping
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestIndexNow(t *testing.T) {
+	var got map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		w.WriteHeader(202)
+	}))
+	defer ts.Close()
+	urls := []string{"https://example.com/", "https://example.com/a.html"}
+	if err := IndexNow(ts.URL, "abcdef12", "https://example.com/abcdef12.txt", urls); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"host":        "example.com",
+		"key":         "abcdef12",
+		"keyLocation": "https://example.com/abcdef12.txt",
+		"urlList":     []interface{}{"https://example.com/", "https://example.com/a.html"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestWebSub(t *testing.T) {
+	var got url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		got = r.PostForm
+		w.WriteHeader(204)
+	}))
+	defer ts.Close()
+	if err := WebSub(ts.URL, "https://example.com/feed.xml"); err != nil {
+		t.Fatal(err)
+	}
+	want := url.Values{"hub.mode": {"publish"}, "hub.url": {"https://example.com/feed.xml"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestIsValidIndexNowKey(t *testing.T) {
+	for key, valid := range map[string]bool{
+		"abcdef12":             true,
+		"0123-4567-89ab-cdef":  true,
+		"short":                false,
+		"with space in it":     false,
+		"with/slash/in/it/xyz": false,
+	} {
+		if IsValidIndexNowKey(key) != valid {
+			t.Errorf("%q: expected %v", key, valid)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package podcast implements rendering of podcast RSS feeds
+// with iTunes extensions.
+package podcast
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// site.yml -> podcast:
+type Config struct {
+	Feed        string   `yaml:"feed"` // feed path, e.g. /podcast.xml
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Author      string   `yaml:"author"`
+	Image       string   `yaml:"image"` // artwork URL (absolute or relative to site URL)
+	Language    string   `yaml:"language"`
+	Categories  []string `yaml:"categories"` // e.g. "Technology" or "Society & Culture/Philosophy"
+	Explicit    bool     `yaml:"explicit"`
+	Type        string   `yaml:"type"` // "episodic" (default) or "serial"
+	Owner       struct {
+		Name  string `yaml:"name"`
+		Email string `yaml:"email"`
+	} `yaml:"owner"`
+	Hub string `yaml:"-"` // WebSub hub URL
+}
+
+// Audio describes an episode enclosure, which is set
+// in `audio:` front matter of a post.
+type Audio struct {
+	URL      string `yaml:"url"`
+	Length   int64  `yaml:"length"` // in bytes
+	Type     string `yaml:"type"`   // MIME type, e.g. audio/mpeg
+	Duration string `yaml:"duration"`
+	Explicit bool   `yaml:"explicit"`
+	Episode  int    `yaml:"episode"`
+	Season   int    `yaml:"season"`
+	Image    string `yaml:"image"`
+}
+
+type Episode struct {
+	Title       string
+	Link        string
+	GUID        string
+	Description string
+	Date        time.Time
+	Audio       *Audio
+}
+
+type category struct {
+	Name string
+	Sub  string
+}
+
+func parseCategories(cs []string) []category {
+	out := make([]category, 0, len(cs))
+	for _, c := range cs {
+		parts := strings.SplitN(c, "/", 2)
+		cat := category{Name: strings.TrimSpace(parts[0])}
+		if len(parts) > 1 {
+			cat.Sub = strings.TrimSpace(parts[1])
+		}
+		out = append(out, cat)
+	}
+	return out
+}
+
+// absURL returns u prefixed with baseURL if it starts with a slash.
+func absURL(baseURL, u string) string {
+	if strings.HasPrefix(u, "/") {
+		return baseURL + u
+	}
+	return u
+}
+
+// Render renders podcast feed with the given build date.
+func Render(w io.Writer, c *Config, siteURL string, date time.Time, episodes []*Episode) error {
+	return feedTemplate.Execute(w, struct {
+		Config     *Config
+		SiteURL    string
+		Categories []category
+		Episodes   []*Episode
+		Date       time.Time
+	}{
+		c,
+		siteURL,
+		parseCategories(c.Categories),
+		episodes,
+		date,
+	})
+}
+
+var feedFuncs = template.FuncMap{
+	// `xml` function escapes XML.
+	"xml": func(in string) (string, error) {
+		var buf bytes.Buffer
+		if err := xml.EscapeText(&buf, []byte(in)); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	},
+	"abs": absURL,
+	"rfc822": func(t time.Time) string {
+		return t.Format(time.RFC1123Z)
+	},
+}
+
+var feedTemplate = template.Must(template.New("").Funcs(feedFuncs).Parse(
+	`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" xmlns:atom="http://www.w3.org/2005/Atom">
+ <channel>
+  <title>{{.Config.Title | xml}}</title>
+  <link>{{.SiteURL | xml}}/</link>
+  <atom:link href="{{abs .SiteURL .Config.Feed | xml}}" rel="self" type="application/rss+xml"/>
+  {{- with .Config.Hub}}
+  <atom:link href="{{. | xml}}" rel="hub"/>
+  {{- end}}
+  <description>{{.Config.Description | xml}}</description>
+  {{- with .Config.Language}}
+  <language>{{. | xml}}</language>
+  {{- end}}
+  <lastBuildDate>{{rfc822 .Date}}</lastBuildDate>
+  {{- with .Config.Author}}
+  <itunes:author>{{. | xml}}</itunes:author>
+  {{- end}}
+  {{- with .Config.Image}}
+  <itunes:image href="{{abs $.SiteURL . | xml}}"/>
+  {{- end}}
+  {{- range .Categories}}
+  {{- if .Sub}}
+  <itunes:category text="{{.Name | xml}}"><itunes:category text="{{.Sub | xml}}"/></itunes:category>
+  {{- else}}
+  <itunes:category text="{{.Name | xml}}"/>
+  {{- end}}
+  {{- end}}
+  <itunes:explicit>{{if .Config.Explicit}}true{{else}}false{{end}}</itunes:explicit>
+  {{- with .Config.Type}}
+  <itunes:type>{{. | xml}}</itunes:type>
+  {{- end}}
+  {{- if .Config.Owner.Email}}
+  <itunes:owner>
+   <itunes:name>{{.Config.Owner.Name | xml}}</itunes:name>
+   <itunes:email>{{.Config.Owner.Email | xml}}</itunes:email>
+  </itunes:owner>
+  {{- end}}
+  {{- range .Episodes}}
+  <item>
+   <title>{{.Title | xml}}</title>
+   <link>{{.Link | xml}}</link>
+   <guid isPermaLink="false">{{.GUID | xml}}</guid>
+   <pubDate>{{rfc822 .Date}}</pubDate>
+   {{- with .Description}}
+   <description>{{. | xml}}</description>
+   {{- end}}
+   <enclosure url="{{abs $.SiteURL .Audio.URL | xml}}" length="{{.Audio.Length}}" type="{{.Audio.Type | xml}}"/>
+   {{- with .Audio.Duration}}
+   <itunes:duration>{{. | xml}}</itunes:duration>
+   {{- end}}
+   {{- if .Audio.Explicit}}
+   <itunes:explicit>true</itunes:explicit>
+   {{- end}}
+   {{- with .Audio.Episode}}
+   <itunes:episode>{{.}}</itunes:episode>
+   {{- end}}
+   {{- with .Audio.Season}}
+   <itunes:season>{{.}}</itunes:season>
+   {{- end}}
+   {{- with .Audio.Image}}
+   <itunes:image href="{{abs $.SiteURL . | xml}}"/>
+   {{- end}}
+  </item>
+  {{- end}}
+ </channel>
+</rss>
+`))
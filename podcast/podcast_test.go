@@ -0,0 +1,48 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package podcast
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	c := &Config{
+		Feed:       "/podcast.xml",
+		Title:      "Tom & Jerry",
+		Categories: []string{"Arts/Books", "Comedy"},
+		Hub:        "https://hub.example.com/",
+	}
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	episodes := []*Episode{{
+		Title: "First",
+		Link:  "https://example.com/first/",
+		GUID:  "abc",
+		Date:  date,
+		Audio: &Audio{URL: "/first.mp3", Length: 100, Type: "audio/mpeg", Duration: "1:02:03"},
+	}}
+	var buf bytes.Buffer
+	if err := Render(&buf, c, "https://example.com", date, episodes); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		`<title>Tom &amp; Jerry</title>`,
+		`<atom:link href="https://example.com/podcast.xml"`,
+		`<atom:link href="https://hub.example.com/" rel="hub"/>`,
+		`<itunes:category text="Arts"><itunes:category text="Books"/></itunes:category>`,
+		`<itunes:category text="Comedy"/>`,
+		`<pubDate>Fri, 02 Jan 2026 03:04:05 +0000</pubDate>`,
+		`<enclosure url="https://example.com/first.mp3" length="100" type="audio/mpeg"/>`,
+		`<itunes:duration>1:02:03</itunes:duration>`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output doesn't contain %q", s)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package purge implements purging of URLs from CDN caches
+// using APIs of Cloudflare, Fastly and Bunny.
+package purge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Purger purges URLs from a CDN cache.
+type Purger interface {
+	Name() string
+	Purge(urls []string) error
+}
+
+// Cloudflare purges URLs from cache of the Cloudflare zone.
+type Cloudflare struct {
+	Zone  string // zone ID
+	Token string // API token with Cache Purge permission
+
+	API    string // defaults to https://api.cloudflare.com/client/v4
+	Client *http.Client
+}
+
+// Fastly purges URLs cached by Fastly.
+type Fastly struct {
+	Token string // API token with purge_select scope
+
+	API    string // defaults to https://api.fastly.com
+	Client *http.Client
+}
+
+// Bunny purges URLs cached by bunny.net.
+type Bunny struct {
+	AccessKey string // account API key
+
+	API    string // defaults to https://api.bunny.net
+	Client *http.Client
+}
+
+// cloudflareMaxFiles is the maximum number of URLs in a single request.
+const cloudflareMaxFiles = 30
+
+func do(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
+
+func apiURL(api, def string) string {
+	if api == "" {
+		return def
+	}
+	return strings.TrimSuffix(api, "/")
+}
+
+func (c *Cloudflare) Name() string { return "Cloudflare" }
+
+func (c *Cloudflare) Purge(urls []string) error {
+	for len(urls) > 0 {
+		n := len(urls)
+		if n > cloudflareMaxFiles {
+			n = cloudflareMaxFiles
+		}
+		body, err := json.Marshal(map[string][]string{"files": urls[:n]})
+		if err != nil {
+			return err
+		}
+		u := apiURL(c.API, "https://api.cloudflare.com/client/v4") + "/zones/" + url.PathEscape(c.Zone) + "/purge_cache"
+		req, err := http.NewRequest("POST", u, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		req.Header.Set("Content-Type", "application/json")
+		if err := do(c.Client, req); err != nil {
+			return fmt.Errorf("cloudflare purge: %w", err)
+		}
+		urls = urls[n:]
+	}
+	return nil
+}
+
+func (f *Fastly) Name() string { return "Fastly" }
+
+func (f *Fastly) Purge(urls []string) error {
+	for _, v := range urls {
+		u, err := url.Parse(v)
+		if err != nil {
+			return err
+		}
+		// Fastly takes the URL without scheme.
+		req, err := http.NewRequest("POST", apiURL(f.API, "https://api.fastly.com")+"/purge/"+u.Host+u.EscapedPath(), nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Fastly-Key", f.Token)
+		if err := do(f.Client, req); err != nil {
+			return fmt.Errorf("fastly purge %s: %w", v, err)
+		}
+	}
+	return nil
+}
+
+func (b *Bunny) Name() string { return "Bunny" }
+
+func (b *Bunny) Purge(urls []string) error {
+	for _, v := range urls {
+		req, err := http.NewRequest("POST", apiURL(b.API, "https://api.bunny.net")+"/purge?url="+url.QueryEscape(v), nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("AccessKey", b.AccessKey)
+		if err := do(b.Client, req); err != nil {
+			return fmt.Errorf("bunny purge %s: %w", v, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package purge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCloudflare(t *testing.T) {
+	var batches []int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/zones/abc/purge_cache" || r.Header.Get("Authorization") != "Bearer tok" {
+			http.Error(w, "bad request", 400)
+			return
+		}
+		var req struct{ Files []string }
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		batches = append(batches, len(req.Files))
+	}))
+	defer ts.Close()
+
+	var urls []string
+	for i := 0; i < 45; i++ {
+		urls = append(urls, fmt.Sprintf("https://example.com/%d.html", i))
+	}
+	c := &Cloudflare{Zone: "abc", Token: "tok", API: ts.URL}
+	if err := c.Purge(urls); err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 2 || batches[0] != 30 || batches[1] != 15 {
+		t.Errorf("batches: %v", batches)
+	}
+	c.Token = "wrong"
+	if err := c.Purge(urls); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestFastly(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+	}))
+	defer ts.Close()
+	f := &Fastly{Token: "tok", API: ts.URL}
+	if err := f.Purge([]string{"https://example.com/a%20b/"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || paths[0] != "/purge/example.com/a%20b/" {
+		t.Errorf("paths: %v", paths)
+	}
+}
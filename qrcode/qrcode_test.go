@@ -0,0 +1,61 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package qrcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	for _, v := range []struct {
+		text string
+		size int
+	}{
+		{"http://192.168.1.2:8080", 25}, // version 2
+		{strings.Repeat("x", 130), 49},  // version 8
+		{strings.Repeat("x", 213), 57},  // version 10
+	} {
+		c, err := Encode(v.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Size != v.size {
+			t.Errorf("%d bytes: size %d, expected %d", len(v.text), c.Size, v.size)
+		}
+		// Finder patterns.
+		for _, p := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+			for i := 0; i < 7; i++ {
+				if !c.Dark(p[0]+i, p[1]) || !c.Dark(p[0], p[1]+i) || !c.Dark(p[0]+3, p[1]+3) {
+					t.Fatalf("%d bytes: bad finder pattern at %v", len(v.text), p)
+				}
+			}
+		}
+	}
+	if _, err := Encode(strings.Repeat("x", 214)); err != ErrTooLong {
+		t.Errorf("expected ErrTooLong, got %v", err)
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	c, err := Encode("http://192.168.1.2:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(c.Terminal(), "\n"), "\n")
+	if len(lines) != (c.Size+8+1)/2 {
+		t.Fatalf("got %d lines for size %d", len(lines), c.Size)
+	}
+	blank := "\x1b[30;47m" + strings.Repeat(" ", c.Size+8) + "\x1b[0m"
+	for i := 0; i < 2; i++ {
+		if lines[i] != blank {
+			t.Errorf("line %d is not in quiet zone: %q", i, lines[i])
+		}
+	}
+	// Finder pattern starts after four modules.
+	if !strings.HasPrefix(lines[2], "\x1b[30;47m    █") {
+		t.Errorf("bad quiet zone: %q", lines[2])
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package redirects implements loading of redirect maps and rendering
+// them as meta refresh pages and redirect files for hosting providers.
+//
+// A redirect map is a YAML file mapping old paths to new paths or URLs,
+// optionally with a status code:
+//
+//	/old/: /new/
+//	/temporary/:
+//	  to: https://example.com/
+//	  status: 302
+package redirects
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const DefaultStatus = 301
+
+type Redirect struct {
+	From   string
+	To     string
+	Status int
+}
+
+type target struct {
+	To     string `yaml:"to"`
+	Status int    `yaml:"status"`
+}
+
+func (t *target) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&t.To)
+	}
+	type plain target
+	return value.Decode((*plain)(t))
+}
+
+// Parse parses a redirect map. Redirects are sorted by source path.
+func Parse(data []byte) ([]*Redirect, error) {
+	var m map[string]target
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	list := make([]*Redirect, 0, len(m))
+	for from, t := range m {
+		if !strings.HasPrefix(from, "/") {
+			return nil, fmt.Errorf("redirect from %q: path must start with /", from)
+		}
+		if t.To == "" {
+			return nil, fmt.Errorf("redirect from %s: missing target", from)
+		}
+		switch t.Status {
+		case 0:
+			t.Status = DefaultStatus
+		case 301, 302, 307, 308:
+			// ok
+		default:
+			return nil, fmt.Errorf("redirect from %s: unsupported status %d", from, t.Status)
+		}
+		list = append(list, &Redirect{From: from, To: t.To, Status: t.Status})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].From < list[j].From })
+	return list, nil
+}
+
+// Load loads a redirect map from the file.
+// If the file doesn't exist, it returns nil.
+func Load(filename string) ([]*Redirect, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	list, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return list, nil
+}
+
+// Find returns a redirect for the path or nil if there's none.
+// Paths are matched with and without trailing "index.html".
+func Find(list []*Redirect, path string) *Redirect {
+	alt := path
+	if strings.HasSuffix(path, "/index.html") {
+		alt = strings.TrimSuffix(path, "index.html")
+	}
+	for _, r := range list {
+		if r.From == path || r.From == alt {
+			return r
+		}
+	}
+	return nil
+}
+
+// HTML returns a page redirecting to the target with meta refresh.
+func (r *Redirect) HTML() []byte {
+	to := html.EscapeString(r.To)
+	var b bytes.Buffer
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n")
+	b.WriteString(`<meta charset="utf-8">` + "\n")
+	b.WriteString(`<meta name="robots" content="noindex">` + "\n")
+	fmt.Fprintf(&b, `<meta http-equiv="refresh" content="0; url=%s">`+"\n", to)
+	fmt.Fprintf(&b, `<link rel="canonical" href="%s">`+"\n", to)
+	fmt.Fprintf(&b, "<title>Redirecting to %s</title>\n", to)
+	b.WriteString("</head>\n<body>\n")
+	fmt.Fprintf(&b, `<p>This page has moved to <a href="%s">%s</a>.</p>`+"\n", to, to)
+	b.WriteString("</body>\n</html>\n")
+	return b.Bytes()
+}
+
+// Netlify returns redirects in _redirects file format,
+// which is used by Netlify and Cloudflare Pages.
+func Netlify(list []*Redirect) []byte {
+	var b bytes.Buffer
+	for _, r := range list {
+		fmt.Fprintf(&b, "%s %s %d\n", r.From, r.To, r.Status)
+	}
+	return b.Bytes()
+}
+
+// Apache returns redirects as Apache .htaccess directives.
+func Apache(list []*Redirect) []byte {
+	var b bytes.Buffer
+	for _, r := range list {
+		fmt.Fprintf(&b, "Redirect %d %s %s\n", r.Status, quoteApache(r.From), quoteApache(r.To))
+	}
+	return b.Bytes()
+}
+
+// Nginx returns redirects as entries of nginx map block, for example:
+//
+//	map $uri $redirect_uri {
+//		include redirects.map;
+//	}
+//	if ($redirect_uri) {
+//		return 301 $redirect_uri;
+//	}
+//
+// Status codes are not included.
+func Nginx(list []*Redirect) []byte {
+	var b bytes.Buffer
+	for _, r := range list {
+		fmt.Fprintf(&b, "%s %s;\n", quoteNginx(r.From), quoteNginx(r.To))
+	}
+	return b.Bytes()
+}
+
+func quoteNginx(s string) string {
+	if strings.ContainsAny(s, " \t\";{}") {
+		return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+	}
+	return s
+}
+
+func quoteApache(s string) string {
+	if strings.ContainsAny(s, " \t\"") {
+		return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+	}
+	return s
+}
@@ -0,0 +1,47 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redirects
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	list, err := Parse([]byte(`
+/old/: /new/
+/temp/:
+  to: https://example.com/
+  status: 302
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("got %d redirects", len(list))
+	}
+	if r := list[0]; r.From != "/old/" || r.To != "/new/" || r.Status != 301 {
+		t.Errorf("bad redirect: %+v", r)
+	}
+	if r := list[1]; r.From != "/temp/" || r.To != "https://example.com/" || r.Status != 302 {
+		t.Errorf("bad redirect: %+v", r)
+	}
+	if r := Find(list, "/old/index.html"); r != list[0] {
+		t.Errorf("Find: got %+v", r)
+	}
+	if r := Find(list, "/other/"); r != nil {
+		t.Errorf("Find: got %+v", r)
+	}
+	if out := string(Netlify(list)); out != "/old/ /new/ 301\n/temp/ https://example.com/ 302\n" {
+		t.Errorf("Netlify: got %q", out)
+	}
+
+	for _, bad := range []string{
+		"old/: /new/",
+		"/old/:\n  status: 301",
+		"/old/:\n  to: /new/\n  status: 200",
+	} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package s3 implements a minimal client for S3-compatible storage,
+// such as Amazon S3 or Cloudflare R2, sufficient for deploying sites:
+// listing, uploading and deleting objects.
+//
+// Requests use path-style URLs and are signed with AWS Signature Version 4.
+package s3
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	timeFormat = "20060102T150405Z"
+	dateFormat = "20060102"
+
+	// maxDelete is the maximum number of keys in a single delete request.
+	maxDelete = 1000
+)
+
+type Client struct {
+	Endpoint  string // e.g. https://s3.us-east-1.amazonaws.com
+	Region    string // e.g. us-east-1, or auto for R2
+	Bucket    string
+	AccessKey string
+	SecretKey string
+
+	HTTPClient *http.Client
+}
+
+type Object struct {
+	Key  string
+	ETag string // without quotes
+	Size int64
+}
+
+// Error is an error returned by the storage.
+type Error struct {
+	Status  int
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+	Key     string `xml:"Key"`
+}
+
+func (e *Error) Error() string {
+	s := fmt.Sprintf("s3: %d %s", e.Status, e.Code)
+	if e.Key != "" {
+		s += " (" + e.Key + ")"
+	}
+	if e.Message != "" {
+		s += ": " + e.Message
+	}
+	return s
+}
+
+// escapePath escapes each segment of the path as required by
+// the signature algorithm, keeping slashes.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = escape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// escape percent-encodes everything except unreserved characters.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), q[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, escape(k)+"="+escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func hmacSHA256(key []byte, s string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(s))
+	return h.Sum(nil)
+}
+
+func sha256Hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// sign signs the request with the payload hash at the given time,
+// setting X-Amz-Date, X-Amz-Content-Sha256 and Authorization headers.
+// All headers of the request and the host are signed.
+func (c *Client) sign(req *http.Request, payloadHash string, t time.Time) {
+	t = t.UTC()
+	req.Header.Set("X-Amz-Date", t.Format(timeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var ch strings.Builder
+	for _, k := range names {
+		ch.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		escapePath(req.URL.Path),
+		canonicalQuery(req.URL.Query()),
+		ch.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := t.Format(dateFormat) + "/" + c.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + t.Format(timeFormat) + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+c.SecretKey), t.Format(dateFormat))
+	key = hmacSHA256(key, c.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.AccessKey, scope, signedHeaders, signature))
+}
+
+// do sends a signed request for the key (or the bucket if key is empty)
+// and returns the response body.
+func (c *Client) do(method, key string, query url.Values, header http.Header, body []byte) ([]byte, error) {
+	u, err := url.Parse(strings.TrimSuffix(c.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	u.Path += "/" + c.Bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = escapePath(u.Path)
+	u.RawQuery = canonicalQuery(query)
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	c.sign(req, sha256Hex(body), time.Now())
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		e := &Error{Status: resp.StatusCode}
+		xml.Unmarshal(b, e) // best effort
+		if e.Code == "" {
+			e.Code = http.StatusText(resp.StatusCode)
+		}
+		return nil, e
+	}
+	return b, nil
+}
+
+// List returns all objects with keys starting with the prefix.
+func (c *Client) List(prefix string) ([]*Object, error) {
+	var objects []*Object
+	token := ""
+	for {
+		q := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		b, err := c.do("GET", "", q, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var res struct {
+			Contents []struct {
+				Key  string `xml:"Key"`
+				ETag string `xml:"ETag"`
+				Size int64  `xml:"Size"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		if err := xml.Unmarshal(b, &res); err != nil {
+			return nil, fmt.Errorf("s3: list: %w", err)
+		}
+		for _, v := range res.Contents {
+			objects = append(objects, &Object{
+				Key:  v.Key,
+				ETag: strings.Trim(v.ETag, `"`),
+				Size: v.Size,
+			})
+		}
+		if !res.IsTruncated || res.NextContinuationToken == "" {
+			break
+		}
+		token = res.NextContinuationToken
+	}
+	return objects, nil
+}
+
+// Put uploads an object with headers, such as Content-Type,
+// Content-Encoding and Cache-Control.
+func (c *Client) Put(key string, body []byte, header http.Header) error {
+	h := make(http.Header)
+	for k, v := range header {
+		h[k] = v
+	}
+	h.Set("Content-Md5", contentMD5(body))
+	_, err := c.do("PUT", key, nil, h, body)
+	return err
+}
+
+// Delete deletes objects with the given keys.
+func (c *Client) Delete(keys []string) error {
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > maxDelete {
+			n = maxDelete
+		}
+		if err := c.deleteBatch(keys[:n]); err != nil {
+			return err
+		}
+		keys = keys[n:]
+	}
+	return nil
+}
+
+func (c *Client) deleteBatch(keys []string) error {
+	type object struct {
+		Key string `xml:"Key"`
+	}
+	req := struct {
+		XMLName xml.Name `xml:"Delete"`
+		Objects []object `xml:"Object"`
+		Quiet   bool     `xml:"Quiet"`
+	}{Quiet: true}
+	for _, k := range keys {
+		req.Objects = append(req.Objects, object{k})
+	}
+	body, err := xml.Marshal(req)
+	if err != nil {
+		return err
+	}
+	h := make(http.Header)
+	h.Set("Content-Type", "application/xml")
+	h.Set("Content-Md5", contentMD5(body))
+	b, err := c.do("POST", "", url.Values{"delete": {""}}, h, body)
+	if err != nil {
+		return err
+	}
+	var res struct {
+		Errors []*Error `xml:"Error"`
+	}
+	if err := xml.Unmarshal(b, &res); err != nil {
+		return fmt.Errorf("s3: delete: %w", err)
+	}
+	if len(res.Errors) > 0 {
+		return res.Errors[0]
+	}
+	return nil
+}
+
+func contentMD5(b []byte) string {
+	h := md5.Sum(b)
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// ETag returns the ETag that the storage assigns to an object
+// with the content uploaded in a single request.
+func ETag(b []byte) string {
+	h := md5.Sum(b)
+	return hex.EncodeToString(h[:])
+}
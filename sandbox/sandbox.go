@@ -0,0 +1,184 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sandbox launches external commands for features such as exec
+// filters, diagrams and image conversion. Commands can be disabled when
+// building untrusted sites (safe mode) or run inside a sandbox, which
+// denies network access, unless commands need it, and restricts writes
+// to the site and temporary directories.
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrSafeMode is returned by Check in safe mode.
+var ErrSafeMode = errors.New("external commands are disabled in safe mode")
+
+var safeMode int32
+
+// SetSafeMode enables or disables safe mode, in which
+// external commands are not allowed to run.
+func SetSafeMode(safe bool) {
+	var v int32
+	if safe {
+		v = 1
+	}
+	atomic.StoreInt32(&safeMode, v)
+}
+
+// SafeMode returns true if safe mode is enabled.
+func SafeMode() bool {
+	return atomic.LoadInt32(&safeMode) == 1
+}
+
+// Check returns an error if running the external command
+// with the given name is not allowed.
+func Check(name string) error {
+	if SafeMode() {
+		return fmt.Errorf("%s: %w", name, ErrSafeMode)
+	}
+	return nil
+}
+
+// site.yml -> sandbox:
+//
+// Either preset or prefix must be set. Arguments of prefix can contain
+// :dir, which is replaced with the site directory, and :tmp, which is
+// replaced with the temporary directory.
+type Config struct {
+	// Preset is "bwrap" (Bubblewrap on Linux) or "sandbox-exec" (macOS).
+	Preset string `yaml:"preset"`
+	// Prefix is the command running the external command given after
+	// its arguments, e.g. [bwrap, --ro-bind, /, /, --unshare-net, --].
+	Prefix []string `yaml:"prefix"`
+	// Network allows network access in presets, which is needed, for
+	// example, for PDF commands loading pages from the local server.
+	Network bool `yaml:"network"`
+}
+
+var presets = map[string]func(network bool) []string{
+	"bwrap": func(network bool) []string {
+		args := []string{"bwrap", "--ro-bind", "/", "/", "--dev", "/dev", "--proc", "/proc",
+			"--bind", ":dir", ":dir", "--bind", ":tmp", ":tmp", "--die-with-parent"}
+		if !network {
+			args = append(args, "--unshare-net")
+		}
+		return append(args, "--")
+	},
+	"sandbox-exec": func(network bool) []string {
+		profile := `(version 1)(allow default)(deny file-write*)` +
+			`(allow file-write* (subpath ":dir") (subpath ":tmp") (literal "/dev/null"))`
+		if !network {
+			profile += `(deny network*)`
+		}
+		return []string{"sandbox-exec", "-p", profile}
+	},
+}
+
+var (
+	prefixMu  sync.Mutex
+	prefix    []string
+	netPrefix []string // for commands which need network access
+)
+
+// Configure sets the sandbox for external commands of the site
+// in the directory. If c is nil, commands run without a sandbox.
+func Configure(c *Config, dir string) error {
+	var p, np []string
+	if c != nil {
+		switch {
+		case c.Preset != "" && len(c.Prefix) > 0:
+			return errors.New("sandbox: both preset and prefix are set")
+		case c.Preset != "":
+			preset, ok := presets[c.Preset]
+			if !ok {
+				return fmt.Errorf("sandbox: unknown preset %q", c.Preset)
+			}
+			p = preset(c.Network)
+			np = preset(true)
+		case len(c.Prefix) > 0:
+			p = c.Prefix
+			np = c.Prefix
+		default:
+			return errors.New("sandbox: preset or prefix must be set")
+		}
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		tmp, err := filepath.EvalSymlinks(os.TempDir())
+		if err != nil {
+			tmp = os.TempDir()
+		}
+		p = expand(p, absDir, tmp)
+		np = expand(np, absDir, tmp)
+	}
+	prefixMu.Lock()
+	prefix, netPrefix = p, np
+	prefixMu.Unlock()
+	return nil
+}
+
+// expand returns arguments with :dir and :tmp replaced.
+func expand(args []string, dir, tmp string) []string {
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	out := make([]string, len(args))
+	for i, a := range args {
+		r := strings.NewReplacer(":dir", dir, ":tmp", tmp)
+		if strings.HasPrefix(a, "(") {
+			// sandbox-exec profile with quoted paths.
+			r = strings.NewReplacer(":dir", quote.Replace(dir), ":tmp", quote.Replace(tmp))
+		}
+		out[i] = r.Replace(a)
+	}
+	return out
+}
+
+// Command returns the command to run the external program with
+// the given arguments inside the configured sandbox, or an error
+// in safe mode.
+func Command(name string, args ...string) (*exec.Cmd, error) {
+	if err := Check(name); err != nil {
+		return nil, err
+	}
+	prefixMu.Lock()
+	p := prefix
+	prefixMu.Unlock()
+	return command(p, name, args)
+}
+
+// NetworkCommand is like Command, but the sandbox configured with
+// a preset allows network access, which is needed, for example, to
+// clone repositories or send email. A sandbox configured with prefix
+// is used as is.
+func NetworkCommand(name string, args ...string) (*exec.Cmd, error) {
+	if err := Check(name); err != nil {
+		return nil, err
+	}
+	prefixMu.Lock()
+	p := netPrefix
+	prefixMu.Unlock()
+	return command(p, name, args)
+}
+
+// command returns the command to run the external program
+// with the sandbox prefix p.
+func command(p []string, name string, args []string) (*exec.Cmd, error) {
+	if len(p) == 0 {
+		return exec.Command(name, args...), nil
+	}
+	full := make([]string, 0, len(p)+1+len(args))
+	full = append(full, p[1:]...)
+	full = append(full, name)
+	full = append(full, args...)
+	return exec.Command(p[0], full...), nil
+}
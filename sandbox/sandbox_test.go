@@ -0,0 +1,58 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sandbox
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	defer Configure(nil, "")
+	if err := Configure(&Config{Prefix: []string{"wrap", "--bind", ":dir", "--"}}, "/site"); err != nil {
+		t.Fatal(err)
+	}
+	cmd, err := Command("fold", "-w", "60")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := strings.Join(cmd.Args, " "); s != "wrap --bind /site -- fold -w 60" {
+		t.Errorf("got %q", s)
+	}
+	if err := Configure(&Config{Preset: "bwrap", Network: true}, "/site"); err != nil {
+		t.Fatal(err)
+	}
+	cmd, _ = Command("fold")
+	if s := strings.Join(cmd.Args, " "); strings.Contains(s, "--unshare-net") || !strings.Contains(s, "--bind /site /site") {
+		t.Errorf("bad bwrap command %q", s)
+	}
+	if err := Configure(&Config{Preset: "bwrap"}, "/site"); err != nil {
+		t.Fatal(err)
+	}
+	cmd, _ = Command("fold")
+	if s := strings.Join(cmd.Args, " "); !strings.Contains(s, "--unshare-net") {
+		t.Errorf("bwrap command %q allows network", s)
+	}
+	cmd, _ = NetworkCommand("git")
+	if s := strings.Join(cmd.Args, " "); strings.Contains(s, "--unshare-net") || !strings.HasSuffix(s, "-- git") {
+		t.Errorf("bad bwrap network command %q", s)
+	}
+	if err := Configure(&Config{Preset: "nope"}, "/site"); err == nil {
+		t.Errorf("expected error for unknown preset")
+	}
+	SetSafeMode(true)
+	defer SetSafeMode(false)
+	if _, err := Command("fold"); !errors.Is(err, ErrSafeMode) {
+		t.Errorf("expected ErrSafeMode, got %v", err)
+	}
+}
+
+func TestExpandProfile(t *testing.T) {
+	out := expand([]string{`(allow file-write* (subpath ":dir"))`}, `/a "b"`, "/tmp")
+	if out[0] != `(allow file-write* (subpath "/a \"b\""))` {
+		t.Errorf("got %q", out[0])
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"math"
 	"sort"
 	"strings"
+	"sync"
 	"unicode/utf16"
 
 	"github.com/dchest/stemmer/porter2"
@@ -14,7 +15,10 @@ import (
 	"github.com/dchest/kkr/search/indexer/tokenizer"
 )
 
+// Index is a search index. Documents can be added concurrently.
 type Index struct {
+	mu sync.Mutex // protects Docs and wordsToDoc while adding
+
 	Docs       []*Document              `json:"docs"`
 	Words      map[string][]interface{} `json:"words"`
 	wordsToDoc map[string]map[*Document]int
@@ -61,8 +65,13 @@ func (n *Index) WriteJSON(w io.Writer) error {
 	// Make JSON smaller:
 	// Sort docs by most frequent, so that smaller
 	// doc ids are taken for most frequent ones.
+	// Docs with the same number of words are sorted by URL,
+	// so that the output doesn't depend on the order of adding.
 	sort.SliceStable(n.Docs, func(i, j int) bool {
-		return n.Docs[i].numWords > n.Docs[j].numWords
+		if n.Docs[i].numWords != n.Docs[j].numWords {
+			return n.Docs[i].numWords > n.Docs[j].numWords
+		}
+		return n.Docs[i].URL < n.Docs[j].URL
 	})
 
 	// Add indexes to docs
@@ -101,6 +110,8 @@ func (n *Index) WriteJSON(w io.Writer) error {
 }
 
 func (n *Index) addWord(word string, doc *Document, weight float64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
 	m := n.wordsToDoc[word]
 	if m == nil {
 		m = make(map[*Document]int)
@@ -111,6 +122,8 @@ func (n *Index) addWord(word string, doc *Document, weight float64) {
 
 func (n *Index) newDocument(url, title string) *Document {
 	doc := &Document{URL: url, Title: title}
+	n.mu.Lock()
+	defer n.mu.Unlock()
 	n.Docs = append(n.Docs, doc)
 	return doc
 }
@@ -0,0 +1,179 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package shortcodes implements parsing of shortcodes in page content:
+//
+//	{{< name arg "quoted arg" key=value key="quoted value" >}}
+//
+// Shortcodes are extracted from content before markup processing,
+// replaced with placeholders, and restored after it.
+package shortcodes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	leftDelim  = "{{<"
+	rightDelim = ">}}"
+)
+
+type Shortcode struct {
+	Name  string
+	Args  []string          // positional arguments
+	Named map[string]string // key=value arguments
+}
+
+// Arg returns the positional argument or an empty string
+// if there's no argument with this index.
+func (s *Shortcode) Arg(i int) string {
+	if i < 0 || i >= len(s.Args) {
+		return ""
+	}
+	return s.Args[i]
+}
+
+// Get returns the named argument or an empty string.
+func (s *Shortcode) Get(key string) string {
+	return s.Named[key]
+}
+
+func isNameChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '_' || c == '/'
+}
+
+// readValue reads a quoted or unquoted value from the beginning of s,
+// returning the value and the rest of the string.
+func readValue(s string) (value, rest string, err error) {
+	if strings.HasPrefix(s, `"`) {
+		i := 1
+		for ; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+			} else if s[i] == '"' {
+				break
+			}
+		}
+		if i >= len(s) {
+			return "", "", errors.New("unterminated quoted string")
+		}
+		value, err = strconv.Unquote(s[:i+1])
+		return value, s[i+1:], err
+	}
+	i := strings.IndexAny(s, " \t\r\n")
+	if i < 0 {
+		i = len(s)
+	}
+	return s[:i], s[i:], nil
+}
+
+// Parse parses shortcode source between delimiters.
+func Parse(src string) (*Shortcode, error) {
+	s := strings.TrimSpace(src)
+	i := 0
+	for i < len(s) && isNameChar(s[i]) {
+		i++
+	}
+	if i == 0 {
+		return nil, fmt.Errorf("shortcode %q: missing name", src)
+	}
+	sc := &Shortcode{Name: s[:i], Named: make(map[string]string)}
+	s = s[i:]
+	for {
+		s = strings.TrimLeft(s, " \t\r\n")
+		if s == "" {
+			break
+		}
+		if !strings.HasPrefix(s, `"`) {
+			// Named argument?
+			j := 0
+			for j < len(s) && isNameChar(s[j]) {
+				j++
+			}
+			if j > 0 && j < len(s) && s[j] == '=' {
+				key := s[:j]
+				value, rest, err := readValue(s[j+1:])
+				if err != nil {
+					return nil, fmt.Errorf("shortcode %s: %w", sc.Name, err)
+				}
+				sc.Named[key] = value
+				s = rest
+				continue
+			}
+		}
+		value, rest, err := readValue(s)
+		if err != nil {
+			return nil, fmt.Errorf("shortcode %s: %w", sc.Name, err)
+		}
+		sc.Args = append(sc.Args, value)
+		s = rest
+	}
+	return sc, nil
+}
+
+func placeholder(i int) string {
+	return fmt.Sprintf("KKRSHORTCODE%dX", i)
+}
+
+var placeholderRe = regexp.MustCompile(`(<p>)?KKRSHORTCODE(\d+)X(</p>)?`)
+
+// Extract replaces shortcodes in content with placeholders, which are
+// not changed by markup processing, and returns their sources.
+func Extract(content []byte) (out []byte, sources []string, err error) {
+	if !bytes.Contains(content, []byte(leftDelim)) {
+		return content, nil, nil
+	}
+	var b bytes.Buffer
+	for {
+		i := bytes.Index(content, []byte(leftDelim))
+		if i < 0 {
+			break
+		}
+		j := bytes.Index(content[i:], []byte(rightDelim))
+		if j < 0 {
+			return nil, nil, errors.New("unclosed shortcode")
+		}
+		src := string(content[i+len(leftDelim) : i+j])
+		if _, err := Parse(src); err != nil {
+			return nil, nil, err
+		}
+		b.Write(content[:i])
+		b.WriteString(placeholder(len(sources)))
+		sources = append(sources, src)
+		content = content[i+j+len(rightDelim):]
+	}
+	b.Write(content)
+	return b.Bytes(), sources, nil
+}
+
+// Restore replaces placeholders with results of f called with
+// the shortcode source. Paragraphs that consist only of a placeholder
+// are replaced entirely, so that shortcodes can produce blocks.
+func Restore(content []byte, sources []string, f func(src string) string) []byte {
+	if len(sources) == 0 {
+		return content
+	}
+	return placeholderRe.ReplaceAllFunc(content, func(m []byte) []byte {
+		sm := placeholderRe.FindSubmatch(m)
+		i, err := strconv.Atoi(string(sm[2]))
+		if err != nil || i >= len(sources) {
+			return m
+		}
+		out := f(sources[i])
+		// Keep unbalanced paragraph tags.
+		if len(sm[1]) > 0 && len(sm[3]) == 0 {
+			out = string(sm[1]) + out
+		}
+		if len(sm[3]) > 0 && len(sm[1]) == 0 {
+			out += string(sm[3])
+		}
+		return []byte(out)
+	})
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shortcodes
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	sc, err := Parse(` figure /img/a.png "A \"quoted\" caption" class=wide alt="Some text" `)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Shortcode{
+		Name:  "figure",
+		Args:  []string{"/img/a.png", `A "quoted" caption`},
+		Named: map[string]string{"class": "wide", "alt": "Some text"},
+	}
+	if !reflect.DeepEqual(sc, want) {
+		t.Errorf("got %+v, want %+v", sc, want)
+	}
+	if sc.Arg(2) != "" || sc.Get("missing") != "" {
+		t.Errorf("missing arguments must be empty")
+	}
+	for _, s := range []string{"", ` "name"`, `x "unterminated`} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestExtractRestore(t *testing.T) {
+	in := "Text {{< a 1 >}} inline.\n\n{{< b >}}\n"
+	out, sources, err := Extract([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sources, []string{" a 1 ", " b "}) {
+		t.Fatalf("sources: %q", sources)
+	}
+	// Simulate markup processing.
+	html := "<p>" + strings.Replace(string(out), "\n\n", "</p>\n<p>", 1)
+	html = strings.TrimSuffix(html, "\n") + "</p>\n"
+	got := string(Restore([]byte(html), sources, func(src string) string {
+		return "[" + strings.TrimSpace(src) + "]"
+	}))
+	want := "<p>Text [a 1] inline.</p>\n[b]\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, _, err := Extract([]byte("{{< a ")); err == nil {
+		t.Errorf("expected error for unclosed shortcode")
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// site.yml -> access:
+//
+// Restricts access to the site served by kkr serve. If both
+// credentials and allowed addresses are set, both are checked.
+type AccessConfig struct {
+	User     string   `yaml:"user"`
+	Password string   `yaml:"password"`
+	Allow    []string `yaml:"allow"` // IP addresses or networks, e.g. 192.168.1.0/24
+}
+
+func parseAllowList(list []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(list))
+	for _, v := range list {
+		if !strings.Contains(v, "/") {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return nil, fmt.Errorf("access: bad IP address %q", v)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("access: %w", err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+func isAllowedIP(nets []*net.IPNet, remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalStrings(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// accessHandler returns a handler that checks requests according
+// to site.yml -> access: before passing them to h.
+func accessHandler(c *AccessConfig, h http.Handler) (http.Handler, error) {
+	if c == nil {
+		return h, nil
+	}
+	if (c.User == "") != (c.Password == "") {
+		return nil, fmt.Errorf("access: both user and password must be set")
+	}
+	nets, err := parseAllowList(c.Allow)
+	if err != nil {
+		return nil, err
+	}
+	if c.User != "" {
+		log.Printf("* Requiring password for user %s.", c.User)
+	}
+	if len(nets) > 0 {
+		log.Printf("* Allowing access from %s.", strings.Join(c.Allow, ", "))
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(nets) > 0 && !isAllowedIP(nets, r.RemoteAddr) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if c.User != "" {
+			user, password, ok := r.BasicAuth()
+			// Evaluate both comparisons to not leak which one failed.
+			userOK := equalStrings(user, c.User)
+			passwordOK := equalStrings(password, c.Password)
+			if !ok || !userOK || !passwordOK {
+				w.Header().Set("WWW-Authenticate", `Basic realm="kkr", charset="UTF-8"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	}), nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"github.com/dchest/kkr/analytics"
+)
+
+// LoadAnalytics prepares analytics snippet configured in
+// site.yml -> analytics: and CSP sources required by it.
+// Analytics is disabled in dev mode.
+func (s *Site) LoadAnalytics() error {
+	s.analyticsSnippet = ""
+	s.analyticsSources = nil
+	c := s.Config.Analytics
+	if c == nil || s.devMode {
+		return nil
+	}
+	snippet, err := c.Snippet()
+	if err != nil {
+		return err
+	}
+	sources, err := c.Sources()
+	if err != nil {
+		return err
+	}
+	s.analyticsSnippet = snippet
+	s.analyticsSources = sources
+	return nil
+}
+
+// injectAnalytics adds analytics snippet to the HTML page,
+// unless the page has `analytics: false` meta.
+func (s *Site) injectAnalytics(meta map[string]interface{}, data []byte) []byte {
+	if s.analyticsSnippet == "" {
+		return data
+	}
+	if enabled, ok := meta["analytics"].(bool); ok && !enabled {
+		return data
+	}
+	return analytics.Inject(data, s.analyticsSnippet)
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dchest/kkr/utils"
+)
+
+const DefaultArchiveLayout = "archive"
+
+// site.yml -> archives:
+type ArchivesConfig struct {
+	YearPermalink  string `yaml:"year_permalink"`  // e.g. /blog/:year/
+	MonthPermalink string `yaml:"month_permalink"` // e.g. /blog/:year/:month/
+	Layout         string `yaml:"layout"`
+}
+
+type ArchivePage struct {
+	Page
+	Year         int
+	Month        time.Month // zero for yearly archive
+	Filename     string
+	ArchivePosts Posts
+}
+
+func (p *ArchivePage) Meta() map[string]interface{} { return p.meta }
+func (p *ArchivePage) Content() string              { return p.content }
+func (p *ArchivePage) FileInfo() os.FileInfo        { return nil }
+func (p *ArchivePage) URL() string                  { return p.url }
+
+func NewArchivePage(year int, month time.Month, permalink string, posts Posts) *ArchivePage {
+	a := &ArchivePage{Year: year, Month: month, ArchivePosts: posts}
+	a.url = utils.CleanPermalink(permalink)
+	title := fmt.Sprintf("%d", year)
+	if month != 0 {
+		title = fmt.Sprintf("%s %d", month, year)
+	}
+	a.content = title
+	a.meta = map[string]interface{}{
+		"title": title,
+		"year":  year,
+		"month": int(month),
+		"posts": posts,
+		"url":   a.url,
+	}
+	a.Filename = filepath.FromSlash(utils.AddIndexIfNeeded(permalink))
+	return a
+}
+
+func expandArchivePermalink(permalink string, year int, month time.Month) string {
+	out := strings.Replace(permalink, ":year", fmt.Sprintf("%04d", year), -1)
+	return strings.Replace(out, ":month", fmt.Sprintf("%02d", month), -1)
+}
+
+// YearArchiveURL returns URL of the archive page for the year.
+func (c Config) YearArchiveURL(year int) (string, error) {
+	if c.Archives == nil || c.Archives.YearPermalink == "" {
+		return "", errors.New("No archives.year_permalink in site.yml")
+	}
+	return utils.CleanPermalink(expandArchivePermalink(c.Archives.YearPermalink, year, 0)), nil
+}
+
+// MonthArchiveURL returns URL of the archive page for the month.
+func (c Config) MonthArchiveURL(year, month int) (string, error) {
+	if c.Archives == nil || c.Archives.MonthPermalink == "" {
+		return "", errors.New("No archives.month_permalink in site.yml")
+	}
+	return utils.CleanPermalink(expandArchivePermalink(c.Archives.MonthPermalink, year, time.Month(month))), nil
+}
+
+func (s *Site) RenderArchives() error {
+	c := s.Config.Archives
+	if c == nil {
+		return nil
+	}
+	log.Printf("* Rendering archives.")
+	var pages []*ArchivePage
+	if c.YearPermalink != "" {
+		for _, v := range s.Config.Posts.ByYear() {
+			pages = append(pages, NewArchivePage(v.Year, 0,
+				expandArchivePermalink(c.YearPermalink, v.Year, 0), v.Posts))
+		}
+	}
+	if c.MonthPermalink != "" {
+		for _, v := range s.Config.Posts.ByMonth() {
+			pages = append(pages, NewArchivePage(v.Year, v.Month,
+				expandArchivePermalink(c.MonthPermalink, v.Year, v.Month), v.Posts))
+		}
+	}
+	pool := utils.NewPool()
+	for _, v := range pages {
+		p := v
+		if !pool.Add(func() error { return s.RenderArchive(p) }) {
+			break
+		}
+	}
+	return pool.Wait()
+}
+
+func (s *Site) RenderArchive(p *ArchivePage) error {
+	layout := s.Config.Archives.Layout
+	if layout == "" {
+		layout = DefaultArchiveLayout
+	}
+	s.setBreadcrumbs(p.meta)
+	data, err := s.Layouts.RenderPage(p, layout)
+	if err != nil {
+		return err
+	}
+	log.Printf("A > %s\n", filepath.Join(OutDirName, p.Filename))
+	b, err := s.transformHTML(p.meta, p.Filename, []byte(data))
+	if err != nil {
+		return err
+	}
+	b, err = s.PageFilters.ApplyFilter(filepath.Ext(p.Filename), b)
+	if err != nil {
+		return err
+	}
+	if s.sitemap != nil && p.InSitemap() {
+		if err := s.sitemap.Add(p.SitemapEntry()); err != nil {
+			return err
+		}
+	}
+	return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, p.Filename), b)
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"html"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/asciinema"
+	"github.com/dchest/kkr/utils"
+)
+
+const DefaultAsciinemaURL = "/casts/"
+
+// site.yml -> asciinema:
+type AsciinemaConfig struct {
+	URL string   `yaml:"url"` // where to put cast files, "/casts/" by default
+	SVG []string `yaml:"svg"` // command converting cast from stdin to animated SVG, e.g. [svg-term]
+}
+
+// asciinemaEmbed copies the cast file (relative to the site directory,
+// for example, next to the post) to the output and returns a player
+// embed for it, or an animated SVG image if svg command is configured.
+func (s *Site) asciinemaEmbed(name string) (string, error) {
+	clean := path.Clean("/" + filepath.ToSlash(name))[1:]
+	if clean == "" || strings.HasPrefix(clean, OutDirName+"/") {
+		return "", fmt.Errorf("asciinema: bad file name %q", name)
+	}
+	filename := filepath.Join(s.BaseDir, filepath.FromSlash(clean))
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("asciinema: %w", err)
+	}
+	cast, err := asciinema.Parse(b)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	baseURL := DefaultAsciinemaURL
+	var svgCommand []string
+	if c := s.Config.Asciinema; c != nil {
+		if c.URL != "" {
+			baseURL = c.URL
+		}
+		svgCommand = c.SVG
+	}
+	castURL := path.Join("/", baseURL, path.Base(clean))
+	if len(svgCommand) == 0 {
+		outFile := filepath.Join(s.BaseDir, OutDirName, filepath.FromSlash(castURL))
+		if err := s.fileWriter.CopyFile(outFile, filename); err != nil {
+			return "", err
+		}
+		return cast.PlayerHTML(castURL), nil
+	}
+	svg, err := s.runCached("asciinema", svgCommand, b, ".svg", "asciinema "+name)
+	if err != nil {
+		return "", err
+	}
+	svgURL := utils.ReplaceFileExt(castURL, ".svg")
+	outFile := filepath.Join(s.BaseDir, OutDirName, filepath.FromSlash(svgURL))
+	if err := s.fileWriter.WriteFile(outFile, svg); err != nil {
+		return "", err
+	}
+	alt := cast.Title
+	if alt == "" {
+		alt = "Terminal session"
+	}
+	return fmt.Sprintf(`<img class="%s" src="%s" alt="%s">`, asciinema.Class,
+		html.EscapeString(svgURL), html.EscapeString(alt)), nil
+}
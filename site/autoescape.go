@@ -0,0 +1,97 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"html/template"
+	"reflect"
+
+	"github.com/dchest/kkr/layouts"
+	"github.com/dchest/kkr/markup"
+)
+
+// htmlFuncs are names of layout functions that return HTML
+// produced by the site itself, which must not be escaped.
+var htmlFuncs = []string{
+	"include",
+	"partial",
+	"shortcode",
+	"code",
+	"jsonld",
+	"table",
+	"geomap",
+	"asciinema",
+	"video",
+	"mastodon",
+	"tweet",
+	"youtube",
+	"vimeo",
+	"toc",
+	"nav",
+	"image",
+	"content",
+	"inline",
+	markup.WikilinkFunc,
+}
+
+// cssFuncs are names of layout functions that return stylesheets
+// produced by the site itself, which are output inside <style>.
+var cssFuncs = []string{
+	"highlightcss",
+}
+
+var (
+	htmlType = reflect.TypeOf(template.HTML(""))
+	cssType  = reflect.TypeOf(template.CSS(""))
+)
+
+// safeFunc wraps the function returning a string as the first
+// result into the function returning the safe type typ,
+// such as template.HTML.
+func safeFunc(f interface{}, typ reflect.Type) interface{} {
+	v := reflect.ValueOf(f)
+	t := v.Type()
+	in := make([]reflect.Type, t.NumIn())
+	for i := range in {
+		in[i] = t.In(i)
+	}
+	out := make([]reflect.Type, t.NumOut())
+	for i := range out {
+		out[i] = t.Out(i)
+	}
+	out[0] = typ
+	return reflect.MakeFunc(reflect.FuncOf(in, out, t.IsVariadic()), func(args []reflect.Value) []reflect.Value {
+		var res []reflect.Value
+		if t.IsVariadic() {
+			res = v.CallSlice(args)
+		} else {
+			res = v.Call(args)
+		}
+		res[0] = res[0].Convert(typ)
+		return res
+	}).Interface()
+}
+
+// makeHTMLFuncs returns a copy of layout functions for templates with
+// autoescape, in which functions from htmlFuncs return template.HTML
+// and functions from cssFuncs return template.CSS. Templates without
+// autoescape get strings, so that they can be passed to other functions.
+func makeHTMLFuncs(funcs layouts.FuncMap) layouts.FuncMap {
+	m := make(layouts.FuncMap, len(funcs))
+	for k, v := range funcs {
+		m[k] = v
+	}
+	for _, name := range htmlFuncs {
+		if f, ok := m[name]; ok {
+			m[name] = safeFunc(f, htmlType)
+		}
+	}
+	for _, name := range cssFuncs {
+		if f, ok := m[name]; ok {
+			m[name] = safeFunc(f, cssType)
+		}
+	}
+	return m
+}
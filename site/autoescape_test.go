@@ -0,0 +1,34 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dchest/kkr/highlight"
+	"github.com/dchest/kkr/layouts"
+)
+
+func TestHighlightCSSAutoescape(t *testing.T) {
+	css, err := (&highlight.Options{}).CSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	funcs := makeHTMLFuncs(layouts.FuncMap{
+		"highlightcss": func() (string, error) { return css, nil },
+	})
+	tpl, err := layouts.Parse("test", "<style>{{ highlightcss }}</style>", funcs, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := tpl.Execute(&b, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := "<style>" + css + "</style>"; b.String() != want {
+		t.Errorf("got %s, want %s", b.String(), want)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/dchest/kkr/headings"
+	"github.com/dchest/kkr/layouts"
+	"github.com/dchest/kkr/links"
+	"github.com/dchest/kkr/utils"
+)
+
+// Backlink is a reference to the page that links to another page.
+type Backlink struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+// backlinksGraph maps target URLs to source URLs and their titles.
+type backlinksGraph map[string]map[string]string
+
+func (g backlinksGraph) add(target, source, title string) {
+	m, ok := g[target]
+	if !ok {
+		m = make(map[string]string)
+		g[target] = m
+	}
+	m[source] = title
+}
+
+// targets returns sorted URLs of pages the source links to.
+func (g backlinksGraph) targets(source string) []string {
+	var out []string
+	for target, m := range g {
+		if _, ok := m[source]; ok {
+			out = append(out, target)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// CollectBacklinks renders content of posts and pages into memory,
+// without layouts, and collects links between them and their headings
+// before anything is rendered, so that every page gets complete
+// backlinks and tables of contents of other pages.
+// Links from generated pages, such as tag indexes, are not collected.
+func (s *Site) CollectBacklinks() error {
+	log.Printf("* Collecting backlinks.")
+	s.backlinks = make(backlinksGraph)
+	s.headingsMu.Lock()
+	s.pageHeadings = make(map[string][]*headings.Heading)
+	s.headingsMu.Unlock()
+	s.collectingLinks = true
+	defer func() { s.collectingLinks = false }()
+	pool := utils.NewPool()
+	for _, v := range s.Config.Posts {
+		post := v
+		if !pool.Add(func() error {
+			s.setBreadcrumbs(post.meta)
+			_, err := s.Layouts.RenderContent(&post.Page)
+			return err
+		}) {
+			break
+		}
+	}
+	inDir := filepath.Join(s.BaseDir, PagesDirName)
+	err := filepath.Walk(inDir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() || s.isIgnoredFile(fi.Name()) || fi.Name() == DefaultsFileName {
+			return nil
+		}
+		relname, err := filepath.Rel(inDir, path)
+		if err != nil {
+			return err
+		}
+		if !pool.Add(func() error {
+			_, _, err := s.renderPageContent(inDir, relname)
+			return err
+		}) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if perr := pool.Wait(); perr != nil {
+		return perr
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// renderPageContent loads the page and renders its content without
+// layouts. It returns nil page for files that are not pages.
+func (s *Site) renderPageContent(pagesDir, relname string) (*Page, string, error) {
+	p, err := LoadPage(pagesDir, relname)
+	if err != nil {
+		if IsNotPage(err) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	s.preparePageMeta(p, nil)
+	content, err := s.Layouts.RenderContent(p)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", relname, err)
+	}
+	return p, content, nil
+}
+
+// pageLinksChanged reports whether the page links to a different set
+// of pages or has different headings than when backlinks and headings
+// were collected.
+func (s *Site) pageLinksChanged(pagesDir, relname string) (bool, error) {
+	p, content, err := s.renderPageContent(pagesDir, relname)
+	if err != nil || p == nil {
+		return false, err
+	}
+	hs, err := headings.Find(content, s.headingLevels())
+	if err != nil {
+		return false, err
+	}
+	s.headingsMu.Lock()
+	old := s.pageHeadings[pageKey(p.URL())]
+	s.headingsMu.Unlock()
+	if !reflect.DeepEqual(hs, old) {
+		return true, nil
+	}
+	targets, err := s.pageLinks(p, content)
+	if err != nil {
+		return false, err
+	}
+	sort.Strings(targets)
+	s.backlinksMu.Lock()
+	defer s.backlinksMu.Unlock()
+	return !reflect.DeepEqual(targets, s.backlinks.targets(pageKey(p.URL()))), nil
+}
+
+// pageLinks returns URLs of other pages of the site the page content
+// links to, or nothing if the page has `backlinks: false` meta (useful
+// for lists of posts).
+func (s *Site) pageLinks(p layouts.PageContext, content string) ([]string, error) {
+	if enabled, ok := p.Meta()["backlinks"].(bool); ok && !enabled {
+		return nil, nil
+	}
+	ownHost := ""
+	if u, err := url.Parse(s.Config.URL); err == nil {
+		ownHost = u.Hostname()
+	}
+	source := pageKey(p.URL())
+	targets, err := links.Internal([]byte(content), source, ownHost)
+	if err != nil {
+		return nil, err
+	}
+	out := targets[:0]
+	seen := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		if target != source && !seen[target] {
+			seen[target] = true
+			out = append(out, target)
+		}
+	}
+	return out, nil
+}
+
+// collectLinks adds links from the page content to backlinks
+// while they are being collected.
+func (s *Site) collectLinks(p layouts.PageContext, content string) error {
+	if !s.collectingLinks {
+		return nil
+	}
+	targets, err := s.pageLinks(p, content)
+	if err != nil {
+		return err
+	}
+	title, _ := p.Meta()["title"].(string)
+	s.backlinksMu.Lock()
+	defer s.backlinksMu.Unlock()
+	for _, target := range targets {
+		s.backlinks.add(target, pageKey(p.URL()), title)
+	}
+	return nil
+}
+
+// pageBacklinks returns pages linking to the page with the given URL,
+// sorted by title.
+func (s *Site) pageBacklinks(url string) []Backlink {
+	url = pageKey(url)
+	s.backlinksMu.Lock()
+	defer s.backlinksMu.Unlock()
+	out := make([]Backlink, 0, len(s.backlinks[url]))
+	for source, title := range s.backlinks[url] {
+		out = append(out, Backlink{URL: source, Title: title})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Title != out[j].Title {
+			return out[i].Title < out[j].Title
+		}
+		return out[i].URL < out[j].URL
+	})
+	return out
+}
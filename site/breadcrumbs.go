@@ -0,0 +1,102 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/metafile"
+	"github.com/dchest/kkr/utils"
+)
+
+// LoadPageIndex loads titles of pages, including those of docs versions,
+// by their URLs for breadcrumbs, references to pages by their IDs, and
+// the navigation tree.
+// Only front matter is read.
+func (s *Site) LoadPageIndex() error {
+	s.pageTitles = make(map[string]string)
+	s.navPages = make(map[string]*navPage)
+	s.refs = make(map[string]*pageRef)
+	h := sha256.New()
+	inDir := filepath.Join(s.BaseDir, PagesDirName)
+	err := filepath.Walk(inDir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() || s.isIgnoredFile(fi.Name()) {
+			return nil
+		}
+		relname, err := filepath.Rel(inDir, path)
+		if err != nil {
+			return err
+		}
+		f, err := metafile.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if !f.HasMeta() {
+			return nil
+		}
+		meta := f.Meta()
+		fmt.Fprintf(h, "%s\x00%v\x00", relname, meta)
+		title, _ := meta["title"].(string)
+		outName := pageOutName(relname, meta)
+		url := utils.CleanPermalink(filepath.ToSlash(outName))
+		s.pageTitles[pageKey(url)] = title
+		s.addPageRefs(filepath.ToSlash(relname), url, title)
+		s.addNavPage(url, outName, meta)
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := s.indexVersionPages(); err != nil {
+		return err
+	}
+	s.linkNavPages()
+	fmt.Fprintf(h, "%v", s.pageTitles)
+	s.pageIndexHash = h.Sum(nil)
+	return nil
+}
+
+// breadcrumbs returns a trail of references to the pages in sections
+// containing the page URL, from the root to the page itself. Sections
+// without index pages are skipped.
+func (s *Site) breadcrumbs(url, title string) []map[string]interface{} {
+	var crumbs []map[string]interface{}
+	add := func(url, title string) {
+		crumbs = append(crumbs, map[string]interface{}{"url": url, "title": title})
+	}
+	rootTitle := s.pageTitles["/"]
+	if rootTitle == "" {
+		rootTitle = s.Config.Name
+	}
+	add("/", rootTitle)
+	if pageKey(url) == "/" {
+		return crumbs
+	}
+	parts := strings.Split(strings.Trim(url, "/"), "/")
+	prefix := "/"
+	for _, part := range parts[:len(parts)-1] {
+		prefix += part + "/"
+		if t, ok := s.pageTitles[prefix]; ok {
+			add(prefix, t)
+		}
+	}
+	add(url, title)
+	return crumbs
+}
+
+// setBreadcrumbs sets `breadcrumbs` meta of the page.
+func (s *Site) setBreadcrumbs(meta map[string]interface{}) {
+	url, _ := meta["url"].(string)
+	title, _ := meta["title"].(string)
+	meta["breadcrumbs"] = s.breadcrumbs(url, title)
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/humanize"
+	"github.com/dchest/kkr/links"
+	"github.com/dchest/kkr/utils"
+)
+
+// site.yml -> budgets:
+//
+// Budgets are maximum sizes of output files, such as "100 KB", which
+// are checked after the build. Sizes are uncompressed.
+type BudgetsConfig struct {
+	HTML    string   `yaml:"html"`    // HTML file
+	CSS     string   `yaml:"css"`     // stylesheet
+	JS      string   `yaml:"js"`      // script
+	Image   string   `yaml:"image"`   // image
+	Page    string   `yaml:"page"`    // HTML page with stylesheets, scripts and images it loads from the site
+	Exclude []string `yaml:"exclude"` // URL patterns of files that are not checked, e.g. /downloads/**
+	Fail    bool     `yaml:"fail"`    // fail the build instead of warning
+}
+
+var (
+	budgetCSSExtensions   = []string{".css"}
+	budgetJSExtensions    = []string{".js", ".mjs"}
+	budgetImageExtensions = []string{".jpg", ".jpeg", ".png", ".gif", ".webp", ".avif", ".svg"}
+)
+
+type budgetLimits struct {
+	html, css, js, image, page int64
+}
+
+func (c *BudgetsConfig) limits() (l budgetLimits, err error) {
+	for _, v := range []struct {
+		s string
+		n *int64
+	}{
+		{c.HTML, &l.html},
+		{c.CSS, &l.css},
+		{c.JS, &l.js},
+		{c.Image, &l.image},
+		{c.Page, &l.page},
+	} {
+		if v.s == "" {
+			continue
+		}
+		if *v.n, err = humanize.ParseSize(v.s); err != nil {
+			return l, fmt.Errorf("budgets: %w", err)
+		}
+	}
+	return l, nil
+}
+
+// resourceFile returns the output file of the resource URL
+// loaded by a page, or an empty string if it's not on the site.
+func (s *Site) resourceFile(outDir, rawURL string) string {
+	p := ""
+	if st := s.Config.Static; st != nil && st.URL != "" && strings.HasPrefix(rawURL, st.URL) {
+		p = path.Join("/", st.Path, strings.TrimPrefix(rawURL, st.URL))
+	} else if u, err := url.Parse(rawURL); err == nil {
+		if u.Host == "" || strings.HasPrefix(s.Config.URL, u.Scheme+"://"+u.Host) {
+			p = u.Path
+		}
+	}
+	if p == "" {
+		return ""
+	}
+	return filepath.Join(outDir, filepath.FromSlash(path.Clean(p)))
+}
+
+// pageWeight returns the total size of the HTML page and
+// stylesheets, scripts and images it loads from the site.
+func (s *Site) pageWeight(outDir, filename, pageURL string, size int64) (int64, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return 0, err
+	}
+	resources, err := links.Resources(b, pageURL)
+	if err != nil {
+		return 0, err
+	}
+	seen := make(map[string]bool)
+	for _, r := range resources {
+		name := s.resourceFile(outDir, r)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if fi, err := os.Stat(name); err == nil && !fi.IsDir() {
+			size += fi.Size()
+		}
+	}
+	return size, nil
+}
+
+// CheckBudgets checks sizes of output files and pages against budgets
+// configured in site.yml, logging files that exceed them. It returns
+// an error if any budget is exceeded and `fail: true` is set.
+func (s *Site) CheckBudgets() error {
+	c := s.Config.Budgets
+	if c == nil {
+		return nil
+	}
+	limits, err := c.limits()
+	if err != nil {
+		return err
+	}
+	log.Printf("* Checking budgets.")
+	outDir := filepath.Join(s.BaseDir, OutDirName)
+	exceeded := 0
+	check := func(u, what string, size, limit int64) {
+		if limit > 0 && size > limit {
+			log.Printf("! Budget: %s: %s %s exceeds %s", u, what,
+				humanize.FileSize(size, ""), humanize.FileSize(limit, ""))
+			exceeded++
+		}
+	}
+	err = filepath.Walk(outDir, func(name string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		relname, err := filepath.Rel(outDir, name)
+		if err != nil {
+			return err
+		}
+		u := "/" + filepath.ToSlash(relname)
+		for _, pattern := range c.Exclude {
+			if matchPath(pattern, u) {
+				return nil
+			}
+		}
+		switch {
+		case utils.HasFileExt(name, HTMLExtensions):
+			check(u, "HTML", fi.Size(), limits.html)
+			if limits.page > 0 {
+				weight, err := s.pageWeight(outDir, name, u, fi.Size())
+				if err != nil {
+					return err
+				}
+				check(u, "page weight", weight, limits.page)
+			}
+		case utils.HasFileExt(name, budgetCSSExtensions):
+			check(u, "CSS", fi.Size(), limits.css)
+		case utils.HasFileExt(name, budgetJSExtensions):
+			check(u, "JS", fi.Size(), limits.js)
+		case utils.HasFileExt(name, budgetImageExtensions):
+			check(u, "image", fi.Size(), limits.image)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if exceeded > 0 && c.Fail {
+		return fmt.Errorf("%d budgets exceeded", exceeded)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/utils"
+)
+
+const DefaultCategoryIndexLayout = "category"
+
+// site.yml -> categoryindex:
+type CategoryIndexConfig struct {
+	Permalink string `yaml:"permalink"` // e.g. /blog/category/:lccategory/
+	Layout    string `yaml:"layout"`
+}
+
+type CategoryIndex struct {
+	Page
+	Category      string
+	Filename      string
+	CategoryPosts Posts
+}
+
+func (p *CategoryIndex) Meta() map[string]interface{} { return p.meta }
+func (p *CategoryIndex) Content() string              { return p.content }
+func (p *CategoryIndex) FileInfo() os.FileInfo        { return nil }
+func (p *CategoryIndex) URL() string                  { return p.url }
+
+func NewCategoryIndex(category, permalink string) *CategoryIndex {
+	c := new(CategoryIndex)
+	c.Category = category
+	c.url = utils.CleanPermalink(permalink)
+	c.content = category
+	c.meta = map[string]interface{}{
+		"title":    category,
+		"category": category,
+		"url":      c.url,
+	}
+	c.Filename = filepath.FromSlash(utils.AddIndexIfNeeded(permalink))
+	return c
+}
+
+// CategoryURL returns URL of the category index.
+func (c Config) CategoryURL(category string) (string, error) {
+	if c.CategoryIndex == nil {
+		return "", errors.New("No categoryindex in site.yml")
+	}
+	out := strings.Replace(c.CategoryIndex.Permalink, ":category", category, -1)
+	return strings.Replace(out, ":lccategory", strings.ToLower(category), -1), nil
+}
+
+func (s *Site) RenderCategoriesIndex() error {
+	log.Printf("* Rendering categories index")
+	pool := utils.NewPool()
+	for _, v := range s.Config.CategoryList {
+		category := v
+		if !pool.Add(func() error { return s.RenderCategory(category) }) {
+			break
+		}
+	}
+	return pool.Wait()
+}
+
+func (s *Site) RenderCategory(category string) error {
+	url, err := s.Config.CategoryURL(category)
+	if err != nil {
+		return fmt.Errorf("cannot generate category index %q: %w", category, err)
+	}
+	p := NewCategoryIndex(category, url)
+	p.CategoryPosts = s.Config.Categories[category]
+	layout := s.Config.CategoryIndex.Layout
+	if layout == "" {
+		layout = DefaultCategoryIndexLayout
+	}
+	s.setBreadcrumbs(p.meta)
+	data, err := s.Layouts.RenderPage(p, layout)
+	if err != nil {
+		return err
+	}
+	log.Printf("T > %s\n", filepath.Join(OutDirName, p.Filename))
+	b, err := s.transformHTML(p.meta, p.Filename, []byte(data))
+	if err != nil {
+		return err
+	}
+	b, err = s.PageFilters.ApplyFilter(filepath.Ext(p.Filename), b)
+	if err != nil {
+		return err
+	}
+	if s.sitemap != nil && p.InSitemap() {
+		if err := s.sitemap.Add(p.SitemapEntry()); err != nil {
+			return err
+		}
+	}
+	return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, p.Filename), b)
+}
@@ -0,0 +1,45 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/snippet"
+)
+
+// codeSnippet returns a code block with lines from the file, which
+// path is relative to the site directory. Range is optional,
+// for example "10-20", "10-" or "10".
+func (s *Site) codeSnippet(name string, lineRange ...string) (string, error) {
+	clean := path.Clean("/" + filepath.ToSlash(name))[1:]
+	if clean == "" || strings.HasPrefix(clean, OutDirName+"/") {
+		return "", fmt.Errorf("code: bad file name %q", name)
+	}
+	if len(lineRange) > 1 {
+		return "", fmt.Errorf("code %s: too many arguments", name)
+	}
+	spec := ""
+	if len(lineRange) == 1 {
+		spec = lineRange[0]
+	}
+	from, to, err := snippet.ParseRange(spec)
+	if err != nil {
+		return "", fmt.Errorf("code %s: %w", name, err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(s.BaseDir, filepath.FromSlash(clean)))
+	if err != nil {
+		return "", fmt.Errorf("code: %w", err)
+	}
+	code, err := snippet.Extract(string(b), from, to)
+	if err != nil {
+		return "", fmt.Errorf("code %s: %w", name, err)
+	}
+	return snippet.HTML(code, snippet.Language(clean), from), nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/dchest/kkr/markup"
+	"github.com/dchest/kkr/metaedit"
+	"github.com/dchest/kkr/metafile"
+	"github.com/dchest/kkr/shortcodes"
+	"github.com/dchest/kkr/utils"
+)
+
+// ConvertFile converts content of the post or page file between HTML
+// and Markdown, preserving front matter. The format is "md" or "html".
+// The original file is replaced with a file with the new extension,
+// whose name is returned.
+func (s *Site) ConvertFile(filename, format string) (string, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	header, content, metaFormat, err := metafile.Split(filename, b)
+	if err != nil {
+		return "", err
+	}
+	if metaFormat == "" {
+		return "", NotPageError
+	}
+	meta, err := metafile.Decode(metaFormat, header)
+	if err != nil {
+		return "", err
+	}
+	isMarkdown := utils.HasFileExt(filename, MarkdownExtensions) || meta["markup"] == "markdown"
+
+	var outname string
+	switch format {
+	case "md", "markdown":
+		outname = utils.ReplaceFileExt(filename, ".md")
+		if !isMarkdown {
+			content, err = markup.HTMLToMarkdown(content)
+			if err != nil {
+				return "", err
+			}
+		}
+	case "html":
+		if !isMarkdown {
+			return "", errors.New("file is already in HTML")
+		}
+		outname = utils.ReplaceFileExt(filename, ".html")
+		markup.SetOptions(s.Config.Markup)
+		// Keep shortcodes as they are.
+		var sources []string
+		content, sources, err = shortcodes.Extract(content)
+		if err != nil {
+			return "", err
+		}
+		content, err = markup.Process("markdown", content)
+		if err != nil {
+			return "", err
+		}
+		content = shortcodes.Restore(content, sources, func(src string) string {
+			return "{{<" + src + ">}}"
+		})
+	default:
+		return "", fmt.Errorf("unknown format %q", format)
+	}
+	// Markup is now determined by extension.
+	if _, ok := meta["markup"]; ok {
+		if metaFormat != metafile.YAML {
+			return "", fmt.Errorf("can't remove markup from %s front matter, convert it to YAML", strings.ToUpper(metaFormat))
+		}
+		header, _, err = metaedit.Apply(header, []metaedit.Op{{Kind: "unset", Key: "markup"}})
+		if err != nil {
+			return "", err
+		}
+	}
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return "", err
+	}
+	if outname != filename {
+		if _, err := os.Stat(outname); err == nil {
+			return "", fmt.Errorf("%s already exists", outname)
+		}
+	}
+	if err := ioutil.WriteFile(outname, metafile.Join(metaFormat, header, content), fi.Mode()); err != nil {
+		return "", err
+	}
+	if outname != filename {
+		if err := os.Remove(filename); err != nil {
+			return "", err
+		}
+	}
+	return outname, nil
+}
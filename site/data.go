@@ -0,0 +1,174 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/geojson"
+	"github.com/dchest/kkr/utils"
+)
+
+const DataDirName = "data"
+
+var (
+	CSVExtensions      = []string{".csv", ".tsv"}
+	GeoJSONExtensions  = []string{".geojson"}
+	DataYAMLExtensions = []string{".yml", ".yaml"}
+	DataJSONExtensions = []string{".json"}
+)
+
+// dataKey returns a key for data file: its relative path
+// with slashes and without extension, e.g. "prices/2024".
+func dataKey(relname string) string {
+	return filepath.ToSlash(utils.ReplaceFileExt(relname, ""))
+}
+
+func readCSV(filename string) ([][]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	if filepath.Ext(filename) == ".tsv" {
+		r.Comma = '\t'
+		r.LazyQuotes = true
+	}
+	return r.ReadAll()
+}
+
+func readJSON(filename string) (interface{}, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func readGeoJSON(filename string) (interface{}, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := geojson.Validate(b); err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// LoadData loads files from data directory into Config.Data.
+// YAML and JSON files are decoded, CSV and TSV files are loaded
+// as slices of rows, GeoJSON files are validated and decoded.
+//
+// In layouts, data is available as .Site.Data.name
+// or for files in subdirectories as index .Site.Data "dir/name".
+func (s *Site) LoadData() error {
+	log.Printf("* Loading data.")
+	data := make(map[string]interface{})
+	dataDir := filepath.Join(s.BaseDir, DataDirName)
+	err := filepath.Walk(dataDir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() || s.isIgnoredFile(fi.Name()) {
+			return nil
+		}
+		relname, err := filepath.Rel(dataDir, path)
+		if err != nil {
+			return err
+		}
+		var v interface{}
+		switch {
+		case utils.HasFileExt(relname, DataYAMLExtensions):
+			err = utils.UnmarshallYAMLFile(path, &v)
+		case utils.HasFileExt(relname, DataJSONExtensions):
+			v, err = readJSON(path)
+		case utils.HasFileExt(relname, CSVExtensions):
+			v, err = readCSV(path)
+		case utils.HasFileExt(relname, GeoJSONExtensions):
+			v, err = readGeoJSON(path)
+		default:
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", relname, err)
+		}
+		log.Printf("D %s", relname)
+		key := dataKey(relname)
+		if _, exists := data[key]; exists {
+			return fmt.Errorf("duplicate data file %q", key)
+		}
+		data[key] = v
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	s.Config.Data = data
+	return nil
+}
+
+// renderTable renders rows as an HTML table.
+// The first row is used as a header.
+func renderTable(rows [][]string) string {
+	var b strings.Builder
+	b.WriteString("<table>\n")
+	for i, row := range rows {
+		if i == 0 {
+			b.WriteString("<thead>\n")
+		} else if i == 1 {
+			b.WriteString("<tbody>\n")
+		}
+		cell := "td"
+		if i == 0 {
+			cell = "th"
+		}
+		b.WriteString("<tr>")
+		for _, v := range row {
+			fmt.Fprintf(&b, "<%s>%s</%s>", cell, html.EscapeString(v), cell)
+		}
+		b.WriteString("</tr>\n")
+		if i == 0 {
+			b.WriteString("</thead>\n")
+		} else if i == len(rows)-1 {
+			b.WriteString("</tbody>\n")
+		}
+	}
+	b.WriteString("</table>\n")
+	return b.String()
+}
+
+// RenderGeoJSON validates and minifies GeoJSON file from pages
+// directory and writes it into output directory.
+func (s *Site) RenderGeoJSON(pagesDir, relname string) error {
+	b, err := ioutil.ReadFile(filepath.Join(pagesDir, relname))
+	if err != nil {
+		return err
+	}
+	b, err = geojson.Minify(b)
+	if err != nil {
+		return fmt.Errorf("%s: %w", relname, err)
+	}
+	log.Printf("G > %s\n", filepath.Join(OutDirName, relname))
+	return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, relname), b)
+}
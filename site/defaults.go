@@ -0,0 +1,105 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultsFileName is the name of file in pages or posts directory
+// (or their subdirectories) with default meta values, e.g.:
+//
+//	layout: doc
+//	sitemap: false
+//
+// Defaults apply to every page or post under the directory.
+// Values from subdirectories override values from parent
+// directories, and meta of the file overrides them all.
+const DefaultsFileName = "_defaults.yml"
+
+type defaultsEntry struct {
+	fi   os.FileInfo
+	meta map[string]interface{}
+}
+
+var defaultsCache = struct {
+	sync.Mutex
+	m map[string]*defaultsEntry
+}{m: make(map[string]*defaultsEntry)}
+
+// readDefaults returns meta from the defaults file, or nil
+// if it doesn't exist. Files are re-read only when they change.
+func readDefaults(filename string) (map[string]interface{}, error) {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defaultsCache.Lock()
+	defer defaultsCache.Unlock()
+	if e := defaultsCache.m[filename]; e != nil &&
+		e.fi.ModTime() == fi.ModTime() && e.fi.Size() == fi.Size() {
+		return e.meta, nil
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var meta map[string]interface{}
+	if err := yaml.Unmarshal(b, &meta); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	defaultsCache.m[filename] = &defaultsEntry{fi, meta}
+	return meta, nil
+}
+
+// loadDefaults returns default meta for the file relative to basedir,
+// collected from defaults files in basedir and its subdirectories
+// down to the file's directory.
+func loadDefaults(basedir, relname string) (map[string]interface{}, error) {
+	dirs := []string{basedir}
+	dir := basedir
+	if d := filepath.Dir(relname); d != "." {
+		for _, name := range strings.Split(d, string(filepath.Separator)) {
+			dir = filepath.Join(dir, name)
+			dirs = append(dirs, dir)
+		}
+	}
+	var defaults map[string]interface{}
+	for _, dir := range dirs {
+		meta, err := readDefaults(filepath.Join(dir, DefaultsFileName))
+		if err != nil {
+			return nil, err
+		}
+		if len(meta) == 0 {
+			continue
+		}
+		if defaults == nil {
+			defaults = make(map[string]interface{})
+		}
+		for k, v := range meta {
+			defaults[k] = v
+		}
+	}
+	return defaults, nil
+}
+
+// applyDefaults sets meta values that are missing from defaults.
+func applyDefaults(meta, defaults map[string]interface{}) {
+	for k, v := range defaults {
+		if _, ok := meta[k]; !ok {
+			meta[k] = v
+		}
+	}
+}
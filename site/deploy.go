@@ -0,0 +1,333 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dchest/kkr/purge"
+	"github.com/dchest/kkr/s3"
+	"github.com/dchest/kkr/syndicate"
+	"github.com/dchest/kkr/utils"
+)
+
+// site.yml -> deploy:
+type DeployConfig struct {
+	S3          *S3DeployConfig  `yaml:"s3"`
+	Purge       *PurgeConfig     `yaml:"purge"`
+	IndexNow    *IndexNowConfig  `yaml:"indexnow"`
+	PingSitemap bool             `yaml:"ping_sitemap"` // deprecated, search engines removed sitemap pings; use indexnow
+	Syndicate   *SyndicateConfig `yaml:"syndicate"`
+}
+
+// site.yml -> deploy: s3:
+//
+// Credentials are taken from AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY environment variables.
+type S3DeployConfig struct {
+	Endpoint string `yaml:"endpoint"` // e.g. https://<account>.r2.cloudflarestorage.com
+	Region   string `yaml:"region"`   // e.g. us-east-1 or auto for R2
+	Bucket   string `yaml:"bucket"`
+	Prefix   string `yaml:"prefix"` // key prefix, e.g. www/
+}
+
+// site.yml -> deploy: purge:
+//
+// After deploying, changed URLs are purged from the configured CDNs.
+// API tokens are taken from CLOUDFLARE_API_TOKEN, FASTLY_API_TOKEN
+// and BUNNY_API_KEY environment variables.
+type PurgeConfig struct {
+	CloudflareZone string `yaml:"cloudflare_zone"` // zone ID
+	Fastly         bool   `yaml:"fastly"`
+	Bunny          bool   `yaml:"bunny"`
+}
+
+// DeployResult lists paths of deployed files relative
+// to output directory (with slashes).
+type DeployResult struct {
+	Uploaded []string
+	Deleted  []string
+}
+
+// Deploy uploads the built site to the storage configured in
+// site.yml -> deploy:. Only changed files are uploaded, and files
+// that are no longer in the output directory are deleted.
+// Changed URLs are then purged from CDNs and submitted
+// to search engines, WebSub hub is notified about changed feeds,
+// and new posts are announced on social networks.
+// If dryRun is true, it only reports changes.
+func (s *Site) Deploy(dryRun bool) (*DeployResult, error) {
+	if s.Config.Deploy == nil || s.Config.Deploy.S3 == nil {
+		return nil, errors.New("no deploy in site.yml")
+	}
+	var purgers []purge.Purger
+	if c := s.Config.Deploy.Purge; c != nil {
+		if s.Config.URL == "" {
+			return nil, errors.New("deploy purge: url must be set in site.yml")
+		}
+		var err error
+		if purgers, err = c.purgers(); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkPing(); err != nil {
+		return nil, err
+	}
+	var posters []syndicate.Poster
+	if c := s.Config.Deploy.Syndicate; c != nil {
+		if s.Config.URL == "" {
+			return nil, errors.New("deploy syndicate: url must be set in site.yml")
+		}
+		var err error
+		if posters, err = c.posters(); err != nil {
+			return nil, err
+		}
+	}
+	res, err := s.deployS3(s.Config.Deploy.S3, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.purgeDeployed(purgers, res, dryRun); err != nil {
+		return nil, err
+	}
+	s.pingSearchEngines(res, dryRun)
+	s.pingWebSub(res, dryRun)
+	s.syndicatePosts(posters, dryRun)
+	return res, nil
+}
+
+// deployFile is a file to upload.
+type deployFile struct {
+	name   string // path relative to output directory
+	header http.Header
+}
+
+// deployFiles returns files in output directory with headers
+// for uploading: Content-Type and Cache-Control of the file, and
+// Content-Encoding for precompressed .br and .gz siblings.
+func (s *Site) deployFiles() ([]*deployFile, error) {
+	outDir := filepath.Join(s.BaseDir, OutDirName)
+	exists := make(map[string]bool)
+	err := filepath.Walk(outDir, func(name string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+		relname, err := filepath.Rel(outDir, name)
+		if err != nil {
+			return err
+		}
+		exists[filepath.ToSlash(relname)] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	files := make([]*deployFile, 0, len(exists))
+	for name := range exists {
+		h := make(http.Header)
+		orig := name
+		for _, enc := range precompressedEncodings {
+			if strings.HasSuffix(name, enc.ext) && exists[strings.TrimSuffix(name, enc.ext)] {
+				orig = strings.TrimSuffix(name, enc.ext)
+				h.Set("Content-Encoding", enc.name)
+				break
+			}
+		}
+		ctype := mime.TypeByExtension(filepath.Ext(orig))
+		if ctype == "" {
+			ctype = "application/octet-stream"
+		}
+		h.Set("Content-Type", ctype)
+		if v := cacheControl(s.Config.CacheControl, "/"+orig); v != "" {
+			h.Set("Cache-Control", v)
+		}
+		files = append(files, &deployFile{name: name, header: h})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+	return files, nil
+}
+
+func (s *Site) deployS3(c *S3DeployConfig, dryRun bool) (*DeployResult, error) {
+	if c.Endpoint == "" || c.Bucket == "" {
+		return nil, errors.New("deploy s3: endpoint and bucket are required")
+	}
+	client := &s3.Client{
+		Endpoint:  c.Endpoint,
+		Region:    c.Region,
+		Bucket:    c.Bucket,
+		AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+	}
+	if client.Region == "" {
+		client.Region = "us-east-1"
+	}
+	if client.AccessKey == "" || client.SecretKey == "" {
+		return nil, errors.New("deploy s3: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	prefix := strings.TrimPrefix(c.Prefix, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	log.Printf("* Deploying to %s/%s/%s.", strings.TrimSuffix(c.Endpoint, "/"), c.Bucket, prefix)
+	files, err := s.deployFiles()
+	if err != nil {
+		return nil, err
+	}
+	objects, err := client.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+	remote := make(map[string]string) // key -> etag
+	for _, o := range objects {
+		remote[o.Key] = o.ETag
+	}
+
+	// Metadata of unchanged files is not compared: after changing
+	// cache_control, remove objects from the bucket to upload them again.
+	res := new(DeployResult)
+	outDir := filepath.Join(s.BaseDir, OutDirName)
+	for _, f := range files {
+		key := prefix + f.name
+		b, err := ioutil.ReadFile(filepath.Join(outDir, filepath.FromSlash(f.name)))
+		if err != nil {
+			return nil, err
+		}
+		etag, ok := remote[key]
+		delete(remote, key)
+		if ok && etag == s3.ETag(b) {
+			continue
+		}
+		log.Printf("+ %s", key)
+		res.Uploaded = append(res.Uploaded, f.name)
+		if dryRun {
+			continue
+		}
+		if err := client.Put(key, b, f.header); err != nil {
+			return nil, fmt.Errorf("deploy %s: %w", key, err)
+		}
+	}
+
+	var keys []string
+	for key := range remote {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		log.Printf("- %s", key)
+		res.Deleted = append(res.Deleted, strings.TrimPrefix(key, prefix))
+	}
+	if !dryRun && len(keys) > 0 {
+		if err := client.Delete(keys); err != nil {
+			return nil, fmt.Errorf("deploy: %w", err)
+		}
+	}
+	log.Printf("* Uploaded %d files, deleted %d files.", len(res.Uploaded), len(res.Deleted))
+	return res, nil
+}
+
+// purgers returns purgers configured in site.yml -> deploy: purge:.
+func (c *PurgeConfig) purgers() ([]purge.Purger, error) {
+	var list []purge.Purger
+	env := func(name string) (string, error) {
+		v := os.Getenv(name)
+		if v == "" {
+			return "", fmt.Errorf("deploy purge: %s must be set", name)
+		}
+		return v, nil
+	}
+	if c.CloudflareZone != "" {
+		token, err := env("CLOUDFLARE_API_TOKEN")
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &purge.Cloudflare{Zone: c.CloudflareZone, Token: token})
+	}
+	if c.Fastly {
+		token, err := env("FASTLY_API_TOKEN")
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &purge.Fastly{Token: token})
+	}
+	if c.Bunny {
+		key, err := env("BUNNY_API_KEY")
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &purge.Bunny{AccessKey: key})
+	}
+	return list, nil
+}
+
+// purgeURLs returns site URLs of changed files. Precompressed
+// siblings are skipped, and index files are purged both
+// with and without index.html.
+func (s *Site) purgeURLs(changed []string) []string {
+	base := strings.TrimSuffix(s.Config.URL, "/")
+	set := make(map[string]bool)
+	for _, name := range changed {
+		set[name] = true
+	}
+	seen := make(map[string]bool)
+	var urls []string
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			urls = append(urls, base+p)
+		}
+	}
+	for _, name := range changed {
+		sibling := false
+		for _, enc := range precompressedEncodings {
+			if strings.HasSuffix(name, enc.ext) && set[strings.TrimSuffix(name, enc.ext)] {
+				sibling = true
+			}
+		}
+		if sibling {
+			continue
+		}
+		add("/" + name)
+		clean := utils.CleanPermalink(name)
+		if clean == "" {
+			clean = "/" // root index.html
+		}
+		if clean != "/"+name {
+			add(clean)
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// purgeDeployed purges URLs of uploaded and deleted files from CDNs.
+func (s *Site) purgeDeployed(purgers []purge.Purger, res *DeployResult, dryRun bool) error {
+	if len(purgers) == 0 {
+		return nil
+	}
+	urls := s.purgeURLs(append(append([]string(nil), res.Uploaded...), res.Deleted...))
+	if len(urls) == 0 {
+		return nil
+	}
+	for _, p := range purgers {
+		log.Printf("* Purging %d URLs from %s.", len(urls), p.Name())
+		if dryRun {
+			continue
+		}
+		if err := p.Purge(urls); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/diagram"
+	"github.com/dchest/kkr/filters"
+	"github.com/dchest/kkr/utils"
+)
+
+const diagramsDirName = "diagrams"
+
+// renderDiagrams replaces code blocks in languages configured
+// in site.yml diagrams with SVG rendered by their commands.
+func (s *Site) renderDiagrams(data []byte) ([]byte, error) {
+	if len(s.Config.Diagrams) == 0 {
+		return data, nil
+	}
+	langs := make(map[string]bool, len(s.Config.Diagrams))
+	for lang := range s.Config.Diagrams {
+		langs[lang] = true
+	}
+	return diagram.Replace(data, langs, s.renderDiagram)
+}
+
+// renderDiagram runs the command configured for the language with the
+// diagram source as input and returns SVG from its output.
+func (s *Site) renderDiagram(lang, source string) ([]byte, error) {
+	command := s.Config.Diagrams[lang]
+	if len(command) == 0 {
+		return nil, fmt.Errorf("diagrams: no command for %q", lang)
+	}
+	return s.runCached(diagramsDirName, command, []byte(source), ".svg", lang+" diagram")
+}
+
+// runCached runs the command with input and returns its output.
+// Results are cached in the named subdirectory of the site cache
+// directory with the given extension.
+func (s *Site) runCached(dirName string, command []string, input []byte, ext, what string) ([]byte, error) {
+	key := strings.Join(command, "\x00") + "\x00" + string(input)
+	cacheFile := filepath.Join(s.BaseDir, CacheDirName, dirName,
+		utils.NoVowelsHexEncode(utils.Hash([]byte(key))[:16])+ext)
+	if b, err := ioutil.ReadFile(cacheFile); err == nil {
+		return b, nil
+	}
+	log.Printf("R %s", what)
+	out, err := filters.Make("exec", command).Apply(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+		return nil, err
+	}
+	return out, ioutil.WriteFile(cacheFile, out, 0644)
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/dchest/kkr/udiff"
+	"github.com/dchest/kkr/utils"
+)
+
+// diffTextExtensions are extensions of files for which unified
+// diffs are shown.
+var diffTextExtensions = []string{".html", ".htm", ".xml"}
+
+// FileChange is a difference between the current and the new output.
+type FileChange struct {
+	Name string // slash-separated name relative to output directory
+	Op   byte   // '+' added, '-' removed, '~' changed
+}
+
+// hashOutputFiles returns hashes of files in dir by their slash-separated
+// relative names, skipping precompressed siblings of other files.
+func hashOutputFiles(dir string) (map[string][sha256.Size]byte, error) {
+	hashes := make(map[string][sha256.Size]byte)
+	err := filepath.Walk(dir, func(name string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && name == dir {
+				return nil // no output yet
+			}
+			return err
+		}
+		if fi.IsDir() || isPrecompressedSibling(name) {
+			return nil
+		}
+		relname, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		var sum [sha256.Size]byte
+		copy(sum[:], h.Sum(nil))
+		hashes[filepath.ToSlash(relname)] = sum
+		return nil
+	})
+	return hashes, err
+}
+
+// Diff builds the site into a temporary directory and reports which
+// files in the output directory would change, by calling report with
+// the current and the new output directories for each change.
+// The current output directory is left intact.
+func (s *Site) Diff(report func(c FileChange, oldDir, newDir string) error) error {
+	outDir := filepath.Join(s.BaseDir, OutDirName)
+	tmpDir, err := ioutil.TempDir(s.BaseDir, ".kkr-diff-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	// The output directory name is fixed, so move the current output
+	// away while building, and put it back afterwards.
+	oldDir := filepath.Join(tmpDir, "old")
+	newDir := filepath.Join(tmpDir, "new")
+	if err := os.Rename(outDir, oldDir); err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.Mkdir(oldDir, 0755); err != nil {
+			return err
+		}
+	}
+	restored := false
+	restore := func() error {
+		if restored {
+			return nil
+		}
+		restored = true
+		if err := os.RemoveAll(outDir); err != nil {
+			return err
+		}
+		return os.Rename(oldDir, outDir)
+	}
+	defer func() {
+		if err := restore(); err != nil {
+			log.Printf("! cannot restore output directory from %s: %s", oldDir, err)
+		}
+	}()
+	if err := s.Build(); err != nil {
+		return err
+	}
+	if err := os.Rename(outDir, newDir); err != nil {
+		return err
+	}
+	if err := restore(); err != nil {
+		return err
+	}
+	oldHashes, err := hashOutputFiles(outDir)
+	if err != nil {
+		return err
+	}
+	newHashes, err := hashOutputFiles(newDir)
+	if err != nil {
+		return err
+	}
+	var changes []FileChange
+	for name, h := range newHashes {
+		oh, ok := oldHashes[name]
+		switch {
+		case !ok:
+			changes = append(changes, FileChange{name, '+'})
+		case oh != h:
+			changes = append(changes, FileChange{name, '~'})
+		}
+	}
+	for name := range oldHashes {
+		if _, ok := newHashes[name]; !ok {
+			changes = append(changes, FileChange{name, '-'})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	for _, c := range changes {
+		if err := report(c, outDir, newDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReportDiff builds the site and logs output files that would be
+// added, changed or removed compared to the current output directory.
+// If unified is true, it also prints unified diffs of changed HTML
+// and XML files.
+func (s *Site) ReportDiff(unified bool) error {
+	counts := make(map[byte]int)
+	err := s.Diff(func(c FileChange, oldDir, newDir string) error {
+		counts[c.Op]++
+		log.Printf("%c %s", c.Op, c.Name)
+		if !unified || c.Op != '~' || !utils.HasFileExt(c.Name, diffTextExtensions) {
+			return nil
+		}
+		a, err := ioutil.ReadFile(filepath.Join(oldDir, filepath.FromSlash(c.Name)))
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadFile(filepath.Join(newDir, filepath.FromSlash(c.Name)))
+		if err != nil {
+			return err
+		}
+		fmt.Print(udiff.Unified("a/"+c.Name, "b/"+c.Name, string(a), string(b)))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("* %d added, %d changed, %d removed.", counts['+'], counts['~'], counts['-'])
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dchest/kkr/humanize"
+	"github.com/dchest/kkr/metafile"
+)
+
+// site.yml -> downloads:
+type DownloadsConfig struct {
+	Dir       string `yaml:"dir"`       // directory in pages, e.g. /downloads/
+	Checksums string `yaml:"checksums"` // if set, name of checksums file to write into dir, e.g. SHA256SUMS
+}
+
+// Download describes a file in downloads directory.
+type Download struct {
+	Name    string
+	URL     string
+	Size    int64
+	ModTime time.Time
+	SHA256  string // hex-encoded
+}
+
+// SizeString returns human-readable file size, e.g. "1.5 MB".
+func (d *Download) SizeString() string {
+	return humanize.FileSize(d.Size, "")
+}
+
+func hasFrontMatter(filename string) (bool, error) {
+	f, err := metafile.Open(filename)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	return f.HasMeta(), nil
+}
+
+func hashFile(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// LoadDownloads computes sizes and checksums of files in the configured
+// downloads directory. Files are sorted by name.
+func (s *Site) LoadDownloads() error {
+	s.downloads = nil
+	c := s.Config.Downloads
+	if c == nil {
+		return nil
+	}
+	if c.Dir == "" {
+		return fmt.Errorf("downloads must have dir")
+	}
+	log.Printf("* Loading downloads.")
+	dirURL := path.Join("/", c.Dir)
+	fis, err := ioutil.ReadDir(filepath.Join(s.BaseDir, PagesDirName, filepath.FromSlash(dirURL)))
+	if err != nil {
+		return err
+	}
+	for _, fi := range fis {
+		name := fi.Name()
+		if fi.IsDir() || strings.HasPrefix(name, ".") || name == c.Checksums {
+			continue
+		}
+		isPage, err := hasFrontMatter(filepath.Join(s.BaseDir, PagesDirName, filepath.FromSlash(dirURL), name))
+		if err != nil {
+			return err
+		}
+		if isPage {
+			continue // rendered page, such as listing itself
+		}
+		d := &Download{
+			Name:    name,
+			URL:     path.Join(dirURL, name),
+			Size:    fi.Size(),
+			ModTime: fi.ModTime(),
+		}
+		d.SHA256, err = hashFile(filepath.Join(s.BaseDir, PagesDirName, filepath.FromSlash(d.URL)))
+		if err != nil {
+			return err
+		}
+		s.downloads = append(s.downloads, d)
+	}
+	sort.Slice(s.downloads, func(i, j int) bool { return s.downloads[i].Name < s.downloads[j].Name })
+	return nil
+}
+
+// RenderDownloadChecksums writes checksums file in sha256sum format
+// into downloads directory if it's configured.
+func (s *Site) RenderDownloadChecksums() error {
+	c := s.Config.Downloads
+	if c == nil || c.Checksums == "" {
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, d := range s.downloads {
+		fmt.Fprintf(&buf, "%s  %s\n", d.SHA256, d.Name)
+	}
+	filename := filepath.FromSlash(path.Join(c.Dir, c.Checksums))
+	log.Printf("D > %s", filepath.Join(OutDirName, filename))
+	return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, filename), buf.Bytes())
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/embed"
+)
+
+// mastodonEmbed returns a static quotation of Mastodon post. Post is
+// fetched from the server API, which must be allowed in fetch.hosts.
+func (s *Site) mastodonEmbed(postURL string) (string, error) {
+	apiURL, err := embed.MastodonAPIURL(postURL)
+	if err != nil {
+		return "", err
+	}
+	b, err := s.fetcher.Get(apiURL)
+	if err != nil {
+		return "", err
+	}
+	p, err := embed.ParseMastodon(b)
+	if err != nil {
+		return "", fmt.Errorf("mastodon %s: %w", postURL, err)
+	}
+	return p.HTML(), nil
+}
+
+// tweetEmbed returns a static quotation of the tweet. Tweet is fetched
+// using oEmbed API, publish.twitter.com must be allowed in fetch.hosts.
+func (s *Site) tweetEmbed(postURL string) (string, error) {
+	apiURL, err := embed.TweetOEmbedURL(postURL)
+	if err != nil {
+		return "", err
+	}
+	b, err := s.fetcher.Get(apiURL)
+	if err != nil {
+		return "", err
+	}
+	p, err := embed.ParseTweet(b, postURL)
+	if err != nil {
+		return "", fmt.Errorf("tweet %s: %w", postURL, err)
+	}
+	return p.HTML(), nil
+}
+
+// videoEmbed returns a click-to-load facade of YouTube or Vimeo video:
+// its thumbnail, fetched at build time and stored in embeds directory,
+// linking to the video. Include $video-script in a script asset to
+// replace it with the player when clicked. Requires oEmbed and
+// thumbnail hosts in fetch.hosts.
+func (s *Site) videoEmbed(provider, video string, title ...string) (string, error) {
+	id, err := embed.ParseVideoID(provider, video)
+	if err != nil {
+		return "", err
+	}
+	b, err := s.fetcher.Get(embed.VideoOEmbedURL(provider, id))
+	if err != nil {
+		return "", err
+	}
+	v, err := embed.ParseVideoOEmbed(b, provider, id)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", provider, id, err)
+	}
+	if len(title) > 0 && title[0] != "" {
+		v.Title = title[0]
+	}
+	thumb, err := s.fetcher.Get(v.ThumbnailURL)
+	if err != nil {
+		return "", err
+	}
+	ext := path.Ext(strings.SplitN(v.ThumbnailURL, "?", 2)[0])
+	if ext == "" {
+		ext = ".jpg"
+	}
+	name := "embeds/" + provider + "-" + id + ext
+	if err := s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, filepath.FromSlash(name)), thumb); err != nil {
+		return "", err
+	}
+	return v.HTML("/" + name), nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dchest/kkr/links"
+	"github.com/dchest/kkr/utils"
+)
+
+// Embed is a third-party resource used by pages.
+type Embed struct {
+	links.Resource
+	Pages        []string // URLs of pages using the resource
+	AllowedByCSP bool     // false if CSP from csp.yml blocks it
+}
+
+// ownDomains returns domains of the site: its host, host of static
+// files and own domains from links policy.
+func (s *Site) ownDomains() []string {
+	var domains []string
+	for _, u := range []string{s.Config.URL, staticURL(s.Config.Static)} {
+		if pu, err := url.Parse(u); err == nil && pu.Hostname() != "" {
+			domains = append(domains, pu.Hostname())
+		}
+	}
+	if s.Config.Links != nil {
+		domains = append(domains, s.Config.Links.OwnDomains...)
+	}
+	return domains
+}
+
+func staticURL(c *StaticConfig) string {
+	if c == nil {
+		return ""
+	}
+	return c.URL
+}
+
+// Embeds returns third-party resources, such as scripts and iframes,
+// found in HTML files of the output directory, sorted by URL.
+//
+// The site must be built.
+func (s *Site) Embeds() ([]*Embed, error) {
+	domains := s.ownDomains()
+	embeds := make(map[links.Resource]*Embed)
+	outDir := filepath.Join(s.BaseDir, OutDirName)
+	err := filepath.Walk(outDir, func(name string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() || !utils.HasFileExt(name, HTMLExtensions) {
+			return nil
+		}
+		relname, err := filepath.Rel(outDir, name)
+		if err != nil {
+			return err
+		}
+		page := utils.CleanPermalink(filepath.ToSlash(relname))
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		list, err := links.ThirdParty(b, domains)
+		if err != nil {
+			return err
+		}
+		for _, r := range list {
+			e := embeds[r]
+			if e == nil {
+				e = &Embed{Resource: r, AllowedByCSP: len(s.CSP) == 0 || s.CSP.Allows(r.Directive, r.URL)}
+				embeds[r] = e
+			}
+			if n := len(e.Pages); n == 0 || e.Pages[n-1] != page {
+				e.Pages = append(e.Pages, page)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*Embed, 0, len(embeds))
+	for _, e := range embeds {
+		sort.Strings(e.Pages)
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].URL != list[j].URL {
+			return list[i].URL < list[j].URL
+		}
+		return list[i].Tag < list[j].Tag
+	})
+	return list, nil
+}
+
+// maxReportedPages is the maximum number of pages listed for an embed.
+const maxReportedPages = 5
+
+// ReportEmbeds logs third-party resources with pages using them,
+// and whether CSP needs updating to allow them, see Embeds.
+func (s *Site) ReportEmbeds() error {
+	embeds, err := s.Embeds()
+	if err != nil {
+		return err
+	}
+	for _, e := range embeds {
+		pages := e.Pages
+		more := ""
+		if len(pages) > maxReportedPages {
+			more = ", ..."
+			pages = pages[:maxReportedPages]
+		}
+		log.Printf("E %s %s (%d pages: %s%s)", e.Tag, e.URL, len(e.Pages), strings.Join(pages, ", "), more)
+		if !e.AllowedByCSP {
+			log.Printf("! %s is not allowed by CSP %s", e.URL, e.Directive)
+		}
+	}
+	log.Printf("* Found %d third-party resources.", len(embeds))
+	return nil
+}
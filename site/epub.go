@@ -0,0 +1,186 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/epub"
+	"github.com/dchest/kkr/metaedit"
+)
+
+// epubBuilder collects chapters and images of the book.
+type epubBuilder struct {
+	s        *Site
+	book     *epub.Book
+	chapters map[string]string // post URL -> chapter file name
+	images   map[string]string // output file URL -> book file name
+}
+
+// localPath returns the path of a link to the site, resolved against
+// the page URL, or an empty string if it's an external link.
+func (b *epubBuilder) localPath(pageURL, link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Path == "" || strings.HasPrefix(link, "#") {
+		return ""
+	}
+	if u.Scheme != "" || u.Host != "" {
+		own, err := url.Parse(b.s.Config.URL)
+		if err != nil || own.Host == "" || u.Host != own.Host {
+			return ""
+		}
+		return u.Path
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(u).Path
+}
+
+// image adds the output file with the given URL path to the book
+// and returns its name in the book, or an empty string if there's
+// no such file.
+func (b *epubBuilder) image(p string) (string, error) {
+	if name, ok := b.images[p]; ok {
+		return name, nil
+	}
+	data, err := ioutil.ReadFile(filepath.Join(b.s.BaseDir, OutDirName, filepath.FromSlash(p)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	name := fmt.Sprintf("images/%d-%s", len(b.images)+1, path.Base(p))
+	b.images[p] = name
+	b.book.Files = append(b.book.Files, epub.File{Name: name, Data: data})
+	log.Printf("G > %s", p)
+	return name, nil
+}
+
+// chapter converts rendered content of the post into a chapter body,
+// adding images to the book and pointing links to posts in the book
+// to their chapters, and other links to the site.
+func (b *epubBuilder) chapter(p *Post) (string, error) {
+	content, err := b.s.Layouts.RenderContent(&p.Page)
+	if err != nil {
+		return "", err
+	}
+	var rerr error
+	body, err := epub.XHTML(content, func(tag, attr, value string) string {
+		lp := b.localPath(p.url, value)
+		if lp == "" || rerr != nil {
+			return value
+		}
+		if attr == "src" {
+			name, err := b.image(lp)
+			if err != nil {
+				rerr = err
+			}
+			if name != "" {
+				return name
+			}
+		} else if name, ok := b.chapters[pageKey(lp)]; ok {
+			if u, err := url.Parse(value); err == nil && u.Fragment != "" {
+				name += "#" + u.Fragment
+			}
+			return name
+		}
+		u, _ := url.Parse(value)
+		abs := strings.TrimSuffix(b.s.Config.URL, "/") + lp
+		if u != nil && u.Fragment != "" {
+			abs += "#" + u.Fragment
+		}
+		return abs
+	})
+	if err != nil {
+		return "", err
+	}
+	return body, rerr
+}
+
+func hasTag(p *Post, tag string) bool {
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportEPUB writes posts with the tag, if it's not empty, which
+// match the condition, into EPUB file, oldest first. Local images
+// are included in the book. The site must be built.
+func (s *Site) ExportEPUB(filename, tag string, cond *metaedit.Condition) error {
+	var posts Posts
+	for _, p := range s.Config.Posts {
+		if tag != "" && !hasTag(p, tag) {
+			continue
+		}
+		if !cond.Match(p.meta) {
+			continue
+		}
+		posts = append(posts, p)
+	}
+	if len(posts) == 0 {
+		return errors.New("no posts to export")
+	}
+	title, id := s.Config.Name, s.Config.URL+"/"
+	if tag != "" {
+		title += ": " + tag
+		id += "#" + url.PathEscape(tag)
+	}
+	b := &epubBuilder{
+		s: s,
+		book: &epub.Book{
+			Identifier: id,
+			Title:      title,
+			Author:     s.Config.Author,
+			Language:   s.siteLang(),
+			Modified:   s.Config.Date,
+		},
+		chapters: make(map[string]string),
+		images:   make(map[string]string),
+	}
+	// Posts are sorted newest first.
+	for i := range posts {
+		b.chapters[pageKey(posts[len(posts)-1-i].url)] = fmt.Sprintf("chapter-%d.xhtml", i+1)
+	}
+	for i := len(posts) - 1; i >= 0; i-- {
+		p := posts[i]
+		body, err := b.chapter(p)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.url, err)
+		}
+		b.book.Chapters = append(b.book.Chapters, epub.Chapter{
+			Name:  b.chapters[pageKey(p.url)],
+			Title: metaString(p.meta, "title"),
+			Body:  body,
+		})
+		log.Printf("E %s", p.url)
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if _, err := b.book.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	log.Printf("* Exported %d posts to %s.", len(posts), filename)
+	return nil
+}
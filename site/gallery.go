@@ -0,0 +1,246 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dchest/kkr/exif"
+	"github.com/dchest/kkr/imaging"
+	"github.com/dchest/kkr/utils"
+)
+
+const (
+	DefaultGalleryLayout = "gallery"
+	GalleryManifestName  = "gallery.yml"
+	DefaultThumbWidth    = 400
+
+	thumbsDirName = "thumbs"
+)
+
+// site.yml -> galleries:
+type GalleryConfig struct {
+	Dir        string `yaml:"dir"`       // directory with images relative to site
+	Permalink  string `yaml:"permalink"` // e.g. /photos/hikes/
+	Layout     string `yaml:"layout"`
+	ThumbWidth int    `yaml:"thumb_width"`
+}
+
+// gallery.yml in gallery directory.
+type galleryManifest struct {
+	Title       string          `yaml:"title"`
+	Description string          `yaml:"description"`
+	Images      []*GalleryImage `yaml:"images"`
+}
+
+type GalleryImage struct {
+	File    string `yaml:"file"`
+	Title   string `yaml:"title"`
+	Caption string `yaml:"caption"`
+
+	// Generated.
+	URL         string     `yaml:"-"`
+	ThumbURL    string     `yaml:"-"`
+	Width       int        `yaml:"-"`
+	Height      int        `yaml:"-"`
+	ThumbWidth  int        `yaml:"-"`
+	ThumbHeight int        `yaml:"-"`
+	Exif        *exif.Info `yaml:"-"` // nil if image has no EXIF data
+}
+
+type GalleryPage struct {
+	Page
+	Filename string
+	Images   []*GalleryImage
+}
+
+func (p *GalleryPage) Meta() map[string]interface{} { return p.meta }
+func (p *GalleryPage) Content() string              { return p.content }
+func (p *GalleryPage) FileInfo() os.FileInfo        { return nil }
+func (p *GalleryPage) URL() string                  { return p.url }
+
+// loadGalleryImages returns images from manifest, or if there's
+// no manifest, all images in the directory sorted by name.
+func loadGalleryImages(dir string) (*galleryManifest, error) {
+	var m galleryManifest
+	err := utils.UnmarshallYAMLFile(filepath.Join(dir, GalleryManifestName), &m)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(m.Images) > 0 {
+		return &m, nil
+	}
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, fi := range fis {
+		if !fi.IsDir() && imaging.IsImage(fi.Name()) {
+			m.Images = append(m.Images, &GalleryImage{File: fi.Name()})
+		}
+	}
+	sort.Slice(m.Images, func(i, j int) bool { return m.Images[i].File < m.Images[j].File })
+	return &m, nil
+}
+
+// galleryMarkup returns lightbox-ready markup for images.
+func galleryMarkup(images []*GalleryImage) string {
+	var b strings.Builder
+	b.WriteString(`<div class="kkr-gallery">` + "\n")
+	for _, img := range images {
+		alt := img.Title
+		if alt == "" {
+			alt = img.Caption
+		}
+		fmt.Fprintf(&b, `<a href="%s" data-lightbox="gallery" data-title="%s">`,
+			html.EscapeString(img.URL), html.EscapeString(img.Caption))
+		fmt.Fprintf(&b, `<img src="%s" width="%d" height="%d" alt="%s" loading="lazy">`,
+			html.EscapeString(img.ThumbURL), img.ThumbWidth, img.ThumbHeight, html.EscapeString(alt))
+		b.WriteString("</a>\n")
+	}
+	b.WriteString("</div>\n")
+	return b.String()
+}
+
+// makeThumbnail returns the name of thumbnail file for the image
+// resized to width. Thumbnails are cached in the site cache directory.
+func (s *Site) makeThumbnail(filename string, width int) (string, error) {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf("%s %d %d %d", filename, fi.Size(), fi.ModTime().UnixNano(), width)
+	thumbFile := filepath.Join(s.BaseDir, CacheDirName, thumbsDirName,
+		utils.NoVowelsHexEncode(utils.Hash([]byte(key))[:16])+strings.ToLower(filepath.Ext(filename)))
+	if _, err := os.Stat(thumbFile); err == nil {
+		return thumbFile, nil // cached
+	}
+	img, err := imaging.Decode(filename)
+	if err != nil {
+		return "", err
+	}
+	b, err := imaging.Encode(filename, imaging.Resize(img, width))
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(thumbFile), 0755); err != nil {
+		return "", err
+	}
+	return thumbFile, ioutil.WriteFile(thumbFile, b, 0644)
+}
+
+func (s *Site) renderGalleryImage(c *GalleryConfig, img *GalleryImage, outDir string) error {
+	thumbWidth := c.ThumbWidth
+	if thumbWidth == 0 {
+		thumbWidth = DefaultThumbWidth
+	}
+	inFile := filepath.Join(s.BaseDir, c.Dir, filepath.FromSlash(img.File))
+	w, h, err := imaging.DecodeConfig(inFile)
+	if err != nil {
+		return err
+	}
+	img.Width, img.Height = w, h
+	img.Exif, err = readExif(inFile)
+	if err != nil {
+		return err
+	}
+	img.ThumbWidth, img.ThumbHeight = imaging.FitSize(w, h, thumbWidth)
+	img.URL = path.Join(utils.CleanPermalink(c.Permalink), img.File)
+	img.ThumbURL = path.Join(utils.CleanPermalink(c.Permalink), thumbsDirName, img.File)
+
+	if err := s.copyImage(filepath.Join(outDir, filepath.FromSlash(img.File)), inFile); err != nil {
+		return err
+	}
+	thumbFile, err := s.makeThumbnail(inFile, thumbWidth)
+	if err != nil {
+		return err
+	}
+	log.Printf("G > %s", filepath.Join(OutDirName, filepath.FromSlash(img.ThumbURL)))
+	return s.fileWriter.CopyFile(filepath.Join(outDir, thumbsDirName, filepath.FromSlash(img.File)), thumbFile)
+}
+
+func (s *Site) RenderGallery(c *GalleryConfig) error {
+	if c.Dir == "" || c.Permalink == "" {
+		return fmt.Errorf("gallery must have dir and permalink")
+	}
+	m, err := loadGalleryImages(filepath.Join(s.BaseDir, c.Dir))
+	if err != nil {
+		return err
+	}
+	outDir := filepath.Join(s.BaseDir, OutDirName, filepath.FromSlash(c.Permalink))
+	pool := utils.NewPool()
+	for _, v := range m.Images {
+		img := v
+		if !pool.Add(func() error { return s.renderGalleryImage(c, img, outDir) }) {
+			break
+		}
+	}
+	if err := pool.Wait(); err != nil {
+		return err
+	}
+
+	layout := c.Layout
+	if layout == "" {
+		layout = DefaultGalleryLayout
+	}
+	title := m.Title
+	if title == "" {
+		title = filepath.Base(c.Dir)
+	}
+	p := &GalleryPage{
+		Filename: filepath.FromSlash(utils.AddIndexIfNeeded(c.Permalink)),
+		Images:   m.Images,
+	}
+	p.url = utils.CleanPermalink(c.Permalink)
+	p.content = galleryMarkup(m.Images)
+	p.meta = map[string]interface{}{
+		"title":       title,
+		"description": m.Description,
+		"images":      m.Images,
+		"layout":      layout,
+		"url":         p.url,
+	}
+	s.setBreadcrumbs(p.meta)
+	data, err := s.Layouts.RenderPage(p, layout)
+	if err != nil {
+		return err
+	}
+	log.Printf("G > %s\n", filepath.Join(OutDirName, p.Filename))
+	b, err := s.transformHTML(p.meta, p.Filename, []byte(data))
+	if err != nil {
+		return err
+	}
+	b, err = s.PageFilters.ApplyFilter(filepath.Ext(p.Filename), b)
+	if err != nil {
+		return err
+	}
+	if s.sitemap != nil {
+		if err := s.sitemap.Add(p.SitemapEntry()); err != nil {
+			return err
+		}
+	}
+	return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, p.Filename), b)
+}
+
+func (s *Site) RenderGalleries() error {
+	if len(s.Config.Galleries) == 0 {
+		return nil
+	}
+	log.Printf("* Rendering galleries.")
+	for _, c := range s.Config.Galleries {
+		if err := s.RenderGallery(c); err != nil {
+			return fmt.Errorf("gallery %s: %w", c.Dir, err)
+		}
+	}
+	return nil
+}
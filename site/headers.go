@@ -0,0 +1,142 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dchest/kkr/utils"
+)
+
+// site.yml -> cache_control:
+//
+// Rules are checked in order, the first matching one is used.
+type CacheControlRule struct {
+	// Path pattern: "/assets/**" matches everything under /assets/,
+	// patterns with slashes are matched against the whole path,
+	// others, such as "*.html", against the file name.
+	Path  string `yaml:"path"`
+	Value string `yaml:"value"` // e.g. "public, max-age=31536000, immutable"
+}
+
+// matchPath returns true if the URL path matches the pattern.
+// Paths ending with slash are matched as paths to index.html.
+func matchPath(pattern, p string) bool {
+	p = utils.AddIndexIfNeeded(p)
+	if strings.HasSuffix(pattern, "/**") {
+		return strings.HasPrefix(p, pattern[:len(pattern)-2])
+	}
+	if !strings.Contains(pattern, "/") {
+		p = path.Base(p)
+	}
+	ok, _ := path.Match(pattern, p)
+	return ok
+}
+
+func cacheControl(rules []CacheControlRule, p string) string {
+	for _, r := range rules {
+		if matchPath(r.Path, p) {
+			return r.Value
+		}
+	}
+	return ""
+}
+
+// LoadHeaders prepares headers sent by the server: Content-Security-Policy
+// from csp.yml, custom headers from site.yml -> headers:, and Cache-Control
+// from site.yml -> cache_control:.
+func (s *Site) LoadHeaders() error {
+	h := make(http.Header)
+	if len(s.CSP) > 0 {
+		h.Set("Content-Security-Policy", s.CSP.String())
+	}
+	for k, v := range s.Config.Headers {
+		h.Set(k, v)
+	}
+	for _, r := range s.Config.CacheControl {
+		if _, err := path.Match(r.Path, ""); err != nil || r.Path == "" {
+			return fmt.Errorf("cache_control: bad path pattern %q", r.Path)
+		}
+	}
+	s.headersMu.Lock()
+	defer s.headersMu.Unlock()
+	s.headers = h
+	s.cacheControl = s.Config.CacheControl
+	return nil
+}
+
+// headersHandler adds headers prepared by LoadHeaders
+// to responses of h.
+func (s *Site) headersHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.headersMu.Lock()
+		for k, v := range s.headers {
+			w.Header()[k] = v
+		}
+		if v := cacheControl(s.cacheControl, r.URL.Path); v != "" {
+			w.Header().Set("Cache-Control", v)
+		}
+		s.headersMu.Unlock()
+		h.ServeHTTP(w, r)
+	})
+}
+
+// RenderHeadersFile renders headers for all output files into
+// a file in format used by Netlify and Cloudflare Pages, if it's
+// configured in site.yml -> headers_file:.
+func (s *Site) RenderHeadersFile() error {
+	if s.Config.HeadersFile == "" {
+		return nil
+	}
+	log.Printf("* Rendering %s.", s.Config.HeadersFile)
+	var b bytes.Buffer
+	if len(s.headers) > 0 {
+		b.WriteString("/*\n")
+		keys := make([]string, 0, len(s.headers))
+		for k := range s.headers {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&b, "  %s: %s\n", k, s.headers.Get(k))
+		}
+	}
+	if len(s.Config.CacheControl) > 0 {
+		// Hosts combine values of all matching rules, so list
+		// files explicitly to keep the first match semantics.
+		outDir := filepath.Join(s.BaseDir, OutDirName)
+		err := filepath.Walk(outDir, func(name string, fi os.FileInfo, err error) error {
+			if err != nil || fi.IsDir() {
+				return err
+			}
+			relname, err := filepath.Rel(outDir, name)
+			if err != nil {
+				return err
+			}
+			if ext := filepath.Ext(name); ext == ".gz" || ext == ".br" {
+				if _, err := os.Stat(strings.TrimSuffix(name, ext)); err == nil {
+					return nil // precompressed
+				}
+			}
+			u := utils.CleanPermalink(filepath.ToSlash(relname))
+			if v := cacheControl(s.Config.CacheControl, u); v != "" {
+				fmt.Fprintf(&b, "%s\n  Cache-Control: %s\n", u, v)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, filepath.FromSlash(s.Config.HeadersFile)), b.Bytes())
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"path/filepath"
+)
+
+const HumansFileName = "humans.txt"
+
+type HumanConfig struct {
+	Name     string `yaml:"name"`
+	Role     string `yaml:"role"`
+	Contact  string `yaml:"contact"`
+	Location string `yaml:"location"`
+}
+
+// site.yml -> humans:
+type HumansConfig struct {
+	Team   []HumanConfig `yaml:"team"`
+	Thanks []HumanConfig `yaml:"thanks"`
+	Site   []string      `yaml:"site"` // additional lines for SITE section
+}
+
+func writeHumans(buf *bytes.Buffer, title string, humans []HumanConfig) {
+	if len(humans) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "/* %s */\n", title)
+	for _, h := range humans {
+		for _, v := range []struct{ key, value string }{
+			{"Name", h.Name},
+			{"Role", h.Role},
+			{"Contact", h.Contact},
+			{"Location", h.Location},
+		} {
+			if v.value != "" {
+				fmt.Fprintf(buf, "\t%s: %s\n", v.key, v.value)
+			}
+		}
+		buf.WriteString("\n")
+	}
+}
+
+// RenderHumans renders humans.txt (see humanstxt.org).
+// If the team is not configured, site author is used.
+func (s *Site) RenderHumans() error {
+	if s.Config.Humans == nil {
+		return nil
+	}
+	log.Printf("* Rendering %s.", HumansFileName)
+	c := s.Config.Humans
+	team := c.Team
+	if len(team) == 0 && s.Config.Author != "" {
+		team = []HumanConfig{{Name: s.Config.Author}}
+	}
+	var buf bytes.Buffer
+	writeHumans(&buf, "TEAM", team)
+	writeHumans(&buf, "THANKS", c.Thanks)
+	buf.WriteString("/* SITE */\n")
+	fmt.Fprintf(&buf, "\tLast update: %s\n", s.Config.Date.Format("2006/01/02"))
+	buf.WriteString("\tSoftware: Kukuruz\n")
+	for _, v := range c.Site {
+		fmt.Fprintf(&buf, "\t%s\n", v)
+	}
+	return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, HumansFileName), buf.Bytes())
+}
@@ -0,0 +1,448 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dchest/kkr/exif"
+	"github.com/dchest/kkr/imaging"
+	"github.com/dchest/kkr/imgtags"
+	"github.com/dchest/kkr/sandbox"
+	"github.com/dchest/kkr/utils"
+)
+
+// site.yml -> images:
+type ImagesConfig struct {
+	// StripMetadata removes EXIF (including GPS location), XMP and
+	// IPTC metadata from copied images.
+	StripMetadata bool `yaml:"strip_metadata"`
+	// Widths of images generated by `image` function,
+	// DefaultImageWidths if empty.
+	Widths []int `yaml:"widths"`
+	// Sizes is the default sizes attribute, DefaultImageSizes if empty.
+	Sizes string `yaml:"sizes"`
+	// Outname is the template of output names of generated images
+	// with :name, :width and :hash, DefaultImageOutname if empty.
+	// The extension is added to it.
+	Outname string `yaml:"outname"`
+	// Formats are commands by extensions converting generated images
+	// into additional formats, which are offered in <picture> sources,
+	// e.g. .webp: [magick, -, "webp:-"].
+	Formats map[string][]string `yaml:"formats"`
+	// Variants configures generation of images in other formats next
+	// to copied images.
+	Variants *ImageVariantsConfig `yaml:"variants"`
+	// Dimensions adds missing width and height attributes
+	// to <img> tags with local images in HTML pages.
+	Dimensions bool `yaml:"dimensions"`
+	// Lazy adds loading="lazy" and decoding="async" attributes
+	// to <img> tags, unless they have them.
+	Lazy bool `yaml:"lazy"`
+	// InlineMax is the maximum size in bytes of local images which
+	// are inlined into <img> tags as data URIs, 0 to not inline.
+	InlineMax int64 `yaml:"inline_max"`
+}
+
+// site.yml -> images: variants:
+//
+// Variants are written next to copied images with the extension
+// of the format appended, like precompressed files, for example,
+// photo.jpg.webp, so that servers can offer them to browsers that
+// accept the format.
+type ImageVariantsConfig struct {
+	Formats    []string `yaml:"formats"`    // webp, avif
+	Quality    int      `yaml:"quality"`    // DefaultVariantQuality if 0
+	Extensions []string `yaml:"extensions"` // of images to convert, jpg, jpeg, png if empty
+	// Commands override default commands converting images by format.
+	// Arguments :in, :out and :quality are replaced with names of
+	// input and output files and quality.
+	Commands map[string][]string `yaml:"commands"`
+}
+
+const DefaultVariantQuality = 80
+
+var defaultVariantCommands = map[string][]string{
+	"webp": {"cwebp", "-quiet", "-q", ":quality", ":in", "-o", ":out"},
+	"avif": {"avifenc", "-q", ":quality", ":in", ":out"},
+}
+
+const variantsDirName = "variants"
+
+const (
+	DefaultImageSizes   = "100vw"
+	DefaultImageOutname = "/images/:name-:width-:hash"
+)
+
+var DefaultImageWidths = []int{480, 800, 1200, 1600}
+
+const imagesDirName = "images"
+
+func (c *ImagesConfig) widths() []int {
+	if c == nil || len(c.Widths) == 0 {
+		return DefaultImageWidths
+	}
+	return c.Widths
+}
+
+func (c *ImagesConfig) sizes() string {
+	if c == nil || c.Sizes == "" {
+		return DefaultImageSizes
+	}
+	return c.Sizes
+}
+
+func (c *ImagesConfig) outname() string {
+	if c == nil || c.Outname == "" {
+		return DefaultImageOutname
+	}
+	return c.Outname
+}
+
+func (c *ImagesConfig) formats() map[string][]string {
+	if c == nil {
+		return nil
+	}
+	return c.Formats
+}
+
+func (s *Site) stripImageMetadata() bool {
+	return s.Config.Images != nil && s.Config.Images.StripMetadata
+}
+
+// copyImage copies image file, stripping its metadata and generating
+// its variants in other formats if configured.
+func (s *Site) copyImage(outFile, inFile string) error {
+	if !s.stripImageMetadata() || !imaging.IsImage(inFile) {
+		if err := s.fileWriter.CopyFile(outFile, inFile); err != nil {
+			return err
+		}
+		return s.writeImageVariants(outFile)
+	}
+	b, err := ioutil.ReadFile(inFile)
+	if err != nil {
+		return err
+	}
+	b, err = exif.Strip(b)
+	if err != nil {
+		return fmt.Errorf("%s: %w", inFile, err)
+	}
+	if err := s.fileWriter.WriteFile(outFile, b); err != nil {
+		return err
+	}
+	return s.writeImageVariants(outFile)
+}
+
+// variantsConfig returns config of image variants,
+// or nil if they are not generated.
+func (s *Site) variantsConfig() *ImageVariantsConfig {
+	if s.devMode || s.Config.Images == nil {
+		return nil
+	}
+	return s.Config.Images.Variants
+}
+
+// writeImageVariants writes variants of the copied image in other
+// formats next to it if configured.
+func (s *Site) writeImageVariants(outFile string) error {
+	c := s.variantsConfig()
+	if c == nil || len(c.Formats) == 0 {
+		return nil
+	}
+	exts := []string{".jpg", ".jpeg", ".png"}
+	if len(c.Extensions) > 0 {
+		exts = make([]string, len(c.Extensions))
+		for i, v := range c.Extensions {
+			exts[i] = "." + strings.ToLower(v)
+		}
+	}
+	if !utils.HasFileExt(strings.ToLower(outFile), exts) {
+		return nil
+	}
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(outFile)), ".")
+	b, err := ioutil.ReadFile(outFile)
+	if err != nil {
+		return err
+	}
+	quality := c.Quality
+	if quality == 0 {
+		quality = DefaultVariantQuality
+	}
+	for _, format := range c.Formats {
+		command := c.Commands[format]
+		if len(command) == 0 {
+			command = defaultVariantCommands[format]
+		}
+		if len(command) == 0 {
+			return fmt.Errorf("images: no command for variants in %q format", format)
+		}
+		args := make([]string, len(command))
+		for i, a := range command {
+			args[i] = strings.NewReplacer(":quality", strconv.Itoa(quality)).Replace(a)
+		}
+		kind := strings.Join(append([]string{"variant", ext}, args...), "\x00")
+		converted, err := s.variantsCache.Do(kind, b, func(in []byte) ([]byte, error) {
+			log.Printf("R %s", strings.TrimPrefix(outFile, s.BaseDir+string(filepath.Separator))+"."+format)
+			return convertImage(args, "."+ext, "."+format, in)
+		})
+		if err != nil {
+			return fmt.Errorf("images: %s to %s: %w", outFile, format, err)
+		}
+		if err := s.fileWriter.WriteFile(outFile+"."+format, converted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertImage runs the command converting the image with the given
+// extensions of input and output files, replacing :in and :out
+// arguments with their names, and returns the converted image.
+func convertImage(command []string, inExt, outExt string, in []byte) ([]byte, error) {
+	dir, err := ioutil.TempDir("", "kkr-image-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	inFile := filepath.Join(dir, "in"+inExt)
+	outFile := filepath.Join(dir, "out"+outExt)
+	if err := ioutil.WriteFile(inFile, in, 0644); err != nil {
+		return nil, err
+	}
+	args := make([]string, len(command))
+	r := strings.NewReplacer(":in", inFile, ":out", outFile)
+	for i, a := range command {
+		args[i] = r.Replace(a)
+	}
+	cmd, err := sandbox.Command(args[0], args[1:]...)
+	if err != nil {
+		return nil, err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return ioutil.ReadFile(outFile)
+}
+
+// readExif returns EXIF information from the image file
+// or nil if it doesn't have any.
+func readExif(filename string) (*exif.Info, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	info, err := exif.Read(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return info, nil
+}
+
+// exifFunc is a template function which returns EXIF information
+// of the image in pages directory or nil.
+func (s *Site) exifFunc(name string) (*exif.Info, error) {
+	return readExif(filepath.Join(s.BaseDir, PagesDirName, filepath.FromSlash(name)))
+}
+
+// imageWidths returns widths to generate for the image with the given
+// width: configured widths smaller than it, and the image width itself
+// unless it's larger than all configured widths.
+func imageWidths(width int, widths []int) []int {
+	var out []int
+	largest := 0
+	for _, w := range widths {
+		if w < width {
+			out = append(out, w)
+		}
+		if w > largest {
+			largest = w
+		}
+	}
+	if width <= largest || len(out) == 0 {
+		out = append(out, width)
+	}
+	sort.Ints(out)
+	return out
+}
+
+// writeImage writes the generated image into the output directory
+// under the configured name and returns its URL.
+func (s *Site) writeImage(name string, width int, ext string, b []byte) (string, error) {
+	outname := strings.NewReplacer(
+		":name", utils.ReplaceFileExt(path.Base(name), ""),
+		":width", strconv.Itoa(width),
+	).Replace(s.Config.Images.outname())
+	url := path.Join("/", utils.TemplatedHash(outname, b)+ext)
+	filename := filepath.Join(OutDirName, filepath.FromSlash(url))
+	log.Printf("G > %s", filename)
+	return url, s.fileWriter.WriteFile(filepath.Join(s.BaseDir, filename), b)
+}
+
+// imageSrcset returns srcset attribute value from URLs by widths.
+func imageSrcset(urls []string, widths []int) string {
+	parts := make([]string, len(urls))
+	for i, u := range urls {
+		parts[i] = fmt.Sprintf("%s %dw", u, widths[i])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// imageFunc is a template function which resizes the image from pages
+// directory to the configured widths and returns <img> with srcset for
+// them, or <picture> with sources in additional formats if configured.
+// Optional argument overrides the sizes attribute.
+func (s *Site) imageFunc(name, alt string, sizes ...string) (string, error) {
+	s.imagesMu.Lock()
+	defer s.imagesMu.Unlock()
+
+	c := s.Config.Images
+	if len(sizes) > 1 {
+		return "", fmt.Errorf("image: expected at most one sizes argument, got %d", len(sizes))
+	}
+	sizesAttr := c.sizes()
+	if len(sizes) == 1 {
+		sizesAttr = sizes[0]
+	}
+	name = path.Join("/", name)
+	inFile := filepath.Join(s.BaseDir, PagesDirName, filepath.FromSlash(name))
+	if !imaging.IsImage(inFile) {
+		return "", fmt.Errorf("image: %s is not a supported image", name)
+	}
+	w, h, err := imaging.DecodeConfig(inFile)
+	if err != nil {
+		return "", err
+	}
+	widths := imageWidths(w, c.widths())
+	exts := make([]string, 0, len(c.formats()))
+	for ext := range c.formats() {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	var urls []string
+	formatURLs := make(map[string][]string)
+	for _, width := range widths {
+		resized, err := s.makeThumbnail(inFile, width)
+		if err != nil {
+			return "", err
+		}
+		b, err := ioutil.ReadFile(resized)
+		if err != nil {
+			return "", err
+		}
+		url, err := s.writeImage(name, width, strings.ToLower(path.Ext(name)), b)
+		if err != nil {
+			return "", err
+		}
+		urls = append(urls, url)
+		for _, ext := range exts {
+			converted, err := s.runCached(imagesDirName, c.Formats[ext], b, ext, "image "+ext)
+			if err != nil {
+				return "", fmt.Errorf("image: converting %s to %s: %w", name, ext, err)
+			}
+			url, err := s.writeImage(name, width, ext, converted)
+			if err != nil {
+				return "", err
+			}
+			formatURLs[ext] = append(formatURLs[ext], url)
+		}
+	}
+	largest := widths[len(widths)-1]
+	width, height := imaging.FitSize(w, h, largest)
+	img := fmt.Sprintf(`<img src="%s" srcset="%s" sizes="%s" width="%d" height="%d" alt="%s" loading="lazy" decoding="async">`,
+		html.EscapeString(urls[len(urls)-1]), html.EscapeString(imageSrcset(urls, widths)),
+		html.EscapeString(sizesAttr), width, height, html.EscapeString(alt))
+	if len(exts) == 0 {
+		return img, nil
+	}
+	var b strings.Builder
+	b.WriteString("<picture>")
+	for _, ext := range exts {
+		typ := mime.TypeByExtension(ext)
+		if typ == "" {
+			typ = "image/" + strings.TrimPrefix(ext, ".")
+		}
+		fmt.Fprintf(&b, `<source type="%s" srcset="%s" sizes="%s">`, html.EscapeString(typ),
+			html.EscapeString(imageSrcset(formatURLs[ext], widths)), html.EscapeString(sizesAttr))
+	}
+	b.WriteString(img)
+	b.WriteString("</picture>")
+	return b.String(), nil
+}
+
+// imageFiles returns names of files which can be the image with the
+// given URL path: in pages directory, directories of galleries, which
+// are rendered after pages, and the output directory.
+func (s *Site) imageFiles(urlPath string) []string {
+	urlPath = path.Clean(urlPath)
+	names := []string{filepath.Join(s.BaseDir, PagesDirName, filepath.FromSlash(urlPath))}
+	for _, g := range s.Config.Galleries {
+		prefix := strings.TrimSuffix(utils.CleanPermalink(g.Permalink), "/") + "/"
+		if g.Dir != "" && strings.HasPrefix(urlPath, prefix) {
+			rel := urlPath[len(prefix):]
+			names = append(names, filepath.Join(s.BaseDir, g.Dir, filepath.FromSlash(rel)))
+		}
+	}
+	return append(names, filepath.Join(s.BaseDir, OutDirName, filepath.FromSlash(urlPath)))
+}
+
+// imageSize returns dimensions of the image by its URL path,
+// or false if it's not found or not an image.
+func (s *Site) imageSize(urlPath string) (width, height int, ok bool) {
+	for _, filename := range s.imageFiles(urlPath) {
+		fi, err := os.Stat(filename)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		key := fmt.Sprintf("%s %d %d", filename, fi.Size(), fi.ModTime().UnixNano())
+		s.imagesMu.Lock()
+		size, cached := s.imageSizes[key]
+		s.imagesMu.Unlock()
+		if !cached {
+			size[0], size[1], err = imaging.DecodeConfig(filename)
+			if err != nil {
+				size = [2]int{} // unsupported format
+			}
+			s.imagesMu.Lock()
+			if s.imageSizes == nil {
+				s.imageSizes = make(map[string][2]int)
+			}
+			s.imageSizes[key] = size
+			s.imagesMu.Unlock()
+		}
+		return size[0], size[1], size[0] > 0
+	}
+	return 0, 0, false
+}
+
+// addImageAttributes adds missing dimensions and lazy loading attributes
+// to <img> tags in HTML and inlines small images if configured.
+func (s *Site) addImageAttributes(meta map[string]interface{}, data []byte) ([]byte, error) {
+	c := s.Config.Images
+	if c == nil || (!c.Dimensions && !c.Lazy && c.InlineMax <= 0) {
+		return data, nil
+	}
+	opts := &imgtags.Options{Lazy: c.Lazy}
+	if c.Dimensions {
+		opts.Size = s.imageSize
+	}
+	if c.InlineMax > 0 {
+		opts.Inline = s.inlineImage
+	}
+	url, _ := meta["url"].(string)
+	return imgtags.Apply(data, path.Join("/", url), opts)
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"os"
+	"path"
+)
+
+// addInlineHashes adds hashes of assets with `csp: true`
+// to script-src and style-src of CSP.
+func (s *Site) addInlineHashes() error {
+	sources, err := s.Assets.CSPSources()
+	if err != nil {
+		return err
+	}
+	if len(sources) == 0 || len(s.CSP) == 0 {
+		return nil
+	}
+	s.CSP = s.CSP.WithSources(sources)
+	return s.LoadHeaders()
+}
+
+// inlineFunc returns the processed asset wrapped into <style>
+// or <script> element depending on its type.
+func (s *Site) inlineFunc(name string) (string, error) {
+	a := s.Assets.Get(name)
+	if a == nil {
+		return "", fmt.Errorf("asset %q not found", name)
+	}
+	var tag string
+	switch a.Kind() {
+	case "css":
+		tag = "style"
+	case "js":
+		tag = "script"
+	default:
+		return "", fmt.Errorf("inline: unknown type of asset %q, set type: css or js", name)
+	}
+	if bytes.Contains(bytes.ToLower(a.Result), []byte("</"+tag)) {
+		return "", fmt.Errorf("inline: asset %q contains </%s", name, tag)
+	}
+	return "<" + tag + ">" + string(a.Result) + "</" + tag + ">", nil
+}
+
+// inlineImage returns data URI of the local image by its URL path
+// if its size doesn't exceed site.yml -> images: inline_max.
+func (s *Site) inlineImage(urlPath string) (string, bool) {
+	typ := mime.TypeByExtension(path.Ext(urlPath))
+	if typ == "" {
+		return "", false
+	}
+	for _, filename := range s.imageFiles(urlPath) {
+		fi, err := os.Stat(filename)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		if fi.Size() > s.Config.Images.InlineMax {
+			return "", false
+		}
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return "", false
+		}
+		return "data:" + typ + ";base64," + base64.StdEncoding.EncodeToString(b), true
+	}
+	return "", false
+}
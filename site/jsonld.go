@@ -0,0 +1,60 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"time"
+
+	"github.com/dchest/kkr/jsonld"
+)
+
+// searchQueryParam is the URL parameter with query used by search UI.
+const searchQueryParam = "query"
+
+// pageJSONLD returns JSON-LD script with WebSite, BlogPosting (for posts)
+// and BreadcrumbList (from `breadcrumbs` meta) objects for the page with
+// the given meta.
+func (s *Site) pageJSONLD(meta map[string]interface{}) (string, error) {
+	siteURL := s.Config.URL + "/"
+	searchURL := ""
+	if c := s.Config.Search; c != nil && c.Page != "" {
+		searchURL = s.Config.URL + c.Page
+	}
+	objects := []jsonld.Object{
+		jsonld.WebSite(s.Config.Name, siteURL, searchURL, searchQueryParam),
+	}
+	pageURL, _ := meta["url"].(string)
+	title, _ := meta["title"].(string)
+	if isPost, _ := meta["is_post"].(bool); isPost {
+		p := &jsonld.Post{
+			URL:    s.Config.URL + pageURL,
+			Title:  title,
+			Author: s.Config.Author,
+		}
+		p.Description, _ = meta["description"].(string)
+		p.Image, _ = meta["image"].(string)
+		if author, ok := meta["author"].(string); ok {
+			p.Author = author
+		}
+		p.Published, _ = meta["date"].(time.Time)
+		if updated, ok := meta["updated"].(time.Time); ok {
+			p.Modified = updated
+		}
+		p.Keywords, _ = meta["tags"].([]string)
+		if categories, _ := meta["categories"].([]string); len(categories) > 0 {
+			p.Section = categories[0]
+		}
+		objects = append(objects, jsonld.BlogPosting(p, siteURL))
+	}
+	if crumbs, _ := meta["breadcrumbs"].([]map[string]interface{}); len(crumbs) > 1 {
+		items := make([]jsonld.Crumb, len(crumbs))
+		for i, c := range crumbs {
+			items[i].Name, _ = c["title"].(string)
+			items[i].URL = s.Config.URL + c["url"].(string)
+		}
+		objects = append(objects, jsonld.BreadcrumbList(items))
+	}
+	return jsonld.Script(objects...)
+}
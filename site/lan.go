@@ -0,0 +1,86 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"log"
+	"net"
+
+	"github.com/dchest/kkr/qrcode"
+)
+
+// isLocalHost returns true if host is a loopback address or localhost.
+func isLocalHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// lanAddrs returns non-loopback unicast addresses of network interfaces.
+func lanAddrs() ([]net.IP, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, err
+	}
+	var ips []net.IP
+	for _, a := range addrs {
+		n, ok := a.(*net.IPNet)
+		if !ok || n.IP.IsLoopback() || n.IP.IsLinkLocalUnicast() || !n.IP.IsGlobalUnicast() {
+			continue
+		}
+		ips = append(ips, n.IP)
+	}
+	return ips, nil
+}
+
+// reportServeAddr logs URLs at which the site served at addr can be
+// reached from other devices, prints a QR code for the first of them
+// if enabled, and warns if drafts are exposed.
+func (s *Site) reportServeAddr(addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if isLocalHost(host) {
+		return nil
+	}
+	var urls []string
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		ips, err := lanAddrs()
+		if err != nil {
+			return err
+		}
+		for _, ip := range ips {
+			if ip.To4() == nil {
+				continue // IPv4 addresses are easier to type
+			}
+			urls = append(urls, s.ServeURL(net.JoinHostPort(ip.String(), port)))
+		}
+	} else {
+		urls = append(urls, s.ServeURL(addr))
+	}
+	for _, u := range urls {
+		log.Printf("  %s", u)
+	}
+	if s.drafts {
+		log.Printf("! Serving drafts on non-local address %s.", addr)
+	}
+	if s.qrCode && len(urls) > 0 {
+		c, err := qrcode.Encode(urls[0])
+		if err != nil {
+			return err
+		}
+		log.Print(c.Terminal())
+	}
+	return nil
+}
+
+// SetQRCode sets whether to print a QR code with the LAN URL
+// of the served site.
+func (s *Site) SetQRCode(qr bool) {
+	s.qrCode = qr
+}
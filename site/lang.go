@@ -0,0 +1,88 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"strings"
+)
+
+const DefaultCharset = "utf-8"
+
+// rtlLangs are languages written right to left.
+var rtlLangs = map[string]bool{
+	"ar":  true,
+	"arc": true,
+	"ckb": true,
+	"dv":  true,
+	"fa":  true,
+	"he":  true,
+	"ks":  true,
+	"ps":  true,
+	"sd":  true,
+	"ug":  true,
+	"ur":  true,
+	"yi":  true,
+}
+
+// textDirection returns "rtl" for languages written right to left,
+// such as "ar" or "he-IL", and "ltr" for others.
+func textDirection(lang string) string {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	if rtlLangs[lang] {
+		return "rtl"
+	}
+	return "ltr"
+}
+
+func checkDirection(dir string) error {
+	switch dir {
+	case "", "ltr", "rtl", "auto":
+		return nil
+	}
+	return fmt.Errorf("unknown text direction %q", dir)
+}
+
+// siteLang returns the default language of the site from site.yml
+// -> lang, or, if it's not set, from typography or hyphenation config.
+func (s *Site) siteLang() string {
+	if s.Config.Lang != "" {
+		return s.Config.Lang
+	}
+	if c := s.Config.Typography; c != nil && c.Lang != "" {
+		return c.Lang
+	}
+	if c := s.Config.Hyphenation; c != nil && c.Lang != "" {
+		return c.Lang
+	}
+	return ""
+}
+
+// langFunc returns language of the page from its `lang` meta
+// or the site language.
+func (s *Site) langFunc(meta map[string]interface{}) string {
+	return pageLang(meta, s.siteLang())
+}
+
+// dirFunc returns text direction of the page from its `dir` meta,
+// or from its `lang` meta, or the site direction.
+func (s *Site) dirFunc(meta map[string]interface{}) (string, error) {
+	if v, ok := meta["dir"].(string); ok && v != "" {
+		if err := checkDirection(v); err != nil {
+			return "", err
+		}
+		return v, nil
+	}
+	if v, ok := meta["lang"].(string); ok && v != "" {
+		return textDirection(v), nil
+	}
+	if s.Config.Dir != "" {
+		return s.Config.Dir, nil
+	}
+	return textDirection(s.siteLang()), nil
+}
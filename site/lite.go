@@ -0,0 +1,125 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/dchest/kkr/analytics"
+)
+
+const (
+	DefaultLitePrefix = "/lite"
+	DefaultLiteLayout = "lite"
+)
+
+// site.yml -> lite: (list)
+//
+// Posts (and, optionally, pages) in the section also get a lightweight version,
+// rendered with a minimal layout without scripts, at the URL with
+// the prefix, for readers on slow connections. The full and the
+// lightweight versions link to each other with rel=alternate and
+// rel=canonical, and the former gets `lite_url` meta.
+type LiteConfig struct {
+	Section string `yaml:"section"` // URL prefix of posts and pages, e.g. /blog/
+	Prefix  string `yaml:"prefix"`  // URL prefix of lightweight pages, DefaultLitePrefix if empty
+	Layout  string `yaml:"layout"`  // DefaultLiteLayout if empty
+	Pages   bool   `yaml:"pages"`   // also render pages in the section, not only posts
+}
+
+func (c *LiteConfig) prefix() string {
+	if c.Prefix == "" {
+		return DefaultLitePrefix
+	}
+	return "/" + strings.Trim(c.Prefix, "/")
+}
+
+func (c *LiteConfig) layout() string {
+	if c.Layout == "" {
+		return DefaultLiteLayout
+	}
+	return c.Layout
+}
+
+// liteSection returns config of the lightweight version for the page
+// or nil if it doesn't have one: the page is not HTML, is not in any
+// of the sections (or is not a post, unless the section includes pages),
+// or has `lite: false` meta.
+func (s *Site) liteSection(p *Page, isPost bool) *LiteConfig {
+	if len(s.Config.Lite) == 0 || !isHTMLURL(pageKey(p.url)) {
+		return nil
+	}
+	if enabled, ok := p.meta["lite"].(bool); ok && !enabled {
+		return nil
+	}
+	for _, c := range s.Config.Lite {
+		if (isPost || c.Pages) && strings.HasPrefix(p.url, c.Section) && !strings.HasPrefix(p.url, c.prefix()+"/") {
+			return c
+		}
+	}
+	return nil
+}
+
+// prepareLite sets `lite_url` meta of the page which has a lightweight
+// version and returns its config, or nil if it doesn't have one.
+func (s *Site) prepareLite(p *Page, isPost bool) *LiteConfig {
+	c := s.liteSection(p, isPost)
+	if c == nil {
+		return nil
+	}
+	p.meta["lite_url"] = c.prefix() + pageKey(p.url)
+	return c
+}
+
+// liteLinks adds rel=alternate link to the lightweight version to
+// the rendered page, if it has one.
+func (s *Site) liteLinks(meta map[string]interface{}, data []byte) []byte {
+	u, ok := meta["lite_url"].(string)
+	if !ok {
+		return data
+	}
+	return analytics.Inject(data, fmt.Sprintf(`<link rel="alternate" href="%s" title="Lightweight version">`+"\n", html.EscapeString(u)))
+}
+
+var scriptsRx = regexp.MustCompile(`(?is)<script\b[^>]*>.*?</script>\s*`)
+
+// renderLite renders the lightweight version of the page, without
+// scripts and analytics, linking to the full version as canonical.
+func (s *Site) renderLite(p *Page, c *LiteConfig) error {
+	lp := *p
+	lp.url = p.meta["lite_url"].(string)
+	lp.Filename = filepath.Join(filepath.FromSlash(strings.Trim(c.prefix(), "/")), p.Filename)
+	lp.meta = make(map[string]interface{}, len(p.meta)+2)
+	for k, v := range p.meta {
+		lp.meta[k] = v
+	}
+	lp.meta["url"] = lp.url
+	lp.meta["full_url"] = p.url
+	lp.meta["analytics"] = false
+	lp.meta["layout"] = c.layout()
+	delete(lp.meta, "lite_url")
+	data, err := s.Layouts.RenderPage(&lp, c.layout())
+	if err != nil {
+		return err
+	}
+	log.Printf("P > %s\n", filepath.Join(OutDirName, lp.Filename))
+	b, err := s.transformHTML(lp.meta, lp.Filename, []byte(data))
+	if err != nil {
+		return err
+	}
+	b = scriptsRx.ReplaceAll(b, nil)
+	canonical := s.Config.URL + pageKey(p.url)
+	b = analytics.Inject(b, fmt.Sprintf(`<link rel="canonical" href="%s">`+"\n", html.EscapeString(canonical)))
+	b, err = s.PageFilters.ApplyFilter(filepath.Ext(lp.Filename), b)
+	if err != nil {
+		return err
+	}
+	return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, lp.Filename), b)
+}
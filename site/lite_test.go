@@ -0,0 +1,34 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import "testing"
+
+func TestLiteExcludedFromSearch(t *testing.T) {
+	s := &Site{
+		Config: &Config{
+			Search: &SearchConfig{Index: "/search/search-index.json"},
+			Lite:   []*LiteConfig{{Section: "/blog/"}, {Section: "/docs/", Prefix: "text/"}},
+		},
+		draftURLs: map[string]bool{"/blog/2013/11/01/upcoming/": true},
+	}
+	var tests = []struct {
+		url      string
+		excluded bool
+	}{
+		{"/blog/2013/01/01/new-year/", false},
+		{"/lite/blog/2013/01/01/new-year/", true},
+		{"/blog/2013/11/01/upcoming/", true},
+		{"/lite/blog/2013/11/01/upcoming/", true},
+		{"/text/docs/intro/", true},
+		{"/docs/intro/", false},
+		{"/literature/", false},
+	}
+	for _, v := range tests {
+		if excluded := s.isExcludedFromSearch(v.url); excluded != v.excluded {
+			t.Errorf("%s: excluded = %t, expected %t", v.url, excluded, v.excluded)
+		}
+	}
+}
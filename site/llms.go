@@ -0,0 +1,146 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/dchest/kkr/utils"
+)
+
+const (
+	LLMsFileName     = "llms.txt"
+	LLMsFullFileName = "llms-full.txt"
+)
+
+type LLMsSectionConfig struct {
+	Title string   `yaml:"title"`
+	Pages []string `yaml:"pages"` // page URLs
+}
+
+// site.yml -> llms:
+type LLMsConfig struct {
+	Description string              `yaml:"description"`
+	Details     string              `yaml:"details"`
+	Sections    []LLMsSectionConfig `yaml:"sections"`
+	Posts       bool                `yaml:"posts"` // add section with all posts
+	Full        bool                `yaml:"full"`  // also render llms-full.txt
+}
+
+type llmsLink struct {
+	URL         string
+	Title       string
+	Description string
+	Content     string
+}
+
+func metaString(meta map[string]interface{}, key string) string {
+	if v, ok := meta[key]; ok && v != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return ""
+}
+
+var emptyLinesRx = regexp.MustCompile(`\n\s*\n`)
+
+// plainText strips tags from HTML, trims lines and
+// collapses consecutive empty lines.
+func plainText(html string) string {
+	lines := strings.Split(utils.StripHTMLTags(html), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+	return emptyLinesRx.ReplaceAllString(strings.TrimSpace(strings.Join(lines, "\n")), "\n\n")
+}
+
+func (s *Site) newLLMsLink(p *Page) (llmsLink, error) {
+	content, err := s.Layouts.RenderContent(p)
+	if err != nil {
+		return llmsLink{}, err
+	}
+	return llmsLink{
+		URL:         p.url,
+		Title:       metaString(p.meta, "title"),
+		Description: metaString(p.meta, "description"),
+		Content:     plainText(content),
+	}, nil
+}
+
+// RenderLLMs renders llms.txt (see llmstxt.org) and, optionally,
+// llms-full.txt with plain text content of the listed pages.
+func (s *Site) RenderLLMs() error {
+	c := s.Config.LLMs
+	if c == nil {
+		return nil
+	}
+	log.Printf("* Rendering %s.", LLMsFileName)
+	type section struct {
+		title string
+		links []llmsLink
+	}
+	sections := make([]section, 0, len(c.Sections)+1)
+	for _, sc := range c.Sections {
+		sec := section{title: sc.Title}
+		for _, u := range sc.Pages {
+			p := s.renderedPage(u)
+			if p == nil {
+				return fmt.Errorf("llms: page %q not found", u)
+			}
+			l, err := s.newLLMsLink(p)
+			if err != nil {
+				return err
+			}
+			sec.links = append(sec.links, l)
+		}
+		sections = append(sections, sec)
+	}
+	if c.Posts && len(s.Config.Posts) > 0 {
+		sec := section{title: "Posts"}
+		for _, p := range s.Config.Posts {
+			l, err := s.newLLMsLink(&p.Page)
+			if err != nil {
+				return err
+			}
+			sec.links = append(sec.links, l)
+		}
+		sections = append(sections, sec)
+	}
+
+	var buf, full bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n\n", s.Config.Name)
+	if c.Description != "" {
+		fmt.Fprintf(&buf, "> %s\n\n", strings.TrimSpace(c.Description))
+	}
+	if c.Details != "" {
+		fmt.Fprintf(&buf, "%s\n\n", strings.TrimSpace(c.Details))
+	}
+	full.Write(buf.Bytes())
+	for _, sec := range sections {
+		fmt.Fprintf(&buf, "## %s\n\n", sec.title)
+		for _, l := range sec.links {
+			fmt.Fprintf(&buf, "- [%s](%s%s)", l.Title, s.Config.URL, l.URL)
+			if l.Description != "" {
+				fmt.Fprintf(&buf, ": %s", l.Description)
+			}
+			buf.WriteString("\n")
+			fmt.Fprintf(&full, "## %s\n\nURL: %s%s\n\n%s\n\n", l.Title, s.Config.URL, l.URL, l.Content)
+		}
+		buf.WriteString("\n")
+	}
+	outDir := filepath.Join(s.BaseDir, OutDirName)
+	if err := s.fileWriter.WriteFile(filepath.Join(outDir, LLMsFileName), buf.Bytes()); err != nil {
+		return err
+	}
+	if c.Full {
+		log.Printf("* Rendering %s.", LLMsFullFileName)
+		return s.fileWriter.WriteFile(filepath.Join(outDir, LLMsFullFileName), full.Bytes())
+	}
+	return nil
+}
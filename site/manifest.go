@@ -0,0 +1,86 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestEntry describes an output file in manifest.
+type ManifestEntry struct {
+	SHA256 string `json:"sha256"` // hex-encoded
+	Size   int64  `json:"size"`
+	ETag   string `json:"etag"` // strong ETag derived from SHA256
+}
+
+// Manifest maps paths of output files relative
+// to output directory (with slashes) to their entries.
+type Manifest struct {
+	Files map[string]*ManifestEntry `json:"files"`
+}
+
+// manifestETag returns ETag for the file with hex-encoded hash.
+func manifestETag(sha256hex string) string {
+	return `"` + sha256hex[:32] + `"`
+}
+
+// MakeManifest returns manifest of files in output directory,
+// except for the manifest itself.
+func (s *Site) MakeManifest() (*Manifest, error) {
+	m := &Manifest{Files: make(map[string]*ManifestEntry)}
+	outDir := filepath.Join(s.BaseDir, OutDirName)
+	err := filepath.Walk(outDir, func(name string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+		relname, err := filepath.Rel(outDir, name)
+		if err != nil {
+			return err
+		}
+		relname = filepath.ToSlash(relname)
+		if s.Config.Manifest != "" && strings.HasPrefix(relname, strings.TrimPrefix(s.Config.Manifest, "/")) {
+			return nil // manifest and its compressed versions
+		}
+		sum, err := hashFile(name)
+		if err != nil {
+			return err
+		}
+		m.Files[relname] = &ManifestEntry{
+			SHA256: sum,
+			Size:   fi.Size(),
+			ETag:   manifestETag(sum),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RenderManifest writes manifest of output files into output
+// directory if it's configured in site.yml -> manifest:.
+// Deploy tools can use it to verify uploads or to upload only
+// changed files.
+func (s *Site) RenderManifest() error {
+	if s.Config.Manifest == "" {
+		return nil
+	}
+	m, err := s.MakeManifest()
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(OutDirName, filepath.FromSlash(strings.TrimPrefix(s.Config.Manifest, "/")))
+	log.Printf("* Rendering %s (%d files).", filename, len(m.Files))
+	return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, filename), b)
+}
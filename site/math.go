@@ -0,0 +1,29 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"github.com/dchest/kkr/mathtex"
+)
+
+const mathDirName = "math"
+
+// renderMathHTML replaces TeX math in HTML with the output of commands
+// configured in site.yml markup: math. Pages can set `math: false`
+// meta to disable it.
+func (s *Site) renderMathHTML(meta map[string]interface{}, data []byte) ([]byte, error) {
+	if s.Config.Markup.Math == nil {
+		return data, nil
+	}
+	if enabled, ok := meta["math"].(bool); ok && !enabled {
+		return data, nil
+	}
+	return mathtex.Replace(data, s.renderMath)
+}
+
+// renderMath runs the math command with TeX as input and returns its output.
+func (s *Site) renderMath(tex string, display bool) ([]byte, error) {
+	return s.runCached(mathDirName, s.Config.Markup.Math.Command(display), []byte(tex), ".html", "math")
+}
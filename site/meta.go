@@ -0,0 +1,91 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/metaedit"
+	"github.com/dchest/kkr/metafile"
+)
+
+// EditMeta applies edit operations to front matter of posts and pages
+// matching the condition, rewriting their files. Files which can't be
+// edited, for example, because of malformed front matter, are logged
+// and skipped, and an error is returned after editing other files.
+// It returns the number of changed files.
+func (s *Site) EditMeta(cond *metaedit.Condition, ops []metaedit.Op) (n int, err error) {
+	failed := 0
+	for _, dirName := range []string{PostsDirName, PagesDirName} {
+		dir := filepath.Join(s.BaseDir, dirName)
+		err = filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if fi.IsDir() || s.isIgnoredFile(fi.Name()) {
+				return nil
+			}
+			relname, err := filepath.Rel(s.BaseDir, path)
+			if err != nil {
+				return err
+			}
+			changed, err := editFileMeta(path, fi.Mode(), cond, ops)
+			if err != nil {
+				log.Printf("! %s: %s", relname, err)
+				failed++
+				return nil
+			}
+			if changed {
+				log.Printf("M %s", relname)
+				n++
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return n, err
+		}
+	}
+	if failed > 0 {
+		return n, fmt.Errorf("failed to edit %d files", failed)
+	}
+	return n, nil
+}
+
+func editFileMeta(filename string, mode os.FileMode, cond *metaedit.Condition, ops []metaedit.Op) (bool, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return false, err
+	}
+	header, content, format, err := metafile.Split(filename, b)
+	if err != nil {
+		return false, err
+	}
+	if format == "" {
+		return false, nil // not a page or post
+	}
+	meta, err := metafile.Decode(format, header)
+	if err != nil {
+		return false, err
+	}
+	if !cond.Match(meta) {
+		return false, nil
+	}
+	if format != metafile.YAML {
+		return false, fmt.Errorf("editing %s front matter is not supported, convert it to YAML", strings.ToUpper(format))
+	}
+	header, changed, err := metaedit.Apply(header, ops)
+	if err != nil {
+		return false, err
+	}
+	if !changed {
+		return false, nil
+	}
+	return true, ioutil.WriteFile(filename, metafile.Join(format, header, content), mode)
+}
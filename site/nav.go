@@ -0,0 +1,222 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/dchest/kkr/utils"
+)
+
+// navPage is a page in the navigation tree.
+type navPage struct {
+	url      string
+	title    string
+	weight   float64
+	parent   *navPage
+	children []*navPage
+}
+
+// navWeight returns `weight` meta of the page, or 0 if it's not set.
+func navWeight(meta map[string]interface{}) float64 {
+	switch w := meta["weight"].(type) {
+	case int:
+		return float64(w)
+	case float64:
+		return w
+	}
+	return 0
+}
+
+// addNavPage adds the page to the navigation tree unless it's not
+// HTML, has no title, or has `nav: false` meta.
+func (s *Site) addNavPage(url, outName string, meta map[string]interface{}) {
+	title, _ := meta["title"].(string)
+	if title == "" || !utils.HasFileExt(outName, HTMLExtensions) {
+		return
+	}
+	if v, ok := meta["nav"].(bool); ok && !v {
+		return
+	}
+	s.navPages[pageKey(url)] = &navPage{url: url, title: title, weight: navWeight(meta)}
+}
+
+// linkNavPages links pages to their sections: the parent of a page is
+// the index page of the closest section containing it, as in breadcrumbs.
+// Versions of docs section get separate trees under their index pages.
+// Pages are ordered by weight, then by title.
+func (s *Site) linkNavPages() {
+	s.navRoots = nil
+	for key, p := range s.navPages {
+		p.parent, p.children = nil, nil
+		root := s.versionRoot(key)
+		if key == "/" || key == root {
+			continue
+		}
+		if root == "" {
+			p.parent = s.navPages["/"]
+		}
+		parts := strings.Split(strings.Trim(key, "/"), "/")
+		prefix := "/"
+		for _, part := range parts[:len(parts)-1] {
+			prefix += part + "/"
+			if len(prefix) < len(root) {
+				continue // outside of version
+			}
+			if parent, ok := s.navPages[prefix]; ok {
+				p.parent = parent
+			}
+		}
+	}
+	for key, p := range s.navPages {
+		switch {
+		case p.parent != nil:
+			p.parent.children = append(p.parent.children, p)
+		case key != "/" && s.versionRoot(key) == "":
+			s.navRoots = append(s.navRoots, p)
+		}
+	}
+	sortNavPages(s.navRoots)
+	for _, p := range s.navPages {
+		sortNavPages(p.children)
+	}
+}
+
+func sortNavPages(pages []*navPage) {
+	sort.Slice(pages, func(i, j int) bool {
+		a, b := pages[i], pages[j]
+		if a.weight != b.weight {
+			return a.weight < b.weight
+		}
+		if a.title != b.title {
+			return a.title < b.title
+		}
+		return a.url < b.url
+	})
+}
+
+// topNavPages returns top-level pages of the navigation tree:
+// those in the root section after the root index page.
+func (s *Site) topNavPages() []*navPage {
+	if root, ok := s.navPages["/"]; ok {
+		return append(root.children, s.navRoots...)
+	}
+	return s.navRoots
+}
+
+// navTop returns top-level pages of the tree containing the page:
+// of its version of docs section, or of the site.
+func (s *Site) navTop(p *navPage) []*navPage {
+	if p != nil {
+		if root := s.versionRoot(p.url); root != "" {
+			if rp, ok := s.navPages[root]; ok {
+				return rp.children
+			}
+			return nil
+		}
+	}
+	return s.topNavPages()
+}
+
+// navSiblings returns pages in the same section as the page.
+func (s *Site) navSiblings(p *navPage) []*navPage {
+	if p.parent != nil {
+		return p.parent.children
+	}
+	return s.navTop(p)
+}
+
+// setSectionLinks sets `prev_page` and `next_page` meta of the page
+// to references to the previous and the next pages in its section.
+func (s *Site) setSectionLinks(meta map[string]interface{}) {
+	url, _ := meta["url"].(string)
+	p, ok := s.navPages[pageKey(url)]
+	if !ok || pageKey(url) == "/" {
+		return
+	}
+	siblings := s.navSiblings(p)
+	for i, sp := range siblings {
+		if sp != p {
+			continue
+		}
+		if i > 0 {
+			meta["prev_page"] = navRef(siblings[i-1])
+		}
+		if i < len(siblings)-1 {
+			meta["next_page"] = navRef(siblings[i+1])
+		}
+		break
+	}
+}
+
+// navRef returns a reference to the page for navigation.
+func navRef(p *navPage) map[string]interface{} {
+	return map[string]interface{}{
+		"url":   p.url,
+		"title": p.title,
+	}
+}
+
+// navTree returns references to top-level pages with their
+// `children`, recursively.
+func (s *Site) navTree() []map[string]interface{} {
+	var tree func(pages []*navPage) []map[string]interface{}
+	tree = func(pages []*navPage) []map[string]interface{} {
+		refs := make([]map[string]interface{}, len(pages))
+		for i, p := range pages {
+			refs[i] = navRef(p)
+			refs[i]["children"] = tree(p.children)
+		}
+		return refs
+	}
+	return tree(s.topNavPages())
+}
+
+// navHTML returns the navigation tree as nested lists. If page meta is
+// given, the link to the page is marked with aria-current, and items
+// containing it with the "active" class. Pages of docs versions get
+// the tree of their version.
+func (s *Site) navHTML(page ...map[string]interface{}) (string, error) {
+	if len(page) > 1 {
+		return "", fmt.Errorf("nav: expected at most one page, got %d", len(page))
+	}
+	active := make(map[*navPage]bool)
+	var current *navPage
+	if len(page) == 1 && page[0] != nil {
+		url, _ := page[0]["url"].(string)
+		current = s.navPages[pageKey(url)]
+		for p := current; p != nil; p = p.parent {
+			active[p] = true
+		}
+	}
+	var b strings.Builder
+	var write func(pages []*navPage, class string)
+	write = func(pages []*navPage, class string) {
+		if len(pages) == 0 {
+			return
+		}
+		b.WriteString("<ul" + class + ">")
+		for _, p := range pages {
+			if active[p] {
+				b.WriteString(`<li class="active">`)
+			} else {
+				b.WriteString("<li>")
+			}
+			b.WriteString(`<a href="` + html.EscapeString(p.url) + `"`)
+			if p == current {
+				b.WriteString(` aria-current="page"`)
+			}
+			b.WriteString(">" + html.EscapeString(p.title) + "</a>")
+			write(p.children, "")
+			b.WriteString("</li>")
+		}
+		b.WriteString("</ul>")
+	}
+	write(s.navTop(current), ` class="nav"`)
+	return b.String(), nil
+}
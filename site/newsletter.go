@@ -0,0 +1,166 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/filters"
+	"github.com/dchest/kkr/sandbox"
+	"github.com/dchest/kkr/utils"
+)
+
+const (
+	DefaultNewsletterLayout    = "newsletter"
+	DefaultNewsletterPermalink = "/newsletter.html"
+)
+
+// site.yml -> newsletter:
+type NewsletterConfig struct {
+	Layout    string      `yaml:"layout"`    // DefaultNewsletterLayout if empty
+	Permalink string      `yaml:"permalink"` // DefaultNewsletterPermalink if empty
+	Posts     int         `yaml:"posts"`     // number of latest posts, 1 if zero
+	Filter    interface{} `yaml:"filter"`    // e.g. inlinecss or [exec, juice, ...]
+	// Command sending the newsletter, which gets HTML on stdin, with
+	// :subject (title of the latest post) and :url (its URL), e.g.
+	// [sh, -c, "mail -a 'Content-Type: text/html' -s \"$0\" list@example.com", ":subject"].
+	Command []string `yaml:"command"`
+}
+
+// NewsletterPage is an email with the latest posts.
+type NewsletterPage struct {
+	Page
+	Filename        string
+	NewsletterPosts Posts
+}
+
+func (p *NewsletterPage) Meta() map[string]interface{} { return p.meta }
+func (p *NewsletterPage) Content() string              { return p.content }
+func (p *NewsletterPage) FileInfo() os.FileInfo        { return nil }
+func (p *NewsletterPage) URL() string                  { return p.url }
+
+func (c *NewsletterConfig) permalink() string {
+	if c.Permalink == "" {
+		return DefaultNewsletterPermalink
+	}
+	return c.Permalink
+}
+
+// newsletterPosts returns the latest posts for the newsletter.
+func (s *Site) newsletterPosts() Posts {
+	n := s.Config.Newsletter.Posts
+	if n <= 0 {
+		n = 1
+	}
+	var posts Posts
+	for _, p := range s.Config.Posts {
+		if len(posts) == n {
+			break
+		}
+		if !s.draftURLs[p.url] {
+			posts = append(posts, p)
+		}
+	}
+	return posts
+}
+
+// RenderNewsletter renders email-safe HTML with the latest posts into
+// the output directory: URLs are made absolute and the configured
+// filter, such as inlinecss, is applied.
+func (s *Site) RenderNewsletter() error {
+	c := s.Config.Newsletter
+	if c == nil {
+		return nil
+	}
+	posts := s.newsletterPosts()
+	if len(posts) == 0 {
+		return nil
+	}
+	log.Printf("* Rendering newsletter.")
+	layout := c.Layout
+	if layout == "" {
+		layout = DefaultNewsletterLayout
+	}
+	permalink := c.permalink()
+	p := &NewsletterPage{NewsletterPosts: posts}
+	p.url = utils.CleanPermalink(permalink)
+	title := metaString(posts[0].meta, "title")
+	p.content = title
+	p.meta = map[string]interface{}{
+		"title": title,
+		"posts": posts,
+		"url":   p.url,
+	}
+	p.Filename = filepath.FromSlash(utils.AddIndexIfNeeded(permalink))
+	data, err := s.Layouts.RenderPage(p, layout)
+	if err != nil {
+		return err
+	}
+	b := []byte(utils.AbsPaths(s.Config.URL, data))
+	if c.Filter != nil {
+		fc := filters.NewCollection()
+		if err := fc.AddFromYAML("newsletter", c.Filter); err != nil {
+			return fmt.Errorf("newsletter: %w", err)
+		}
+		if b, err = fc.ApplyFilter("newsletter", b); err != nil {
+			return fmt.Errorf("newsletter: %w", err)
+		}
+	}
+	log.Printf("N > %s", filepath.Join(OutDirName, p.Filename))
+	return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, p.Filename), b)
+}
+
+// SendNewsletter pipes the rendered newsletter to the configured
+// command. If dryRun is true, it only logs the command.
+// The site must be built.
+func (s *Site) SendNewsletter(dryRun bool) error {
+	c := s.Config.Newsletter
+	if c == nil {
+		return errors.New("no newsletter in site.yml")
+	}
+	if len(c.Command) == 0 {
+		return errors.New("no newsletter command in site.yml")
+	}
+	posts := s.newsletterPosts()
+	if len(posts) == 0 {
+		return errors.New("no posts for newsletter")
+	}
+	filename := filepath.Join(s.BaseDir, OutDirName, filepath.FromSlash(utils.AddIndexIfNeeded(c.permalink())))
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	r := strings.NewReplacer(
+		":subject", metaString(posts[0].meta, "title"),
+		":url", s.Config.URL+posts[0].url,
+	)
+	args := make([]string, len(c.Command))
+	for i, a := range c.Command {
+		args[i] = r.Replace(a)
+	}
+	log.Printf("R %s", strings.Join(args, " "))
+	if dryRun {
+		return nil
+	}
+	cmd, err := sandbox.NetworkCommand(args[0], args[1:]...)
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = bytes.NewReader(b)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	log.Printf("* Sent newsletter %q.", metaString(posts[0].meta, "title"))
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/dchest/kkr/humanize"
+)
+
+// numberArg returns the number given to template function,
+// either as an integer or, if it's not one, as a float.
+func numberArg(v interface{}) (n int64, f float64, isInt bool, err error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), 0, true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), 0, true, nil
+	case reflect.Float32, reflect.Float64:
+		return 0, rv.Float(), false, nil
+	case reflect.String:
+		if n, err := strconv.ParseInt(rv.String(), 10, 64); err == nil {
+			return n, 0, true, nil
+		}
+		f, err := strconv.ParseFloat(rv.String(), 64)
+		return 0, f, false, err
+	}
+	return 0, 0, false, fmt.Errorf("expected number, got %T", v)
+}
+
+// langArg returns the optional language argument
+// of template function or the site language.
+func (s *Site) langArg(lang []string) string {
+	if len(lang) > 0 {
+		return lang[0]
+	}
+	return s.siteLang()
+}
+
+func (s *Site) numberFunc(v interface{}, lang ...string) (string, error) {
+	n, f, isInt, err := numberArg(v)
+	if err != nil {
+		return "", fmt.Errorf("number: %w", err)
+	}
+	if isInt {
+		return humanize.Int(n, s.langArg(lang)), nil
+	}
+	return humanize.Float(f, -1, s.langArg(lang)), nil
+}
+
+func (s *Site) fileSizeFunc(v interface{}, lang ...string) (string, error) {
+	n, f, isInt, err := numberArg(v)
+	if err != nil {
+		return "", fmt.Errorf("filesize: %w", err)
+	}
+	if !isInt {
+		n = int64(f)
+	}
+	return humanize.FileSize(n, s.langArg(lang)), nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/dchest/kkr/links"
+	"github.com/dchest/kkr/utils"
+)
+
+// site.yml -> orphans:
+type OrphansConfig struct {
+	// URL patterns of pages that are not supposed to be linked,
+	// such as /404.html or /drafts/*.
+	EntryPoints []string `yaml:"entry_points"`
+}
+
+func isHTMLURL(u string) bool {
+	return path.Ext(u) == "" || utils.HasFileExt(u, HTMLExtensions)
+}
+
+// Orphans returns sorted URLs of pages and posts that are not reachable
+// by following links from the home page, generated index pages (such as
+// tag indexes and archives), or configured entry points.
+//
+// Links are collected from the output directory, so the site must be built.
+func (s *Site) Orphans() ([]string, error) {
+	// Pages and posts are checked, other outputs are indexes.
+	content := make(map[string]bool)
+	s.pagesMu.Lock()
+	for u := range s.pages {
+		if isHTMLURL(u) {
+			content[u] = true
+		}
+	}
+	s.pagesMu.Unlock()
+	for _, p := range s.Config.Posts {
+		content[pageKey(p.url)] = true
+	}
+
+	ownHost := ""
+	if u, err := url.Parse(s.Config.URL); err == nil {
+		ownHost = u.Hostname()
+	}
+	var entryPoints []string
+	if s.Config.Orphans != nil {
+		entryPoints = s.Config.Orphans.EntryPoints
+	}
+	isEntryPoint := func(u string) bool {
+		for _, pattern := range entryPoints {
+			if ok, _ := path.Match(pattern, u); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	outDir := filepath.Join(s.BaseDir, OutDirName)
+	graph := make(map[string][]string) // source -> targets
+	var queue []string
+	err := filepath.Walk(outDir, func(name string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() || !utils.HasFileExt(name, HTMLExtensions) {
+			return nil
+		}
+		relname, err := filepath.Rel(outDir, name)
+		if err != nil {
+			return err
+		}
+		source := pageKey(utils.CleanPermalink(filepath.ToSlash(relname)))
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		targets, err := links.Internal(b, source, ownHost)
+		if err != nil {
+			return err
+		}
+		graph[source] = targets
+		if source == "/" || !content[source] || isEntryPoint(source) {
+			queue = append(queue, source)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Follow links.
+	reached := make(map[string]bool)
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		if reached[u] {
+			continue
+		}
+		reached[u] = true
+		for _, t := range graph[u] {
+			t = pageKey(utils.CleanPermalink(t))
+			if _, ok := graph[t]; !ok {
+				if _, ok := graph[t+"/"]; ok {
+					t += "/" // link without trailing slash
+				}
+			}
+			if !reached[t] {
+				queue = append(queue, t)
+			}
+		}
+	}
+
+	var orphans []string
+	for u := range content {
+		if !reached[u] {
+			orphans = append(orphans, u)
+		}
+	}
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+// ReportOrphans logs URLs of orphan pages, see Orphans.
+func (s *Site) ReportOrphans() error {
+	orphans, err := s.Orphans()
+	if err != nil {
+		return err
+	}
+	for _, u := range orphans {
+		log.Printf("O %s", u)
+	}
+	log.Printf("* Found %d orphan pages.", len(orphans))
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 
@@ -56,14 +57,21 @@ type Page struct {
 	Basedir      string
 	Filename     string
 	url          string
+	relname      string                 // source file name relative to Basedir
+	defaults     map[string]interface{} // meta from defaults files
+	sourceMeta   string                 // meta as loaded, before it's changed by rendering
 }
 
 func (p *Page) Meta() map[string]interface{} { return p.meta }
 func (p *Page) Content() string              { return p.content }
 func (p *Page) FileInfo() os.FileInfo        { return p.fi }
 func (p *Page) URL() string                  { return p.url }
+func (p *Page) UID() string                  { return p.uid }
 
 func (p *Page) InSitemap() bool {
+	if draft, _ := p.meta["draft"].(bool); draft {
+		return false
+	}
 	if value, ok := p.meta["sitemap"].(bool); ok {
 		return value
 	}
@@ -109,12 +117,48 @@ func extractShortContent(s string) (shortContent, content string) {
 	return
 }
 
+// pageOutName returns output file name of the page.
+func pageOutName(filename string, meta map[string]interface{}) string {
+	// Replace Markdown or external markup file extension with .html.
+	if utils.HasFileExt(filename, MarkdownExtensions) || markup.ExternalFor(filename) != "" {
+		filename = utils.ReplaceFileExt(filename, ".html")
+	}
+
+	// Change filename if there's 'permalink'.
+	if permalink, ok := meta["permalink"]; ok {
+		filename = filepath.FromSlash(permalink.(string))
+	}
+
+	// Change filename to filename/index.html
+	// if 'folder' is true.
+	if folder, ok := meta["folder"]; ok && folder.(bool) {
+		filename = filepath.Join(utils.ReplaceFileExt(filename, ""), "index.html")
+	}
+	return filename
+}
+
 func LoadPage(basedir, filename string) (p *Page, err error) {
+	return loadPage(basedir, filename, "")
+}
+
+// loadPage loads the page with the output name prefixed with
+// the given slash-separated directory, such as "docs/v1".
+func loadPage(basedir, filename, prefix string) (p *Page, err error) {
+	relname := filename
 	fullname := filepath.Join(basedir, filename)
+	cacheKey := fullname
+	if prefix != "" {
+		cacheKey = prefix + "\x00" + fullname
+	}
+	defaults, err := loadDefaults(basedir, filename)
+	if err != nil {
+		return
+	}
 	if pageCache != nil {
 		// Try getting from cache
-		page := pageCache.Get(fullname)
-		if page != nil && !metafile.Changed(fullname, page.fi) {
+		page := pageCache.Get(cacheKey)
+		if page != nil && !metafile.Changed(fullname, page.fi) &&
+			reflect.DeepEqual(page.defaults, defaults) {
 			return page, nil
 		}
 	}
@@ -129,40 +173,37 @@ func LoadPage(basedir, filename string) (p *Page, err error) {
 	}
 
 	meta := f.Meta()
+	applyDefaults(meta, defaults)
 	content, err := f.Content()
 	if err != nil {
 		return
 	}
 
 	// If page is a Markdown file, set its markup meta to Markdown (to
-	// process content) and replace output file extension with .html.
+	// process content), same for external markups.
 	if utils.HasFileExt(filename, MarkdownExtensions) {
 		meta["markup"] = "markdown"
-		filename = utils.ReplaceFileExt(filename, ".html")
+	} else if name := markup.ExternalFor(filename); name != "" {
+		meta["markup"] = name
 	}
 
-	if markupName, ok := meta["markup"]; ok {
-		markupName, ok := markupName.(string)
-		if !ok {
+	markupName := ""
+	if v, ok := meta["markup"]; ok {
+		if markupName, ok = v.(string); !ok {
 			return nil, errors.New("markup must be a string")
 		}
-		content, err = markup.Process(markupName, content)
-		if err != nil {
-			return
-		}
-	}
-
-	// Change filename if there's 'permalink'.
-	if permalink, ok := meta["permalink"]; ok {
-		filename = filepath.FromSlash(permalink.(string))
 	}
-
-	// Change filename to filename/index.html
-	// if 'folder' is true.
-	if folder, ok := meta["folder"]; ok && folder.(bool) {
-		filename = filepath.Join(utils.ReplaceFileExt(filename, ""), "index.html")
+	content, err = expandShortcodes(content, func(content []byte) ([]byte, error) {
+		if markupName == "" {
+			return content, nil
+		}
+		return markup.Process(markupName, content)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
 	}
 
+	filename = filepath.Join(filepath.FromSlash(prefix), pageOutName(filename, meta))
 	url := utils.CleanPermalink(filepath.ToSlash(filename))
 	meta["url"] = url
 	meta["id"] = filepath.ToSlash(filename)
@@ -177,10 +218,13 @@ func LoadPage(basedir, filename string) (p *Page, err error) {
 		Basedir:      basedir,
 		Filename:     filename,
 		url:          url,
+		relname:      relname,
+		defaults:     defaults,
+		sourceMeta:   fmt.Sprint(meta),
 	}
 	if pageCache != nil {
 		// Cache this page
-		pageCache.Put(fullname, p)
+		pageCache.Put(cacheKey, p)
 	}
 	return p, nil
 }
@@ -0,0 +1,90 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/dchest/kkr/layouts"
+)
+
+// partial executes include file as a template with the given data
+// as dot. Templates have access to all layout functions.
+func (s *Site) partial(name string, data ...interface{}) (string, error) {
+	var dot interface{}
+	switch len(data) {
+	case 0:
+	case 1:
+		dot = data[0]
+	default:
+		return "", fmt.Errorf("partial %q: too many arguments", name)
+	}
+	t, err := s.partialTemplate(name)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, dot); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// partialTemplate returns a parsed template for the include file.
+// Templates are parsed once per build.
+func (s *Site) partialTemplate(name string) (layouts.Template, error) {
+	s.partialsMu.Lock()
+	defer s.partialsMu.Unlock()
+	if t, ok := s.partials[name]; ok {
+		return t, nil
+	}
+	content, ok := s.Includes[name]
+	if !ok {
+		return nil, fmt.Errorf("partial %q not found", name)
+	}
+	t, err := layouts.Parse(name, content, s.templateFuncs(s.Config.Autoescape), s.Config.Autoescape)
+	if err != nil {
+		return nil, err
+	}
+	s.partials[name] = t
+	return t, nil
+}
+
+// includeExists returns true if there's include file with the name.
+func (s *Site) includeExists(name string) bool {
+	_, ok := s.Includes[name]
+	return ok
+}
+
+// fileExists returns true if the file with the name relative
+// to the site directory exists.
+func (s *Site) fileExists(name string) bool {
+	clean := path.Clean("/" + filepath.ToSlash(name))[1:]
+	if clean == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(s.BaseDir, filepath.FromSlash(clean)))
+	return err == nil
+}
+
+// dict returns a map from key and value pairs.
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict: odd number of arguments")
+	}
+	m := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is not a string", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
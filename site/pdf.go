@@ -0,0 +1,131 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/sandbox"
+	"github.com/dchest/kkr/utils"
+)
+
+// site.yml -> pdf:
+type PDFConfig struct {
+	// Command converting the page at :url into PDF file :out, e.g.
+	// [chromium, --headless, --no-pdf-header-footer, "--print-to-pdf=:out", ":url"]
+	// or [wkhtmltopdf, --quiet, ":url", ":out"].
+	Command []string `yaml:"command"`
+}
+
+const pdfDirName = "pdf"
+
+// pdfPage is a page which has a PDF version.
+type pdfPage struct {
+	htmlName string // output file names relative to output directory
+	pdfName  string
+	url      string
+}
+
+// preparePDF sets `pdf_url` meta of the page with `pdf: true` meta
+// to the URL of its PDF version, which is generated after the build.
+func (s *Site) preparePDF(p *Page) {
+	if enabled, ok := p.meta["pdf"].(bool); !ok || !enabled {
+		return
+	}
+	pdfName := utils.ReplaceFileExt(p.Filename, ".pdf")
+	pdfURL := "/" + filepath.ToSlash(pdfName)
+	p.meta["pdf_url"] = pdfURL
+	s.pdfMu.Lock()
+	defer s.pdfMu.Unlock()
+	s.pdfPages = append(s.pdfPages, &pdfPage{
+		htmlName: p.Filename,
+		pdfName:  pdfName,
+		url:      path.Join("/", p.url),
+	})
+}
+
+// RenderPDFs generates PDF versions of pages with `pdf: true` meta
+// by running the configured command with URLs of these pages served
+// from the output directory. Results are cached.
+func (s *Site) RenderPDFs() error {
+	s.pdfMu.Lock()
+	pages := s.pdfPages
+	s.pdfPages = nil
+	s.pdfMu.Unlock()
+	if len(pages) == 0 {
+		return nil
+	}
+	c := s.Config.PDF
+	if c == nil || len(c.Command) == 0 {
+		return fmt.Errorf("%s requests PDF, but pdf command is not configured", pages[0].url)
+	}
+	log.Printf("* Rendering PDFs.")
+	outDir := filepath.Join(s.BaseDir, OutDirName)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: http.FileServer(http.Dir(outDir))}
+	go server.Serve(ln)
+	defer server.Close()
+	for _, p := range pages {
+		b, err := ioutil.ReadFile(filepath.Join(outDir, p.htmlName))
+		if err != nil {
+			return err
+		}
+		key := strings.Join(c.Command, "\x00") + "\x00" + string(b)
+		cacheFile := filepath.Join(s.BaseDir, CacheDirName, pdfDirName,
+			utils.NoVowelsHexEncode(utils.Hash([]byte(key))[:16])+".pdf")
+		if _, err := os.Stat(cacheFile); err != nil {
+			log.Printf("R pdf %s", p.url)
+			if err := runPDFCommand(c.Command, "http://"+ln.Addr().String()+p.url, cacheFile); err != nil {
+				return fmt.Errorf("pdf: %s: %w", p.url, err)
+			}
+		}
+		log.Printf("P > %s", filepath.Join(OutDirName, p.pdfName))
+		if err := s.fileWriter.CopyFile(filepath.Join(outDir, p.pdfName), cacheFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runPDFCommand runs the command replacing :url and :out arguments.
+// The output is written to a temporary file and then renamed to outFile,
+// so that it's not left incomplete if the command fails.
+func runPDFCommand(command []string, url, outFile string) error {
+	if err := os.MkdirAll(filepath.Dir(outFile), 0755); err != nil {
+		return err
+	}
+	tmpFile := outFile + ".tmp"
+	defer os.Remove(tmpFile)
+	r := strings.NewReplacer(":url", url, ":out", tmpFile)
+	args := make([]string, len(command))
+	for i, a := range command {
+		args[i] = r.Replace(a)
+	}
+	cmd, err := sandbox.Command(args[0], args[1:]...)
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	if fi, err := os.Stat(tmpFile); err != nil || fi.Size() == 0 {
+		return fmt.Errorf("%s didn't write PDF", args[0])
+	}
+	return os.Rename(tmpFile, outFile)
+}
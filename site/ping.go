@@ -0,0 +1,149 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dchest/kkr/ping"
+	"github.com/dchest/kkr/utils"
+)
+
+// site.yml -> deploy: indexnow:
+//
+// The key file is rendered into the output directory as <key>.txt.
+type IndexNowConfig struct {
+	Key      string `yaml:"key"`      // 8-128 characters: a-z, A-Z, 0-9, -
+	Endpoint string `yaml:"endpoint"` // defaults to https://api.indexnow.org/indexnow
+}
+
+// site.yml -> websub:
+//
+// Feeds declare the hub with {{ .Site.WebSubHub }}, podcast feed
+// declares it automatically. After deploying, the hub is notified
+// about changed feeds.
+type WebSubConfig struct {
+	Hub   string   `yaml:"hub"`   // e.g. https://pubsubhubbub.appspot.com/
+	Feeds []string `yaml:"feeds"` // feed paths or patterns, e.g. /blog/feed.xml, /blog/tags/*/feed.xml
+}
+
+// indexNowKeyFile returns the name of IndexNow key file
+// relative to the output directory.
+func indexNowKeyFile(c *IndexNowConfig) string {
+	return c.Key + ".txt"
+}
+
+// RenderIndexNowKey renders IndexNow key file if it's
+// configured in site.yml -> deploy: indexnow:.
+func (s *Site) RenderIndexNowKey() error {
+	if s.Config.Deploy == nil || s.Config.Deploy.IndexNow == nil {
+		return nil
+	}
+	c := s.Config.Deploy.IndexNow
+	if !ping.IsValidIndexNowKey(c.Key) {
+		return fmt.Errorf("indexnow: invalid key %q", c.Key)
+	}
+	filename := filepath.Join(OutDirName, indexNowKeyFile(c))
+	log.Printf("* Rendering %s.", filename)
+	return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, filename), []byte(c.Key))
+}
+
+// checkPing returns an error if search engine notifications
+// are configured, but can't be sent.
+func (s *Site) checkPing() error {
+	c := s.Config.Deploy
+	if c.PingSitemap {
+		log.Printf("! deploy: ping_sitemap is ignored, search engines no longer accept sitemap pings; use indexnow")
+	}
+	if c.IndexNow == nil && s.Config.WebSub == nil {
+		return nil
+	}
+	if s.Config.URL == "" {
+		return errors.New("deploy: url must be set in site.yml to notify search engines")
+	}
+	return nil
+}
+
+// changedPageURLs returns sorted site URLs of deployed HTML pages.
+func (s *Site) changedPageURLs(res *DeployResult) []string {
+	base := strings.TrimSuffix(s.Config.URL, "/")
+	var urls []string
+	for _, list := range [][]string{res.Uploaded, res.Deleted} {
+		for _, name := range list {
+			u := utils.CleanPermalink(name)
+			if isHTMLURL(u) {
+				urls = append(urls, base+u)
+			}
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// changedFeedURLs returns sorted site URLs of uploaded feeds,
+// which are configured in websub: feeds: or podcast: feed:.
+func (s *Site) changedFeedURLs(res *DeployResult) []string {
+	patterns := s.Config.WebSub.Feeds
+	if s.Config.Podcast != nil {
+		patterns = append(patterns[:len(patterns):len(patterns)], s.Config.Podcast.Feed)
+	}
+	base := strings.TrimSuffix(s.Config.URL, "/")
+	var urls []string
+	for _, name := range res.Uploaded {
+		for _, pattern := range patterns {
+			if matchPath(pattern, "/"+name) {
+				urls = append(urls, base+"/"+name)
+				break
+			}
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// pingWebSub notifies WebSub hub about changed feeds.
+// Errors are logged, since the site is already deployed.
+func (s *Site) pingWebSub(res *DeployResult, dryRun bool) {
+	if s.Config.WebSub == nil || s.Config.WebSub.Hub == "" {
+		return
+	}
+	for _, u := range s.changedFeedURLs(res) {
+		log.Printf("* Notifying WebSub hub about %s.", u)
+		if dryRun {
+			continue
+		}
+		if err := ping.WebSub(s.Config.WebSub.Hub, u); err != nil {
+			log.Printf("! %s", err)
+		}
+	}
+}
+
+// pingSearchEngines submits changed pages to IndexNow.
+// Errors are logged, since the site is already deployed.
+func (s *Site) pingSearchEngines(res *DeployResult, dryRun bool) {
+	c := s.Config.Deploy
+	urls := s.changedPageURLs(res)
+	if len(urls) == 0 {
+		return
+	}
+	if c.IndexNow != nil {
+		log.Printf("* Submitting %d URLs to IndexNow.", len(urls))
+		endpoint := c.IndexNow.Endpoint
+		if endpoint == "" {
+			endpoint = ping.DefaultIndexNowEndpoint
+		}
+		keyLocation := strings.TrimSuffix(s.Config.URL, "/") + "/" + indexNowKeyFile(c.IndexNow)
+		if !dryRun {
+			if err := ping.IndexNow(endpoint, c.IndexNow.Key, keyLocation, urls); err != nil {
+				log.Printf("! %s", err)
+			}
+		}
+	}
+}
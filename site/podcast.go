@@ -0,0 +1,150 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/podcast"
+	"github.com/dchest/kkr/video"
+	"gopkg.in/yaml.v3"
+)
+
+const DefaultPodcastFeed = "/podcast.xml"
+
+var audioTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".m4a":  "audio/x-m4a",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".oga":  "audio/ogg",
+	".opus": "audio/ogg",
+	".flac": "audio/flac",
+	".wav":  "audio/wav",
+}
+
+// postAudio returns audio enclosure from post's `audio:` meta,
+// which can be either a URL or a map, or nil if there's no audio.
+func postAudio(p *Post) (*podcast.Audio, error) {
+	v, ok := p.meta["audio"]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	var a podcast.Audio
+	switch m := v.(type) {
+	case string:
+		a.URL = m
+	case map[string]interface{}:
+		b, err := yaml.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(b, &a); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("'audio' must be a URL or a map")
+	}
+	if a.URL == "" {
+		return nil, fmt.Errorf("'audio' must have url")
+	}
+	if a.Type == "" {
+		a.Type = audioTypes[strings.ToLower(path.Ext(a.URL))]
+	}
+	return &a, nil
+}
+
+// fillLocalAudio sets length and duration of the audio file from pages
+// directory if they are not set in front matter.
+func (s *Site) fillLocalAudio(a *podcast.Audio) error {
+	if !strings.HasPrefix(a.URL, "/") || strings.HasPrefix(a.URL, "//") {
+		return nil // remote file
+	}
+	filename := filepath.Join(s.BaseDir, PagesDirName, filepath.FromSlash(a.URL))
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	if a.Length == 0 {
+		a.Length = fi.Size()
+	}
+	if a.Duration == "" {
+		s.videoMu.Lock()
+		defer s.videoMu.Unlock()
+		key, err := videoCacheKey(filename)
+		if err != nil {
+			return err
+		}
+		cacheDir := filepath.Join(s.BaseDir, CacheDirName, "video")
+		if err := os.MkdirAll(cacheDir, 0755); err != nil {
+			return err
+		}
+		info, err := s.probeAudio(filename, cacheDir, key)
+		if err != nil {
+			// Duration is optional.
+			log.Printf("! cannot get duration of %s: %s", a.URL, err)
+			return nil
+		}
+		a.Duration = video.FormatDuration(info.Duration)
+	}
+	return nil
+}
+
+// RenderPodcast renders podcast RSS feed from posts with audio.
+func (s *Site) RenderPodcast() error {
+	c := s.Config.Podcast
+	if c == nil {
+		return nil
+	}
+	if c.Feed == "" {
+		c.Feed = DefaultPodcastFeed
+	}
+	if c.Title == "" {
+		c.Title = s.Config.Name
+	}
+	if c.Author == "" {
+		c.Author = s.Config.Author
+	}
+	c.Hub = s.Config.WebSubHub()
+	log.Printf("* Rendering podcast feed.")
+	var episodes []*podcast.Episode
+	for _, p := range s.Config.Posts {
+		a, err := postAudio(p)
+		if err != nil {
+			return fmt.Errorf("post %s: %w", p.Filename, err)
+		}
+		if a == nil {
+			continue
+		}
+		if err := s.fillLocalAudio(a); err != nil {
+			return fmt.Errorf("post %s: %w", p.Filename, err)
+		}
+		title, _ := p.meta["title"].(string)
+		description, _ := p.meta["description"].(string)
+		guid := p.UID()
+		if guid == "" {
+			guid = s.Config.URL + p.url
+		}
+		episodes = append(episodes, &podcast.Episode{
+			Title:       title,
+			Link:        s.Config.URL + p.url,
+			GUID:        guid,
+			Description: description,
+			Date:        p.Date,
+			Audio:       a,
+		})
+	}
+	var buf bytes.Buffer
+	if err := podcast.Render(&buf, c, s.Config.URL, s.Config.Date, episodes); err != nil {
+		return err
+	}
+	return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, filepath.FromSlash(c.Feed)), buf.Bytes())
+}
@@ -19,8 +19,10 @@ import (
 
 type Post struct {
 	Page
-	Tags []string
-	Date time.Time
+	Tags       []string
+	Categories []string
+	Terms      map[string][]string // taxonomy -> terms
+	Date       time.Time
 }
 
 func LoadPost(basedir, filename, outNameTemplate string) (p *Post, err error) {
@@ -76,47 +78,75 @@ func LoadPost(basedir, filename, outNameTemplate string) (p *Post, err error) {
 	page.meta["is_post"] = true
 
 	// Get tags.
-	var tags []string
-	if mt, ok := page.meta["tags"]; ok {
-		switch t := mt.(type) {
-		case string:
-			tags = strings.Split(t, ",")
-			for i, v := range tags {
-				tags[i] = strings.TrimSpace(v)
-			}
-		case []string:
-			tags = make([]string, 0, len(t))
-			for _, v := range t {
-				tags = append(tags, v)
-			}
-		case []interface{}:
-			tags = make([]string, 0, len(t))
-			for _, v := range t {
-				s, ok := v.(string)
-				if !ok {
-					return nil, fmt.Errorf("'tags' contains a non-string: %v", reflect.TypeOf(v))
-				}
-				tags = append(tags, s)
-			}
-		case nil:
-			// nothing
-		default:
-			return nil, fmt.Errorf("'tags' is not an array of strings or a string: %v", reflect.TypeOf(mt))
-		}
+	tags, err := metaStrings(page.meta, "tags")
+	if err != nil {
+		return nil, err
+	}
+	if tags != nil {
 		page.meta["tags"] = tags
 	}
+	// Get categories: "category" is a shortcut for a single category.
+	categories, err := metaStrings(page.meta, "categories")
+	if err != nil {
+		return nil, err
+	}
+	if categories == nil {
+		if categories, err = metaStrings(page.meta, "category"); err != nil {
+			return nil, err
+		}
+	}
+	if categories != nil {
+		page.meta["categories"] = categories
+	}
 
 	// Add index.html if ends with slash.
 	outname = utils.AddIndexIfNeeded(outname)
 	page.Filename = filepath.FromSlash(outname)
 	page.url = url
 	return &Post{
-		Page: *page,
-		Date: date,
-		Tags: tags,
+		Page:       *page,
+		Date:       date,
+		Tags:       tags,
+		Categories: categories,
 	}, nil
 }
 
+// metaStrings returns a list of strings from meta key, which
+// can be either a list or a comma-separated string.
+func metaStrings(meta map[string]interface{}, key string) ([]string, error) {
+	mt, ok := meta[key]
+	if !ok {
+		return nil, nil
+	}
+	var out []string
+	switch t := mt.(type) {
+	case string:
+		out = strings.Split(t, ",")
+		for i, v := range out {
+			out[i] = strings.TrimSpace(v)
+		}
+	case []string:
+		out = make([]string, 0, len(t))
+		for _, v := range t {
+			out = append(out, v)
+		}
+	case []interface{}:
+		out = make([]string, 0, len(t))
+		for _, v := range t {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("'%s' contains a non-string: %v", key, reflect.TypeOf(v))
+			}
+			out = append(out, s)
+		}
+	case nil:
+		// nothing
+	default:
+		return nil, fmt.Errorf("'%s' is not an array of strings or a string: %v", key, reflect.TypeOf(mt))
+	}
+	return out, nil
+}
+
 type Posts []*Post
 
 func (pp Posts) Limit(n int) Posts {
@@ -138,6 +168,18 @@ func (pp Posts) Sort() {
 	sort.Sort(pp)
 }
 
+// OnDay returns posts published on the same month and day
+// as the given date in previous years.
+func (pp Posts) OnDay(date time.Time) Posts {
+	out := make(Posts, 0)
+	for _, p := range pp {
+		if p.Date.Year() < date.Year() && p.Date.Month() == date.Month() && p.Date.Day() == date.Day() {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
 type postsByYear struct {
 	Year  int
 	Posts Posts
@@ -166,3 +208,28 @@ func (pp Posts) ByYear() []postsByYear {
 	})
 	return pby
 }
+
+type postsByMonth struct {
+	Year  int
+	Month time.Month
+	Posts Posts
+}
+
+// ByMonth returns posts grouped by year and month,
+// most recent first.
+func (pp Posts) ByMonth() []postsByMonth {
+	var pbm []postsByMonth
+	for _, p := range pp {
+		y, m := p.Date.Year(), p.Date.Month()
+		i := sort.Search(len(pbm), func(i int) bool {
+			return pbm[i].Year < y || (pbm[i].Year == y && pbm[i].Month <= m)
+		})
+		if i == len(pbm) || pbm[i].Year != y || pbm[i].Month != m {
+			pbm = append(pbm, postsByMonth{})
+			copy(pbm[i+1:], pbm[i:])
+			pbm[i] = postsByMonth{Year: y, Month: m}
+		}
+		pbm[i].Posts = append(pbm[i].Posts, p)
+	}
+	return pbm
+}
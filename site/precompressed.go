@@ -0,0 +1,89 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Content encodings of precompressed files in the order of preference
+// with extensions of files produced by filewriter.
+var precompressedEncodings = []struct{ name, ext string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// acceptsEncoding returns true if Accept-Encoding header
+// value allows the given content encoding.
+func acceptsEncoding(header, encoding string) bool {
+	for _, v := range strings.Split(header, ",") {
+		parts := strings.Split(v, ";")
+		if !strings.EqualFold(strings.TrimSpace(parts[0]), encoding) {
+			continue
+		}
+		for _, p := range parts[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if q, err := strconv.ParseFloat(p[2:], 64); err == nil && q == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// precompressedHandler serves .br and .gz siblings of files in dir
+// if the client accepts them, passing other requests to h.
+func precompressedHandler(dir string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upath := r.URL.Path
+		if (r.Method != "GET" && r.Method != "HEAD") || strings.HasSuffix(upath, "/index.html") {
+			h.ServeHTTP(w, r) // FileServer redirects index.html
+			return
+		}
+		name := filepath.Join(dir, filepath.FromSlash(path.Clean("/"+upath)))
+		if strings.HasSuffix(upath, "/") {
+			name = filepath.Join(name, "index.html")
+		}
+		fi, err := os.Stat(name)
+		if err != nil || !fi.Mode().IsRegular() {
+			h.ServeHTTP(w, r)
+			return
+		}
+		accept := r.Header.Get("Accept-Encoding")
+		for _, enc := range precompressedEncodings {
+			cfi, err := os.Stat(name + enc.ext)
+			if err != nil || !cfi.Mode().IsRegular() {
+				continue
+			}
+			w.Header().Set("Vary", "Accept-Encoding")
+			if !acceptsEncoding(accept, enc.name) {
+				continue
+			}
+			f, err := os.Open(name + enc.ext)
+			if err != nil {
+				break
+			}
+			defer f.Close()
+			ctype := mime.TypeByExtension(filepath.Ext(name))
+			if ctype == "" {
+				ctype = "application/octet-stream"
+			}
+			w.Header().Set("Content-Type", ctype)
+			w.Header().Set("Content-Encoding", enc.name)
+			http.ServeContent(w, r, name, fi.ModTime(), f)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// proxyHandler returns a handler that forwards requests with path
+// prefixes from site.yml -> proxy: to upstream URLs, passing other
+// requests to h. Request paths are passed to upstream unchanged,
+// for example, with "/api/": "http://localhost:8080", request
+// for /api/items goes to http://localhost:8080/api/items.
+func proxyHandler(proxy map[string]string, h http.Handler) (http.Handler, error) {
+	if len(proxy) == 0 {
+		return h, nil
+	}
+	proxies := make(map[string]http.Handler)
+	prefixes := make([]string, 0, len(proxy))
+	for prefix, upstream := range proxy {
+		if !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("proxy %s: path prefix must start with /", prefix)
+		}
+		target, err := url.Parse(upstream)
+		if err != nil {
+			return nil, fmt.Errorf("proxy %s: %w", prefix, err)
+		}
+		if target.Scheme != "http" && target.Scheme != "https" {
+			return nil, fmt.Errorf("proxy %s: upstream must be http or https URL", prefix)
+		}
+		p := httputil.NewSingleHostReverseProxy(target)
+		director := p.Director
+		p.Director = func(r *http.Request) {
+			director(r)
+			r.Host = target.Host
+		}
+		proxies[prefix] = p
+		prefixes = append(prefixes, prefix)
+		log.Printf("* Proxying %s to %s", prefix, upstream)
+	}
+	// Longest prefixes first.
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				proxies[prefix].ServeHTTP(w, r)
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	}), nil
+}
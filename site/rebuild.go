@@ -0,0 +1,241 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/layouts"
+	"github.com/dchest/kkr/utils"
+)
+
+// Prefixes of dependency graph inputs referred to by templates,
+// in addition to layouts (see layouts.Input). Includes and shortcodes
+// are also outputs in the graph, depending on what they refer to.
+const (
+	includeInputPrefix   = "include:"
+	assetInputPrefix     = "asset:"
+	shortcodeInputPrefix = "shortcode:"
+)
+
+// refFuncs are template functions which refer to includes, assets
+// and shortcodes by name.
+var refFuncs = map[string]layouts.RefFunc{
+	"include":       includeInput,
+	"includeExists": includeInput,
+	"partial":       includeInput,
+	"asset":         assetInput,
+	"inline":        assetInput,
+	"shortcode": func(src string) string {
+		// Name is the first word of shortcode source.
+		return shortcodeInputPrefix + strings.SplitN(strings.TrimSpace(src), " ", 2)[0]
+	},
+}
+
+func includeInput(name string) string { return includeInputPrefix + name }
+
+// assetInput returns input for the asset name, which is the same for
+// all variants of the asset.
+func assetInput(name string) string {
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		name = name[:i]
+	}
+	return assetInputPrefix + name
+}
+
+// isTemplateInput returns true if the dependency graph output
+// is an include or shortcode rather than a page.
+func isTemplateInput(output string) bool {
+	return strings.HasPrefix(output, includeInputPrefix) || strings.HasPrefix(output, shortcodeInputPrefix)
+}
+
+// trackTemplate adds inputs referred to by the include or shortcode
+// template to the dependency graph.
+func (s *Site) trackTemplate(input, content string) {
+	refs, err := layouts.Refs(content, refFuncs)
+	if err != nil {
+		// Not a template, e.g. text returned by `include`.
+		refs = nil
+	}
+	s.graph.Set(input, refs...)
+}
+
+// runPartialBuild re-renders only outputs affected by the changed files,
+// which are full paths reported by the watcher.
+//
+// Changes to existing layouts, includes, assets (in assets directory)
+// and pages are handled: they affect pages and posts that used the
+// layouts and referred to the includes and assets during the previous
+// build, and the changed pages themselves. If a change may affect other
+// outputs (for example, a page's title, its links or a post changed,
+// a file was added or removed, or an include or asset is referred to
+// by a generated page, such as tag index), it returns false, so that
+// the caller can do a full build.
+func (s *Site) runPartialBuild(changed []string) (ok bool, err error) {
+	if !s.built {
+		return false, nil
+	}
+	pagesDir := filepath.Join(s.BaseDir, PagesDirName)
+	var inputs, pageNames []string
+	var includesChanged, assetsChanged bool
+	for _, name := range changed {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return false, nil // deleted
+		}
+		if filepath.Base(name) == DefaultsFileName {
+			return false, nil // may affect any page in the directory
+		}
+		if fi.IsDir() || s.isIgnoredFile(filepath.Base(name)) {
+			continue
+		}
+		rel, err := filepath.Rel(s.BaseDir, name)
+		if err != nil {
+			return false, nil
+		}
+		parts := strings.SplitN(filepath.ToSlash(rel), "/", 2)
+		if len(parts) != 2 {
+			return false, nil
+		}
+		switch parts[0] {
+		case LayoutsDirName:
+			base := filepath.Base(name)
+			inputs = append(inputs, layouts.Input(base[:len(base)-len(filepath.Ext(base))]))
+		case IncludesDirName:
+			includesChanged = true
+		case AssetsDirName:
+			assetsChanged = true
+		case PagesDirName:
+			pageNames = append(pageNames, filepath.FromSlash(parts[1]))
+		default:
+			return false, nil
+		}
+	}
+	if includesChanged {
+		old := s.Includes
+		if err := s.LoadIncludes(); err != nil {
+			s.built = false
+			return true, err
+		}
+		for name, content := range s.Includes {
+			if old[name] != content {
+				inputs = append(inputs, includeInput(name), includeInput("*"))
+			}
+		}
+	}
+	if assetsChanged {
+		changedAssets, ok, err := s.reloadAssets()
+		if err != nil {
+			s.built = false
+			return true, err
+		}
+		if !ok {
+			return false, nil
+		}
+		for _, name := range changedAssets {
+			inputs = append(inputs, assetInput(name), assetInput("*"))
+		}
+	}
+
+	seen := make(map[string]bool)
+	var pages []string
+	var posts []*Post
+	for _, relname := range pageNames {
+		p, err := LoadPage(pagesDir, relname)
+		if err != nil {
+			if IsNotPage(err) {
+				// Static file, copy it.
+				pages = append(pages, relname)
+				continue
+			}
+			return false, nil
+		}
+		old := s.renderedPage(p.url)
+		if old == nil || old.relname != relname || old.sourceMeta != p.sourceMeta {
+			// New or moved page, or its meta changed, which may
+			// change other pages: title, weight, nav, etc.
+			return false, nil
+		}
+		if changed, err := s.pageLinksChanged(pagesDir, relname); err != nil || changed {
+			return false, nil // backlinks or tables of contents changed
+		}
+		seen[p.url] = true
+		pages = append(pages, relname)
+	}
+	if len(inputs) > 0 {
+		postsByURL := make(map[string]*Post, len(s.Config.Posts))
+		for _, p := range s.Config.Posts {
+			postsByURL[p.url] = p
+		}
+		for _, url := range s.graph.Dependents(inputs...) {
+			if seen[url] || isTemplateInput(url) {
+				continue
+			}
+			seen[url] = true
+			if p := s.renderedPage(url); p != nil {
+				pages = append(pages, p.relname)
+			} else if p, ok := postsByURL[url]; ok {
+				posts = append(posts, p)
+			} else {
+				return false, nil // tag index, archive, etc.
+			}
+		}
+		if err := s.LoadLayouts(); err != nil {
+			s.built = false
+			return true, err
+		}
+	}
+
+	log.Printf("* Rebuilding %d outputs.", len(pages)+len(posts))
+	pool := utils.NewPool()
+	for _, v := range pages {
+		relname := v
+		if !pool.Add(func() error { return s.RenderPage(pagesDir, relname) }) {
+			break
+		}
+	}
+	for _, v := range posts {
+		post := v
+		if !pool.Add(func() error { return s.RenderPost(post) }) {
+			break
+		}
+	}
+	if err := pool.Wait(); err != nil {
+		s.built = false
+		return true, err
+	}
+	return true, s.state.Save()
+}
+
+// reloadAssets loads, processes and renders assets again and returns
+// names of assets which changed. It returns false if the change needs
+// a full build, because an asset with CSP hash changed.
+func (s *Site) reloadAssets() (names []string, ok bool, err error) {
+	old := s.Assets
+	if err := s.LoadAssets(); err != nil {
+		return nil, false, err
+	}
+	if err := s.ProcessAssets(); err != nil {
+		return nil, false, err
+	}
+	for _, name := range s.Assets.Names() {
+		a, prev := s.Assets.Get(name), old.Get(name)
+		if prev != nil && prev.RenderedName == a.RenderedName && bytes.Equal(prev.Result, a.Result) {
+			continue
+		}
+		if a.CSP {
+			return nil, false, nil // headers changed
+		}
+		names = append(names, name)
+	}
+	if err := s.RenderAssets(); err != nil {
+		return nil, false, err
+	}
+	return names, true, nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dchest/kkr/redirects"
+	"github.com/dchest/kkr/utils"
+)
+
+const RedirectsFileName = "redirects.yml"
+
+// site.yml -> redirects:
+//
+// Redirects themselves are in redirects.yml, `aliases` meta of pages and
+// short links of posts, this configures which redirect files for hosting
+// providers to generate.
+type RedirectsConfig struct {
+	Netlify string `yaml:"netlify"` // e.g. _redirects (Netlify, Cloudflare Pages)
+	Apache  string `yaml:"apache"`  // e.g. .htaccess
+	Nginx   string `yaml:"nginx"`   // e.g. redirects.map
+}
+
+// LoadRedirects loads redirects.yml from the site directory.
+// Redirects from page aliases are added when pages are rendered.
+func (s *Site) LoadRedirects() error {
+	list, err := redirects.Load(filepath.Join(s.BaseDir, RedirectsFileName))
+	if err != nil {
+		return err
+	}
+	s.redirectsMu.Lock()
+	defer s.redirectsMu.Unlock()
+	s.redirects = list
+	return nil
+}
+
+// addAliases adds redirects from URLs in `aliases` and `short_url`
+// meta to the page URL.
+func (s *Site) addAliases(meta map[string]interface{}, url string) error {
+	aliases, err := metaStrings(meta, "aliases")
+	if err != nil {
+		return err
+	}
+	if short, ok := meta["short_url"].(string); ok {
+		aliases = append(aliases, short)
+	}
+	s.redirectsMu.Lock()
+	defer s.redirectsMu.Unlock()
+	for _, alias := range aliases {
+		if !strings.HasPrefix(alias, "/") {
+			return fmt.Errorf("alias %q must start with /", alias)
+		}
+		if r := redirects.Find(s.redirects, alias); r != nil {
+			if r.To == url {
+				continue // page rendered again
+			}
+			return fmt.Errorf("alias %s: redirect to %s already exists", alias, r.To)
+		}
+		s.redirects = append(s.redirects, &redirects.Redirect{
+			From:   alias,
+			To:     url,
+			Status: redirects.DefaultStatus,
+		})
+	}
+	// Pages are rendered concurrently, keep the order stable.
+	sort.Slice(s.redirects, func(i, j int) bool { return s.redirects[i].From < s.redirects[j].From })
+	return nil
+}
+
+// findRedirect returns a redirect from the URL path or nil if there's none.
+func (s *Site) findRedirect(path string) *redirects.Redirect {
+	s.redirectsMu.Lock()
+	defer s.redirectsMu.Unlock()
+	return redirects.Find(s.redirects, path)
+}
+
+// RenderRedirects renders meta refresh pages for redirects
+// and redirect files for hosting providers.
+func (s *Site) RenderRedirects() error {
+	s.redirectsMu.Lock()
+	list := s.redirects
+	s.redirectsMu.Unlock()
+	if len(list) == 0 {
+		return nil
+	}
+	log.Printf("* Rendering redirects.")
+	for _, r := range list {
+		if !isHTMLURL(r.From) {
+			continue // only the server can redirect
+		}
+		if s.renderedPage(r.From) != nil {
+			return fmt.Errorf("redirect from %s: page exists", r.From)
+		}
+		filename := filepath.Join(OutDirName, filepath.FromSlash(utils.AddIndexIfNeeded(r.From)))
+		log.Printf("R > %s", filename)
+		if err := s.fileWriter.WriteFile(filepath.Join(s.BaseDir, filename), r.HTML()); err != nil {
+			return err
+		}
+	}
+	c := s.Config.Redirects
+	if c == nil {
+		return nil
+	}
+	for _, v := range []struct {
+		filename string
+		data     func([]*redirects.Redirect) []byte
+	}{
+		{c.Netlify, redirects.Netlify},
+		{c.Apache, redirects.Apache},
+		{c.Nginx, redirects.Nginx},
+	} {
+		if v.filename == "" {
+			continue
+		}
+		filename := filepath.Join(OutDirName, filepath.FromSlash(v.filename))
+		log.Printf("R > %s", filename)
+		if err := s.fileWriter.WriteFile(filepath.Join(s.BaseDir, filename), v.data(list)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redirectHandler responds with redirects from redirects.yml,
+// passing other requests to h.
+func (s *Site) redirectHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rd := s.findRedirect(r.URL.Path); rd != nil {
+			http.Redirect(w, r, rd.To, rd.Status)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"html"
+	"path"
+	"strings"
+)
+
+type pageRef struct {
+	URL   string
+	Title string
+}
+
+// addRef adds a reference to the page by ID. If a different
+// page already has this ID, the reference becomes ambiguous.
+func (s *Site) addRef(id, url, title string) {
+	s.refsMu.Lock()
+	defer s.refsMu.Unlock()
+	if r, ok := s.refs[id]; ok && (r == nil || r.URL != url) {
+		s.refs[id] = nil
+		return
+	}
+	s.refs[id] = &pageRef{URL: pageKey(url), Title: title}
+}
+
+// addPageRefs adds references to the page by its file name relative
+// to the pages directory with and without extension.
+func (s *Site) addPageRefs(relname, url, title string) {
+	s.addRef(relname, url, title)
+	if noext := strings.TrimSuffix(relname, path.Ext(relname)); noext != relname {
+		s.addRef(noext, url, title)
+	}
+}
+
+// addPostRefs adds references to the post by its file name without
+// extension (e.g. "2013-01-01-new-year") and its UID.
+func (s *Site) addPostRefs(p *Post) {
+	title, _ := p.meta["title"].(string)
+	if id, ok := p.meta["id"].(string); ok {
+		s.addRef(id, p.url, title)
+	}
+	if p.uid != "" {
+		s.addRef(p.uid, p.url, title)
+	}
+}
+
+// ref returns a reference to the page or post by ID.
+func (s *Site) ref(id string) (*pageRef, error) {
+	s.refsMu.Lock()
+	defer s.refsMu.Unlock()
+	r, ok := s.refs[id]
+	if !ok {
+		return nil, fmt.Errorf("ref: page %q not found", id)
+	}
+	if r == nil {
+		return nil, fmt.Errorf("ref: page ID %q is ambiguous", id)
+	}
+	return r, nil
+}
+
+// refURL returns permalink of the page or post by ID.
+func (s *Site) refURL(id string) (string, error) {
+	r, err := s.ref(id)
+	if err != nil {
+		return "", err
+	}
+	return r.URL, nil
+}
+
+// wikilink returns a link to the page or post by ID with the label
+// or, if it's empty, the page title.
+func (s *Site) wikilink(id, label string) (string, error) {
+	r, err := s.ref(id)
+	if err != nil {
+		return "", err
+	}
+	if label == "" {
+		label = r.Title
+	}
+	if label == "" {
+		label = id
+	}
+	return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(r.URL), html.EscapeString(label)), nil
+}
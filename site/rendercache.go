@@ -0,0 +1,257 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dchest/kkr/deps"
+	"github.com/dchest/kkr/layouts"
+)
+
+const (
+	renderedDirName = "rendered" // in CacheDirName
+	graphFileName   = "deps.json"
+
+	// renderCacheVersion changes when keys of rendered pages
+	// are computed differently.
+	renderCacheVersion = "2"
+)
+
+// Prefixes of dependency graph inputs which are only used by the render
+// cache: files read by templates, backlinks of the page, headings for
+// tables of contents, and functions which write files or use data not
+// tracked by the cache.
+const (
+	fileInputPrefix     = "file:"
+	backlinksInput      = "backlinks:"
+	tocInput            = "toc:"
+	uncachedInputPrefix = "uncached:"
+)
+
+func fileInput(name string) string { return fileInputPrefix + name }
+
+func uncachedInput(name string) string { return uncachedInputPrefix + name }
+
+func init() {
+	for _, name := range []string{"code", "fileExists"} {
+		refFuncs[name] = fileInput
+	}
+	refFuncs["backlinks"] = func(string) string { return backlinksInput }
+	refFuncs["toc"] = func(string) string { return tocInput }
+	for _, name := range []string{
+		"content", "getjson", "getcsv", "state", "image", "exif",
+		"video", "videoinfo", "asciinema", "geojson", "geomap",
+		"mastodon", "tweet", "youtube", "vimeo",
+	} {
+		name := name
+		refFuncs[name] = func(string) string { return uncachedInput(name) }
+	}
+}
+
+// loadPrevGraph sets the dependency graph of the previous build for
+// renderCache, loading it from the cache directory if there was no
+// previous build in this process.
+func (s *Site) loadPrevGraph() error {
+	s.prevGraph, s.graph = s.graph, deps.New()
+	if s.prevGraph.Len() > 0 {
+		return nil
+	}
+	b, err := ioutil.ReadFile(filepath.Join(s.BaseDir, CacheDirName, graphFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := json.Unmarshal(b, s.prevGraph); err != nil {
+		s.prevGraph.Reset() // ignore corrupted file
+	}
+	return nil
+}
+
+// saveGraph saves the dependency graph of this build
+// into the cache directory for the next build.
+func (s *Site) saveGraph() error {
+	b, err := json.Marshal(s.graph)
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(s.BaseDir, CacheDirName, graphFileName)
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, b, 0644)
+}
+
+// prepareRenderCache computes fingerprints of site data, which pages
+// may use in templates, for keys of rendered pages. It must be called
+// after loading posts, assets and backlinks.
+//
+// Posts get the fingerprint of site config, data, downloads, front
+// matter of pages, and meta and short content of posts. Pages also
+// depend on full content of posts, since they may list them.
+func (s *Site) prepareRenderCache() error {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%t%t%t\x00", renderCacheVersion,
+		s.Config.Date.Format("2006-01-02"), s.drafts, s.future, s.devMode)
+	for _, dir := range []string{s.themeDir(), s.BaseDir} {
+		if dir == "" {
+			continue
+		}
+		for _, name := range []string{ConfigFileName, ThemeConfigFileName} {
+			b, err := ioutil.ReadFile(filepath.Join(dir, name))
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			h.Write(b)
+			h.Write([]byte{0})
+		}
+	}
+	fmt.Fprintf(h, "%v\x00%v\x00", s.Config.Data, s.CSP)
+	for _, d := range s.downloads {
+		fmt.Fprintf(h, "%+v\x00", *d)
+	}
+	h.Write(s.pageIndexHash)
+	for _, p := range s.Config.Posts {
+		fmt.Fprintf(h, "%s\x00%v\x00%s\x00", p.url, p.meta, p.ShortContent)
+	}
+	s.renderFingerprints[0] = h.Sum(nil)
+	for _, p := range s.Config.Posts {
+		h.Write([]byte(p.content))
+		h.Write([]byte{0})
+	}
+	s.renderFingerprints[1] = h.Sum(nil)
+	return nil
+}
+
+// renderKey returns a key of the page rendered with layouts, or false
+// if it can't be cached. Inputs are the page's dependencies from the
+// graph: layouts, includes, assets, shortcodes and files, contents of
+// which are included into the key.
+func (s *Site) renderKey(p layouts.PageContext, defaultLayout string, isPost bool, inputs []string) ([]byte, bool) {
+	h := sha256.New()
+	if isPost {
+		h.Write(s.renderFingerprints[0])
+	} else {
+		h.Write(s.renderFingerprints[1])
+	}
+	fmt.Fprintf(h, "%s\x00%s\x00%v\x00", p.URL(), defaultLayout, p.Meta())
+	h.Write([]byte(p.Content()))
+	for _, in := range inputs {
+		fmt.Fprintf(h, "\x00%s\x00", in)
+		if !s.writeInputSource(h, in) {
+			return nil, false
+		}
+	}
+	return h.Sum(nil), true
+}
+
+// writeInputSource writes the content of dependency graph input
+// to h. It returns false if the input is not cacheable.
+func (s *Site) writeInputSource(h hash.Hash, in string) bool {
+	name := in[strings.IndexByte(in, ':')+1:]
+	switch {
+	case strings.HasPrefix(in, "layout:"):
+		src, ok := s.Layouts.Source(name)
+		fmt.Fprintf(h, "%t%s", ok, src)
+	case strings.HasPrefix(in, includeInputPrefix):
+		for n, content := range s.Includes {
+			if n == name || name == "*" {
+				fmt.Fprintf(h, "%s\x00%s\x00", n, content)
+			}
+		}
+		_, ok := s.Includes[name]
+		fmt.Fprintf(h, "%t", ok)
+	case strings.HasPrefix(in, shortcodeInputPrefix):
+		src, ok := s.shortcodeSources[name]
+		fmt.Fprintf(h, "%t%s", ok, src)
+	case strings.HasPrefix(in, assetInputPrefix):
+		for _, n := range s.Assets.Names() {
+			if assetInput(n) == in || name == "*" {
+				a := s.Assets.Get(n)
+				fmt.Fprintf(h, "%s\x00%s\x00", n, a.RenderedName)
+				h.Write(a.Result)
+			}
+		}
+	case strings.HasPrefix(in, fileInputPrefix):
+		if name == "*" {
+			return false
+		}
+		clean := path.Clean("/" + filepath.ToSlash(name))[1:]
+		b, err := ioutil.ReadFile(filepath.Join(s.BaseDir, filepath.FromSlash(clean)))
+		fmt.Fprintf(h, "%t", err == nil)
+		h.Write(b)
+	case in == backlinksInput:
+		// Backlinks may be requested for any URL.
+		s.backlinksMu.Lock()
+		fmt.Fprintf(h, "%v", s.backlinks)
+		s.backlinksMu.Unlock()
+	case in == tocInput:
+		// Table of contents may be requested for any page.
+		s.headingsMu.Lock()
+		urls := make([]string, 0, len(s.pageHeadings))
+		for url := range s.pageHeadings {
+			urls = append(urls, url)
+		}
+		sort.Strings(urls)
+		for _, url := range urls {
+			fmt.Fprintf(h, "%s\x00", url)
+			for _, hd := range s.pageHeadings[url] {
+				fmt.Fprintf(h, "%d\x00%s\x00%s\x00", hd.Level, hd.ID, hd.Text)
+			}
+		}
+		s.headingsMu.Unlock()
+	default:
+		return false // uncached or unknown
+	}
+	return true
+}
+
+// renderedPage is a page rendered with layouts in renderCache.
+type renderedPage struct {
+	Output string
+}
+
+// renderWithLayouts renders the page with layouts, or returns the result
+// cached during a previous build if the page and its dependencies
+// haven't changed.
+func (s *Site) renderWithLayouts(p layouts.PageContext, defaultLayout string, isPost bool) (string, error) {
+	url := p.URL()
+	if inputs := s.prevGraph.Inputs(url); inputs != nil {
+		if key, ok := s.renderKey(p, defaultLayout, isPost, inputs); ok {
+			if b, ok := s.renderCache.Get("page", key); ok {
+				var r renderedPage
+				if err := json.Unmarshal(b, &r); err == nil {
+					s.graph.Set(url, inputs...)
+					return r.Output, nil
+				}
+			}
+		}
+	}
+	out, err := s.Layouts.RenderPage(p, defaultLayout)
+	if err != nil {
+		return "", err
+	}
+	if key, ok := s.renderKey(p, defaultLayout, isPost, s.graph.Inputs(url)); ok {
+		b, err := json.Marshal(&renderedPage{Output: out})
+		if err != nil {
+			return "", err
+		}
+		if err := s.renderCache.Put("page", key, b); err != nil {
+			return "", err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// rotationPeriod returns the number of the period containing the date
+// counted from the Unix epoch. Weeks start on Monday.
+func rotationPeriod(date time.Time, period string) (int64, error) {
+	y, m, d := date.Date()
+	days := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60)
+	switch period {
+	case "", "day":
+		return days, nil
+	case "week":
+		return (days + 3) / 7, nil // 1970-01-01 was Thursday
+	case "month":
+		return int64(y)*12 + int64(m) - 1, nil
+	case "year":
+		return int64(y), nil
+	}
+	return 0, fmt.Errorf("unknown period %q", period)
+}
+
+// rotateFunc returns the item of the list or of the data file with
+// the given name (e.g. "quotes" for data/quotes.yml), which changes
+// every day or, optionally, week, month or year of the build date.
+// The same item is returned for all builds during the period.
+func (s *Site) rotateFunc(v interface{}, period ...string) (interface{}, error) {
+	if name, ok := v.(string); ok {
+		data, ok := s.Config.Data[name]
+		if !ok {
+			return nil, fmt.Errorf("rotate: data %q not found", name)
+		}
+		v = data
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("rotate: expected list, got %T", v)
+	}
+	if rv.Len() == 0 {
+		return nil, nil
+	}
+	p := ""
+	if len(period) > 0 {
+		p = period[0]
+	}
+	n, err := rotationPeriod(s.Config.Date, p)
+	if err != nil {
+		return nil, fmt.Errorf("rotate: %w", err)
+	}
+	i := n % int64(rv.Len())
+	if i < 0 {
+		i += int64(rv.Len())
+	}
+	return rv.Index(int(i)).Interface(), nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/dchest/kkr/layouts"
+	"github.com/dchest/kkr/shortcodes"
+	"github.com/dchest/kkr/utils"
+)
+
+const ShortcodesDirName = "shortcodes"
+
+// shortcodeCall returns a template call of the shortcode, which
+// replaces it in page content, so that it's rendered with the page.
+func shortcodeCall(src string) string {
+	return "{{ shortcode .Page " + strconv.Quote(src) + " }}"
+}
+
+// expandShortcodes processes content with the markup function,
+// expanding shortcodes into template calls.
+func expandShortcodes(content []byte, process func([]byte) ([]byte, error)) ([]byte, error) {
+	content, sources, err := shortcodes.Extract(content)
+	if err != nil {
+		return nil, err
+	}
+	if content, err = process(content); err != nil {
+		return nil, err
+	}
+	return shortcodes.Restore(content, sources, shortcodeCall), nil
+}
+
+// LoadShortcodes loads shortcode templates from shortcodes directory.
+// Shortcode name is the template path without extension,
+// e.g. shortcodes/youtube.html is used as {{< youtube ID >}}.
+func (s *Site) LoadShortcodes() error {
+	s.shortcodes = make(map[string]layouts.Template)
+	s.shortcodeSources = make(map[string]string)
+	log.Printf("* Loading shortcodes.")
+	files := make(map[string]string) // shortcode name -> file
+	for _, dir := range s.overlayDirs(ShortcodesDirName) {
+		if err := s.loadShortcodesDir(dir, files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Site) loadShortcodesDir(dir string, files map[string]string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+		relname, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if s.isIgnoredFile(filepath.Base(relname)) {
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(utils.ReplaceFileExt(relname, ""))
+		s.logTemplate("S", name, path, files[name])
+		files[name] = path
+		t, err := layouts.Parse(name, string(b), s.templateFuncs(s.Config.Autoescape), s.Config.Autoescape)
+		if err != nil {
+			return err
+		}
+		s.shortcodes[name] = t
+		s.shortcodeSources[name] = string(b)
+		s.trackTemplate(shortcodeInputPrefix+name, string(b))
+		return nil
+	})
+}
+
+// shortcode renders the shortcode source with its template.
+// Templates get arguments as .Args and .Named, methods .Arg and .Get
+// to access them, and .Page and .Site.
+func (s *Site) shortcode(page interface{}, src string) (string, error) {
+	sc, err := shortcodes.Parse(src)
+	if err != nil {
+		return "", err
+	}
+	t, ok := s.shortcodes[sc.Name]
+	if !ok {
+		return "", fmt.Errorf("shortcode %q not found", sc.Name)
+	}
+	var buf bytes.Buffer
+	err = t.Execute(&buf, struct {
+		*shortcodes.Shortcode
+		Page interface{}
+		Site interface{}
+	}{sc, page, s.LayoutData()})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
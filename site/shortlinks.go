@@ -0,0 +1,39 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"strings"
+)
+
+const DefaultShortLinkPermalink = "/s/:uid/"
+
+// site.yml -> shortlinks:
+//
+// Posts get short URLs made from their stable UIDs, which redirect
+// to posts like aliases do. Layouts get them as `short_url` meta
+// for share links: {{ .Site.URL }}{{ .Page.short_url }}.
+type ShortLinksConfig struct {
+	Permalink string `yaml:"permalink"` // defaults to /s/:uid/
+}
+
+// setShortURL sets `short_url` meta of the post
+// if short links are configured.
+func (s *Site) setShortURL(p *Post) error {
+	c := s.Config.ShortLinks
+	if c == nil {
+		return nil
+	}
+	permalink := c.Permalink
+	if permalink == "" {
+		permalink = DefaultShortLinkPermalink
+	}
+	if !strings.HasPrefix(permalink, "/") || !strings.Contains(permalink, ":uid") {
+		return fmt.Errorf("shortlinks: permalink %q must start with / and contain :uid", permalink)
+	}
+	p.meta["short_url"] = strings.Replace(permalink, ":uid", p.UID(), -1)
+	return nil
+}
@@ -7,10 +7,14 @@ package site
 
 import (
 	"bytes"
+	"crypto/tls"
+	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"html"
+	htmltemplate "html/template"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -21,21 +25,35 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
 	"github.com/dchest/kkr/csp"
+	"github.com/dchest/kkr/deps"
+	"github.com/dchest/kkr/devcert"
+	"github.com/dchest/kkr/fetch"
 	"github.com/dchest/kkr/filewriter"
+	"github.com/dchest/kkr/sandbox"
 	"github.com/dchest/kkr/search"
 	"github.com/dchest/kkr/search/indexer"
 	"github.com/dchest/kkr/sitemap"
+	"github.com/dchest/kkr/state"
+	"github.com/dchest/kkr/typography"
 	"gopkg.in/yaml.v3"
 
+	"github.com/dchest/kkr/analytics"
 	"github.com/dchest/kkr/assets"
+	"github.com/dchest/kkr/embed"
 	"github.com/dchest/kkr/filters"
 	"github.com/dchest/kkr/fspoll"
+	"github.com/dchest/kkr/hashcache"
+	"github.com/dchest/kkr/headings"
 	"github.com/dchest/kkr/layouts"
+	"github.com/dchest/kkr/links"
 	"github.com/dchest/kkr/markup"
+	"github.com/dchest/kkr/podcast"
+	"github.com/dchest/kkr/redirects"
 	"github.com/dchest/kkr/utils"
 )
 
@@ -43,6 +61,11 @@ const (
 	ConfigFileName = "site.yml"
 	AssetsFileName = "assets.yml"
 	CSPFileName    = "csp.yml"
+	StateFileName  = ".kkr-state.yml"
+	CacheDirName   = ".kkr-cache"
+
+	compressedDirName = "compressed"
+	certDirName       = "cert"
 
 	AssetsDirName   = "assets" // just a convention, currently used for watching only
 	IncludesDirName = "includes"
@@ -57,6 +80,7 @@ const (
 	DefaultPostLayout     = "post"
 	DefaultPageLayout     = "default"
 	DefaultTagIndexLayout = "tag"
+	DefaultTagFeedLayout  = "tagfeed"
 )
 
 var (
@@ -68,11 +92,14 @@ var (
 type SearchConfig struct {
 	Index   string   `yaml:"index"`
 	Exclude []string `yaml:"exclude"`
+	Page    string   `yaml:"page"` // URL of search page, e.g. /search/
 }
 
 type TagIndexConfig struct {
-	Permalink string `yaml:"permalink"`
-	Layout    string `yaml:"layout"`
+	Permalink     string `yaml:"permalink"`
+	Layout        string `yaml:"layout"`
+	FeedPermalink string `yaml:"feed_permalink"` // e.g. /blog/tags/:tag/feed.xml
+	FeedLayout    string `yaml:"feed_layout"`
 }
 
 type StaticConfig struct {
@@ -84,37 +111,127 @@ type StaticConfig struct {
 
 type Config struct {
 	// Loadable from YAML.
-	Name       string                     `yaml:"name"`
-	Author     string                     `yaml:"author"`
-	Permalink  string                     `yaml:"permalink"`
-	URL        string                     `yaml:"url"`
-	Static     *StaticConfig              `yaml:"static"`
-	Filters    map[string]interface{}     `yaml:"filters"`
-	Properties map[string]interface{}     `yaml:"properties"`
-	Search     *SearchConfig              `yaml:"search"`
-	Markup     *markup.Options            `yaml:"markup"`
-	Compress   *filewriter.CompressConfig `yaml:"compress"`
-	TagIndex   *TagIndexConfig            `yaml:"tagindex"`
-	Sitemap    string                     `yaml:"sitemap"`
+	Name          string                     `yaml:"name"`
+	Author        string                     `yaml:"author"`
+	Lang          string                     `yaml:"lang"`    // e.g. en, pages can override it with `lang` meta
+	Dir           string                     `yaml:"dir"`     // ltr, rtl or auto, guessed from lang if empty
+	Charset       string                     `yaml:"charset"` // DefaultCharset if empty
+	Permalink     string                     `yaml:"permalink"`
+	Theme         string                     `yaml:"theme"` // directory in themes
+	URL           string                     `yaml:"url"`
+	Static        *StaticConfig              `yaml:"static"`
+	Filters       map[string]interface{}     `yaml:"filters"`
+	Properties    map[string]interface{}     `yaml:"properties"`
+	Search        *SearchConfig              `yaml:"search"`
+	Whitespace    bool                       `yaml:"collapse_whitespace"` // collapse whitespace between tags in HTML
+	Markup        *markup.Options            `yaml:"markup"`
+	Autoescape    bool                       `yaml:"autoescape"` // render layouts with html/template
+	Compress      *filewriter.CompressConfig `yaml:"compress"`
+	TagIndex      *TagIndexConfig            `yaml:"tagindex"`
+	CategoryIndex *CategoryIndexConfig       `yaml:"categoryindex"`
+	Taxonomies    map[string]*TaxonomyConfig `yaml:"taxonomies"`
+	Archives      *ArchivesConfig            `yaml:"archives"`
+	Sitemap       string                     `yaml:"sitemap"`
+	Humans        *HumansConfig              `yaml:"humans"`
+	LLMs          *LLMsConfig                `yaml:"llms"`
+	Fetch         *fetch.Config              `yaml:"fetch"`
+	Galleries     []*GalleryConfig           `yaml:"galleries"`
+	Images        *ImagesConfig              `yaml:"images"`
+	Versions      *VersionsConfig            `yaml:"versions"`
+	PDF           *PDFConfig                 `yaml:"pdf"`
+	Newsletter    *NewsletterConfig          `yaml:"newsletter"`
+	Lite          []*LiteConfig              `yaml:"lite"`
+	Video         *VideoConfig               `yaml:"video"`
+	Asciinema     *AsciinemaConfig           `yaml:"asciinema"`
+	Podcast       *podcast.Config            `yaml:"podcast"`
+	WebSub        *WebSubConfig              `yaml:"websub"`
+	Downloads     *DownloadsConfig           `yaml:"downloads"`
+	Typography    *typography.Options        `yaml:"typography"`
+	Hyphenation   *HyphenationConfig         `yaml:"hyphenation"`
+	Links         *links.Policy              `yaml:"links"`
+	Anchors       *headings.Options          `yaml:"anchors"`
+	Analytics     *analytics.Config          `yaml:"analytics"`
+	Orphans       *OrphansConfig             `yaml:"orphans"`
+	Budgets       *BudgetsConfig             `yaml:"budgets"`
+	Proxy         map[string]string          `yaml:"proxy"` // path prefix -> upstream URL for dev server
+	HTTPS         bool                       `yaml:"https"` // serve over HTTPS with self-signed certificate
+	Access        *AccessConfig              `yaml:"access"`
+	Headers       map[string]string          `yaml:"headers"` // sent by the server
+	CacheControl  []CacheControlRule         `yaml:"cache_control"`
+	HeadersFile   string                     `yaml:"headers_file"` // e.g. _headers (Netlify, Cloudflare Pages)
+	Manifest      string                     `yaml:"manifest"`     // e.g. manifest.json, checksums of output files
+	Redirects     *RedirectsConfig           `yaml:"redirects"`
+	ShortLinks    *ShortLinksConfig          `yaml:"shortlinks"`
+	Deploy        *DeployConfig              `yaml:"deploy"`
+	Test          *TestConfig                `yaml:"test"`
+	Diagrams      map[string][]string        `yaml:"diagrams"` // language -> command producing SVG
+	Future        bool                       `yaml:"future"`   // include future-dated posts
+	Safe          bool                       `yaml:"safe"`     // don't run external commands, see SetSafeMode
+	Sandbox       *sandbox.Config            `yaml:"sandbox"`  // run external commands in sandbox
 
 	// Generated.
-	Date    time.Time
-	Posts   Posts            `yaml:"-"`
-	Tags    map[string]Posts `yaml:"-"`
-	TagList []string         `yaml:"-"`
+	Date         time.Time
+	Data         map[string]interface{}      `yaml:"-"`
+	Posts        Posts                       `yaml:"-"`
+	Tags         map[string]Posts            `yaml:"-"`
+	TagList      []string                    `yaml:"-"`
+	Categories   map[string]Posts            `yaml:"-"`
+	CategoryList []string                    `yaml:"-"`
+	Terms        map[string]map[string]Posts `yaml:"-"` // taxonomy -> term -> posts
+	TermLists    map[string][]string         `yaml:"-"`
 }
 
 func (c Config) PostsByTag(tag string) Posts {
 	return c.Tags[tag]
 }
 
+func (c Config) PostsByCategory(category string) Posts {
+	return c.Categories[category]
+}
+
+// OnThisDay returns posts published on the month and day
+// of the site build date in previous years.
+func (c Config) OnThisDay() Posts {
+	return c.Posts.OnDay(c.Date)
+}
+
+// YearsSince returns the number of full years passed
+// since the given time until the site build date.
+func (c Config) YearsSince(t time.Time) int {
+	years := c.Date.Year() - t.Year()
+	if c.Date.Month() < t.Month() || (c.Date.Month() == t.Month() && c.Date.Day() < t.Day()) {
+		years--
+	}
+	return years
+}
+
 func (c Config) TagURL(tag string) (string, error) {
 	if c.TagIndex == nil {
 		return "", errors.New("No tagindex in site.yml")
 	}
-	out := strings.Replace(c.TagIndex.Permalink, ":tag", tag, -1)
-	out = strings.Replace(out, ":lctag", strings.ToLower(tag), -1)
-	return out, nil
+	return expandTagPermalink(c.TagIndex.Permalink, tag), nil
+}
+
+// WebSubHub returns URL of WebSub hub or an empty string
+// if it's not configured. Feeds declare it as rel="hub" link.
+func (c Config) WebSubHub() string {
+	if c.WebSub == nil {
+		return ""
+	}
+	return c.WebSub.Hub
+}
+
+// TagFeedURL returns URL of the tag feed.
+func (c Config) TagFeedURL(tag string) (string, error) {
+	if c.TagIndex == nil || c.TagIndex.FeedPermalink == "" {
+		return "", errors.New("No tagindex.feed_permalink in site.yml")
+	}
+	return expandTagPermalink(c.TagIndex.FeedPermalink, tag), nil
+}
+
+func expandTagPermalink(permalink, tag string) string {
+	out := strings.Replace(permalink, ":tag", tag, -1)
+	return strings.Replace(out, ":lctag", strings.ToLower(tag), -1)
 }
 
 func readConfig(filename string) (*Config, error) {
@@ -122,13 +239,32 @@ func readConfig(filename string) (*Config, error) {
 	if err := utils.UnmarshallYAMLFile(filename, &c); err != nil {
 		return nil, err
 	}
+	if c.Theme != "" {
+		b, err := readThemedConfig(filename, c.Theme)
+		if err != nil {
+			return nil, err
+		}
+		c = Config{}
+		if err := yaml.Unmarshal(b, &c); err != nil {
+			return nil, err
+		}
+	}
 	// Set defaults.
 	if c.Permalink == "" {
 		c.Permalink = DefaultPermalink
 	}
+	if c.Charset == "" {
+		c.Charset = DefaultCharset
+	}
+	if err := checkDirection(c.Dir); err != nil {
+		return nil, err
+	}
 	if c.Markup == nil {
 		c.Markup = &markup.Options{} // default options
 	}
+	if err := c.Markup.Check(); err != nil {
+		return nil, err
+	}
 	// Some cleanup.
 	c.URL = utils.StripEndSlash(c.URL)
 	// Precalculate compressors.
@@ -144,22 +280,82 @@ type Site struct {
 	CSP         csp.Directives
 	Includes    map[string]string
 
-	buildQueue  chan bool
+	buildQueue  chan []string // changed files, or nil for full build
 	buildErrors chan error
+	built       bool        // the last full build succeeded
+	graph       *deps.Graph // layouts used by rendered pages
+	prevGraph   *deps.Graph // graph of the previous build, for renderCache
 
 	watcher             *fspoll.Watcher
 	cleanBeforeBuilding bool
+	pruneAfterBuilding  bool // remove files in out/ not written by full builds
+	safe                bool // see SetSafeMode
 	fileWriter          *filewriter.FileWriter
+	compressCache       *hashcache.Cache
+	variantsCache       *hashcache.Cache // image variants in other formats
+	renderCache         *hashcache.Cache // pages rendered with layouts
+	renderFingerprints  [2][]byte        // site data used by posts and pages, see renderKey
+	pageIndexHash       []byte           // front matter of all pages
 	devMode             bool
+	https               bool
+	qrCode              bool
+	traceTemplates      bool
 	layoutFuncs         layouts.FuncMap
+	htmlLayoutFuncs     layouts.FuncMap // with autoescape
+	analyticsSnippet    string
+	analyticsSources    map[string][]string // CSP sources for analytics
 	sitemap             *sitemap.Sitemap
+	state               *state.Store
+	fetcher             *fetch.Fetcher
+	downloads           []*Download
+	drafts              bool // load drafts
+	future              bool // load future-dated posts
+	hyphenPatterns      map[string]*typography.Patterns
+	draftURLs           map[string]bool // URLs of loaded drafts
+
+	videoMu    sync.Mutex
+	imagesMu   sync.Mutex
+	pdfMu      sync.Mutex
+	pdfPages   []*pdfPage        // pages with PDF versions, rendered after the build
+	imageSizes map[string][2]int // dimensions of images by file name, size and time
+	pagesMu    sync.Mutex
+
+	pageTitles map[string]string   // page titles by URL for breadcrumbs
+	navPages   map[string]*navPage // pages in navigation tree by URL
+	navRoots   []*navPage          // top-level pages outside of root section
+	refsMu     sync.Mutex
+	refs       map[string]*pageRef // pages and posts by ID, nil if ambiguous
+
+	backlinksMu     sync.Mutex
+	backlinks       backlinksGraph   // collected before rendering
+	collectingLinks bool             // content filter collects links and headings
+	pages           map[string]*Page // rendered pages by URL
+
+	headingsMu   sync.Mutex
+	pageHeadings map[string][]*headings.Heading // collected before rendering
+
+	redirectsMu sync.Mutex
+	redirects   []*redirects.Redirect // from redirects.yml
+
+	partialsMu sync.Mutex
+	partials   map[string]layouts.Template // parsed includes
+
+	shortcodes       map[string]layouts.Template
+	shortcodeSources map[string]string
+
+	headersMu    sync.Mutex
+	headers      http.Header // sent by the server
+	cacheControl []CacheControlRule
+
+	configMu sync.Mutex // guards replacing Config, which the server reads
 }
 
 func Open(dir string) (s *Site, err error) {
 	s = &Site{
 		BaseDir:     dir,
-		buildQueue:  make(chan bool),
+		buildQueue:  make(chan []string),
 		buildErrors: make(chan error),
+		graph:       deps.New(),
 	}
 	// Try loading config.
 	if err := s.LoadConfig(); err != nil {
@@ -167,12 +363,8 @@ func Open(dir string) (s *Site, err error) {
 	}
 	// Launch builder goroutine.
 	go func() {
-		for {
-			do := <-s.buildQueue
-			if !do {
-				return
-			}
-			s.buildErrors <- s.runBuild()
+		for changed := range s.buildQueue {
+			s.buildErrors <- s.runQueuedBuild(changed)
 		}
 	}()
 	return s, nil
@@ -199,7 +391,20 @@ func (s *Site) LoadConfig() error {
 	if err != nil {
 		return err
 	}
+	s.compressCache = nil
+	if compress != nil {
+		s.compressCache = hashcache.New(filepath.Join(s.BaseDir, CacheDirName, compressedDirName))
+		s.fileWriter.SetCache(s.compressCache)
+	}
+	s.variantsCache = hashcache.New(filepath.Join(s.BaseDir, CacheDirName, variantsDirName))
+	s.renderCache = hashcache.New(filepath.Join(s.BaseDir, CacheDirName, renderedDirName))
+	s.configMu.Lock()
 	s.Config = conf
+	s.configMu.Unlock()
+	sandbox.SetSafeMode(s.safe || conf.Safe)
+	if err := sandbox.Configure(conf.Sandbox, s.BaseDir); err != nil {
+		return err
+	}
 	if conf.Sitemap != "" {
 		s.sitemap = sitemap.New()
 	}
@@ -215,20 +420,35 @@ func (s *Site) LoadConfig() error {
 func (s *Site) LoadAssets() error {
 	log.Printf("* Loading assets.")
 	// Load assets.
-	assets, err := assets.Load(AssetsFileName)
+	var c *assets.Collection
+	var err error
+	if td := s.themeDir(); td != "" {
+		c, err = assets.LoadWithTheme(filepath.Join(s.BaseDir, AssetsFileName), td)
+	} else {
+		c, err = assets.Load(filepath.Join(s.BaseDir, AssetsFileName))
+	}
 	if err != nil {
 		return err
 	}
 	if s.Config.Search != nil && s.Config.Search.Index != "" {
-		assets.SetStringAsset("search-script", search.GetSearchScript(s.Config.Search.Index))
+		c.SetStringAsset("search-script", search.GetSearchScript(s.Config.Search.Index))
 	}
-	s.Assets = assets
+	c.SetStringAsset("video-script", embed.VideoScript)
+	s.Assets = c
 	return nil
 }
 
 func (s *Site) LoadCSP() error {
 	log.Printf("* Loading CSP.")
-	csp, err := csp.Load(CSPFileName)
+	sources := s.analyticsSources
+	if s.Config.Images != nil && s.Config.Images.InlineMax > 0 {
+		sources = make(map[string][]string, len(s.analyticsSources)+1)
+		for k, v := range s.analyticsSources {
+			sources[k] = v
+		}
+		sources["img-src"] = append(append([]string(nil), sources["img-src"]...), "data:")
+	}
+	csp, err := csp.LoadWithSources(CSPFileName, sources)
 	if err != nil {
 		return err
 	}
@@ -236,6 +456,48 @@ func (s *Site) LoadCSP() error {
 	return nil
 }
 
+func (s *Site) LoadFetcher() (err error) {
+	s.fetcher, err = fetch.New(s.Config.Fetch, filepath.Join(s.BaseDir, CacheDirName, "fetch"))
+	return err
+}
+
+func (s *Site) LoadState() error {
+	st, err := state.Load(filepath.Join(s.BaseDir, StateFileName))
+	if err != nil {
+		return err
+	}
+	s.state = st
+	return nil
+}
+
+// recordFirstBuilt sets "first_built" meta of the page to the time
+// it was first built, remembering it in the state.
+func (s *Site) recordFirstBuilt(p *Page) {
+	p.meta["first_built"] = s.state.SetDefault("first_built", p.url, s.Config.Date)
+}
+
+// assignUID sets a stable short identifier of the post, which doesn't
+// change when permalink changes. It is taken from "uid" meta if it
+// exists, otherwise it's generated and remembered in the state under
+// the post filename.
+func (s *Site) assignUID(p *Post, relname string) error {
+	if v, ok := p.meta["uid"]; ok {
+		uid, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s: 'uid' is not a string", relname)
+		}
+		p.uid = uid
+		return nil
+	}
+	uid, ok := s.state.SetDefault("uids", filepath.ToSlash(relname), utils.NewUID()).(string)
+	if !ok {
+		return fmt.Errorf("%s: uid in %s is not a string", relname, StateFileName)
+	}
+	p.uid = uid
+	p.meta["uid"] = uid
+	return nil
+}
+
 func (s *Site) LoadPageFilters() error {
 	// Load page filters.
 	pageFilters := filters.NewCollection()
@@ -251,34 +513,60 @@ func (s *Site) LoadPageFilters() error {
 func (s *Site) LoadLayouts() (err error) {
 	log.Printf("* Loading layouts.")
 	s.Layouts = layouts.NewCollection(s)
-	return s.Layouts.AddDir(filepath.Join(s.BaseDir, LayoutsDirName))
+	s.Layouts.SetContentFilter(s.filterContent)
+	s.Layouts.SetAutoescape(s.Config.Autoescape)
+	s.Layouts.SetGraph(s.graph)
+	s.Layouts.SetRefFuncs(refFuncs)
+	s.Layouts.SetTrace(func(l, overridden *layouts.Layout) {
+		old := ""
+		if overridden != nil {
+			old = overridden.Filename
+		}
+		s.logTemplate("L", l.Name, l.Filename, old)
+	})
+	for _, dir := range s.overlayDirs(LayoutsDirName) {
+		if err := s.Layouts.AddDir(dir); err != nil {
+			if os.IsNotExist(err) && s.themeDir() != "" {
+				continue // theme or site may have no layouts
+			}
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *Site) LoadIncludes() (err error) {
 	log.Printf("* Loading includes.")
 	s.Includes = make(map[string]string)
-	includesDir := filepath.Join(s.BaseDir, IncludesDirName)
-	err = filepath.Walk(includesDir, func(path string, fi os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		relname, err := filepath.Rel(includesDir, path)
-		if err != nil {
-			return err
-		}
-		if fi.IsDir() {
+	s.partialsMu.Lock()
+	s.partials = make(map[string]layouts.Template)
+	s.partialsMu.Unlock()
+	files := make(map[string]string) // include name -> file
+	for _, includesDir := range s.overlayDirs(IncludesDirName) {
+		err = filepath.Walk(includesDir, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			relname, err := filepath.Rel(includesDir, path)
+			if err != nil {
+				return err
+			}
+			if fi.IsDir() {
+				return nil
+			}
+			s.logTemplate("I", relname, path, files[relname])
+			files[relname] = path
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			s.Includes[relname] = string(b)
+			s.trackTemplate(includeInput(relname), string(b))
 			return nil
-		}
-		log.Printf("I %s", relname)
-		b, err := ioutil.ReadFile(path)
-		if err != nil {
+		})
+		if err != nil && !os.IsNotExist(err) {
 			return err
 		}
-		s.Includes[relname] = string(b)
-		return nil
-	})
-	if err != nil && !os.IsNotExist(err) {
-		return err
 	}
 	return nil
 }
@@ -297,10 +585,9 @@ func (s *Site) isIgnoredFile(filename string) bool {
 	return false
 }
 
-func (s *Site) LoadPosts() (err error) {
-	log.Printf("* Loading posts.")
-	postsDir := filepath.Join(s.BaseDir, PostsDirName)
-	posts := make(Posts, 0)
+// loadPostsDir loads posts from the given directory, marking them
+// as drafts if draft is true.
+func (s *Site) loadPostsDir(postsDir string, draft bool) (posts Posts, err error) {
 	err = filepath.Walk(postsDir, func(path string, fi os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -315,7 +602,7 @@ func (s *Site) LoadPosts() (err error) {
 		if s.isIgnoredFile(relname) {
 			return nil // skip ignored files
 		}
-		if !utils.HasFileExt(relname, PostExtensions) {
+		if !utils.HasFileExt(relname, PostExtensions) && markup.ExternalFor(relname) == "" {
 			return nil
 		}
 		log.Printf("B < %s\n", relname)
@@ -323,55 +610,132 @@ func (s *Site) LoadPosts() (err error) {
 		if err != nil {
 			return err
 		}
+		if p.Date.After(s.Config.Date) && !s.future && !s.Config.Future {
+			log.Printf("B - %s (scheduled for %s)\n", relname, p.Date.Format("2006-01-02 15:04"))
+			return nil
+		}
+		if err := s.loadPostTerms(p); err != nil {
+			return fmt.Errorf("%s: %w", relname, err)
+		}
+		if draft {
+			p.meta["draft"] = true
+		} else {
+			s.recordFirstBuilt(&p.Page)
+		}
+		if err := s.assignUID(p, relname); err != nil {
+			return err
+		}
+		if err := s.setShortURL(p); err != nil {
+			return err
+		}
 		posts = append(posts, p)
 		return nil
 	})
 	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (s *Site) LoadPosts() (err error) {
+	log.Printf("* Loading posts.")
+	posts, err := s.loadPostsDir(filepath.Join(s.BaseDir, PostsDirName), false)
+	if err != nil {
 		return err
 	}
+	s.draftURLs = make(map[string]bool)
+	if s.drafts {
+		log.Printf("* Loading drafts.")
+		drafts, err := s.loadPostsDir(filepath.Join(s.BaseDir, DraftsDirName), true)
+		if err != nil {
+			return err
+		}
+		for _, p := range drafts {
+			s.draftURLs[p.url] = true
+		}
+		posts = append(posts, drafts...)
+	}
 	// Sort and add to config.
 	posts.Sort()
 	s.Config.Posts = posts
-	// Distribute by tags.
-	tags := make(map[string]Posts)
 	for _, p := range posts {
-		for _, tag := range p.Tags {
-			// If we have a lowercased tag, but don't have
-			// the original-cased tag, normalize it to lowercase;
-			// do the same with title-cased tag.
-			lowerTag := strings.ToLower(tag)
-			titleTag := strings.Title(tag) // deprecated, but we don't care about punctuation
-			if _, hasTag := tags[tag]; !hasTag {
-				if _, hasLower := tags[lowerTag]; hasLower {
-					tag = lowerTag
+		s.addPostRefs(p)
+	}
+	// Link posts chronologically (posts are sorted newest first).
+	for i, p := range posts {
+		if i > 0 {
+			p.meta["next_post"] = postRef(posts[i-1])
+		}
+		if i < len(posts)-1 {
+			p.meta["prev_post"] = postRef(posts[i+1])
+		}
+	}
+	// Distribute by tags and categories.
+	s.Config.Tags, s.Config.TagList = groupPosts(posts, func(p *Post) []string { return p.Tags })
+	s.Config.Categories, s.Config.CategoryList = groupPosts(posts, func(p *Post) []string { return p.Categories })
+	s.groupTerms(posts)
+	return nil
+}
+
+// postRef returns a reference to the post for navigation.
+func postRef(p *Post) map[string]interface{} {
+	return map[string]interface{}{
+		"url":   p.url,
+		"title": p.meta["title"],
+	}
+}
+
+// groupPosts distributes posts by names returned by namesFn
+// (tags or categories) and returns the map and a sorted list of names.
+func groupPosts(posts Posts, namesFn func(p *Post) []string) (map[string]Posts, []string) {
+	groups := make(map[string]Posts)
+	for _, p := range posts {
+		for _, name := range namesFn(p) {
+			// If we have a lowercased name, but don't have
+			// the original-cased name, normalize it to lowercase;
+			// do the same with title-cased name.
+			lowerName := strings.ToLower(name)
+			titleName := strings.Title(name) // deprecated, but we don't care about punctuation
+			if _, hasName := groups[name]; !hasName {
+				if _, hasLower := groups[lowerName]; hasLower {
+					name = lowerName
 				} else {
-					if _, hasTitle := tags[titleTag]; hasTitle {
-						tag = titleTag
+					if _, hasTitle := groups[titleName]; hasTitle {
+						name = titleName
 					}
 				}
 			}
-			tags[tag] = append(tags[tag], p)
+			groups[name] = append(groups[name], p)
 		}
 	}
-	tagList := make([]string, 0, len(tags))
-	for tagName := range tags {
-		tagList = append(tagList, tagName)
+	list := make([]string, 0, len(groups))
+	for name := range groups {
+		list = append(list, name)
 	}
-	sort.Strings(tagList)
-	s.Config.TagList = tagList
-	s.Config.Tags = tags
-	return nil
+	sort.Strings(list)
+	return groups, list
 }
 
 func (s *Site) RenderPost(p *Post) error {
+	if err := s.addAliases(p.meta, p.url); err != nil {
+		return fmt.Errorf("%s: %w", p.Filename, err)
+	}
+	s.setBreadcrumbs(p.meta)
+	s.preparePDF(&p.Page)
+	lite := s.prepareLite(&p.Page, true)
 	// Render post.
-	data, err := s.Layouts.RenderPage(p, DefaultPostLayout)
+	data, err := s.renderWithLayouts(p, DefaultPostLayout, true)
 	if err != nil {
 		return err
 	}
 	log.Printf("B > %s\n", filepath.Join(OutDirName, p.Filename))
+	b, err := s.transformHTML(p.meta, p.Filename, []byte(data))
+	if err != nil {
+		return err
+	}
+	b = s.liteLinks(p.meta, b)
 	// Apply filter.
-	b, err := s.PageFilters.ApplyFilter(filepath.Ext(p.Filename), []byte(data))
+	b, err = s.PageFilters.ApplyFilter(filepath.Ext(p.Filename), b)
 	if err != nil {
 		return err
 	}
@@ -384,7 +748,13 @@ func (s *Site) RenderPost(p *Post) error {
 		}
 	}
 	// Write to file.
-	return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, p.Filename), b)
+	if err := s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, p.Filename), b); err != nil {
+		return err
+	}
+	if lite != nil {
+		return s.renderLite(&p.Page, lite)
+	}
+	return nil
 }
 
 func (s *Site) RenderPosts() error {
@@ -418,13 +788,44 @@ func (s *Site) RenderTag(tag string) error {
 		return fmt.Errorf("cannot generate tag index %q: %w", tag, err)
 	}
 	p := NewTagIndex(tag, url)
-	data, err := s.Layouts.RenderPage(p, DefaultTagIndexLayout)
+	p.TagPosts = s.Config.Tags[tag]
+	layout := s.Config.TagIndex.Layout
+	if layout == "" {
+		layout = DefaultTagIndexLayout
+	}
+	if s.Config.TagIndex.FeedPermalink != "" {
+		feedURL, _ := s.Config.TagFeedURL(tag)
+		p.meta["feed_url"] = utils.CleanPermalink(feedURL)
+		// Render tag feed.
+		f := NewTagIndex(tag, feedURL)
+		f.TagPosts = p.TagPosts
+		f.meta["sitemap"] = false
+		feedLayout := s.Config.TagIndex.FeedLayout
+		if feedLayout == "" {
+			feedLayout = DefaultTagFeedLayout
+		}
+		if err := s.renderTagIndex(f, feedLayout); err != nil {
+			return err
+		}
+	}
+	return s.renderTagIndex(p, layout)
+}
+
+func (s *Site) renderTagIndex(p *TagIndex, layout string) error {
+	p.meta["url"] = p.url
+	p.meta["tag"] = p.Tag
+	s.setBreadcrumbs(p.meta)
+	data, err := s.Layouts.RenderPage(p, layout)
 	if err != nil {
 		return err
 	}
 	log.Printf("T > %s\n", filepath.Join(OutDirName, p.Filename))
+	b, err := s.transformHTML(p.meta, p.Filename, []byte(data))
+	if err != nil {
+		return err
+	}
 	// Apply filter.
-	b, err := s.PageFilters.ApplyFilter(filepath.Ext(p.Filename), []byte(data))
+	b, err = s.PageFilters.ApplyFilter(filepath.Ext(p.Filename), b)
 	if err != nil {
 		return err
 	}
@@ -438,28 +839,58 @@ func (s *Site) RenderTag(tag string) error {
 	}
 	// Write to file.
 	return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, p.Filename), b)
-
 }
 
 func (s *Site) RenderPage(pagesDir, relname string) error {
+	return s.renderPage(pagesDir, relname, nil)
+}
+
+// renderPage renders the page from pagesDir, or from the directory
+// of the given docs version, which prefixes its output name.
+func (s *Site) renderPage(pagesDir, relname string, v *Version) error {
 	log.Printf("P < %s\n", relname)
-	p, err := LoadPage(pagesDir, relname)
+	prefix := ""
+	if v != nil {
+		prefix = strings.Trim(v.Permalink, "/")
+	}
+	p, err := loadPage(pagesDir, relname, prefix)
 	if err != nil {
 		if IsNotPage(err) {
+			if v != nil {
+				return s.copyVersionFile(v, relname)
+			}
+			if utils.HasFileExt(relname, GeoJSONExtensions) {
+				return s.RenderGeoJSON(pagesDir, relname)
+			}
 			// Not a page, copy file.
 			return s.CopyFile(relname)
 		}
 		return err
 	}
+	s.preparePageMeta(p, v)
+	s.addRenderedPage(p)
+	if err := s.addAliases(p.meta, p.url); err != nil {
+		return fmt.Errorf("%s: %w", relname, err)
+	}
+	s.preparePDF(p)
+	var lite *LiteConfig
+	if v == nil {
+		lite = s.prepareLite(p, false)
+	}
 	// Render page.
-	data, err := s.Layouts.RenderPage(p, DefaultPageLayout)
+	data, err := s.renderWithLayouts(p, DefaultPageLayout, false)
 	if err != nil {
 		return err
 	}
 	log.Printf("P > %s\n", filepath.Join(OutDirName, p.Filename))
 	fileExt := filepath.Ext(p.Filename)
+	b, err := s.transformHTML(p.meta, p.Filename, []byte(data))
+	if err != nil {
+		return err
+	}
+	b = s.liteLinks(p.meta, b)
 	// Apply filter.
-	b, err := s.PageFilters.ApplyFilter(fileExt, []byte(data))
+	b, err = s.PageFilters.ApplyFilter(fileExt, b)
 	if err != nil {
 		return err
 	}
@@ -477,11 +908,50 @@ func (s *Site) RenderPage(pagesDir, relname string) error {
 		}
 	}
 	// Write to file.
-	return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, p.Filename), b)
+	if err := s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, p.Filename), b); err != nil {
+		return err
+	}
+	if lite != nil {
+		return s.renderLite(p, lite)
+	}
+	return nil
+}
+
+// preparePageMeta sets meta of the page which depends on the site:
+// version, first build time, breadcrumbs and section links.
+func (s *Site) preparePageMeta(p *Page, v *Version) {
+	s.setPageVersion(p.meta, v)
+	s.recordFirstBuilt(p)
+	s.setBreadcrumbs(p.meta)
+	s.setSectionLinks(p.meta)
+}
+
+// pageKey returns a key for pages map from URL.
+// Root index page has an empty URL, so it's changed to "/".
+func pageKey(url string) string {
+	if url == "" {
+		return "/"
+	}
+	return url
+}
+
+func (s *Site) addRenderedPage(p *Page) {
+	s.pagesMu.Lock()
+	defer s.pagesMu.Unlock()
+	s.pages[pageKey(p.url)] = p
+}
+
+// renderedPage returns a rendered page by its URL
+// or nil if there's no such page.
+func (s *Site) renderedPage(url string) *Page {
+	s.pagesMu.Lock()
+	defer s.pagesMu.Unlock()
+	return s.pages[pageKey(url)]
 }
 
 func (s *Site) RenderPages() error {
 	log.Printf("* Rendering pages")
+	s.pages = make(map[string]*Page)
 	inDir := filepath.Join(s.BaseDir, PagesDirName)
 	pool := utils.NewPool()
 	err := filepath.Walk(inDir, func(path string, fi os.FileInfo, err error) error {
@@ -498,6 +968,9 @@ func (s *Site) RenderPages() error {
 		if s.isIgnoredFile(filepath.Base(relname)) {
 			return nil // skip ignored files
 		}
+		if filepath.Base(relname) == DefaultsFileName {
+			return nil // not content, see LoadPage
+		}
 		if !pool.Add(func() error { return s.RenderPage(inDir, relname) }) {
 			return filepath.SkipDir
 		}
@@ -515,7 +988,7 @@ func (s *Site) CopyFile(filename string) error {
 	inFile := filepath.Join(inDir, filename)
 	outFile := filepath.Join(outDir, filename)
 
-	if err := s.fileWriter.CopyFile(outFile, inFile); err != nil {
+	if err := s.copyImage(outFile, inFile); err != nil {
 		return err
 	}
 	log.Printf("C > %s\n", filepath.Join(OutDirName, filename))
@@ -529,14 +1002,17 @@ func (s *Site) RenderSitemap() error {
 		if err := s.sitemap.Render(&buf, s.Config.URL); err != nil {
 			return err
 		}
-		return s.fileWriter.WriteFile(filepath.Join(OutDirName, s.Config.Sitemap), buf.Bytes())
+		return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, s.Config.Sitemap), buf.Bytes())
 	}
 	return nil
 }
 
 func (s *Site) ProcessAssets() error {
 	log.Printf("* Processing assets.")
-	return s.Assets.Process()
+	if err := s.Assets.Process(); err != nil {
+		return err
+	}
+	return s.addInlineHashes()
 }
 
 func (s *Site) RenderAssets() error {
@@ -549,10 +1025,17 @@ func (s *Site) RenderAssets() error {
 }
 
 func (s *Site) runBuild() error {
+	s.built = false
+	if err := s.loadPrevGraph(); err != nil {
+		return err
+	}
+	s.pdfPages = nil
 	if s.cleanBeforeBuilding {
 		if err := s.Clean(); err != nil {
 			return err
 		}
+	} else if err := filewriter.RemoveTemp(filepath.Join(s.BaseDir, OutDirName)); err != nil {
+		return err
 	}
 	// Reload config.
 	if err := s.LoadConfig(); err != nil {
@@ -560,7 +1043,17 @@ func (s *Site) runBuild() error {
 	}
 	s.Config.Date = time.Now()
 
+	if err := s.LoadState(); err != nil {
+		return err
+	}
+	if err := s.LoadFetcher(); err != nil {
+		return err
+	}
+
 	markup.SetOptions(s.Config.Markup)
+	if s.Config.Markup.MarkdownFootnotes {
+		log.Printf("! markup: markdown_footnotes is deprecated, use footnotes: true")
+	}
 
 	if err := s.LoadPageFilters(); err != nil {
 		return err
@@ -568,18 +1061,45 @@ func (s *Site) runBuild() error {
 	if err := s.LoadAssets(); err != nil {
 		return err
 	}
+	if err := s.LoadAnalytics(); err != nil {
+		return err
+	}
 	if err := s.LoadCSP(); err != nil {
 		return err
 	}
+	if err := s.LoadHeaders(); err != nil {
+		return err
+	}
 	if err := s.LoadIncludes(); err != nil {
 		return err
 	}
+	if err := s.LoadData(); err != nil {
+		return err
+	}
+	if err := s.LoadDownloads(); err != nil {
+		return err
+	}
+	if err := s.LoadHyphenation(); err != nil {
+		return err
+	}
+	if err := s.LoadRedirects(); err != nil {
+		return err
+	}
 	if err := s.LoadLayoutFuncs(); err != nil {
 		return err
 	}
+	if err := s.LoadShortcodes(); err != nil {
+		return err
+	}
 	if err := s.LoadLayouts(); err != nil {
 		return err
 	}
+	if err := s.LoadVersions(); err != nil {
+		return err
+	}
+	if err := s.LoadPageIndex(); err != nil {
+		return err
+	}
 	if err := s.LoadPosts(); err != nil {
 		return err
 	}
@@ -589,44 +1109,161 @@ func (s *Site) runBuild() error {
 	if err := s.RenderAssets(); err != nil {
 		return err
 	}
+	if err := s.CollectBacklinks(); err != nil {
+		return err
+	}
+	if err := s.prepareRenderCache(); err != nil {
+		return err
+	}
 	if err := s.RenderPosts(); err != nil {
 		return err
 	}
 	if err := s.RenderPages(); err != nil {
 		return err
 	}
+	if err := s.RenderVersions(); err != nil {
+		return err
+	}
 	if s.Config.TagIndex != nil {
 		if err := s.RenderTagsIndex(); err != nil {
 			return err
 		}
 	}
+	if s.Config.CategoryIndex != nil {
+		if err := s.RenderCategoriesIndex(); err != nil {
+			return err
+		}
+	}
+	if err := s.RenderTaxonomies(); err != nil {
+		return err
+	}
+	if err := s.RenderArchives(); err != nil {
+		return err
+	}
+	if err := s.RenderGalleries(); err != nil {
+		return err
+	}
+	if err := s.RenderRedirects(); err != nil {
+		return err
+	}
 	if err := s.RenderSitemap(); err != nil {
 		return err
 	}
+	if err := s.RenderHumans(); err != nil {
+		return err
+	}
+	if err := s.RenderIndexNowKey(); err != nil {
+		return err
+	}
+	if err := s.RenderLLMs(); err != nil {
+		return err
+	}
+	if err := s.RenderPodcast(); err != nil {
+		return err
+	}
+	if err := s.RenderNewsletter(); err != nil {
+		return err
+	}
+	if err := s.RenderDownloadChecksums(); err != nil {
+		return err
+	}
+	if err := s.RenderPDFs(); err != nil {
+		return err
+	}
+	if s.compressCache != nil {
+		// Remove compressed data of files that are no longer generated.
+		if _, err := s.compressCache.Prune(); err != nil {
+			return err
+		}
+	}
+	// Remove pages that are no longer rendered.
+	if _, err := s.renderCache.Prune(); err != nil {
+		return err
+	}
+	if err := s.saveGraph(); err != nil {
+		return err
+	}
+	if s.variantsConfig() != nil {
+		// Remove variants of images that are no longer copied.
+		if _, err := s.variantsCache.Prune(); err != nil {
+			return err
+		}
+	}
+	if err := s.state.Save(); err != nil {
+		return err
+	}
+	s.built = true
 	return nil
 }
 
-func (s *Site) Build() (err error) {
+func (s *Site) Build() error {
+	return s.build(nil)
+}
+
+// build builds the site. If changed files are given, it tries
+// to rebuild only outputs that depend on them.
+func (s *Site) build(changed []string) (err error) {
 	t := time.Now()
 
-	s.buildQueue <- true
+	s.buildQueue <- changed
 	err = <-s.buildErrors
 	if err != nil {
 		return err
 	}
+	log.Printf("* Built in %s", time.Now().Sub(t))
+	return nil
+}
+
+// runQueuedBuild runs in the builder goroutine. It tries to rebuild
+// only outputs that depend on the changed files, or builds the whole
+// site, and then renders files which use all outputs.
+func (s *Site) runQueuedBuild(changed []string) error {
+	full := true
+	if len(changed) > 0 {
+		ok, err := s.runPartialBuild(changed)
+		if ok && err != nil {
+			return err
+		}
+		full = !ok
+	}
+	if full {
+		if err := s.runBuild(); err != nil {
+			return err
+		}
+	}
 	if s.Config.Search != nil {
 		if err := s.generateSearchIndex(); err != nil {
 			return err
 		}
 	}
-	log.Printf("* Built in %s", time.Now().Sub(t))
-	return nil
+	if full && s.pruneAfterBuilding && !s.cleanBeforeBuilding {
+		if err := s.removeStaleOutputs(); err != nil {
+			return err
+		}
+	}
+	// Headers file and manifest list all output files, so they are rendered last.
+	if err := s.RenderHeadersFile(); err != nil {
+		return err
+	}
+	if err := s.RenderManifest(); err != nil {
+		return err
+	}
+	return s.CheckBudgets()
 }
 
 func (s *Site) isExcludedFromSearch(url string) bool {
 	if s.Config.Search == nil {
 		return false
 	}
+	if s.draftURLs[url] || s.findRedirect(url) != nil {
+		return true
+	}
+	// Lightweight versions duplicate posts and pages, including drafts.
+	for _, c := range s.Config.Lite {
+		if strings.HasPrefix(url, c.prefix()+"/") {
+			return true
+		}
+	}
 	for _, ex := range s.Config.Search.Exclude {
 		if ex == url {
 			return true
@@ -700,6 +1337,40 @@ func (s *Site) Clean() error {
 	return os.RemoveAll(filepath.Join(s.BaseDir, OutDirName))
 }
 
+// removeStaleOutputs removes files from the output directory which
+// weren't written during the last full build, such as pages of deleted
+// posts, except for the headers file and manifest, which are written
+// after it.
+func (s *Site) removeStaleOutputs() error {
+	outDir, err := filepath.Abs(filepath.Join(s.BaseDir, OutDirName))
+	if err != nil {
+		return err
+	}
+	var keep []string
+	for _, name := range []string{s.Config.HeadersFile, s.Config.Manifest} {
+		if name != "" {
+			keep = append(keep, filepath.Join(outDir, filepath.FromSlash(name)))
+		}
+	}
+	removed, err := s.fileWriter.RemoveUnwritten(outDir, func(name string) bool {
+		for _, k := range keep {
+			if name == k || strings.HasPrefix(name, k+".") { // with compressed versions
+				return true
+			}
+		}
+		return false
+	})
+	for _, name := range removed {
+		if rel, err := filepath.Rel(outDir, name); err == nil {
+			log.Printf("- %s", filepath.Join(OutDirName, rel))
+		}
+	}
+	if len(removed) > 0 {
+		log.Printf("* Removed %d stale files.", len(removed))
+	}
+	return err
+}
+
 func (s *Site) LayoutData() interface{} {
 	return *s.Config
 }
@@ -708,6 +1379,21 @@ func (s *Site) LayoutFuncs() layouts.FuncMap {
 	return s.layoutFuncs
 }
 
+// HTMLLayoutFuncs returns functions for templates with autoescape,
+// see makeHTMLFuncs.
+func (s *Site) HTMLLayoutFuncs() layouts.FuncMap {
+	return s.htmlLayoutFuncs
+}
+
+// templateFuncs returns functions for templates
+// with or without autoescape.
+func (s *Site) templateFuncs(autoescape bool) layouts.FuncMap {
+	if autoescape {
+		return s.htmlLayoutFuncs
+	}
+	return s.layoutFuncs
+}
+
 func (s *Site) LoadLayoutFuncs() error {
 	s.layoutFuncs = layouts.FuncMap{
 		// `xml` function escapes XML.
@@ -731,8 +1417,13 @@ func (s *Site) LoadLayoutFuncs() error {
 			// slice out quotes and new line
 			return out[1 : len(out)-2], nil
 		},
-		// `asset` function returns asset URL or content by its name.
-		"asset": func(name string) (string, error) {
+		// `asset` function returns asset URL or content by its name,
+		// and, optionally, the name of its variant (the first one if
+		// not given), e.g. {{asset "theme" "dark"}}.
+		"asset": func(name string, variant ...string) (string, error) {
+			if len(variant) > 0 {
+				name = assets.VariantName(name, variant[0])
+			}
 			a := s.Assets.Get(name)
 			if a == nil {
 				return "", fmt.Errorf("asset %q not found", name)
@@ -766,6 +1457,41 @@ func (s *Site) LoadLayoutFuncs() error {
 			}
 			return out, nil
 		},
+		// `includeExists` returns true if the include file exists, so that
+		// layouts can render optional includes and partials.
+		"includeExists": s.includeExists,
+		// `fileExists` returns true if the file relative
+		// to the site directory exists.
+		"fileExists": s.fileExists,
+		// `safeHTML` marks the string as safe HTML, which is not
+		// escaped by layouts with autoescape.
+		"safeHTML": func(s string) htmltemplate.HTML {
+			return htmltemplate.HTML(s)
+		},
+		// `partial` executes include file as a template with
+		// the given data, e.g. {{ partial "card.html" (dict "title" "Hi") }}.
+		"partial": s.partial,
+		// `shortcode` renders {{< name args >}} shortcode in page
+		// content with the template from shortcodes directory.
+		"shortcode": s.shortcode,
+		// `dict` returns a map from key and value pairs.
+		"dict": dict,
+		// `code` returns a code block with the source file (relative
+		// to the site directory) or its line range, e.g. "10-20".
+		"code": s.codeSnippet,
+		// `ref` returns permalink of the page or post by ID: file name
+		// relative to pages directory (with or without extension),
+		// post file name without extension, or post UID.
+		"ref": s.refURL,
+		// `wikilink` returns a link to the page or post by ID with
+		// the label or title, used for [[wikilinks]] in Markdown.
+		markup.WikilinkFunc: s.wikilink,
+		// `backlinks` returns pages linking to the page with the URL.
+		"backlinks": func(url string) ([]Backlink, error) {
+			return s.pageBacklinks(url), nil
+		},
+		// `jsonld` returns schema.org JSON-LD script for the page meta.
+		"jsonld": s.pageJSONLD,
 		// `abspaths` adds site URL to relative paths of src and href attributes.
 		"abspaths": func(in string) (string, error) {
 			return utils.AbsPaths(s.Config.URL, in), nil
@@ -785,6 +1511,30 @@ func (s *Site) LoadLayoutFuncs() error {
 			}
 			return s, nil
 		},
+		// `number` returns the number with digits grouped by thousands
+		// for the site language or the given one, e.g. "1,234.5".
+		"number": s.numberFunc,
+		// `trim` function removes leading and trailing whitespace.
+		"trim": strings.TrimSpace,
+		// `nbsp` function replaces spaces with non-breaking spaces,
+		// e.g. {{ nbsp "10 km" }}.
+		"nbsp": func(s string) string {
+			return strings.ReplaceAll(s, " ", "\u00a0")
+		},
+		// `lang` function returns language of the page
+		// from its meta or the site language.
+		"lang": s.langFunc,
+		// `dir` function returns text direction of the page, "ltr"
+		// or "rtl", from its meta or language, or the site direction.
+		"dir": s.dirFunc,
+		// `rotate` function returns an item of the list or data file,
+		// which changes every day (or "week", "month", "year").
+		"rotate": s.rotateFunc,
+		// `inline` function returns the asset inside <style> or <script>.
+		"inline": s.inlineFunc,
+		// `filesize` returns human-readable size for the number of bytes
+		// for the site language or the given one, e.g. "1.2 MB".
+		"filesize": s.fileSizeFunc,
 		// `striptags` removes HTML tags from the given string.
 		"striptags": func(s string) (string, error) {
 			return utils.StripHTMLTags(s), nil
@@ -796,6 +1546,146 @@ func (s *Site) LoadLayoutFuncs() error {
 			}
 			return s.CSP.String(), nil
 		},
+		// `highlightcss` returns stylesheet for syntax highlighting
+		// configured in markup.highlight of site config.
+		"highlightcss": func() (string, error) {
+			if s.Config.Markup.Highlight == nil {
+				return "", errors.New("No markup.highlight in site.yml")
+			}
+			return s.Config.Markup.Highlight.CSS()
+		},
+		// `state` returns a value from the persistent state file
+		// (.kkr-state.yml) or nil if it's not set.
+		"state": func(section, key string) (interface{}, error) {
+			return s.state.Get(section, key), nil
+		},
+		// `getjson` fetches and decodes JSON from the URL.
+		// Hosts must be allowed in fetch.hosts of site config.
+		"getjson": func(url string) (interface{}, error) {
+			b, err := s.fetcher.Get(url)
+			if err != nil {
+				return nil, err
+			}
+			var v interface{}
+			if err := json.Unmarshal(b, &v); err != nil {
+				return nil, fmt.Errorf("getjson %s: %w", url, err)
+			}
+			return v, nil
+		},
+		// `getcsv` fetches and decodes CSV from the URL into rows.
+		// Hosts must be allowed in fetch.hosts of site config.
+		"getcsv": func(url string) ([][]string, error) {
+			b, err := s.fetcher.Get(url)
+			if err != nil {
+				return nil, err
+			}
+			rows, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+			if err != nil {
+				return nil, fmt.Errorf("getcsv %s: %w", url, err)
+			}
+			return rows, nil
+		},
+		// `table` renders rows (for example, from CSV data file or
+		// getcsv) or a data file by name as an HTML table.
+		// The first row is a header.
+		"table": func(v interface{}) (string, error) {
+			if name, ok := v.(string); ok {
+				if v, ok = s.Config.Data[name]; !ok {
+					return "", fmt.Errorf("table: data %q not found", name)
+				}
+			}
+			rows, ok := v.([][]string)
+			if !ok {
+				return "", fmt.Errorf("table: expecting rows, got %T", v)
+			}
+			return renderTable(rows), nil
+		},
+		// `geojson` returns minified GeoJSON from data file by name.
+		"geojson": func(name string) (string, error) {
+			v, ok := s.Config.Data[name]
+			if !ok {
+				return "", fmt.Errorf("geojson: data %q not found", name)
+			}
+			b, err := json.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+			return string(b), nil
+		},
+		// `geomap` returns a map placeholder element for the GeoJSON URL,
+		// to be initialized by a map script (such as Leaflet) on the page:
+		//
+		//	<div class="kkr-map" data-geojson="/tracks/hike.geojson"></div>
+		"geomap": func(geojsonURL string) (string, error) {
+			return fmt.Sprintf(`<div class="kkr-map" data-geojson="%s"></div>`,
+				html.EscapeString(geojsonURL)), nil
+		},
+		// `exif` returns EXIF information (.Date, .Camera, .Make, .Model,
+		// .ExposureTime, .FNumber, .ISO, .FocalLength) of the image
+		// from pages directory, or nil if it doesn't have it.
+		"exif": s.exifFunc,
+		// `image` resizes the image from pages directory to widths
+		// configured in site.yml images and returns <img> with srcset,
+		// or <picture> if additional formats are configured. Arguments
+		// are the image name, alt text and optional sizes attribute.
+		"image": s.imageFunc,
+		// `videoinfo` returns information about the video file from pages
+		// directory by its URL (.Width, .Height, .Duration, .PosterURL).
+		"videoinfo": s.videoInfo,
+		// `asciinema` returns a player embed for the asciinema cast file
+		// (relative to the site directory) or an animated SVG image.
+		"asciinema": s.asciinemaEmbed,
+		// `video` returns <video> element for the video file from pages
+		// directory by its URL.
+		"video": func(url string) (string, error) {
+			v, err := s.videoInfo(url)
+			if err != nil {
+				return "", err
+			}
+			return v.HTML(), nil
+		},
+		// `downloads` returns files from downloads directory
+		// (.Name, .URL, .Size, .SizeString, .ModTime, .SHA256).
+		"downloads": func() []*Download {
+			return s.downloads
+		},
+		// `mastodon` returns a static quotation of the Mastodon post
+		// by its URL, fetched at build time. The Mastodon server must
+		// be allowed in fetch.hosts of site config.
+		"mastodon": s.mastodonEmbed,
+		// `tweet` returns a static quotation of the tweet by its URL,
+		// fetched at build time. Requires publish.twitter.com in
+		// fetch.hosts of site config.
+		"tweet": s.tweetEmbed,
+		// `youtube` and `vimeo` return a click-to-load thumbnail of
+		// the video by its URL or ID, with optional title. Requires
+		// www.youtube.com and i.ytimg.com (or vimeo.com and
+		// i.vimeocdn.com) in fetch.hosts of site config.
+		"youtube": func(video string, title ...string) (string, error) {
+			return s.videoEmbed("youtube", video, title...)
+		},
+		"vimeo": func(video string, title ...string) (string, error) {
+			return s.videoEmbed("vimeo", video, title...)
+		},
+		// `toc` returns a table of contents for headings in HTML
+		// (such as .Content) or of the page (.Page), linking to
+		// the same IDs that heading anchors use.
+		"toc": s.tableOfContents,
+		// `nav` returns the navigation tree of pages as nested lists,
+		// marking the given page and sections containing it.
+		"nav": s.navHTML,
+		// `navtree` returns references to top-level pages in the
+		// navigation tree with url, title and children.
+		"navtree": s.navTree,
+		// `versions` returns references to versions of the docs section
+		// for the page with name, url of the same page in the version
+		// (or of the version root) and whether it's current.
+		"versions": s.versionsFunc,
+		// `content` returns content of the post with templates in it
+		// executed, unlike .Content, e.g. for newsletters and feeds.
+		"content": func(p *Post) (string, error) {
+			return s.Layouts.RenderContent(&p.Page)
+		},
 		// `lastindex` returns the index of the last element of a slice.
 		"lastindex": func(item reflect.Value) (int, error) {
 			switch item.Kind() {
@@ -805,13 +1695,65 @@ func (s *Site) LoadLayoutFuncs() error {
 			return 0, fmt.Errorf("lastindex of type %s", item.Type())
 		},
 	}
+	s.htmlLayoutFuncs = makeHTMLFuncs(s.layoutFuncs)
 	return nil
 }
 
+// config returns the current config. Unlike reading Config directly,
+// it can be called outside of the builder goroutine, which replaces
+// Config when the site is rebuilt.
+func (s *Site) config() *Config {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	return s.Config
+}
+
 func (s *Site) Serve(addr string) error {
+	c := s.config()
 	outDir := filepath.Join(s.BaseDir, OutDirName)
-	log.Printf("Serving at %s. Press Ctrl+C to quit.\n", addr)
-	return http.ListenAndServe(addr, http.FileServer(http.Dir(outDir)))
+	log.Printf("Serving at %s. Press Ctrl+C to quit.\n", s.ServeURL(addr))
+	if err := s.reportServeAddr(addr); err != nil {
+		return err
+	}
+	h, err := proxyHandler(c.Proxy, s.headersHandler(s.redirectHandler(
+		precompressedHandler(outDir, http.FileServer(http.Dir(outDir))))))
+	if err != nil {
+		return err
+	}
+	if h, err = accessHandler(c.Access, h); err != nil {
+		return err
+	}
+	if s.isHTTPS() {
+		cert, err := devcert.Load(filepath.Join(s.BaseDir, CacheDirName, certDirName))
+		if err != nil {
+			return err
+		}
+		server := &http.Server{
+			Addr:      addr,
+			Handler:   h,
+			TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+		}
+		return server.ListenAndServeTLS("", "")
+	}
+	return http.ListenAndServe(addr, h)
+}
+
+// SetHTTPS sets whether to serve the site over HTTPS
+// with a self-signed certificate.
+func (s *Site) SetHTTPS(https bool) {
+	s.https = https
+}
+
+func (s *Site) isHTTPS() bool {
+	return s.https || s.config().HTTPS
+}
+
+// ServeURL returns URL of the site served at addr.
+func (s *Site) ServeURL(addr string) string {
+	if s.isHTTPS() {
+		return "https://" + addr
+	}
+	return "http://" + addr
 }
 
 func (s *Site) StartWatching() (err error) {
@@ -820,6 +1762,8 @@ func (s *Site) StartWatching() (err error) {
 		filepath.Join(s.BaseDir, OutDirName),
 		filepath.Join(s.BaseDir, ".git"),
 		".DS_Store",
+		StateFileName + "*", // written by builder
+		filepath.Join(s.BaseDir, CacheDirName),
 	}
 	watcher, err := fspoll.Watch(s.BaseDir, excludeGlobs, 0, 0)
 	if err != nil {
@@ -829,9 +1773,9 @@ func (s *Site) StartWatching() (err error) {
 	go func() {
 		for {
 			select {
-			case <-watcher.Change:
+			case changed := <-watcher.Change:
 				log.Println("W detected change")
-				if err := s.Build(); err != nil {
+				if err := s.build(changed); err != nil {
 					log.Printf("! build error: %s", err)
 				}
 			case err := <-watcher.Error:
@@ -851,10 +1795,36 @@ func (s *Site) StopWatching() {
 	}
 }
 
+// SetDrafts sets whether to load posts from drafts directory.
+func (s *Site) SetDrafts(drafts bool) {
+	s.drafts = drafts
+}
+
+// SetFuture sets whether to load posts dated in the future.
+func (s *Site) SetFuture(future bool) {
+	s.future = future
+}
+
+// SetSafeMode sets whether to disable features that run external
+// commands, such as exec filters, diagrams, image and video conversion,
+// so that building untrusted sites can't run arbitrary commands.
+// Safe mode can also be enabled with `safe: true` in site.yml.
+func (s *Site) SetSafeMode(safe bool) {
+	s.safe = safe
+	sandbox.SetSafeMode(safe || (s.Config != nil && s.Config.Safe))
+}
+
 func (s *Site) SetCleanBeforeBuilding(clean bool) {
 	s.cleanBeforeBuilding = clean
 }
 
+// SetPruneAfterBuilding sets whether full builds remove files from the
+// output directory which they didn't write, such as pages of deleted
+// posts. Other files in the output directory are kept by default.
+func (s *Site) SetPruneAfterBuilding(prune bool) {
+	s.pruneAfterBuilding = prune
+}
+
 // MakePost creates a new post file with the given title.
 // It returns the filename of the created file.
 func (s *Site) MakePost(title string, tags string, link string) (string, error) {
@@ -891,8 +1861,10 @@ func (s *Site) MakePost(title string, tags string, link string) (string, error)
 			Date  time.Time `yaml:"date"`
 			Tags  []string  `yaml:"tags,omitempty,flow"`
 			Link  string    `yaml:"link,omitempty"`
+			UID   string    `yaml:"uid"`
 		}{
 			Title: title,
+			UID:   utils.NewUID(),
 			Date:  time.Now(),
 			Tags:  utils.SplitTags(tags),
 			Link:  link,
@@ -0,0 +1,257 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dchest/kkr/udiff"
+)
+
+// DefaultSnapshotDirName is the default directory with the snapshot
+// of output files, relative to site directory.
+const DefaultSnapshotDirName = "snapshot"
+
+// site.yml -> test:
+type TestConfig struct {
+	Snapshot string   `yaml:"snapshot"` // directory, DefaultSnapshotDirName if empty
+	Exclude  []string `yaml:"exclude"`  // patterns of output files to skip
+}
+
+// ErrSnapshotMismatch is returned by TestSnapshot if the output
+// differs from the snapshot.
+var ErrSnapshotMismatch = errors.New("output differs from snapshot")
+
+// Placeholders for values that change between builds.
+const (
+	buildDatePlaceholder = "BUILD-DATE"
+	hashPlaceholder      = "HASH"
+)
+
+var (
+	// Hexadecimal digests, such as in manifest and ETags.
+	hexHashRe = regexp.MustCompile(`\b[0-9a-f]{32,}\b`)
+	// Base64 digests of SRI and CSP.
+	shaHashRe = regexp.MustCompile(`\b(sha256|sha384|sha512)-[A-Za-z0-9+/_-]+=*`)
+	// Asset fingerprints, see utils.TemplatedHash.
+	fingerprintRe = regexp.MustCompile(`\b[0-9vbcdzf]{20}\b`)
+)
+
+// buildDateFormats are formats of the build date replaced in snapshots.
+var buildDateFormats = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"2006-01-02 15:04:05",
+}
+
+func (s *Site) snapshotDir() string {
+	name := DefaultSnapshotDirName
+	if s.Config.Test != nil && s.Config.Test.Snapshot != "" {
+		name = s.Config.Test.Snapshot
+	}
+	return filepath.Join(s.BaseDir, name)
+}
+
+func (s *Site) isExcludedFromSnapshot(name string) bool {
+	if s.Config.Test == nil {
+		return false
+	}
+	for _, pattern := range s.Config.Test.Exclude {
+		if matchPath(pattern, "/"+name) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeSnapshotName replaces asset fingerprints in the file name.
+func normalizeSnapshotName(name string) string {
+	return fingerprintRe.ReplaceAllString(name, hashPlaceholder)
+}
+
+func isText(b []byte) bool {
+	return bytes.IndexByte(b, 0) < 0 && utf8.Valid(b)
+}
+
+// normalizeSnapshot replaces the build date and hashes in text files.
+func (s *Site) normalizeSnapshot(b []byte) []byte {
+	if !isText(b) {
+		return b
+	}
+	for _, d := range []time.Time{s.Config.Date, s.Config.Date.UTC()} {
+		for _, f := range buildDateFormats {
+			b = bytes.Replace(b, []byte(d.Format(f)), []byte(buildDatePlaceholder), -1)
+		}
+	}
+	b = hexHashRe.ReplaceAll(b, []byte(hashPlaceholder))
+	b = shaHashRe.ReplaceAll(b, []byte("${1}-"+hashPlaceholder))
+	return fingerprintRe.ReplaceAll(b, []byte(hashPlaceholder))
+}
+
+// isPrecompressedSibling returns true if the file is a precompressed
+// version of another file.
+func isPrecompressedSibling(name string) bool {
+	for _, enc := range precompressedEncodings {
+		if strings.HasSuffix(name, enc.ext) {
+			if _, err := os.Stat(strings.TrimSuffix(name, enc.ext)); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// listSnapshotFiles returns file names in dir relative to it by their
+// normalized names, skipping precompressed and excluded files.
+func (s *Site) listSnapshotFiles(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.Walk(dir, func(name string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && name == dir {
+				return nil // no snapshot yet
+			}
+			return err
+		}
+		if fi.IsDir() || isPrecompressedSibling(name) {
+			return nil
+		}
+		relname, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		relname = filepath.ToSlash(relname)
+		if s.isExcludedFromSnapshot(relname) {
+			return nil
+		}
+		files[normalizeSnapshotName(relname)] = relname
+		return nil
+	})
+	return files, err
+}
+
+// matchesAny returns true if name equals one of the given names
+// or is inside one of them as a directory. Empty list matches all.
+func matchesAny(name string, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	for _, n := range names {
+		n = strings.Trim(filepath.ToSlash(n), "/")
+		if n == "" || name == n || strings.HasPrefix(name, n+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// TestSnapshot compares the output directory of the built site with
+// the snapshot, and logs files that differ, with unified diffs of
+// text files if unified is true. Dates of the build, hashes and asset
+// fingerprints are normalized.
+//
+// If update is true, it instead updates the snapshot with the output
+// files that differ, limited to the given names of files or directories
+// relative to the output directory, or all if there are no names.
+//
+// It returns ErrSnapshotMismatch if there are differences and the
+// snapshot is not updated.
+func (s *Site) TestSnapshot(unified, update bool, names []string) error {
+	outDir := filepath.Join(s.BaseDir, OutDirName)
+	snapDir := s.snapshotDir()
+	outFiles, err := s.listSnapshotFiles(outDir)
+	if err != nil {
+		return err
+	}
+	snapFiles, err := s.listSnapshotFiles(snapDir)
+	if err != nil {
+		return err
+	}
+	all := make([]string, 0, len(outFiles))
+	for name := range outFiles {
+		all = append(all, name)
+	}
+	for name := range snapFiles {
+		if _, ok := outFiles[name]; !ok {
+			all = append(all, name)
+		}
+	}
+	sort.Strings(all)
+	counts := make(map[byte]int)
+	for _, name := range all {
+		if !matchesAny(name, names) {
+			continue
+		}
+		snapName := filepath.Join(snapDir, filepath.FromSlash(name))
+		var op byte
+		var got, want []byte
+		outName, inOut := outFiles[name]
+		if inOut {
+			b, err := ioutil.ReadFile(filepath.Join(outDir, filepath.FromSlash(outName)))
+			if err != nil {
+				return err
+			}
+			got = s.normalizeSnapshot(b)
+		}
+		oldName, inSnap := snapFiles[name]
+		if inSnap {
+			if want, err = ioutil.ReadFile(filepath.Join(snapDir, filepath.FromSlash(oldName))); err != nil {
+				return err
+			}
+		}
+		switch {
+		case !inSnap:
+			op = '+'
+		case !inOut:
+			op = '-'
+		case !bytes.Equal(got, want):
+			op = '~'
+		default:
+			continue
+		}
+		counts[op]++
+		log.Printf("%c %s", op, name)
+		if update {
+			if op == '-' {
+				if err := os.Remove(filepath.Join(snapDir, filepath.FromSlash(oldName))); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(snapName), 0755); err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(snapName, got, 0644); err != nil {
+				return err
+			}
+			continue
+		}
+		if unified && op == '~' && isText(got) && isText(want) {
+			fmt.Print(udiff.Unified("a/"+name, "b/"+name, string(want), string(got)))
+		}
+	}
+	if update {
+		log.Printf("* Updated snapshot: %d added, %d changed, %d removed.", counts['+'], counts['~'], counts['-'])
+		return nil
+	}
+	if len(counts) > 0 {
+		log.Printf("* %d added, %d changed, %d removed.", counts['+'], counts['~'], counts['-'])
+		return ErrSnapshotMismatch
+	}
+	log.Printf("* Output matches snapshot.")
+	return nil
+}
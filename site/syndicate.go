@@ -0,0 +1,135 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/dchest/kkr/syndicate"
+)
+
+// syndicationSection is the state section that maps post UIDs
+// to network names and URLs of announcements, so that posts are
+// announced only once. Layouts can get them for u-syndication
+// links with {{ state "syndication" .Page.uid }}.
+const syndicationSection = "syndication"
+
+// site.yml -> deploy: syndicate:
+//
+// After deploying, posts that opt in with `syndicate: true` meta
+// (or a list of networks, e.g. `syndicate: [mastodon]`) are announced.
+// Mastodon access token is taken from MASTODON_ACCESS_TOKEN and
+// Bluesky app password from BLUESKY_APP_PASSWORD environment variables.
+type SyndicateConfig struct {
+	Mastodon       string `yaml:"mastodon"`        // server URL, e.g. https://mastodon.social
+	Bluesky        string `yaml:"bluesky"`         // handle, e.g. example.bsky.social
+	BlueskyService string `yaml:"bluesky_service"` // defaults to https://bsky.social
+}
+
+func (c *SyndicateConfig) posters() ([]syndicate.Poster, error) {
+	var list []syndicate.Poster
+	env := func(name string) (string, error) {
+		v := os.Getenv(name)
+		if v == "" {
+			return "", fmt.Errorf("deploy syndicate: %s must be set", name)
+		}
+		return v, nil
+	}
+	if c.Mastodon != "" {
+		token, err := env("MASTODON_ACCESS_TOKEN")
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &syndicate.Mastodon{Server: c.Mastodon, Token: token})
+	}
+	if c.Bluesky != "" {
+		password, err := env("BLUESKY_APP_PASSWORD")
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &syndicate.Bluesky{Handle: c.Bluesky, Password: password, Service: c.BlueskyService})
+	}
+	return list, nil
+}
+
+// syndicateTo returns true if the post opted in to be announced
+// on the network with the given lowercase name.
+func syndicateTo(p *Post, network string) (bool, error) {
+	switch v := p.meta["syndicate"].(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case string:
+		return strings.ToLower(v) == network, nil
+	case []interface{}:
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return false, fmt.Errorf("post %s: 'syndicate' must be a list of strings", p.Filename)
+			}
+			if strings.ToLower(s) == network {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		return false, fmt.Errorf("post %s: 'syndicate' must be a boolean or a list of networks", p.Filename)
+	}
+}
+
+// syndicatePosts announces posts that weren't announced yet, oldest
+// first, recording announcements in the state. Errors are logged,
+// since the site is already deployed.
+func (s *Site) syndicatePosts(posters []syndicate.Poster, dryRun bool) {
+	if len(posters) == 0 {
+		return
+	}
+	base := strings.TrimSuffix(s.Config.URL, "/")
+	for i := len(s.Config.Posts) - 1; i >= 0; i-- {
+		p := s.Config.Posts[i]
+		if draft, _ := p.meta["draft"].(bool); draft {
+			continue
+		}
+		for _, poster := range posters {
+			network := strings.ToLower(poster.Name())
+			ok, err := syndicateTo(p, network)
+			if err != nil {
+				log.Printf("! %s", err)
+				break
+			}
+			if !ok {
+				continue
+			}
+			rec, _ := s.state.Get(syndicationSection, p.UID()).(map[string]interface{})
+			if _, done := rec[network]; done {
+				continue
+			}
+			log.Printf("* Announcing %s on %s.", p.url, poster.Name())
+			if dryRun {
+				continue
+			}
+			title, _ := p.meta["title"].(string)
+			u, err := poster.Post(&syndicate.Post{ID: p.UID(), Title: title, URL: base + p.url})
+			if err != nil {
+				log.Printf("! %s", err)
+				continue
+			}
+			m := make(map[string]interface{}, len(rec)+1)
+			for k, v := range rec {
+				m[k] = v
+			}
+			m[network] = u
+			s.state.Set(syndicationSection, p.UID(), m)
+			// Save immediately to avoid duplicates if something fails later.
+			if err := s.state.Save(); err != nil {
+				log.Printf("! %s", err)
+			}
+		}
+	}
+}
@@ -25,6 +25,7 @@ func (p *TagIndex) URL() string                  { return p.url }
 
 func NewTagIndex(tag, permalink string) *TagIndex {
 	t := new(TagIndex)
+	t.Tag = tag
 	t.url = utils.CleanPermalink(permalink)
 	t.content = tag
 	t.meta = map[string]interface{}{"title": tag}
@@ -0,0 +1,164 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dchest/kkr/utils"
+)
+
+// site.yml -> taxonomies -> <name>:
+//
+// Name is the post meta key that contains terms, for example "series".
+type TaxonomyConfig struct {
+	Permalink string `yaml:"permalink"` // e.g. /blog/series/:lcterm/
+	Layout    string `yaml:"layout"`    // default is taxonomy name
+}
+
+type TermIndex struct {
+	Page
+	Taxonomy  string
+	Term      string
+	Filename  string
+	TermPosts Posts
+}
+
+func (p *TermIndex) Meta() map[string]interface{} { return p.meta }
+func (p *TermIndex) Content() string              { return p.content }
+func (p *TermIndex) FileInfo() os.FileInfo        { return nil }
+func (p *TermIndex) URL() string                  { return p.url }
+
+func NewTermIndex(taxonomy, term, permalink string) *TermIndex {
+	t := new(TermIndex)
+	t.Taxonomy = taxonomy
+	t.Term = term
+	t.url = utils.CleanPermalink(permalink)
+	t.content = term
+	t.meta = map[string]interface{}{
+		"title":    term,
+		"taxonomy": taxonomy,
+		"term":     term,
+		"url":      t.url,
+	}
+	t.Filename = filepath.FromSlash(utils.AddIndexIfNeeded(permalink))
+	return t
+}
+
+// PostsByTerm returns posts that have the term in the taxonomy.
+func (c Config) PostsByTerm(taxonomy, term string) Posts {
+	return c.Terms[taxonomy][term]
+}
+
+// TermList returns a sorted list of terms in the taxonomy.
+func (c Config) TermList(taxonomy string) []string {
+	return c.TermLists[taxonomy]
+}
+
+// TermURL returns URL of the term index.
+func (c Config) TermURL(taxonomy, term string) (string, error) {
+	t, ok := c.Taxonomies[taxonomy]
+	if !ok {
+		return "", fmt.Errorf("No taxonomy %q in site.yml", taxonomy)
+	}
+	out := strings.Replace(t.Permalink, ":term", term, -1)
+	return strings.Replace(out, ":lcterm", strings.ToLower(term), -1), nil
+}
+
+// loadPostTerms sets post terms for configured taxonomies.
+func (s *Site) loadPostTerms(p *Post) error {
+	if len(s.Config.Taxonomies) == 0 {
+		return nil
+	}
+	p.Terms = make(map[string][]string)
+	for name := range s.Config.Taxonomies {
+		terms, err := metaStrings(p.meta, name)
+		if err != nil {
+			return err
+		}
+		if terms != nil {
+			p.meta[name] = terms
+			p.Terms[name] = terms
+		}
+	}
+	return nil
+}
+
+// groupTerms distributes posts by terms of configured taxonomies.
+func (s *Site) groupTerms(posts Posts) {
+	s.Config.Terms = make(map[string]map[string]Posts)
+	s.Config.TermLists = make(map[string][]string)
+	for name := range s.Config.Taxonomies {
+		taxonomy := name
+		s.Config.Terms[taxonomy], s.Config.TermLists[taxonomy] = groupPosts(posts,
+			func(p *Post) []string { return p.Terms[taxonomy] })
+	}
+}
+
+func (s *Site) RenderTaxonomies() error {
+	if len(s.Config.Taxonomies) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(s.Config.Taxonomies))
+	for name := range s.Config.Taxonomies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		log.Printf("* Rendering %s index", name)
+		pool := utils.NewPool()
+		for _, v := range s.Config.TermLists[name] {
+			taxonomy, term := name, v
+			if !pool.Add(func() error { return s.RenderTerm(taxonomy, term) }) {
+				break
+			}
+		}
+		if err := pool.Wait(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Site) RenderTerm(taxonomy, term string) error {
+	url, err := s.Config.TermURL(taxonomy, term)
+	if err != nil {
+		return err
+	}
+	if url == "" {
+		return fmt.Errorf("taxonomy %q must have permalink", taxonomy)
+	}
+	p := NewTermIndex(taxonomy, term, url)
+	p.TermPosts = s.Config.Terms[taxonomy][term]
+	layout := s.Config.Taxonomies[taxonomy].Layout
+	if layout == "" {
+		layout = taxonomy
+	}
+	s.setBreadcrumbs(p.meta)
+	data, err := s.Layouts.RenderPage(p, layout)
+	if err != nil {
+		return err
+	}
+	log.Printf("T > %s\n", filepath.Join(OutDirName, p.Filename))
+	b, err := s.transformHTML(p.meta, p.Filename, []byte(data))
+	if err != nil {
+		return err
+	}
+	b, err = s.PageFilters.ApplyFilter(filepath.Ext(p.Filename), b)
+	if err != nil {
+		return err
+	}
+	if s.sitemap != nil && p.InSitemap() {
+		if err := s.sitemap.Add(p.SitemapEntry()); err != nil {
+			return err
+		}
+	}
+	return s.fileWriter.WriteFile(filepath.Join(s.BaseDir, OutDirName, p.Filename), b)
+}
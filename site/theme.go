@@ -0,0 +1,167 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/sandbox"
+	"gopkg.in/yaml.v3"
+)
+
+// Themes are in directories inside ThemesDirName, which contain
+// layouts, includes, shortcodes, assets.yml with assets, and
+// ThemeConfigFileName with default site config. Site files with
+// the same names replace theme files.
+const (
+	ThemesDirName       = "themes"
+	ThemeConfigFileName = "theme.yml"
+)
+
+// themeDir returns the directory of the theme in site directory,
+// or an empty string if the theme is not set.
+func (s *Site) themeDir() string {
+	if s.Config.Theme == "" {
+		return ""
+	}
+	return filepath.Join(s.BaseDir, ThemesDirName, s.Config.Theme)
+}
+
+// overlayDirs returns the directory with the given name in the theme,
+// if it's set, followed by the one in site directory.
+//
+// This is the resolution order of layouts, includes and shortcodes:
+// they are loaded from these directories in turn, so files from site
+// directory override theme files with the same names.
+func (s *Site) overlayDirs(name string) []string {
+	var dirs []string
+	if td := s.themeDir(); td != "" {
+		dirs = append(dirs, filepath.Join(td, name))
+	}
+	return append(dirs, filepath.Join(s.BaseDir, name))
+}
+
+// SetTraceTemplates sets whether to log which file provides each layout,
+// include and shortcode, and which files it overrides.
+func (s *Site) SetTraceTemplates(trace bool) {
+	s.traceTemplates = trace
+}
+
+// logTemplate logs the loaded template with the given log prefix,
+// with its file and the file it overrides, if any, when tracing.
+func (s *Site) logTemplate(prefix, name, filename, overridden string) {
+	if !s.traceTemplates {
+		log.Printf("%s %s", prefix, name)
+		return
+	}
+	if rel, err := filepath.Rel(s.BaseDir, filename); err == nil {
+		filename = rel
+	}
+	if overridden == "" {
+		log.Printf("%s %s <- %s", prefix, name, filename)
+		return
+	}
+	if rel, err := filepath.Rel(s.BaseDir, overridden); err == nil {
+		overridden = rel
+	}
+	log.Printf("%s %s <- %s (overrides %s)", prefix, name, filename, overridden)
+}
+
+// mergeConfig merges values from src into dst, except for those that
+// dst already has, recursing into mappings.
+func mergeConfig(dst, src map[string]interface{}) {
+	for k, v := range src {
+		dv, ok := dst[k]
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		dm, ok1 := dv.(map[string]interface{})
+		sm, ok2 := v.(map[string]interface{})
+		if ok1 && ok2 {
+			mergeConfig(dm, sm)
+		}
+	}
+}
+
+// readThemedConfig returns site config file data merged
+// with the default config of the theme.
+func readThemedConfig(filename, theme string) ([]byte, error) {
+	themeDir := filepath.Join(filepath.Dir(filename), ThemesDirName, theme)
+	if fi, err := os.Stat(themeDir); err != nil || !fi.IsDir() {
+		return nil, fmt.Errorf("theme %q not found in %s", theme, ThemesDirName)
+	}
+	var conf, themeConf map[string]interface{}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, &conf); err != nil {
+		return nil, err
+	}
+	tb, err := ioutil.ReadFile(filepath.Join(themeDir, ThemeConfigFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return b, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(tb, &themeConf); err != nil {
+		return nil, fmt.Errorf("%s: %w", ThemeConfigFileName, err)
+	}
+	if conf == nil {
+		conf = make(map[string]interface{})
+	}
+	mergeConfig(conf, themeConf)
+	return yaml.Marshal(conf)
+}
+
+// themeNameFromURL returns theme name from its repository URL,
+// e.g. "https://example.com/user/kkr-theme-minimal.git" -> "minimal".
+func themeNameFromURL(repo string) string {
+	repo = strings.TrimSuffix(strings.TrimRight(repo, "/"), "/.git")
+	name := path.Base(repo)
+	name = strings.TrimSuffix(name, ".git")
+	if i := strings.LastIndexByte(name, ':'); i >= 0 {
+		name = name[i+1:] // git@host:theme
+	}
+	return strings.TrimPrefix(name, "kkr-theme-")
+}
+
+// InstallTheme clones the theme's git repository into the themes
+// directory of the site. If name is empty, it's taken from the URL.
+// It returns the theme name.
+func InstallTheme(dir, repo, name string) (string, error) {
+	if name == "" {
+		name = themeNameFromURL(repo)
+	}
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("bad theme name %q", name)
+	}
+	themeDir := filepath.Join(dir, ThemesDirName, name)
+	if _, err := os.Stat(themeDir); err == nil {
+		return "", fmt.Errorf("theme %q is already installed in %s", name, themeDir)
+	}
+	if err := os.MkdirAll(filepath.Dir(themeDir), 0755); err != nil {
+		return "", err
+	}
+	log.Printf("* Installing theme %s from %s", name, repo)
+	cmd, err := sandbox.NetworkCommand("git", "clone", "--depth", "1", "--", repo, themeDir)
+	if err != nil {
+		return "", err
+	}
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git clone: %w", err)
+	}
+	return name, nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"html/template"
+
+	"github.com/dchest/kkr/headings"
+	"github.com/dchest/kkr/layouts"
+)
+
+// headingLevels returns levels of headings for anchors and
+// tables of contents, or nil for defaults.
+func (s *Site) headingLevels() []int {
+	if s.Config.Anchors != nil {
+		return s.Config.Anchors.Levels
+	}
+	return nil
+}
+
+// collectHeadings remembers headings of the rendered page content
+// while they are being collected, so that layouts can get its table
+// of contents.
+func (s *Site) collectHeadings(p layouts.PageContext, content string) error {
+	if !s.collectingLinks {
+		return nil
+	}
+	hs, err := headings.Find(content, s.headingLevels())
+	if err != nil {
+		return err
+	}
+	s.headingsMu.Lock()
+	defer s.headingsMu.Unlock()
+	s.pageHeadings[pageKey(p.URL())] = hs
+	return nil
+}
+
+// tableOfContents returns a table of contents for HTML, or for
+// content of the post or page given its meta. Headings of posts
+// and pages are collected before rendering (see CollectBacklinks),
+// so the result doesn't depend on the order in which pages are
+// rendered, but generated pages, such as tag indexes, have none.
+func (s *Site) tableOfContents(v interface{}) (string, error) {
+	var hs []*headings.Heading
+	switch v := v.(type) {
+	case string:
+		var err error
+		if hs, err = headings.Find(v, s.headingLevels()); err != nil {
+			return "", err
+		}
+	case template.HTML:
+		return s.tableOfContents(string(v))
+	case map[string]interface{}:
+		url, _ := v["url"].(string)
+		s.headingsMu.Lock()
+		found, ok := s.pageHeadings[pageKey(url)]
+		s.headingsMu.Unlock()
+		if !ok {
+			return "", fmt.Errorf("toc: page %q is not a post or page", url)
+		}
+		hs = found
+	default:
+		return "", fmt.Errorf("toc: expected HTML or page, got %T", v)
+	}
+	return headings.TOC(hs), nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+
+	"github.com/dchest/kkr/headings"
+	"github.com/dchest/kkr/layouts"
+	"github.com/dchest/kkr/typography"
+	"github.com/dchest/kkr/utils"
+	"github.com/dchest/kkr/whitespace"
+)
+
+// transformHTML renders diagrams and math, adds image attributes, and
+// applies typographic transformations, hyphenation and external links
+// policy configured in site.yml to the rendered HTML page, collapses
+// whitespace between tags, and adds analytics snippet to it.
+// Pages can set `lang` meta to override the language, or `typography: false`,
+// `hyphenate: false` and `collapse_whitespace: false` to disable them.
+func (s *Site) transformHTML(meta map[string]interface{}, filename string, data []byte) ([]byte, error) {
+	if !utils.HasFileExt(filename, HTMLExtensions) {
+		return data, nil
+	}
+	data, err := s.renderDiagrams(data)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = s.renderMathHTML(meta, data); err != nil {
+		return nil, err
+	}
+	if data, err = s.addImageAttributes(meta, data); err != nil {
+		return nil, err
+	}
+	defaultLang := s.Config.Lang
+	if c := s.Config.Typography; c != nil {
+		if c.Lang != "" {
+			defaultLang = c.Lang
+		}
+		if enabled, ok := meta["typography"].(bool); !ok || enabled {
+			data, err = typography.Process(data, pageLang(meta, defaultLang), c.Hanging)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if c := s.Config.Hyphenation; c != nil {
+		if enabled, ok := meta["hyphenate"].(bool); !ok || enabled {
+			if c.Lang != "" {
+				defaultLang = c.Lang
+			}
+			lang := pageLang(meta, defaultLang)
+			p := s.hyphenationPatterns(lang)
+			if p == nil {
+				return nil, fmt.Errorf("no hyphenation patterns for language %q in %s", lang, HyphenationDirName)
+			}
+			minLength := c.MinLength
+			if minLength == 0 {
+				minLength = DefaultHyphenMinLength
+			}
+			data, err = typography.Hyphenate(data, p, minLength)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if p := s.Config.Links; p != nil {
+		ownHost := ""
+		if u, err := url.Parse(s.Config.URL); err == nil {
+			ownHost = u.Hostname()
+		}
+		data, err = p.Apply(data, ownHost)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if s.Config.Whitespace {
+		if enabled, ok := meta["collapse_whitespace"].(bool); !ok || enabled {
+			if data, err = whitespace.Collapse(data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s.injectAnalytics(meta, data), nil
+}
+
+// filterContent collects links for backlinks from rendered HTML page
+// content, adds heading anchors to it if they are configured, and
+// remembers its headings for tables of contents.
+func (s *Site) filterContent(p layouts.PageContext, content string) (string, error) {
+	if path.Ext(p.URL()) != "" && !utils.HasFileExt(p.URL(), HTMLExtensions) {
+		return content, nil // not HTML, such as feed.xml
+	}
+	if err := s.collectLinks(p, content); err != nil {
+		return "", err
+	}
+	if s.Config.Anchors != nil {
+		var err error
+		if content, err = s.addHeadingAnchors(p, content); err != nil {
+			return "", err
+		}
+	}
+	if err := s.collectHeadings(p, content); err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+// addHeadingAnchors adds IDs and permalink anchors to headings
+// in rendered page content, unless the page has `anchors: false` meta.
+func (s *Site) addHeadingAnchors(p layouts.PageContext, content string) (string, error) {
+	if enabled, ok := p.Meta()["anchors"].(bool); ok && !enabled {
+		return content, nil
+	}
+	return headings.Anchors(content, s.Config.Anchors)
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/typography"
+)
+
+const (
+	HyphenationDirName     = "hyphenation"
+	DefaultHyphenMinLength = 8
+	hyphenationPatternsExt = ".txt"
+)
+
+// site.yml -> hyphenation:
+type HyphenationConfig struct {
+	Lang      string `yaml:"lang"`       // default language, typography.lang or site lang if empty
+	MinLength int    `yaml:"min_length"` // shortest word to hyphenate
+}
+
+// LoadHyphenation loads hyphenation patterns from hyphenation
+// directory. Each file contains patterns for a language in TeX
+// format and is named after it, e.g. hyphenation/en.txt.
+func (s *Site) LoadHyphenation() error {
+	s.hyphenPatterns = nil
+	if s.Config.Hyphenation == nil {
+		return nil
+	}
+	log.Printf("* Loading hyphenation patterns.")
+	dir := filepath.Join(s.BaseDir, HyphenationDirName)
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	s.hyphenPatterns = make(map[string]*typography.Patterns)
+	for _, fi := range fis {
+		if fi.IsDir() || filepath.Ext(fi.Name()) != hyphenationPatternsExt {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			return err
+		}
+		p, err := typography.ParsePatterns(b)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fi.Name(), err)
+		}
+		lang := strings.ToLower(strings.TrimSuffix(fi.Name(), hyphenationPatternsExt))
+		s.hyphenPatterns[lang] = p
+	}
+	return nil
+}
+
+// hyphenationPatterns returns patterns for the language or its base
+// language (e.g. "en" for "en-US"), or nil if there are none.
+func (s *Site) hyphenationPatterns(lang string) *typography.Patterns {
+	lang = strings.ToLower(lang)
+	if p := s.hyphenPatterns[lang]; p != nil {
+		return p
+	}
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		return s.hyphenPatterns[lang[:i]]
+	}
+	return nil
+}
+
+// pageLang returns language of the page from its `lang` meta
+// or the given default.
+func pageLang(meta map[string]interface{}, def string) string {
+	if v, ok := meta["lang"].(string); ok && v != "" {
+		return v
+	}
+	return def
+}
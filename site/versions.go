@@ -0,0 +1,333 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/metafile"
+	"github.com/dchest/kkr/sandbox"
+	"github.com/dchest/kkr/utils"
+)
+
+// site.yml -> versions:
+type VersionsConfig struct {
+	// Section is the URL of the docs section in pages directory,
+	// "/" if empty.
+	Section string `yaml:"section"`
+	// Current is the name of the version in pages directory.
+	Current string `yaml:"current"`
+	// List contains other versions of the section.
+	List []*Version `yaml:"list"`
+}
+
+// Version is a version of the docs section built into its own
+// directory, such as /docs/v1/, from either a directory with its
+// pages or the section at git tag or branch.
+type Version struct {
+	Name      string `yaml:"name"`
+	Permalink string `yaml:"permalink"` // e.g. /docs/v1/
+	Dir       string `yaml:"dir"`       // relative to site directory
+	Git       string `yaml:"git"`       // tag or branch
+
+	dir string // directory with pages
+}
+
+const versionsDirName = "versions"
+
+func (c *VersionsConfig) section() string {
+	if c.Section == "" {
+		return "/"
+	}
+	return "/" + strings.Trim(c.Section, "/") + "/"
+}
+
+// LoadVersions checks versions config and prepares directories with
+// pages of versions, extracting them from git if needed.
+func (s *Site) LoadVersions() error {
+	c := s.Config.Versions
+	if c == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, v := range c.List {
+		if v.Name == "" || v.Permalink == "" {
+			return errors.New("versions: each version must have name and permalink")
+		}
+		if (v.Dir == "") == (v.Git == "") {
+			return fmt.Errorf("versions: version %q must have either dir or git", v.Name)
+		}
+		v.Permalink = "/" + strings.Trim(v.Permalink, "/") + "/"
+		if seen[v.Permalink] || v.Permalink == "/" {
+			return fmt.Errorf("versions: bad or duplicate permalink %q", v.Permalink)
+		}
+		seen[v.Permalink] = true
+		if v.Dir != "" {
+			v.dir = filepath.Join(s.BaseDir, v.Dir)
+			continue
+		}
+		dir, err := s.gitVersionDir(v.Git, c.section())
+		if err != nil {
+			return fmt.Errorf("versions: %s: %w", v.Name, err)
+		}
+		v.dir = dir
+	}
+	return nil
+}
+
+// git runs git command in site directory and returns its trimmed output.
+func (s *Site) git(args ...string) (string, error) {
+	cmd, err := sandbox.Command("git", args...)
+	if err != nil {
+		return "", err
+	}
+	cmd.Dir = s.BaseDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitVersionDir returns the directory with files of the section in
+// pages directory at the git ref, extracting them into the cache
+// directory by their tree hash.
+func (s *Site) gitVersionDir(ref, section string) (string, error) {
+	prefix, err := s.git("rev-parse", "--show-prefix")
+	if err != nil {
+		return "", err
+	}
+	treePath := strings.TrimSuffix(prefix+PagesDirName+section, "/")
+	tree, err := s.git("rev-parse", ref+":"+treePath)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(s.BaseDir, CacheDirName, versionsDirName, tree)
+	if _, err := os.Stat(dir); err == nil {
+		return dir, nil // extracted
+	}
+	log.Printf("* Extracting %s from %s", treePath, ref)
+	cmd, err := sandbox.Command("git", "archive", "--format=tar", tree)
+	if err != nil {
+		return "", err
+	}
+	cmd.Dir = s.BaseDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git archive: %s", strings.TrimSpace(stderr.String()))
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return "", err
+	}
+	tmpDir, err := ioutil.TempDir(filepath.Dir(dir), ".tmp-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+	if err := extractTar(tmpDir, bytes.NewReader(out)); err != nil {
+		return "", err
+	}
+	return dir, os.Rename(tmpDir, dir)
+}
+
+// extractTar extracts directories and regular files from tar archive.
+func extractTar(dir string, r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(h.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("bad file name in archive: %q", h.Name)
+		}
+		outname := filepath.Join(dir, filepath.FromSlash(name))
+		switch h.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(outname, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(outname), 0755); err != nil {
+				return err
+			}
+			b, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(outname, b, 0644); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// walkVersion calls fn with names of page files of the version
+// relative to its directory.
+func (s *Site) walkVersion(v *Version, fn func(relname string) error) error {
+	return filepath.Walk(v.dir, func(name string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() || s.isIgnoredFile(fi.Name()) || fi.Name() == DefaultsFileName {
+			return nil
+		}
+		relname, err := filepath.Rel(v.dir, name)
+		if err != nil {
+			return err
+		}
+		return fn(relname)
+	})
+}
+
+// indexVersionPages adds titles of pages of versions for breadcrumbs
+// and the navigation tree.
+func (s *Site) indexVersionPages() error {
+	if s.Config.Versions == nil {
+		return nil
+	}
+	for _, v := range s.Config.Versions.List {
+		v := v
+		err := s.walkVersion(v, func(relname string) error {
+			f, err := metafile.Open(filepath.Join(v.dir, relname))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if !f.HasMeta() {
+				return nil
+			}
+			meta := f.Meta()
+			title, _ := meta["title"].(string)
+			outName := filepath.Join(filepath.FromSlash(strings.Trim(v.Permalink, "/")), pageOutName(relname, meta))
+			url := utils.CleanPermalink(filepath.ToSlash(outName))
+			s.pageTitles[pageKey(url)] = title
+			s.addNavPage(url, outName, meta)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("versions: %s: %w", v.Name, err)
+		}
+	}
+	return nil
+}
+
+// RenderVersions renders pages of versions of the docs section.
+func (s *Site) RenderVersions() error {
+	if s.Config.Versions == nil || len(s.Config.Versions.List) == 0 {
+		return nil
+	}
+	log.Printf("* Rendering versions")
+	pool := utils.NewPool()
+	for _, v := range s.Config.Versions.List {
+		v := v
+		err := s.walkVersion(v, func(relname string) error {
+			if !pool.Add(func() error { return s.renderPage(v.dir, relname, v) }) {
+				return filepath.SkipDir
+			}
+			return nil
+		})
+		if err != nil {
+			pool.Wait()
+			return err
+		}
+	}
+	return pool.Wait()
+}
+
+// copyVersionFile copies a file which is not a page from the directory
+// of the version into its output directory.
+func (s *Site) copyVersionFile(v *Version, relname string) error {
+	outName := filepath.Join(filepath.FromSlash(strings.Trim(v.Permalink, "/")), relname)
+	if err := s.copyImage(filepath.Join(s.BaseDir, OutDirName, outName), filepath.Join(v.dir, relname)); err != nil {
+		return err
+	}
+	log.Printf("C > %s\n", filepath.Join(OutDirName, outName))
+	return nil
+}
+
+// versionRoot returns the permalink of the version containing
+// the URL, or an empty string if it's not in a version.
+func (s *Site) versionRoot(url string) string {
+	if v, _, ok := s.pageVersion(url); ok && v != nil {
+		return v.Permalink
+	}
+	return ""
+}
+
+// pageVersion returns the version of the docs section containing the
+// URL and the URL relative to the version, or false if it's not in it.
+func (s *Site) pageVersion(url string) (v *Version, rel string, ok bool) {
+	c := s.Config.Versions
+	if c == nil {
+		return nil, "", false
+	}
+	url = pageKey(url)
+	for _, v := range c.List {
+		if strings.HasPrefix(url, v.Permalink) {
+			return v, strings.TrimPrefix(url, v.Permalink), true
+		}
+	}
+	if strings.HasPrefix(url, c.section()) {
+		return nil, strings.TrimPrefix(url, c.section()), true
+	}
+	return nil, "", false
+}
+
+// setPageVersion sets `version` meta of the page in the docs section
+// to the name of its version.
+func (s *Site) setPageVersion(meta map[string]interface{}, v *Version) {
+	if v != nil {
+		meta["version"] = v.Name
+		return
+	}
+	url, _ := meta["url"].(string)
+	if _, _, ok := s.pageVersion(url); ok && s.Config.Versions.Current != "" {
+		meta["version"] = s.Config.Versions.Current
+	}
+}
+
+// versionsFunc is a template function which returns references to
+// versions of the docs section for a version switcher: name, url of
+// the same page in the version or of the version root if it doesn't
+// have the page, and whether it's the version of the given page.
+func (s *Site) versionsFunc(page map[string]interface{}) []map[string]interface{} {
+	c := s.Config.Versions
+	if c == nil {
+		return nil
+	}
+	url, _ := page["url"].(string)
+	pv, rel, inSection := s.pageVersion(url)
+	ref := func(name, root string, current bool) map[string]interface{} {
+		u := root
+		if _, ok := s.pageTitles[root+rel]; ok && inSection {
+			u = root + rel
+		}
+		return map[string]interface{}{"name": name, "url": u, "current": current}
+	}
+	refs := []map[string]interface{}{ref(c.Current, c.section(), inSection && pv == nil)}
+	for _, v := range c.List {
+		refs = append(refs, ref(v.Name, v.Permalink, inSection && pv == v))
+	}
+	return refs
+}
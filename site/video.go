@@ -0,0 +1,175 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package site
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dchest/kkr/utils"
+	"github.com/dchest/kkr/video"
+)
+
+const DefaultPosterTime = 1.0
+
+// site.yml -> video:
+type VideoConfig struct {
+	FFprobe    string  `yaml:"ffprobe"`     // ffprobe command, "ffprobe" by default
+	FFmpeg     string  `yaml:"ffmpeg"`      // ffmpeg command, "ffmpeg" by default
+	Poster     bool    `yaml:"poster"`      // generate poster images
+	PosterTime float64 `yaml:"poster_time"` // time of poster frame in seconds
+}
+
+var videoTypes = map[string]string{
+	".mp4":  "video/mp4",
+	".m4v":  "video/mp4",
+	".webm": "video/webm",
+	".ogv":  "video/ogg",
+	".mov":  "video/quicktime",
+}
+
+// Video describes a video file from pages directory.
+type Video struct {
+	URL       string
+	Type      string
+	Width     int
+	Height    int
+	Duration  float64 // in seconds
+	PosterURL string  // empty if poster is not generated
+}
+
+// DurationString returns duration formatted as H:MM:SS or M:SS.
+func (v *Video) DurationString() string {
+	return video.FormatDuration(v.Duration)
+}
+
+// HTML returns <video> element for the video.
+func (v *Video) HTML() string {
+	var b strings.Builder
+	b.WriteString(`<video controls preload="metadata"`)
+	if v.Width > 0 && v.Height > 0 {
+		fmt.Fprintf(&b, ` width="%d" height="%d"`, v.Width, v.Height)
+	}
+	if v.PosterURL != "" {
+		fmt.Fprintf(&b, ` poster="%s"`, html.EscapeString(v.PosterURL))
+	}
+	fmt.Fprintf(&b, `><source src="%s"`, html.EscapeString(v.URL))
+	if v.Type != "" {
+		fmt.Fprintf(&b, ` type="%s"`, v.Type)
+	}
+	b.WriteString(`></video>`)
+	return b.String()
+}
+
+func (c *VideoConfig) ffprobe() string {
+	if c == nil || c.FFprobe == "" {
+		return "ffprobe"
+	}
+	return c.FFprobe
+}
+
+func (c *VideoConfig) ffmpeg() string {
+	if c == nil || c.FFmpeg == "" {
+		return "ffmpeg"
+	}
+	return c.FFmpeg
+}
+
+func (c *VideoConfig) posterTime() float64 {
+	if c == nil || c.PosterTime == 0 {
+		return DefaultPosterTime
+	}
+	return c.PosterTime
+}
+
+// videoCacheKey returns a cache key which changes when the file changes.
+func videoCacheKey(filename string) (string, error) {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf("%s %d %d", filename, fi.Size(), fi.ModTime().UnixNano())
+	return utils.NoVowelsHexEncode(utils.Hash([]byte(key))[:16]), nil
+}
+
+// probeVideo returns cached video metadata or probes the video file.
+func (s *Site) probeVideo(filename, cacheDir, key string) (*video.Info, error) {
+	return s.probeMedia(video.Probe, filename, filepath.Join(cacheDir, key+".json"))
+}
+
+// probeAudio returns cached audio duration or probes the audio file.
+func (s *Site) probeAudio(filename, cacheDir, key string) (*video.Info, error) {
+	return s.probeMedia(video.ProbeAudio, filename, filepath.Join(cacheDir, key+".audio.json"))
+}
+
+func (s *Site) probeMedia(probe func(ffprobe, filename string) (*video.Info, error), filename, cacheFile string) (*video.Info, error) {
+	if b, err := ioutil.ReadFile(cacheFile); err == nil {
+		var info video.Info
+		if err := json.Unmarshal(b, &info); err == nil {
+			return &info, nil
+		}
+	}
+	log.Printf("V %s", filename)
+	info, err := probe(s.Config.Video.ffprobe(), filename)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	return info, ioutil.WriteFile(cacheFile, b, 0644)
+}
+
+// videoInfo returns information about video file from pages directory
+// by its URL, generating a poster image if configured.
+func (s *Site) videoInfo(url string) (*Video, error) {
+	s.videoMu.Lock()
+	defer s.videoMu.Unlock()
+
+	url = path.Join("/", url)
+	filename := filepath.Join(s.BaseDir, PagesDirName, filepath.FromSlash(url))
+	key, err := videoCacheKey(filename)
+	if err != nil {
+		return nil, err
+	}
+	cacheDir := filepath.Join(s.BaseDir, CacheDirName, "video")
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, err
+	}
+	info, err := s.probeVideo(filename, cacheDir, key)
+	if err != nil {
+		return nil, err
+	}
+	v := &Video{
+		URL:      url,
+		Type:     videoTypes[strings.ToLower(path.Ext(url))],
+		Width:    info.Width,
+		Height:   info.Height,
+		Duration: info.Duration,
+	}
+	if c := s.Config.Video; c != nil && c.Poster {
+		posterFile := filepath.Join(cacheDir, key+".jpg")
+		if _, err := os.Stat(posterFile); err != nil {
+			log.Printf("V poster %s", filename)
+			if err := video.Poster(c.ffmpeg(), filename, c.posterTime(), posterFile); err != nil {
+				return nil, err
+			}
+		}
+		v.PosterURL = utils.ReplaceFileExt(url, ".poster.jpg")
+		outFile := filepath.Join(s.BaseDir, OutDirName, filepath.FromSlash(v.PosterURL))
+		if err := s.fileWriter.CopyFile(outFile, posterFile); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
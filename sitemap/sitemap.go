@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"sync"
 	"text/template"
 )
 
@@ -16,7 +17,9 @@ type Entry struct {
 	Priority   string
 }
 
+// Sitemap collects entries, which can be added concurrently.
 type Sitemap struct {
+	mu      sync.Mutex
 	entries []Entry
 }
 
@@ -30,13 +33,23 @@ func (m *Sitemap) Add(entry Entry) error {
 	if !isValidChangefreq(entry.Changefreq) {
 		return fmt.Errorf("invalid changefreq '%s'", entry.Changefreq)
 	}
+	m.mu.Lock()
 	m.entries = append(m.entries, entry)
+	m.mu.Unlock()
 	return nil
 }
 
+// Render writes sitemap XML. Entries are sorted by URL, shorter first,
+// so that the output doesn't depend on the order they were added in.
 func (m *Sitemap) Render(w io.Writer, baseURL string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	sort.Slice(m.entries, func(i, j int) bool {
-		return len(m.entries[i].Loc) < len(m.entries[j].Loc)
+		a, b := m.entries[i].Loc, m.entries[j].Loc
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
 	})
 
 	return sitemapTemplate.Execute(w, struct {
@@ -49,6 +62,8 @@ func (m *Sitemap) Render(w io.Writer, baseURL string) error {
 }
 
 func (m *Sitemap) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.entries = m.entries[:0]
 }
 
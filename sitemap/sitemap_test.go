@@ -0,0 +1,37 @@
+package sitemap
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRenderOrder(t *testing.T) {
+	m := New()
+	var wg sync.WaitGroup
+	for _, loc := range []string{"/b/", "/blog/two.html", "/", "/a/", "/blog/one.html"} {
+		wg.Add(1)
+		go func(loc string) {
+			defer wg.Done()
+			if err := m.Add(Entry{Loc: loc}); err != nil {
+				t.Error(err)
+			}
+		}(loc)
+	}
+	wg.Wait()
+	var buf bytes.Buffer
+	if err := m.Render(&buf, "https://example.com"); err != nil {
+		t.Fatal(err)
+	}
+	var locs []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if s := strings.TrimSpace(line); strings.HasPrefix(s, "<loc>") {
+			locs = append(locs, strings.TrimSuffix(strings.TrimPrefix(s, "<loc>https://example.com"), "</loc>"))
+		}
+	}
+	expected := "/ /a/ /b/ /blog/one.html /blog/two.html"
+	if got := strings.Join(locs, " "); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
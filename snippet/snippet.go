@@ -0,0 +1,133 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package snippet implements extracting of line ranges from source
+// files and rendering them as HTML code blocks.
+package snippet
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// languages maps file extensions to language names
+// which differ from extensions.
+var languages = map[string]string{
+	".c":    "c",
+	".h":    "c",
+	".cc":   "cpp",
+	".cpp":  "cpp",
+	".hpp":  "cpp",
+	".cs":   "csharp",
+	".js":   "javascript",
+	".mjs":  "javascript",
+	".ts":   "typescript",
+	".py":   "python",
+	".rb":   "ruby",
+	".rs":   "rust",
+	".sh":   "bash",
+	".yml":  "yaml",
+	".md":   "markdown",
+	".htm":  "html",
+	".kt":   "kotlin",
+	".m":    "objectivec",
+	".pl":   "perl",
+	".txt":  "plaintext",
+	".text": "plaintext",
+}
+
+// Language returns language name for the file based on its extension.
+func Language(filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if lang, ok := languages[ext]; ok {
+		return lang
+	}
+	if ext == "" {
+		return "plaintext"
+	}
+	return ext[1:]
+}
+
+// ParseRange parses line range in the form "from-to", "from-" or "line".
+// Lines are numbered from 1; to is 0 if the range is open.
+func ParseRange(spec string) (from, to int, err error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return 1, 0, nil
+	}
+	fs, ts, hasDash := strings.Cut(spec, "-")
+	if from, err = strconv.Atoi(strings.TrimSpace(fs)); err != nil || from < 1 {
+		return 0, 0, fmt.Errorf("bad line range %q", spec)
+	}
+	if !hasDash {
+		return from, from, nil
+	}
+	if ts = strings.TrimSpace(ts); ts == "" {
+		return from, 0, nil
+	}
+	if to, err = strconv.Atoi(ts); err != nil || to < from {
+		return 0, 0, fmt.Errorf("bad line range %q", spec)
+	}
+	return from, to, nil
+}
+
+// Extract returns lines from..to (inclusive, numbered from 1) of the
+// source. If to is 0, lines until the end are returned. Common
+// indentation is removed from the extracted lines.
+func Extract(src string, from, to int) (string, error) {
+	lines := strings.Split(strings.TrimRight(strings.Replace(src, "\r\n", "\n", -1), "\n"), "\n")
+	if to == 0 {
+		to = len(lines)
+	}
+	if from > len(lines) || to > len(lines) {
+		return "", fmt.Errorf("line range %d-%d is out of bounds (%d lines)", from, to, len(lines))
+	}
+	return dedent(lines[from-1 : to]), nil
+}
+
+func dedent(lines []string) string {
+	prefix := ""
+	first := true
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		indent := l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+		if first {
+			prefix = indent
+			first = false
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(strings.TrimPrefix(l, prefix))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// HTML returns code block markup. Language is set as "language-" class
+// for client-side highlighters, start line as data-start attribute.
+func HTML(code, lang string, start int) string {
+	var b strings.Builder
+	b.WriteString("<pre")
+	if start > 1 {
+		fmt.Fprintf(&b, ` data-start="%d"`, start)
+	}
+	b.WriteString("><code")
+	if lang != "" {
+		fmt.Fprintf(&b, ` class="language-%s"`, html.EscapeString(lang))
+	}
+	b.WriteString(">")
+	b.WriteString(html.EscapeString(code))
+	b.WriteString("</code></pre>\n")
+	return b.String()
+}
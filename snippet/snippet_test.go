@@ -0,0 +1,51 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package snippet
+
+import "testing"
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		spec     string
+		from, to int
+		err      bool
+	}{
+		{"", 1, 0, false},
+		{"5", 5, 5, false},
+		{"3-7", 3, 7, false},
+		{"3-", 3, 0, false},
+		{"7-3", 0, 0, true},
+		{"0-3", 0, 0, true},
+		{"a-b", 0, 0, true},
+	}
+	for _, v := range tests {
+		from, to, err := ParseRange(v.spec)
+		if (err != nil) != v.err || from != v.from || to != v.to {
+			t.Errorf("%q: got %d, %d, %v; expected %d, %d, err=%v", v.spec, from, to, err, v.from, v.to, v.err)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	src := "package main\n\nfunc main() {\n\tif x {\n\t\ty()\n\t}\n}\n"
+	out, err := Extract(src, 4, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "if x {\n\ty()\n}\n"; out != expected {
+		t.Errorf("got %q, expected %q", out, expected)
+	}
+	if _, err := Extract(src, 5, 10); err == nil {
+		t.Errorf("expected error for out of bounds range")
+	}
+}
+
+func TestHTML(t *testing.T) {
+	out := HTML("a < b\n", Language("x.go"), 3)
+	expected := `<pre data-start="3"><code class="language-go">a &lt; b` + "\n</code></pre>\n"
+	if out != expected {
+		t.Errorf("got %q, expected %q", out, expected)
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sourcemap implements generating of source maps (revision 3)
+// for minified JavaScript and CSS.
+//
+// Minifiers don't report positions, so tokens of the output are matched
+// with tokens of the sources in order, skipping whitespace and comments.
+// This works for minifiers which remove whitespace and comments and
+// shorten some values, but don't reorder or rename code, such as jsmin
+// and cssmin. Output tokens which can't be matched are left unmapped.
+package sourcemap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Part is a part of the concatenated input of a minifier: either a source
+// file, or, if Name is empty, text between files, which is not mapped.
+type Part struct {
+	Name    string
+	Content string
+}
+
+// Syntax of comments.
+type Syntax int
+
+const (
+	JS  Syntax = iota // /* */ and // comments
+	CSS               // /* */ comments
+)
+
+type token struct {
+	text string
+	src  int // index of source or -1
+	line int // zero-based
+	col  int // zero-based, in UTF-16 code units
+}
+
+func isWordChar(r rune) bool {
+	return r == '_' || r == '$' || r == '#' || r == '%' || r == '@' || r == '\\' ||
+		r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r >= 0x80
+}
+
+// tokenize appends tokens of s to tokens. Tokens are words, quoted
+// strings and other characters, except for whitespace and comments.
+func tokenize(tokens []token, s string, src int, syntax Syntax) []token {
+	line, col := 0, 0
+	advance := func(text string) {
+		for _, r := range text {
+			if r == '\n' {
+				line++
+				col = 0
+			} else {
+				col += len(utf16.Encode([]rune{r}))
+			}
+		}
+	}
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		j := i + size
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f':
+			advance(s[i:j])
+			i = j
+			continue
+		case strings.HasPrefix(s[i:], "/*"):
+			if k := strings.Index(s[i+2:], "*/"); k >= 0 {
+				j = i + 2 + k + 2
+			} else {
+				j = len(s)
+			}
+			advance(s[i:j])
+			i = j
+			continue
+		case syntax == JS && strings.HasPrefix(s[i:], "//"):
+			if k := strings.IndexByte(s[i:], '\n'); k >= 0 {
+				j = i + k
+			} else {
+				j = len(s)
+			}
+			advance(s[i:j])
+			i = j
+			continue
+		case r == '"' || r == '\'' || r == '`':
+			for j < len(s) && s[j] != byte(r) {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j < len(s) {
+				j++
+			} else {
+				j = len(s)
+			}
+		case isWordChar(r):
+			for j < len(s) {
+				r, size := utf8.DecodeRuneInString(s[j:])
+				if !isWordChar(r) {
+					break
+				}
+				j += size
+			}
+		}
+		tokens = append(tokens, token{text: s[i:j], src: src, line: line, col: col})
+		advance(s[i:j])
+		i = j
+	}
+	return tokens
+}
+
+// lookahead is the number of input tokens which are checked
+// for a match with an output token.
+const lookahead = 16
+
+const base64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// writeVLQ writes base64 VLQ encoding of n.
+func writeVLQ(b *strings.Builder, n int) {
+	v := n << 1
+	if n < 0 {
+		v = (-n << 1) | 1
+	}
+	for {
+		digit := v & 31
+		v >>= 5
+		if v > 0 {
+			digit |= 32
+		}
+		b.WriteByte(base64Chars[digit])
+		if v == 0 {
+			break
+		}
+	}
+}
+
+type sourceMap struct {
+	Version        int      `json:"version"`
+	File           string   `json:"file,omitempty"`
+	Sources        []string `json:"sources"`
+	SourcesContent []string `json:"sourcesContent"`
+	Names          []string `json:"names"`
+	Mappings       string   `json:"mappings"`
+}
+
+// Generate returns JSON source map for the output file with the given
+// name, produced from the concatenated parts.
+func Generate(file string, parts []Part, out string, syntax Syntax) ([]byte, error) {
+	m := sourceMap{Version: 3, File: file, Sources: []string{}, SourcesContent: []string{}, Names: []string{}}
+	var in []token
+	for _, p := range parts {
+		src := -1
+		if p.Name != "" {
+			src = len(m.Sources)
+			m.Sources = append(m.Sources, p.Name)
+			m.SourcesContent = append(m.SourcesContent, p.Content)
+		}
+		in = tokenize(in, p.Content, src, syntax)
+	}
+	var b strings.Builder
+	i := 0
+	line, prevCol, prevSrc, prevSrcLine, prevSrcCol := 0, 0, 0, 0, 0
+	first := true // first segment on the line
+	for _, t := range tokenize(nil, out, -1, syntax) {
+		match := -1
+		for k := i; k < len(in) && k < i+lookahead; k++ {
+			if strings.EqualFold(in[k].text, t.text) {
+				match = k
+				break
+			}
+		}
+		if match < 0 {
+			continue
+		}
+		i = match + 1
+		s := in[match]
+		if s.src < 0 {
+			continue
+		}
+		for ; line < t.line; line++ {
+			b.WriteByte(';')
+			prevCol = 0
+			first = true
+		}
+		if !first {
+			b.WriteByte(',')
+		}
+		first = false
+		writeVLQ(&b, t.col-prevCol)
+		writeVLQ(&b, s.src-prevSrc)
+		writeVLQ(&b, s.line-prevSrcLine)
+		writeVLQ(&b, s.col-prevSrcCol)
+		prevCol, prevSrc, prevSrcLine, prevSrcCol = t.col, s.src, s.line, s.col
+	}
+	m.Mappings = b.String()
+	return json.Marshal(m)
+}
+
+// Comment returns the comment which links to the source map URL
+// to be appended to the output.
+func Comment(url string, syntax Syntax) string {
+	if syntax == CSS {
+		return "\n/*# sourceMappingURL=" + url + " */\n"
+	}
+	return "\n//# sourceMappingURL=" + url + "\n"
+}
+
+// Extract returns the output without the trailing comment with inline
+// source map in base64 data URL, such as the one written by esbuild,
+// and the decoded source map. It returns false if there's no such
+// comment.
+func Extract(out []byte) (content, m []byte, ok bool) {
+	const url = "# sourceMappingURL=data:application/json"
+	i := bytes.LastIndex(out, []byte(url))
+	if i < 2 {
+		return nil, nil, false
+	}
+	start := i - 2
+	prefix := string(out[start:i])
+	if prefix != "//" && prefix != "/*" {
+		return nil, nil, false
+	}
+	rest := out[i+len(url):]
+	comma := bytes.IndexByte(rest, ',')
+	if comma < 0 || !bytes.HasSuffix(rest[:comma], []byte(";base64")) {
+		return nil, nil, false
+	}
+	data := bytes.TrimSpace(rest[comma+1:])
+	if prefix == "/*" {
+		if !bytes.HasSuffix(data, []byte("*/")) {
+			return nil, nil, false
+		}
+		data = bytes.TrimSpace(data[:len(data)-2])
+	}
+	m, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, nil, false
+	}
+	return bytes.TrimRight(out[:start], "\n"), m, true
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sourcemap
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type mapping struct {
+	line, col, src, srcLine, srcCol int
+}
+
+// decode decodes mappings with four fields per segment.
+func decode(t *testing.T, s string) []mapping {
+	var out []mapping
+	var col, src, srcLine, srcCol int
+	for line, l := range strings.Split(s, ";") {
+		col = 0
+		if l == "" {
+			continue
+		}
+		for _, seg := range strings.Split(l, ",") {
+			var fields []int
+			v, shift := 0, 0
+			for _, c := range seg {
+				digit := strings.IndexRune(base64Chars, c)
+				v |= (digit & 31) << shift
+				shift += 5
+				if digit&32 == 0 {
+					n := v >> 1
+					if v&1 != 0 {
+						n = -n
+					}
+					fields = append(fields, n)
+					v, shift = 0, 0
+				}
+			}
+			if len(fields) != 4 {
+				t.Fatalf("bad segment %q", seg)
+			}
+			col += fields[0]
+			src += fields[1]
+			srcLine += fields[2]
+			srcCol += fields[3]
+			out = append(out, mapping{line, col, src, srcLine, srcCol})
+		}
+	}
+	return out
+}
+
+func TestGenerate(t *testing.T) {
+	parts := []Part{
+		{Name: "a.js", Content: "// Add.\nfunction add(a, b) {\n  return a + b;\n}\n"},
+		{Content: "\n/* ---- */\n"},
+		{Name: "b.js", Content: "var s = 'a b';\n"},
+	}
+	out := "function add(a,b){return a+b;}\nvar s='a b';"
+	b, err := Generate("out.js", parts, out, JS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m sourceMap
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Sources) != 2 || m.Sources[1] != "b.js" || m.SourcesContent[0] != parts[0].Content {
+		t.Fatalf("bad sources: %v", m.Sources)
+	}
+	found := make(map[mapping]bool)
+	for _, v := range decode(t, m.Mappings) {
+		found[v] = true
+	}
+	for _, want := range []mapping{
+		{0, 0, 0, 1, 0},   // function
+		{0, 18, 0, 2, 2},  // return
+		{0, 28, 0, 2, 14}, // ;
+		{1, 4, 1, 0, 4},   // s
+		{1, 6, 1, 0, 8},   // 'a b'
+	} {
+		if !found[want] {
+			t.Errorf("missing mapping %+v in %v", want, decode(t, m.Mappings))
+		}
+	}
+}
+
+func TestGenerateCSS(t *testing.T) {
+	parts := []Part{{Name: "a.css", Content: "a {\n  color: #FFFFFF;\n  margin: 0px;\n}\n"}}
+	out := "a{color:#fff;margin:0}"
+	b, err := Generate("out.css", parts, out, CSS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m sourceMap
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[mapping]bool)
+	for _, v := range decode(t, m.Mappings) {
+		found[v] = true
+	}
+	// "margin" is mapped after the unmatched color value.
+	if !found[mapping{0, 13, 0, 2, 2}] {
+		t.Errorf("margin is not mapped: %v", decode(t, m.Mappings))
+	}
+}
+
+func TestExtract(t *testing.T) {
+	m := `{"version":3,"sources":["<stdin>"],"mappings":"AAAA"}`
+	data := base64.StdEncoding.EncodeToString([]byte(m))
+	for _, v := range []struct{ out, content string }{
+		{"var a=1;\n//# sourceMappingURL=data:application/json;base64," + data + "\n", "var a=1;"},
+		{"a{color:red}\n/*# sourceMappingURL=data:application/json;charset=utf-8;base64," + data + " */\n", "a{color:red}"},
+	} {
+		content, b, ok := Extract([]byte(v.out))
+		if !ok {
+			t.Fatalf("%q: no source map", v.out)
+		}
+		if string(content) != v.content {
+			t.Errorf("content: expected %q, got %q", v.content, content)
+		}
+		if string(b) != m {
+			t.Errorf("map: expected %q, got %q", m, b)
+		}
+	}
+	for _, out := range []string{
+		"var a=1;\n",
+		"var a=1;\n//# sourceMappingURL=a.js.map\n",
+		"var a=1;\n//# sourceMappingURL=data:application/json,{}\n",
+	} {
+		if _, _, ok := Extract([]byte(out)); ok {
+			t.Errorf("%q: unexpected source map", out)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package starter creates new sites from starter templates,
+// which are either embedded or cloned from git repositories.
+package starter
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dchest/kkr/sandbox"
+)
+
+// DefaultTemplate is the name of the template used if none is given.
+const DefaultTemplate = "blog"
+
+//go:embed templates
+var templates embed.FS
+
+// dotFiles are embedded without the leading dot, since embedding
+// skips names that start with it.
+var dotFiles = map[string]bool{"gitignore": true}
+
+// Names returns names of embedded templates.
+func Names() []string {
+	entries, err := templates.ReadDir("templates")
+	if err != nil {
+		panic(err)
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsRemote returns true if the template is a git repository URL
+// rather than the name of an embedded template.
+func IsRemote(template string) bool {
+	return strings.Contains(template, "://") || strings.HasPrefix(template, "git@") ||
+		strings.HasSuffix(template, ".git")
+}
+
+// checkEmpty returns an error if dir exists and is not empty.
+func checkEmpty(dir string) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("directory %s is not empty", dir)
+	}
+	return nil
+}
+
+// Create creates a new site in dir, which must not exist or be empty,
+// from the embedded template with the given name, or by cloning
+// the git repository if template is its URL.
+func Create(dir, template string) error {
+	if template == "" {
+		template = DefaultTemplate
+	}
+	if err := checkEmpty(dir); err != nil {
+		return err
+	}
+	if IsRemote(template) {
+		return clone(dir, template)
+	}
+	root := path.Join("templates", template)
+	if fi, err := fs.Stat(templates, root); err != nil || !fi.IsDir() {
+		return fmt.Errorf("unknown template %q (available: %s)", template, strings.Join(Names(), ", "))
+	}
+	log.Printf("* Creating %s site in %s", template, dir)
+	return fs.WalkDir(templates, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relname := strings.TrimPrefix(strings.TrimPrefix(name, root), "/")
+		if dotFiles[path.Base(relname)] {
+			relname = path.Join(path.Dir(relname), "."+path.Base(relname))
+		}
+		outname := filepath.Join(dir, filepath.FromSlash(relname))
+		if d.IsDir() {
+			return os.MkdirAll(outname, 0755)
+		}
+		b, err := templates.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		log.Printf("+ %s", relname)
+		return ioutil.WriteFile(outname, b, 0644)
+	})
+}
+
+// clone clones the git repository into dir without its history.
+func clone(dir, repo string) error {
+	log.Printf("* Creating site in %s from %s", dir, repo)
+	cmd, err := sandbox.NetworkCommand("git", "clone", "--depth", "1", "--", repo, dir)
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git clone: %w", err)
+	}
+	return os.RemoveAll(filepath.Join(dir, ".git"))
+}
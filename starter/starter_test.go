@@ -0,0 +1,49 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package starter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreate(t *testing.T) {
+	for _, name := range Names() {
+		dir, err := ioutil.TempDir("", "kkr-starter-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		if err := Create(dir, name); err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		for _, f := range []string{".gitignore", "site.yml", filepath.Join("themes", name, "theme.yml")} {
+			if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+				t.Errorf("%s: %s", name, err)
+			}
+		}
+		if err := Create(dir, name); err == nil {
+			t.Errorf("%s: expected error for non-empty directory", name)
+		}
+	}
+	if err := Create(filepath.Join(os.TempDir(), "kkr-starter-unknown"), "unknown"); err == nil {
+		t.Errorf("expected error for unknown template")
+	}
+}
+
+func TestIsRemote(t *testing.T) {
+	for template, remote := range map[string]bool{
+		"blog":                                 false,
+		"https://example.com/user/starter.git": true,
+		"git@example.com:user/starter":         true,
+		"../starter.git":                       true,
+	} {
+		if IsRemote(template) != remote {
+			t.Errorf("IsRemote(%q) = %v", template, !remote)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package state implements a persistent key/value store for data that
+// is generated during the first build and shouldn't change on rebuilds.
+//
+// Values are grouped into sections, for example "first_built" section
+// maps page URLs to the time they were first built.
+package state
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Store struct {
+	mu       sync.Mutex
+	filename string
+	sections map[string]map[string]interface{}
+	changed  bool
+}
+
+// Load loads a store from the given file. If the file
+// doesn't exist, it returns an empty store.
+func Load(filename string) (*Store, error) {
+	s := &Store{
+		filename: filename,
+		sections: make(map[string]map[string]interface{}),
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, &s.sections); err != nil {
+		return nil, err
+	}
+	if s.sections == nil {
+		// File was empty.
+		s.sections = make(map[string]map[string]interface{})
+	}
+	return s, nil
+}
+
+// Get returns a value for the key in the section or nil if it's not set.
+func (s *Store) Get(section, key string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sections[section][key]
+}
+
+// Set sets a value for the key in the section.
+func (s *Store) Set(section, key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(section, key, value)
+}
+
+func (s *Store) set(section, key string, value interface{}) {
+	m, ok := s.sections[section]
+	if !ok {
+		m = make(map[string]interface{})
+		s.sections[section] = m
+	}
+	m[key] = value
+	s.changed = true
+}
+
+// SetDefault returns a value for the key in the section. If the value
+// is not set, it sets it to the given value and returns it.
+func (s *Store) SetDefault(section, key string, value interface{}) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.sections[section][key]; ok {
+		return v
+	}
+	s.set(section, key, value)
+	return value
+}
+
+// Section returns a copy of values in the section.
+func (s *Store) Section(section string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := make(map[string]interface{}, len(s.sections[section]))
+	for k, v := range s.sections[section] {
+		m[k] = v
+	}
+	return m
+}
+
+// Save writes the store to its file if it was changed.
+// The file is replaced atomically.
+func (s *Store) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.changed {
+		return nil
+	}
+	b, err := yaml.Marshal(s.sections)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(s.filename), filepath.Base(s.filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), s.filename); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	s.changed = false
+	return nil
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package syndicate implements announcing posts on social networks
+// (Mastodon and Bluesky) via their APIs.
+package syndicate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Post is a post to announce.
+type Post struct {
+	ID    string // stable identifier, used to avoid duplicates
+	Title string
+	URL   string // absolute URL
+}
+
+// Poster announces posts on a social network.
+type Poster interface {
+	Name() string
+	// Post announces the post and returns URL of the announcement.
+	Post(p *Post) (string, error)
+}
+
+// Mastodon announces posts as statuses of a Mastodon account.
+type Mastodon struct {
+	Server string // e.g. https://mastodon.social
+	Token  string // access token with write:statuses scope
+
+	Client *http.Client
+}
+
+// Bluesky announces posts as Bluesky posts.
+type Bluesky struct {
+	Handle   string // e.g. example.bsky.social
+	Password string // app password
+
+	Service string // defaults to https://bsky.social
+	Client  *http.Client
+}
+
+const (
+	mastodonMaxChars = 500
+	blueskyMaxChars  = 300
+)
+
+// text returns announcement text: title and URL, with title
+// truncated to fit into maxChars.
+func text(p *Post, maxChars int) string {
+	title := p.Title
+	max := maxChars - utf8.RuneCountInString(p.URL) - 2
+	if utf8.RuneCountInString(title) > max {
+		r := []rune(title)
+		if max < 1 {
+			max = 1
+		}
+		title = strings.TrimSpace(string(r[:max-1])) + "…"
+	}
+	if title == "" {
+		return p.URL
+	}
+	return title + "\n\n" + p.URL
+}
+
+// do sends request and decodes JSON response into v if it's not nil.
+func do(client *http.Client, req *http.Request, v interface{}) error {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(b, v)
+}
+
+func (m *Mastodon) Name() string { return "Mastodon" }
+
+func (m *Mastodon) Post(p *Post) (string, error) {
+	form := url.Values{
+		"status":     {text(p, mastodonMaxChars)},
+		"visibility": {"public"},
+	}
+	req, err := http.NewRequest("POST", strings.TrimSuffix(m.Server, "/")+"/api/v1/statuses",
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+m.Token)
+	// Server ignores repeated requests with the same key,
+	// in case the announcement wasn't recorded.
+	req.Header.Set("Idempotency-Key", p.ID)
+	var status struct {
+		URL string `json:"url"`
+	}
+	if err := do(m.Client, req, &status); err != nil {
+		return "", fmt.Errorf("mastodon: %w", err)
+	}
+	return status.URL, nil
+}
+
+func (b *Bluesky) Name() string { return "Bluesky" }
+
+func (b *Bluesky) xrpc(method, token string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	service := b.Service
+	if service == "" {
+		service = "https://bsky.social"
+	}
+	req, err := http.NewRequest("POST", strings.TrimSuffix(service, "/")+"/xrpc/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if err := do(b.Client, req, out); err != nil {
+		return fmt.Errorf("bluesky %s: %w", method, err)
+	}
+	return nil
+}
+
+type blueskyFacet struct {
+	Index struct {
+		ByteStart int `json:"byteStart"`
+		ByteEnd   int `json:"byteEnd"`
+	} `json:"index"`
+	Features []map[string]string `json:"features"`
+}
+
+func (b *Bluesky) Post(p *Post) (string, error) {
+	var session struct {
+		AccessJwt string `json:"accessJwt"`
+		DID       string `json:"did"`
+	}
+	err := b.xrpc("com.atproto.server.createSession", "", map[string]string{
+		"identifier": b.Handle,
+		"password":   b.Password,
+	}, &session)
+	if err != nil {
+		return "", err
+	}
+	t := text(p, blueskyMaxChars)
+	// Links are not detected by Bluesky, they must be marked with facets.
+	var link blueskyFacet
+	link.Index.ByteStart = len(t) - len(p.URL)
+	link.Index.ByteEnd = len(t)
+	link.Features = []map[string]string{{"$type": "app.bsky.richtext.facet#link", "uri": p.URL}}
+	var res struct {
+		URI string `json:"uri"`
+	}
+	err = b.xrpc("com.atproto.repo.createRecord", session.AccessJwt, map[string]interface{}{
+		"repo":       session.DID,
+		"collection": "app.bsky.feed.post",
+		"record": map[string]interface{}{
+			"$type":     "app.bsky.feed.post",
+			"text":      t,
+			"createdAt": time.Now().UTC().Format(time.RFC3339),
+			"facets":    []blueskyFacet{link},
+		},
+	}, &res)
+	if err != nil {
+		return "", err
+	}
+	// Convert at://<did>/app.bsky.feed.post/<rkey> to web URL.
+	rkey := res.URI[strings.LastIndex(res.URI, "/")+1:]
+	return "https://bsky.app/profile/" + session.DID + "/post/" + rkey, nil
+}
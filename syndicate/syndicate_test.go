@@ -0,0 +1,94 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package syndicate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var testPost = &Post{ID: "abc", Title: "Hello", URL: "https://example.com/hello/"}
+
+func TestText(t *testing.T) {
+	if s := text(testPost, 100); s != "Hello\n\nhttps://example.com/hello/" {
+		t.Errorf("text: %q", s)
+	}
+	p := &Post{Title: strings.Repeat("й", 400), URL: testPost.URL}
+	s := text(p, 300)
+	if n := utf8.RuneCountInString(s); n != 300 {
+		t.Errorf("length: %d", n)
+	}
+	if !strings.HasSuffix(s, "…\n\n"+p.URL) {
+		t.Errorf("text: %q", s)
+	}
+}
+
+func TestMastodon(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/statuses" || r.Header.Get("Authorization") != "Bearer tok" ||
+			r.Header.Get("Idempotency-Key") != "abc" || r.FormValue("status") != text(testPost, mastodonMaxChars) {
+			http.Error(w, "bad request", 400)
+			return
+		}
+		w.Write([]byte(`{"id":"1","url":"https://mastodon.example/@me/1"}`))
+	}))
+	defer ts.Close()
+	m := &Mastodon{Server: ts.URL + "/", Token: "tok"}
+	u, err := m.Post(testPost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != "https://mastodon.example/@me/1" {
+		t.Errorf("URL: %q", u)
+	}
+	m.Token = "wrong"
+	if _, err := m.Post(testPost); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestBluesky(t *testing.T) {
+	var record struct {
+		Text   string
+		Facets []blueskyFacet
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/xrpc/com.atproto.server.createSession":
+			w.Write([]byte(`{"accessJwt":"jwt","did":"did:plc:me"}`))
+		case "/xrpc/com.atproto.repo.createRecord":
+			if r.Header.Get("Authorization") != "Bearer jwt" {
+				http.Error(w, "unauthorized", 401)
+				return
+			}
+			var req struct{ Record json.RawMessage }
+			json.NewDecoder(r.Body).Decode(&req)
+			json.Unmarshal(req.Record, &record)
+			w.Write([]byte(`{"uri":"at://did:plc:me/app.bsky.feed.post/xyz","cid":"c"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	b := &Bluesky{Handle: "me", Password: "pw", Service: ts.URL}
+	u, err := b.Post(testPost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != "https://bsky.app/profile/did:plc:me/post/xyz" {
+		t.Errorf("URL: %q", u)
+	}
+	if len(record.Facets) != 1 {
+		t.Fatalf("facets: %v", record.Facets)
+	}
+	i := record.Facets[0].Index
+	if record.Text[i.ByteStart:i.ByteEnd] != testPost.URL {
+		t.Errorf("link facet: %q", record.Text[i.ByteStart:i.ByteEnd])
+	}
+}
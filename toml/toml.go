@@ -0,0 +1,606 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package toml implements decoding of TOML documents into maps,
+// sufficient for reading front matter of pages.
+//
+// Values are decoded as string, int, float64, bool, time.Time
+// (offset and local date-times and dates), []interface{} and
+// map[string]interface{}. Local times are decoded as strings.
+package toml
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Unmarshal decodes TOML document into a map.
+func Unmarshal(data []byte) (map[string]interface{}, error) {
+	p := &parser{s: string(data), line: 1}
+	root := make(map[string]interface{})
+	if err := p.parse(root); err != nil {
+		return nil, fmt.Errorf("toml: line %d: %w", p.line, err)
+	}
+	normalize(root)
+	return root, nil
+}
+
+// normalize replaces arrays of tables with []interface{}.
+func normalize(t map[string]interface{}) {
+	for k, v := range t {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			normalize(v)
+		case []map[string]interface{}:
+			list := make([]interface{}, len(v))
+			for i, m := range v {
+				normalize(m)
+				list[i] = m
+			}
+			t[k] = list
+		case []interface{}:
+			for _, e := range v {
+				if m, ok := e.(map[string]interface{}); ok {
+					normalize(m)
+				}
+			}
+		}
+	}
+}
+
+type parser struct {
+	s    string
+	pos  int
+	line int
+
+	// Tables defined with headers, to prevent redefining.
+	defined map[uintptr]bool
+}
+
+func (p *parser) eof() bool { return p.pos >= len(p.s) }
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *parser) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(p.s[p.pos:], prefix)
+}
+
+func (p *parser) advance(n int) {
+	for i := 0; i < n && p.pos < len(p.s); i++ {
+		if p.s[p.pos] == '\n' {
+			p.line++
+		}
+		p.pos++
+	}
+}
+
+// skipSpace skips spaces and tabs.
+func (p *parser) skipSpace() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// skipComment skips a comment till the end of line.
+func (p *parser) skipComment() {
+	if p.peek() == '#' {
+		for !p.eof() && p.peek() != '\n' {
+			p.pos++
+		}
+	}
+}
+
+// skipBlank skips whitespace, newlines and comments.
+func (p *parser) skipBlank() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\r', '\n':
+			p.advance(1)
+		case '#':
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+// endLine expects the end of line, optionally after a comment.
+func (p *parser) endLine() error {
+	p.skipSpace()
+	p.skipComment()
+	if p.hasPrefix("\r\n") {
+		p.advance(2)
+		return nil
+	}
+	if p.eof() || p.peek() == '\n' {
+		p.advance(1)
+		return nil
+	}
+	return fmt.Errorf("unexpected %q after value", p.peek())
+}
+
+func (p *parser) parse(root map[string]interface{}) error {
+	p.defined = make(map[uintptr]bool)
+	current := root
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return nil
+		}
+		if p.peek() == '[' {
+			t, err := p.parseTableHeader(root)
+			if err != nil {
+				return err
+			}
+			current = t
+		} else {
+			if err := p.parseKeyValue(current); err != nil {
+				return err
+			}
+		}
+		if err := p.endLine(); err != nil {
+			return err
+		}
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-'
+}
+
+// parseKey parses a dotted key.
+func (p *parser) parseKey() ([]string, error) {
+	var key []string
+	for {
+		p.skipSpace()
+		var part string
+		switch c := p.peek(); {
+		case c == '"':
+			s, err := p.parseBasicString()
+			if err != nil {
+				return nil, err
+			}
+			part = s
+		case c == '\'':
+			s, err := p.parseLiteralString()
+			if err != nil {
+				return nil, err
+			}
+			part = s
+		case isBareKeyChar(c):
+			start := p.pos
+			for !p.eof() && isBareKeyChar(p.peek()) {
+				p.pos++
+			}
+			part = p.s[start:p.pos]
+		default:
+			return nil, fmt.Errorf("expected key, got %q", c)
+		}
+		key = append(key, part)
+		p.skipSpace()
+		if p.peek() != '.' {
+			return key, nil
+		}
+		p.pos++
+	}
+}
+
+// descend returns the table at the key path, creating missing tables.
+// The last element of arrays of tables is used.
+func descend(t map[string]interface{}, path []string) (map[string]interface{}, error) {
+	for _, k := range path {
+		switch v := t[k].(type) {
+		case nil:
+			m := make(map[string]interface{})
+			t[k] = m
+			t = m
+		case map[string]interface{}:
+			t = v
+		case []map[string]interface{}:
+			t = v[len(v)-1]
+		default:
+			return nil, fmt.Errorf("key %q is not a table", k)
+		}
+	}
+	return t, nil
+}
+
+func (p *parser) parseTableHeader(root map[string]interface{}) (map[string]interface{}, error) {
+	array := p.hasPrefix("[[")
+	if array {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+	key, err := p.parseKey()
+	if err != nil {
+		return nil, err
+	}
+	if array {
+		if !p.hasPrefix("]]") {
+			return nil, errors.New("expected ]] after array of tables")
+		}
+		p.pos += 2
+	} else {
+		if p.peek() != ']' {
+			return nil, errors.New("expected ] after table")
+		}
+		p.pos++
+	}
+	parent, err := descend(root, key[:len(key)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := key[len(key)-1]
+	if array {
+		m := make(map[string]interface{})
+		switch v := parent[last].(type) {
+		case nil:
+			parent[last] = []map[string]interface{}{m}
+		case []map[string]interface{}:
+			parent[last] = append(v, m)
+		default:
+			return nil, fmt.Errorf("key %q is not an array of tables", last)
+		}
+		return m, nil
+	}
+	t, err := descend(parent, []string{last})
+	if err != nil {
+		return nil, err
+	}
+	ptr := reflect.ValueOf(t).Pointer()
+	if p.defined[ptr] {
+		return nil, fmt.Errorf("table %q defined twice", strings.Join(key, "."))
+	}
+	p.defined[ptr] = true
+	return t, nil
+}
+
+func (p *parser) parseKeyValue(t map[string]interface{}) error {
+	key, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	if p.peek() != '=' {
+		return fmt.Errorf("expected = after key %q", strings.Join(key, "."))
+	}
+	p.pos++
+	p.skipSpace()
+	v, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	t, err = descend(t, key[:len(key)-1])
+	if err != nil {
+		return err
+	}
+	last := key[len(key)-1]
+	if _, ok := t[last]; ok {
+		return fmt.Errorf("key %q defined twice", strings.Join(key, "."))
+	}
+	t[last] = v
+	return nil
+}
+
+func (p *parser) parseValue() (interface{}, error) {
+	switch c := p.peek(); {
+	case p.hasPrefix(`"""`):
+		return p.parseMultilineBasicString()
+	case p.hasPrefix("'''"):
+		return p.parseMultilineLiteralString()
+	case c == '"':
+		return p.parseBasicString()
+	case c == '\'':
+		return p.parseLiteralString()
+	case c == '[':
+		return p.parseArray()
+	case c == '{':
+		return p.parseInlineTable()
+	case p.hasPrefix("true"):
+		p.pos += 4
+		return true, nil
+	case p.hasPrefix("false"):
+		p.pos += 5
+		return false, nil
+	case c == 0:
+		return nil, errors.New("missing value")
+	}
+	// Number or date: read until delimiter.
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if c == ',' || c == ']' || c == '}' || c == '#' || c == '\n' || c == '\r' || c == '\t' {
+			break
+		}
+		// Space is allowed between date and time.
+		if c == ' ' && !(p.pos-start == 10 && p.pos+1 < len(p.s) && isDigit(p.s[p.pos+1]) && isDate(p.s[start:p.pos])) {
+			break
+		}
+		p.pos++
+	}
+	return parseScalar(p.s[start:p.pos])
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func isDate(s string) bool {
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
+
+var dateFormats = []struct {
+	layout string
+	local  bool
+}{
+	{"2006-01-02T15:04:05.999999999Z07:00", false},
+	{"2006-01-02 15:04:05.999999999Z07:00", false},
+	{"2006-01-02T15:04:05.999999999", true},
+	{"2006-01-02 15:04:05.999999999", true},
+	{"2006-01-02", true},
+}
+
+func parseScalar(s string) (interface{}, error) {
+	if s == "" {
+		return nil, errors.New("missing value")
+	}
+	if len(s) >= 10 && s[4] == '-' && isDate(s[:10]) {
+		for _, f := range dateFormats {
+			var t time.Time
+			var err error
+			if f.local {
+				t, err = time.ParseInLocation(f.layout, s, time.Local)
+			} else {
+				t, err = time.Parse(f.layout, s)
+			}
+			if err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid date %q", s)
+	}
+	if len(s) >= 8 && s[2] == ':' {
+		if _, err := time.Parse("15:04:05.999999999", s); err == nil {
+			return s, nil // local time
+		}
+	}
+	switch strings.TrimLeft(s, "+-") {
+	case "inf":
+		if s[0] == '-' {
+			return math.Inf(-1), nil
+		}
+		return math.Inf(1), nil
+	case "nan":
+		return math.NaN(), nil
+	}
+	if strings.Contains(s, "__") || strings.HasPrefix(s, "_") || strings.HasSuffix(s, "_") {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	n := strings.ReplaceAll(s, "_", "")
+	for _, prefix := range []struct {
+		s    string
+		base int
+	}{{"0x", 16}, {"0o", 8}, {"0b", 2}} {
+		if strings.HasPrefix(n, prefix.s) {
+			v, err := strconv.ParseInt(n[2:], prefix.base, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", s)
+			}
+			return int(v), nil
+		}
+	}
+	if v, err := strconv.ParseInt(n, 10, 64); err == nil {
+		digits := strings.TrimLeft(n, "+-")
+		if len(digits) > 1 && digits[0] == '0' {
+			return nil, fmt.Errorf("leading zero in %q", s)
+		}
+		return int(v), nil
+	}
+	if v, err := strconv.ParseFloat(n, 64); err == nil && !strings.ContainsAny(n, "xXpP") {
+		return v, nil
+	}
+	return nil, fmt.Errorf("invalid value %q", s)
+}
+
+func (p *parser) parseEscape(b *strings.Builder) error {
+	p.pos++ // backslash
+	if p.eof() {
+		return errors.New("unterminated escape")
+	}
+	c := p.peek()
+	p.pos++
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case 'e':
+		b.WriteByte(0x1b)
+	case '"':
+		b.WriteByte('"')
+	case '\\':
+		b.WriteByte('\\')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.s) {
+			return errors.New("short unicode escape")
+		}
+		v, err := strconv.ParseUint(p.s[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(v)) {
+			return fmt.Errorf("invalid unicode escape %q", p.s[p.pos:p.pos+n])
+		}
+		b.WriteRune(rune(v))
+		p.pos += n
+	default:
+		return fmt.Errorf("invalid escape \\%c", c)
+	}
+	return nil
+}
+
+func (p *parser) parseBasicString() (string, error) {
+	p.pos++ // opening quote
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", errors.New("unterminated string")
+		}
+		switch c := p.peek(); c {
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\\':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *parser) parseLiteralString() (string, error) {
+	p.pos++ // opening quote
+	i := strings.IndexAny(p.s[p.pos:], "'\n")
+	if i < 0 || p.s[p.pos+i] != '\'' {
+		return "", errors.New("unterminated string")
+	}
+	s := p.s[p.pos : p.pos+i]
+	p.pos += i + 1
+	return s, nil
+}
+
+// trimFirstNewline removes a newline immediately following
+// the opening delimiter of multiline strings.
+func (p *parser) trimFirstNewline() {
+	if p.hasPrefix("\r\n") {
+		p.advance(2)
+	} else if p.hasPrefix("\n") {
+		p.advance(1)
+	}
+}
+
+func (p *parser) parseMultilineBasicString() (string, error) {
+	p.pos += 3
+	p.trimFirstNewline()
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", errors.New("unterminated string")
+		}
+		if p.hasPrefix(`"""`) {
+			p.pos += 3
+			// Up to two additional quotes are part of the string.
+			for i := 0; i < 2 && p.peek() == '"'; i++ {
+				b.WriteByte('"')
+				p.pos++
+			}
+			return b.String(), nil
+		}
+		if p.peek() == '\\' {
+			// Line ending backslash trims whitespace and newlines.
+			rest := strings.TrimLeft(p.s[p.pos+1:], " \t\r")
+			if strings.HasPrefix(rest, "\n") {
+				p.advance(len(p.s[p.pos:]) - len(rest))
+				for !p.eof() && strings.IndexByte(" \t\r\n", p.peek()) >= 0 {
+					p.advance(1)
+				}
+				continue
+			}
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+			continue
+		}
+		b.WriteByte(p.peek())
+		p.advance(1)
+	}
+}
+
+func (p *parser) parseMultilineLiteralString() (string, error) {
+	p.pos += 3
+	p.trimFirstNewline()
+	i := strings.Index(p.s[p.pos:], "'''")
+	if i < 0 {
+		return "", errors.New("unterminated string")
+	}
+	// Up to two additional quotes are part of the string.
+	for j := 0; j < 2 && p.pos+i+3 < len(p.s) && p.s[p.pos+i+3] == '\''; j++ {
+		i++
+	}
+	s := p.s[p.pos : p.pos+i]
+	p.advance(i + 3)
+	return s, nil
+}
+
+func (p *parser) parseArray() ([]interface{}, error) {
+	p.pos++ // [
+	list := []interface{}{}
+	for {
+		p.skipBlank()
+		if p.peek() == ']' {
+			p.pos++
+			return list, nil
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return list, nil
+		default:
+			return nil, errors.New("expected , or ] in array")
+		}
+	}
+}
+
+func (p *parser) parseInlineTable() (map[string]interface{}, error) {
+	p.pos++ // {
+	t := make(map[string]interface{})
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.pos++
+		return t, nil
+	}
+	for {
+		if err := p.parseKeyValue(t); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return t, nil
+		default:
+			return nil, errors.New("expected , or } in inline table")
+		}
+	}
+}
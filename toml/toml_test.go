@@ -0,0 +1,115 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package toml
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const doc = `# Front matter
+title = "Hello \"world\" \u00e9"
+path = 'C:\Users\x'
+draft = false
+weight = 1_000
+hex = 0xff
+ratio = -1.5e2
+date = 2019-01-02T10:20:30Z
+local = 2019-01-02 10:20:30
+day = 2019-01-02
+at = 07:32:00
+tags = [ "a", 'b',
+  "c", # comment
+]
+nested = [[1, 2], ["x"]]
+point = { x = 1, y.z = "deep" }
+"quoted key" = true
+site.name = "dotted"
+text = """
+Line one \
+  continued
+Line two"""
+raw = '''
+no \escapes'''
+
+[params]
+  author = "Me"
+
+[params.social]
+  github = "me"
+
+[[links]]
+  url = "/a"
+
+[[links]]
+  url = "/b"
+`
+
+func TestUnmarshal(t *testing.T) {
+	m, err := Unmarshal([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"title":      `Hello "world" é`,
+		"path":       `C:\Users\x`,
+		"draft":      false,
+		"weight":     1000,
+		"hex":        255,
+		"ratio":      -150.0,
+		"date":       time.Date(2019, 1, 2, 10, 20, 30, 0, time.UTC),
+		"local":      time.Date(2019, 1, 2, 10, 20, 30, 0, time.Local),
+		"day":        time.Date(2019, 1, 2, 0, 0, 0, 0, time.Local),
+		"at":         "07:32:00",
+		"tags":       []interface{}{"a", "b", "c"},
+		"nested":     []interface{}{[]interface{}{1, 2}, []interface{}{"x"}},
+		"point":      map[string]interface{}{"x": 1, "y": map[string]interface{}{"z": "deep"}},
+		"quoted key": true,
+		"site":       map[string]interface{}{"name": "dotted"},
+		"text":       "Line one continued\nLine two",
+		"raw":        `no \escapes`,
+		"params": map[string]interface{}{
+			"author": "Me",
+			"social": map[string]interface{}{"github": "me"},
+		},
+		"links": []interface{}{
+			map[string]interface{}{"url": "/a"},
+			map[string]interface{}{"url": "/b"},
+		},
+	}
+	for k, v := range want {
+		got := m[k]
+		if tm, ok := v.(time.Time); ok {
+			if gt, ok := got.(time.Time); !ok || !gt.Equal(tm) {
+				t.Errorf("%s: got %v, want %v", k, got, v)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got, v) {
+			t.Errorf("%s: got %#v, want %#v", k, got, v)
+		}
+	}
+	if len(m) != len(want) {
+		t.Errorf("got %d keys, want %d", len(m), len(want))
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, s := range []string{
+		`a = `,
+		`a = "unterminated`,
+		`a = 1` + "\n" + `a = 2`,
+		"[t]\n[t]",
+		`a = 1 b = 2`,
+		`a = 012`,
+		`a = [1, 2`,
+		`= 1`,
+	} {
+		if _, err := Unmarshal([]byte(s)); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typography
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	softHyphen = "&shy;"
+
+	// Minimum number of characters before and after hyphen.
+	leftMin  = 2
+	rightMin = 3
+)
+
+// Patterns contains hyphenation patterns for a language
+// (see Franklin Liang's "Word Hy-phen-a-tion by Com-put-er").
+type Patterns struct {
+	patterns   map[string][]byte // letters -> digits between them
+	exceptions map[string][]int  // word -> hyphen positions
+	maxLen     int               // maximum pattern length in runes
+}
+
+// ParsePatterns parses hyphenation patterns in TeX format, such as
+// hyph-*.pat.txt files from hyph-utf8 project. Patterns are separated
+// with spaces or newlines, % starts a comment. Hyphenation exceptions
+// can be given in \hyphenation{...} block, for example, "ta-ble".
+func ParsePatterns(data []byte) (*Patterns, error) {
+	p := &Patterns{
+		patterns:   make(map[string][]byte),
+		exceptions: make(map[string][]int),
+	}
+	exceptions := false
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.IndexByte(line, '%'); i >= 0 {
+			line = line[:i]
+		}
+		for _, f := range strings.Fields(line) {
+			switch {
+			case strings.HasPrefix(f, `\patterns{`):
+				exceptions = false
+				f = f[len(`\patterns{`):]
+			case strings.HasPrefix(f, `\hyphenation{`):
+				exceptions = true
+				f = f[len(`\hyphenation{`):]
+			}
+			f = strings.TrimSuffix(f, "}")
+			if f == "" {
+				continue
+			}
+			if exceptions {
+				p.addException(f)
+			} else if err := p.addPattern(f); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return p, nil
+}
+
+func (p *Patterns) addPattern(pat string) error {
+	var letters []rune
+	var digits []byte
+	prevDigit := false
+	for _, r := range pat {
+		if r >= '0' && r <= '9' {
+			if prevDigit {
+				return fmt.Errorf("bad hyphenation pattern %q", pat)
+			}
+			digits = append(digits, byte(r-'0'))
+			prevDigit = true
+			continue
+		}
+		if !prevDigit {
+			digits = append(digits, 0)
+		}
+		letters = append(letters, unicode.ToLower(r))
+		prevDigit = false
+	}
+	if !prevDigit {
+		digits = append(digits, 0)
+	}
+	p.patterns[string(letters)] = digits
+	if len(letters) > p.maxLen {
+		p.maxLen = len(letters)
+	}
+	return nil
+}
+
+func (p *Patterns) addException(word string) {
+	var positions []int
+	n := 0
+	for _, r := range word {
+		if r == '-' {
+			positions = append(positions, n)
+			continue
+		}
+		n++
+	}
+	p.exceptions[strings.ToLower(strings.Replace(word, "-", "", -1))] = positions
+}
+
+// Hyphenate returns positions (in runes) at which the word can be hyphenated.
+func (p *Patterns) Hyphenate(word string) []int {
+	lower := []rune(strings.ToLower(word))
+	if positions, ok := p.exceptions[string(lower)]; ok {
+		return positions
+	}
+	if len(lower) < leftMin+rightMin {
+		return nil
+	}
+	w := make([]rune, 0, len(lower)+2)
+	w = append(w, '.')
+	w = append(w, lower...)
+	w = append(w, '.')
+	points := make([]byte, len(w)+1)
+	for i := range w {
+		for j := i + 1; j <= len(w) && j-i <= p.maxLen; j++ {
+			digits, ok := p.patterns[string(w[i:j])]
+			if !ok {
+				continue
+			}
+			for k, d := range digits {
+				if d > points[i+k] {
+					points[i+k] = d
+				}
+			}
+		}
+	}
+	var positions []int
+	// points[i+1] is a value before w[i], which is lower[i-1].
+	for i := leftMin; i <= len(lower)-rightMin; i++ {
+		if points[i+1]%2 == 1 {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+// hyphenateSkipAtoms contains elements, text inside which is not hyphenated.
+var hyphenateSkipAtoms = map[atom.Atom]bool{atom.Title: true}
+
+func init() {
+	for k, v := range skipAtoms {
+		hyphenateSkipAtoms[k] = v
+	}
+}
+
+// Hyphenate inserts soft hyphens into words that are at least
+// minLength characters long in text of the HTML document.
+func Hyphenate(in []byte, p *Patterns, minLength int) ([]byte, error) {
+	return transformText(in, func(text []byte, block bool) []byte {
+		return p.hyphenateText(text, minLength)
+	}, hyphenateSkipAtoms)
+}
+
+func (p *Patterns) hyphenateText(text []byte, minLength int) []byte {
+	var out bytes.Buffer
+	for len(text) > 0 {
+		r, size := utf8.DecodeRune(text)
+		if r == '&' {
+			// Copy entity.
+			if i := bytes.IndexByte(text, ';'); i > 0 && i < 32 {
+				size = i + 1
+			}
+			out.Write(text[:size])
+			text = text[size:]
+			continue
+		}
+		if !unicode.IsLetter(r) {
+			out.Write(text[:size])
+			text = text[size:]
+			continue
+		}
+		// Find word.
+		end := 0
+		for end < len(text) {
+			r, size := utf8.DecodeRune(text[end:])
+			if !unicode.IsLetter(r) {
+				break
+			}
+			end += size
+		}
+		word := string(text[:end])
+		text = text[end:]
+		if utf8.RuneCountInString(word) < minLength {
+			out.WriteString(word)
+			continue
+		}
+		positions := p.Hyphenate(word)
+		n := 0
+		for _, r := range word {
+			if len(positions) > 0 && positions[0] == n {
+				out.WriteString(softHyphen)
+				positions = positions[1:]
+			}
+			out.WriteRune(r)
+			n++
+		}
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,40 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typography
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Patterns from Liang's thesis for the word "hyphenation".
+const testPatterns = `
+% comment
+\patterns{
+hy3ph he2n hena4 hen5at 1na n2at 1tio 2io o2n
+}
+\hyphenation{ta-ble}
+`
+
+func TestHyphenate(t *testing.T) {
+	p, err := ParsePatterns([]byte(testPatterns))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pos := p.Hyphenate("Hyphenation"); !reflect.DeepEqual(pos, []int{2, 6}) {
+		t.Errorf("hyphenation: got %v", pos)
+	}
+	if pos := p.Hyphenate("table"); !reflect.DeepEqual(pos, []int{2}) {
+		t.Errorf("table: got %v", pos)
+	}
+	out, err := Hyphenate([]byte(`<title>Hyphenation</title><p>Hyphenation &hellip; <code>hyphenation</code></p>`), p, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<title>Hyphenation</title><p>Hy&shy;phen&shy;ation &hellip; <code>hyphenation</code></p>`
+	if string(out) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, out)
+	}
+}
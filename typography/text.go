@@ -0,0 +1,84 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typography
+
+import (
+	"bytes"
+	"io"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// skipAtoms contains elements, text inside which is not transformed.
+var skipAtoms = map[atom.Atom]bool{
+	atom.Pre: true, atom.Code: true, atom.Kbd: true, atom.Samp: true,
+	atom.Var: true, atom.Script: true, atom.Style: true, atom.Textarea: true,
+	atom.Svg: true, atom.Math: true, atom.Noscript: true, // noscript content is raw text
+}
+
+// blockAtoms contains elements, which start or end a block of text.
+var blockAtoms = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.H1: true, atom.H2: true, atom.H3: true,
+	atom.H4: true, atom.H5: true, atom.H6: true, atom.Li: true, atom.Dt: true,
+	atom.Dd: true, atom.Blockquote: true, atom.Td: true, atom.Th: true,
+	atom.Br: true, atom.Figcaption: true, atom.Title: true, atom.Section: true,
+	atom.Article: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+}
+
+// TextFunc transforms raw HTML text. Block is true if the text
+// is the first text after start or end of a block element.
+type TextFunc func(text []byte, block bool) []byte
+
+// TransformText calls fn for every text node of the HTML document
+// outside of preformatted, code, script and similar elements,
+// replacing the text with the result. Everything else is copied
+// without changes.
+func TransformText(in []byte, fn TextFunc) ([]byte, error) {
+	return transformText(in, fn, skipAtoms)
+}
+
+func transformText(in []byte, fn TextFunc, skipAtoms map[atom.Atom]bool) ([]byte, error) {
+	var out bytes.Buffer
+	out.Grow(len(in))
+	z := html.NewTokenizer(bytes.NewReader(in))
+	skip := 0
+	block := true
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			return out.Bytes(), nil
+		}
+		raw := z.Raw()
+		switch tt {
+		case html.TextToken:
+			if skip == 0 {
+				out.Write(fn(raw, block))
+				block = false
+				continue
+			}
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			a := atom.Lookup(name)
+			if skipAtoms[a] {
+				switch tt {
+				case html.StartTagToken:
+					skip++
+				case html.EndTagToken:
+					if skip > 0 {
+						skip--
+					}
+				}
+			}
+			if blockAtoms[a] {
+				block = true
+			}
+		}
+		out.Write(raw)
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package typography implements typographic transformations
+// of HTML text: smart quotes, dashes, ellipses, non-breaking
+// spaces, hanging punctuation, and hyphenation.
+package typography
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	nbsp       = '\u00a0'
+	narrowNbsp = '\u202f'
+)
+
+// site.yml -> typography:
+type Options struct {
+	Lang    string `yaml:"lang"`    // default language, pages can override it with `lang` meta
+	Hanging bool   `yaml:"hanging"` // mark opening quotes for hanging punctuation
+}
+
+type quotes struct {
+	open, close, innerOpen, innerClose string
+}
+
+type rules struct {
+	quotes quotes
+	// spacedDash is a replacement for a hyphen surrounded by spaces.
+	spacedDash string
+	// shortWords is true if spaces after one- and two-letter
+	// words should be non-breaking.
+	shortWords bool
+	// particles that are attached to the previous word
+	// with a non-breaking space.
+	particles []string
+	// frenchSpacing is true if non-breaking spaces should be
+	// inserted before ; : ! ? signs.
+	frenchSpacing bool
+}
+
+var langRules = map[string]*rules{
+	"en": {
+		quotes:     quotes{"“", "”", "‘", "’"},
+		spacedDash: " — ",
+	},
+	"de": {
+		quotes:     quotes{"„", "“", "‚", "‘"},
+		spacedDash: " – ",
+	},
+	"ru": {
+		quotes:     quotes{"«", "»", "„", "“"},
+		spacedDash: "\u00a0— ",
+		shortWords: true,
+		particles:  []string{"же", "ли", "ль", "бы", "б"},
+	},
+	"uk": {
+		quotes:     quotes{"«", "»", "„", "“"},
+		spacedDash: "\u00a0— ",
+		shortWords: true,
+	},
+	"fr": {
+		quotes:        quotes{"«\u00a0", "\u00a0»", "“", "”"},
+		spacedDash:    "\u00a0— ",
+		shortWords:    true,
+		frenchSpacing: true,
+	},
+}
+
+// Classes for hanging punctuation. Styles should shift pulled quotes
+// to the left and compensate with the width of push spaces, e.g.:
+//
+//	.kkr-pull { margin-left: -0.45em }
+//	.kkr-push { margin-right: 0.45em }
+const (
+	PullClass = "kkr-pull"
+	PushClass = "kkr-push"
+)
+
+// IsSupported returns true if there are rules for the language.
+func IsSupported(lang string) bool {
+	return langRules[baseLang(lang)] != nil
+}
+
+// baseLang returns language code without region, e.g. "en" for "en-US".
+func baseLang(lang string) string {
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	return strings.ToLower(lang)
+}
+
+// transformer keeps state between text nodes of a document.
+type transformer struct {
+	r       *rules
+	hanging bool
+	prev    rune // previous character
+	depth   int  // nesting level of double quotes
+}
+
+// Process applies typographic rules for the language to text in HTML.
+// If there are no rules for the language, English rules are used.
+func Process(in []byte, lang string, hanging bool) ([]byte, error) {
+	r := langRules[baseLang(lang)]
+	if r == nil {
+		r = langRules["en"]
+	}
+	t := &transformer{r: r, hanging: hanging, prev: ' '}
+	return TransformText(in, t.transform)
+}
+
+func isOpeningContext(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("([{-—–/«„“‘‚", r)
+}
+
+func (t *transformer) openQuote(out *bytes.Buffer, q string) {
+	if !t.hanging {
+		out.WriteString(q)
+		return
+	}
+	if t.prev == ' ' && bytes.HasSuffix(out.Bytes(), []byte(" ")) {
+		// Replace space with push span.
+		out.Truncate(out.Len() - 1)
+		out.WriteString(`<span class="` + PushClass + `"> </span>`)
+	}
+	out.WriteString(`<span class="` + PullClass + `">` + q + `</span>`)
+}
+
+func (t *transformer) transform(text []byte, block bool) []byte {
+	if block {
+		t.prev = ' '
+		t.depth = 0
+	}
+	var out bytes.Buffer
+	before := t.prev // character before this text
+	q := t.r.quotes
+	s := string(text)
+	for i := 0; i < len(s); {
+		c, size := utf8.DecodeRuneInString(s[i:])
+		rest := s[i+size:]
+		next, _ := utf8.DecodeRuneInString(rest)
+		switch {
+		case c == '"':
+			if isOpeningContext(t.prev) {
+				t.depth++
+				if t.depth == 1 {
+					t.openQuote(&out, q.open)
+				} else {
+					t.openQuote(&out, q.innerOpen)
+				}
+			} else {
+				if t.depth > 1 {
+					out.WriteString(q.innerClose)
+				} else {
+					out.WriteString(q.close)
+				}
+				if t.depth > 0 {
+					t.depth--
+				}
+			}
+		case c == '\'':
+			switch {
+			case unicode.IsLetter(t.prev) || unicode.IsDigit(t.prev) || unicode.IsPunct(t.prev) && !isOpeningContext(t.prev):
+				out.WriteString("’") // apostrophe or closing quote
+			case t.r.quotes.innerClose == "’":
+				out.WriteString(q.innerOpen)
+			case unicode.IsLetter(next):
+				out.WriteString("’") // elision, e.g. 'em
+			default:
+				out.WriteRune(c)
+			}
+		case c == '-' && strings.HasPrefix(rest, "--"):
+			out.WriteString("—")
+			size += 2
+			c = '—'
+		case c == '-' && unicode.IsSpace(t.prev) && strings.HasPrefix(rest, "- "):
+			if r, n := utf8.DecodeLastRune(out.Bytes()); unicode.IsSpace(r) {
+				out.Truncate(out.Len() - n)
+			}
+			out.WriteString(t.r.spacedDash)
+			size += 2
+			c = ' '
+		case c == '-' && strings.HasPrefix(rest, "-"):
+			out.WriteString("–")
+			size++
+			c = '–'
+		case c == '-' && unicode.IsSpace(t.prev) && strings.HasPrefix(rest, " "):
+			if r, n := utf8.DecodeLastRune(out.Bytes()); unicode.IsSpace(r) {
+				out.Truncate(out.Len() - n)
+			}
+			out.WriteString(t.r.spacedDash)
+			size++
+			c = ' '
+		case c == '.' && strings.HasPrefix(rest, ".."):
+			out.WriteString("…")
+			size += 2
+			c = '…'
+		case c == ' ' && t.r.frenchSpacing && strings.ContainsRune(";!?", next):
+			out.WriteRune(narrowNbsp)
+			c = narrowNbsp
+		case c == ' ' && t.r.frenchSpacing && next == ':':
+			out.WriteRune(nbsp)
+			c = nbsp
+		case c == ' ' && t.r.shortWords && isShortWord(out.Bytes(), before):
+			out.WriteRune(nbsp)
+			c = nbsp
+		case c == ' ' && hasParticle(rest, t.r.particles):
+			out.WriteRune(nbsp)
+			c = nbsp
+		default:
+			out.WriteRune(c)
+		}
+		t.prev = c
+		i += size
+	}
+	return out.Bytes()
+}
+
+// isShortWord returns true if text ends with a word of one or two letters.
+// The before character precedes the text.
+func isShortWord(text []byte, before rune) bool {
+	n := 0
+	for len(text) > 0 {
+		r, size := utf8.DecodeLastRune(text)
+		if !unicode.IsLetter(r) {
+			return n > 0 && (unicode.IsSpace(r) || r == '(' || r == ';')
+		}
+		n++
+		if n > 2 {
+			return false
+		}
+		text = text[:len(text)-size]
+	}
+	return n > 0 && !unicode.IsLetter(before)
+}
+
+// hasParticle returns true if s starts with one of the particles
+// followed by a non-letter.
+func hasParticle(s string, particles []string) bool {
+	for _, p := range particles {
+		if strings.HasPrefix(s, p) {
+			r, _ := utf8.DecodeRuneInString(s[len(p):])
+			if !unicode.IsLetter(r) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typography
+
+import "testing"
+
+func TestProcess(t *testing.T) {
+	var tests = []struct {
+		lang, in, out string
+	}{
+		{"en", `<p>"Hello," she said -- it's "fine"...</p>`, `<p>“Hello,” she said — it’s “fine”…</p>`},
+		{"en", `<p>He said "a <em>'nested'</em> one"</p>`, `<p>He said “a <em>‘nested’</em> one”</p>`},
+		{"en", `<p>pages 1--5 - or 6---7</p>`, `<p>pages 1–5 — or 6—7</p>`},
+		{"en", `<pre>"raw" -- text</pre><code>'a'</code>`, `<pre>"raw" -- text</pre><code>'a'</code>`},
+		{"en", `<a title="a -- b" href="x">"link"</a>`, `<a title="a -- b" href="x">“link”</a>`},
+		{"ru", `<p>Он сказал "привет" - и ушёл в дом.</p>`, "<p>Он\u00a0сказал «привет»\u00a0— и\u00a0ушёл в\u00a0дом.</p>"},
+		{"ru", `<p>"Цитата "внутри" цитаты"</p>`, `<p>«Цитата „внутри“ цитаты»</p>`},
+		{"ru", `<p>Я бы сказал же так</p>`, "<p>Я\u00a0бы\u00a0сказал\u00a0же\u00a0так</p>"},
+		{"fr", `<p>Il a dit "oui" : vraiment ?</p>`, "<p>Il\u00a0a\u00a0dit «\u00a0oui\u00a0»\u00a0: vraiment\u202f?</p>"},
+		{"de", `<p>"Ja"</p>`, `<p>„Ja“</p>`},
+		{"xx", `<p>"a"</p>`, `<p>“a”</p>`},
+	}
+	for i, v := range tests {
+		out, err := Process([]byte(v.in), v.lang, false)
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if string(out) != v.out {
+			t.Errorf("%d: expected\n%q\ngot\n%q", i, v.out, out)
+		}
+	}
+}
+
+func TestProcessHanging(t *testing.T) {
+	out, err := Process([]byte(`<p>"One" and "two"</p>`), "en", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<p><span class="kkr-pull">“</span>One” and<span class="kkr-push"> </span><span class="kkr-pull">“</span>two”</p>`
+	if string(out) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, out)
+	}
+}
@@ -7,6 +7,7 @@ package utils
 
 import (
 	"bytes"
+	"crypto/rand"
 	"crypto/sha256"
 	"fmt"
 	"html/template"
@@ -238,6 +239,16 @@ func NoVowelsHexEncode(b []byte) string {
 	return string(dst)
 }
 
+// NewUID returns a new random short identifier
+// encoded with NoVowelsHexEncode.
+func NewUID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		panic("rand: " + err.Error()) // shouldn't happen
+	}
+	return NoVowelsHexEncode(b)
+}
+
 // OpenURL opens URL in the operating system (probably in the default browser).
 func OpenURL(addr string) error {
 	if _, err := url.Parse(addr); err != nil {
@@ -0,0 +1,127 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package video implements extraction of video and audio metadata and
+// video poster frames using ffprobe and ffmpeg.
+package video
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/dchest/kkr/sandbox"
+)
+
+// Info contains video metadata.
+type Info struct {
+	Width    int     `json:"width"`
+	Height   int     `json:"height"`
+	Duration float64 `json:"duration"` // in seconds
+}
+
+func run(command string, args ...string) ([]byte, error) {
+	cmd, err := sandbox.Command(command, args...)
+	if err != nil {
+		return nil, err
+	}
+	var out, errbuf bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errbuf
+	if err := cmd.Run(); err != nil {
+		errbuf.WriteTo(os.Stderr)
+		return nil, fmt.Errorf("`%s` error: %s", command, err)
+	}
+	return out.Bytes(), nil
+}
+
+// Probe returns metadata of the video file using ffprobe command.
+func Probe(ffprobe, filename string) (*Info, error) {
+	out, err := run(ffprobe, "-v", "error", "-select_streams", "v:0",
+		"-show_entries", "stream=width,height:format=duration", "-of", "json", filename)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Streams []struct {
+			Width  int `json:"width"`
+			Height int `json:"height"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("%s: cannot parse ffprobe output: %w", filename, err)
+	}
+	if len(result.Streams) == 0 {
+		return nil, fmt.Errorf("%s: no video stream", filename)
+	}
+	info := &Info{
+		Width:  result.Streams[0].Width,
+		Height: result.Streams[0].Height,
+	}
+	if result.Format.Duration != "" {
+		info.Duration, err = strconv.ParseFloat(result.Format.Duration, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: bad duration: %w", filename, err)
+		}
+	}
+	return info, nil
+}
+
+// ProbeAudio returns duration of the audio file using ffprobe command.
+// Width and Height of the result are zero.
+func ProbeAudio(ffprobe, filename string) (*Info, error) {
+	out, err := run(ffprobe, "-v", "error", "-select_streams", "a:0",
+		"-show_entries", "stream=codec_type:format=duration", "-of", "json", filename)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("%s: cannot parse ffprobe output: %w", filename, err)
+	}
+	if len(result.Streams) == 0 {
+		return nil, fmt.Errorf("%s: no audio stream", filename)
+	}
+	if result.Format.Duration == "" {
+		return nil, fmt.Errorf("%s: unknown duration", filename)
+	}
+	info := &Info{}
+	info.Duration, err = strconv.ParseFloat(result.Format.Duration, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s: bad duration: %w", filename, err)
+	}
+	return info, nil
+}
+
+// Poster extracts a frame at the given time (in seconds) from the video
+// file into outfile using ffmpeg command. Image format is determined by
+// outfile extension.
+func Poster(ffmpeg, filename string, seconds float64, outfile string) error {
+	_, err := run(ffmpeg, "-v", "error", "-y", "-ss", strconv.FormatFloat(seconds, 'f', -1, 64),
+		"-i", filename, "-frames:v", "1", outfile)
+	return err
+}
+
+// FormatDuration returns duration in seconds formatted as
+// H:MM:SS or M:SS.
+func FormatDuration(seconds float64) string {
+	t := int(seconds + 0.5)
+	h, m, s := t/3600, t/60%60, t%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
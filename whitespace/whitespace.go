@@ -0,0 +1,69 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package whitespace collapses whitespace between tags in HTML.
+package whitespace
+
+import (
+	"bytes"
+	"io"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+func isSpace(b []byte) bool {
+	for _, c := range b {
+		switch c {
+		case ' ', '\t', '\n', '\r', '\f':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Collapse replaces each run of whitespace between tags with a newline,
+// if it contains one, or a space, which renders the same way. Text and
+// contents of <pre> and <textarea> elements are not changed.
+func Collapse(in []byte) ([]byte, error) {
+	var out bytes.Buffer
+	out.Grow(len(in))
+	z := html.NewTokenizer(bytes.NewReader(in))
+	preserve := 0 // depth of <pre> and <textarea>
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			return out.Bytes(), nil
+		case html.TextToken:
+			raw := z.Raw()
+			if preserve == 0 && len(raw) > 0 && isSpace(raw) {
+				if bytes.IndexByte(raw, '\n') >= 0 {
+					out.WriteByte('\n')
+				} else {
+					out.WriteByte(' ')
+				}
+				continue
+			}
+			out.Write(raw)
+		case html.StartTagToken, html.EndTagToken:
+			raw := z.Raw()
+			out.Write(raw)
+			name, _ := z.TagName()
+			if a := atom.Lookup(name); a == atom.Pre || a == atom.Textarea {
+				if tt == html.StartTagToken {
+					preserve++
+				} else if preserve > 0 {
+					preserve--
+				}
+			}
+		default:
+			out.Write(z.Raw())
+		}
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright 2026 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package whitespace
+
+import "testing"
+
+func TestCollapse(t *testing.T) {
+	in := "<ul>\n    <li>One  two</li>\t <li><b>A</b> <i>B</i></li>\n\n</ul>\n" +
+		"<pre>\n  <b>x</b>\n  </pre>  <textarea>  </textarea>"
+	expected := "<ul>\n<li>One  two</li> <li><b>A</b> <i>B</i></li>\n</ul>\n" +
+		"<pre>\n  <b>x</b>\n  </pre> <textarea>  </textarea>"
+	out, err := Collapse([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != expected {
+		t.Errorf("got\n%q\nexpected\n%q", out, expected)
+	}
+}